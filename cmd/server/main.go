@@ -25,10 +25,26 @@ import (
 	postgres "chat-service/internal/adapter"
 	"chat-service/internal/app"
 	"chat-service/internal/handler"
+	"chat-service/internal/health"
+	"chat-service/internal/metrics"
 	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase/admin"
+	"chat-service/internal/usecase/apikey"
+	"chat-service/internal/usecase/block"
+	"chat-service/internal/usecase/contentfilter"
+	"chat-service/internal/usecase/idempotency"
+	"chat-service/internal/usecase/loginguard"
 	"chat-service/internal/usecase/message"
+	"chat-service/internal/usecase/mfa"
+	"chat-service/internal/usecase/moderation"
+	"chat-service/internal/usecase/oidc"
+	"chat-service/internal/usecase/password"
+	"chat-service/internal/usecase/ratelimit"
+	"chat-service/internal/usecase/rbac"
 	"chat-service/internal/usecase/session"
 	"chat-service/internal/usecase/user"
+	"chat-service/internal/usecase/verification"
 	"chat-service/pkg/config"
 	"chat-service/pkg/logger"
 
@@ -55,18 +71,36 @@ func main() {
 	appLogger.Info("Starting chat service application")
 
 	// Load configuration
-	cfg, err := config.Load("")
+	cfg, configWatcher, err := config.Load("")
 	if err != nil {
 		appLogger.WithError(err).Fatal("failed to load configuration")
 	}
 
-	// Set logger level from config
-	level, err := logrus.ParseLevel(cfg.Logger.Level)
+	// Rebuild logger from config
+	configuredLogger, logCloser, err := logger.New(logger.Config{
+		Level:  cfg.Logger.Level,
+		Format: cfg.Logger.Format,
+		Output: cfg.Logger.Output,
+		File: logger.FileConfig{
+			Path:       cfg.Logger.File.Path,
+			MaxSizeMB:  cfg.Logger.File.MaxSizeMB,
+			MaxAge:     cfg.Logger.File.MaxAge,
+			MaxBackups: cfg.Logger.File.MaxBackups,
+			Compress:   cfg.Logger.File.Compress,
+		},
+		RedactFields: cfg.Logger.RedactFields,
+		EmailFields:  cfg.Logger.EmailFields,
+		Sampling: logger.SamplingConfig{
+			Initial:    cfg.Logger.Sampling.Initial,
+			Thereafter: cfg.Logger.Sampling.Thereafter,
+			Interval:   cfg.Logger.Sampling.Interval,
+		},
+	})
 	if err != nil {
-		appLogger.WithError(err).Warnf("invalid log level %s, using info", cfg.Logger.Level)
-		level = logrus.InfoLevel
+		appLogger.WithError(err).Fatal("failed to initialize logger")
 	}
-	appLogger.SetLevel(level)
+	defer logCloser.Close()
+	appLogger = configuredLogger
 
 	// Print configuration
 	cfg.Print()
@@ -81,25 +115,175 @@ func main() {
 		dbPool.Close()
 	}()
 
+	// Initialize tracing; spans are not recorded when it is disabled
+	shutdownTracing, err := initTracing(cfg, appLogger)
+	if err != nil {
+		appLogger.WithError(err).Fatal("failed to initialize tracing")
+	}
+
+	// Initialize metrics; nil disables collection and the /metrics endpoint
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		appMetrics.TrackPool(dbPool)
+	}
+
 	// Initialize adapters
-	dbAdapter := postgres.NewPostgresAdapter(dbPool, appLogger)
+	dbAdapter := postgres.NewPostgresAdapter(dbPool, appMetrics, appLogger)
 
 	// Initialize services
-	hashService := service.NewHashService(appLogger)
+	hashService := initHashService(cfg, appLogger)
 	jwtService := service.NewJWTService(cfg.JWT.SecretKey, appLogger)
+	totpService := service.NewTOTPService(appLogger)
+	passwordPolicy, err := initPasswordPolicy(cfg, appLogger)
+	if err != nil {
+		appLogger.WithError(err).Fatal("failed to initialize password policy")
+	}
+	mailer, err := initMailer(cfg, appLogger)
+	if err != nil {
+		appLogger.WithError(err).Fatal("failed to initialize mailer")
+	}
 
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(dbAdapter)
 	messageRepo := postgres.NewMessageRepository(dbAdapter)
 	sessionRepo := postgres.NewSessionRepository(dbAdapter)
+	if appMetrics != nil {
+		appMetrics.TrackActiveSessions(func(ctx context.Context) (int, error) {
+			return sessionRepo.CountActive(ctx, time.Now())
+		}, appLogger)
+	}
+	mfaRepo := postgres.NewMFARepository(dbAdapter)
+	passwordResetRepo := postgres.NewPasswordResetRepository(dbAdapter)
+	emailVerificationRepo := postgres.NewEmailVerificationRepository(dbAdapter)
+	loginAttemptRepo := postgres.NewLoginAttemptRepository(dbAdapter)
+	userIdentityRepo := postgres.NewUserIdentityRepository(dbAdapter)
+	oidcAuthRequestRepo := postgres.NewOIDCAuthRequestRepository(dbAdapter)
+	apiKeyRepo := postgres.NewAPIKeyRepository(dbAdapter)
+	reportRepo := postgres.NewReportRepository(dbAdapter)
+	moderationActionRepo := postgres.NewModerationActionRepository(dbAdapter)
+	contentFilterRuleRepo := postgres.NewContentFilterRuleRepository(dbAdapter)
+	userBlockRepo := postgres.NewUserBlockRepository(dbAdapter)
+
+	// Initialize content filter; rules are loaded from the database below
+	contentFilter := service.NewContentFilter(appLogger)
+	contentFilterUsecase := contentfilter.NewContentFilterUsecase(contentFilterRuleRepo, userRepo, contentFilter, appLogger)
+	var messageContentFilter service.ContentFilter
+	if cfg.ContentFilter.Enabled {
+		if err := contentFilterUsecase.Reload(context.Background()); err != nil {
+			appLogger.WithError(err).Fatal("failed to load content filter rules")
+		}
+		messageContentFilter = contentFilter
+	}
 
 	// Initialize usecases
-	userUsecase := user.NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, appLogger)
-	messageUsecase := message.NewMessageUsecase(messageRepo, userRepo, appLogger)
-	sessionUsecase := session.NewSessionUsecase(sessionRepo, jwtService, appLogger)
+	userUsecase := user.NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, appLogger)
+	runtimeSettings := cfg.RuntimeSettings()
+	messageUsecase := message.NewMessageUsecase(messageRepo, userRepo, reportRepo, userBlockRepo, messageContentFilter, messageConfig(runtimeSettings), appLogger)
+	sessionUsecase := session.NewSessionUsecase(sessionRepo, userRepo, jwtService, appLogger)
+	mfaUsecase := mfa.NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, mfa.Config{
+		Issuer:               cfg.MFA.Issuer,
+		ChallengeTTL:         cfg.MFA.ChallengeTTL,
+		RecoveryCodeCount:    cfg.MFA.RecoveryCodeCount,
+		MaxChallengeAttempts: cfg.MFA.MaxChallengeAttempts,
+	}, appLogger)
+	passwordUsecase := password.NewPasswordUsecase(userRepo, sessionRepo, passwordResetRepo, hashService, passwordPolicy, mailer, password.Config{
+		ResetTokenTTL: cfg.Password.ResetTokenTTL,
+		ResetURL:      cfg.Password.ResetURL,
+	}, appLogger)
+	verificationUsecase := verification.NewVerificationUsecase(userRepo, emailVerificationRepo, mailer, verification.Config{
+		TokenTTL:       cfg.Verification.TokenTTL,
+		ResendInterval: cfg.Verification.ResendInterval,
+		VerifyURL:      cfg.Verification.VerifyURL,
+	}, appLogger)
+	loginGuard := loginguard.NewLoginGuardUsecase(loginAttemptRepo, loginGuardConfig(runtimeSettings), appLogger)
+	oidcUsecase := oidc.NewOIDCUsecase(initOIDCProviders(cfg, appLogger), userRepo, userIdentityRepo, oidcAuthRequestRepo, hashService, oidc.Config{
+		StateTTL: cfg.OIDC.StateTTL,
+	}, appLogger)
+	apiKeyUsecase := apikey.NewAPIKeyUsecase(apiKeyRepo, userRepo, apikey.Config{
+		MaxKeysPerUser: cfg.APIKeys.MaxPerUser,
+		MaxTTL:         cfg.APIKeys.MaxTTL,
+	}, appLogger)
+
+	rbacUsecase := rbac.NewRBACUsecase(userRepo, appLogger)
+	if err := rbacUsecase.BootstrapAdmins(context.Background(), cfg.RBAC.AdminEmails); err != nil {
+		appLogger.WithError(err).Fatal("failed to bootstrap admin users")
+	}
+	adminUsecase := admin.NewAdminUsecase(userRepo, sessionRepo, apiKeyRepo, hashService, appLogger)
+	moderationUsecase := moderation.NewModerationUsecase(reportRepo, moderationActionRepo, messageRepo, userRepo, sessionRepo, mailer, appLogger)
+	blockUsecase := block.NewBlockUsecase(userBlockRepo, userRepo, appLogger)
+
+	// Initialize HTTP rate limiting; nil disables it
+	var rateLimitUsecase ratelimit.RateLimitUsecase
+	if cfg.RateLimit.Enabled {
+		rateLimitConfig, err := initRateLimitConfig(cfg.RateLimit)
+		if err != nil {
+			appLogger.WithError(err).Fatal("failed to initialize rate limiting")
+		}
+		rateLimitStore := postgres.NewMemoryRateLimitRepository()
+		if cfg.RateLimit.Store == "postgres" {
+			rateLimitStore = postgres.NewRateLimitRepository(dbAdapter)
+		}
+		rateLimitUsecase = ratelimit.NewRateLimitUsecase(rateLimitStore, rateLimitConfig, appLogger)
+	}
+
+	// Initialize idempotency keys; nil disables them
+	var idempotencyUsecase idempotency.IdempotencyUsecase
+	if cfg.Idempotency.Enabled {
+		idempotencyUsecase = idempotency.NewIdempotencyUsecase(postgres.NewIdempotencyRepository(dbAdapter), idempotency.Config{
+			TTL:               cfg.Idempotency.TTL,
+			InProgressTimeout: cfg.Idempotency.InProgressTimeout,
+		}, appLogger)
+	}
+
+	// Initialize health probes
+	appHealth, err := initHealth(cfg, dbAdapter, appLogger)
+	if err != nil {
+		appLogger.WithError(err).Fatal("failed to initialize health checks")
+	}
 
 	// Initialize handler
-	appHandler := handler.NewHandler(userUsecase, messageUsecase, sessionUsecase, appLogger)
+	logLevels := logger.NewLevelController(appLogger, cfg.Logger.MaxLevelOverride)
+	corsConfig := handler.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+	appHandler := handler.NewHandler(userUsecase, messageUsecase, sessionUsecase, mfaUsecase, passwordUsecase, verificationUsecase, loginGuard, oidcUsecase, apiKeyUsecase, rbacUsecase, adminUsecase, moderationUsecase, contentFilterUsecase, blockUsecase, rateLimitUsecase, idempotencyUsecase, appHealth, appMetrics, corsConfig, logLevels, appLogger)
+	// By default gin trusts X-Forwarded-For from any peer, which lets clients spoof their IP.
+	// An empty list disables the header so the connection address is used
+	var trustedProxies []string
+	if len(cfg.Server.TrustedProxies) > 0 {
+		trustedProxies = cfg.Server.TrustedProxies
+	}
+	if err := appHandler.GetRouter().SetTrustedProxies(trustedProxies); err != nil {
+		appLogger.WithError(err).Fatal("invalid trusted proxies")
+	}
+
+	// Apply safe settings from the config file without a restart
+	configWatcher.OnChange(func(settings config.RuntimeSettings) {
+		// The level has passed config validation, so parsing cannot fail
+		if level, err := logrus.ParseLevel(settings.LogLevel); err == nil {
+			logLevels.SetBase(level)
+		}
+		messageUsecase.UpdateConfig(messageConfig(settings))
+		loginGuard.UpdateConfig(loginGuardConfig(settings))
+		appHandler.SetCORSOrigins(settings.CORSOrigins)
+		if rateLimitUsecase != nil {
+			// The allowlist has passed config validation, so parsing cannot fail
+			if rateLimitConfig, err := initRateLimitConfig(settings.RateLimit); err == nil {
+				rateLimitUsecase.UpdateConfig(rateLimitConfig)
+			}
+		}
+		appLogger.Info("runtime settings reloaded from configuration file")
+	})
+	configWatcher.OnReloadError(func(err error) {
+		appLogger.WithError(err).Error("configuration file change ignored, keeping previous settings")
+	})
+	configWatcher.Start()
 
 	// Initialize HTTP server
 	httpServer := &http.Server{
@@ -111,7 +295,7 @@ func main() {
 	}
 
 	// Create application instance
-	application := app.NewApp(httpServer, dbAdapter, appHandler, appLogger)
+	application := app.NewApp(httpServer, dbAdapter, appHandler, appHealth, cfg.Health.DrainDelay, appLogger)
 
 	// Start server in a goroutine
 	appLogger.WithField("address", cfg.GetServerAddress()).Info("starting HTTP server")
@@ -121,12 +305,20 @@ func main() {
 		}
 	}()
 
+	// Periodically reload content filter rules to pick up changes made on other instances
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if cfg.ContentFilter.Enabled {
+		go contentFilterUsecase.WatchRules(watchCtx, cfg.ContentFilter.ReloadInterval)
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	appLogger.Info("shutting down server...")
+	stopWatch()
 
 	// Create context with timeout for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -137,6 +329,11 @@ func main() {
 		appLogger.WithError(err).Fatal("failed to shutdown application gracefully")
 	}
 
+	// Flush spans that have not been exported yet
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.WithError(err).Warn("failed to flush traces")
+	}
+
 	appLogger.Info("server exited gracefully")
 }
 
@@ -174,3 +371,176 @@ func initDatabase(cfg *config.Config, logger *logrus.Logger) (*pgxpool.Pool, err
 	logger.Info("database connection pool initialized successfully")
 	return pool, nil
 }
+
+// initTracing installs the global tracer provider exporting spans over OTLP.
+// The returned function flushes pending spans and must be called on shutdown
+func initTracing(cfg *config.Config, logger *logrus.Logger) (func(context.Context) error, error) {
+	if !cfg.Tracing.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tracingCfg := tracing.Config{
+		ServiceName:    cfg.App.Name,
+		ServiceVersion: cfg.App.Version,
+		Environment:    cfg.App.Environment,
+		Endpoint:       cfg.Tracing.Endpoint,
+		URLPath:        cfg.Tracing.URLPath,
+		Insecure:       cfg.Tracing.Insecure,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+		ExportTimeout:  cfg.Tracing.ExportTimeout,
+	}
+
+	exporter, err := tracing.NewOTLPExporter(context.Background(), tracingCfg)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := tracing.NewProvider(tracingCfg, exporter)
+	if err != nil {
+		return nil, err
+	}
+	tracing.Install(provider)
+
+	logger.WithField("endpoint", cfg.Tracing.Endpoint).Info("tracing initialized")
+	return provider.Shutdown, nil
+}
+
+// initHealth creates readiness checks for the database, its schema version and pool saturation
+func initHealth(cfg *config.Config, db *postgres.PostgresAdapter, logger *logrus.Logger) (*health.Health, error) {
+	readiness := []health.Checker{
+		health.NewDatabaseCheck(db),
+		health.NewPoolCheck(db, cfg.Health.PoolMaxUtilization),
+	}
+	if cfg.Health.MigrationsPath != "" {
+		version, err := health.LatestMigrationVersion(cfg.Health.MigrationsPath)
+		if err != nil {
+			return nil, err
+		}
+		readiness = append(readiness, health.NewMigrationCheck(db, version))
+	}
+
+	return health.NewHealth(nil, readiness, health.Config{
+		CheckTimeout: cfg.Health.CheckTimeout,
+		CacheTTL:     cfg.Health.CacheTTL,
+	}, logger), nil
+}
+
+// messageConfig builds message posting settings; they are reapplied on config reload
+func messageConfig(settings config.RuntimeSettings) message.Config {
+	return message.Config{
+		RequireVerifiedEmail: settings.RequireVerifiedToPost,
+		AntiSpam: message.AntiSpamConfig{
+			Burst:           settings.AntiSpam.Burst,
+			RefillInterval:  settings.AntiSpam.RefillInterval,
+			DuplicateWindow: settings.AntiSpam.DuplicateWindow,
+			SlowMode:        settings.AntiSpam.SlowMode,
+		},
+	}
+}
+
+// loginGuardConfig builds brute-force protection thresholds; they are reapplied on config reload
+func loginGuardConfig(settings config.RuntimeSettings) loginguard.Config {
+	return loginguard.Config{
+		Window:             settings.LoginProtection.Window,
+		AccountMaxFailures: settings.LoginProtection.AccountMaxFailures,
+		IPMaxFailures:      settings.LoginProtection.IPMaxFailures,
+		BaseLockout:        settings.LoginProtection.BaseLockout,
+		MaxLockout:         settings.LoginProtection.MaxLockout,
+	}
+}
+
+// initRateLimitConfig converts rate limit policies from the config file
+func initRateLimitConfig(cfg config.RateLimitConfig) (ratelimit.Config, error) {
+	allowlist, err := ratelimit.ParseAllowlist(cfg.Allowlist)
+	if err != nil {
+		return ratelimit.Config{}, err
+	}
+
+	policy := func(p config.RateLimitPolicyConfig) ratelimit.Policy {
+		return ratelimit.Policy{Name: p.Name, Routes: p.Routes, Key: p.Key, Limit: p.Limit, Window: p.Window}
+	}
+	policies := make([]ratelimit.Policy, 0, len(cfg.Policies))
+	for _, p := range cfg.Policies {
+		policies = append(policies, policy(p))
+	}
+
+	return ratelimit.Config{
+		Default:   policy(cfg.Default),
+		Policies:  policies,
+		Allowlist: allowlist,
+	}, nil
+}
+
+// initHashService creates the password hasher for the configured algorithm
+func initHashService(cfg *config.Config, logger *logrus.Logger) service.HashService {
+	if cfg.Hashing.Algorithm == "bcrypt" {
+		return service.NewBcryptHashService(cfg.Hashing.BcryptCost, logger)
+	}
+	return service.NewArgon2HashService(service.Argon2Params{
+		Memory:      cfg.Hashing.Argon2.Memory,
+		Iterations:  cfg.Hashing.Argon2.Iterations,
+		Parallelism: cfg.Hashing.Argon2.Parallelism,
+		SaltLength:  cfg.Hashing.Argon2.SaltLength,
+		KeyLength:   cfg.Hashing.Argon2.KeyLength,
+	}, logger)
+}
+
+// initPasswordPolicy creates the password policy with an optional breached password list
+func initPasswordPolicy(cfg *config.Config, logger *logrus.Logger) (service.PasswordPolicy, error) {
+	var breached service.BreachedPasswordChecker
+	if cfg.PasswordPolicy.BreachedListPath != "" {
+		list, err := service.NewBreachedPasswordList(cfg.PasswordPolicy.BreachedListPath, logger)
+		if err != nil {
+			return nil, err
+		}
+		breached = list
+	}
+
+	return service.NewPasswordPolicy(service.PasswordPolicyConfig{
+		MinLength:        cfg.PasswordPolicy.MinLength,
+		MaxLength:        cfg.PasswordPolicy.MaxLength,
+		RequireUpper:     cfg.PasswordPolicy.RequireUpper,
+		RequireLower:     cfg.PasswordPolicy.RequireLower,
+		RequireDigit:     cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:    cfg.PasswordPolicy.RequireSymbol,
+		DisallowUserInfo: cfg.PasswordPolicy.DisallowUserInfo,
+	}, breached, logger), nil
+}
+
+// initOIDCProviders creates clients for the configured OpenID Connect providers
+func initOIDCProviders(cfg *config.Config, logger *logrus.Logger) map[string]service.OIDCClient {
+	httpClient := &http.Client{Timeout: cfg.OIDC.HTTPTimeout}
+
+	providers := make(map[string]service.OIDCClient, len(cfg.OIDC.Providers))
+	for _, p := range cfg.OIDC.Providers {
+		providers[p.Name] = service.NewOIDCClient(service.OIDCProviderConfig{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, httpClient, logger)
+	}
+	return providers
+}
+
+// initMailer creates the mailer for the configured driver
+func initMailer(cfg *config.Config, logger *logrus.Logger) (service.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return service.NewSMTPMailer(service.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+			Timeout:  cfg.Mail.Timeout,
+		}, logger), nil
+	case "file":
+		return service.NewFileMailer(cfg.Mail.From, cfg.Mail.OutboxDir, logger)
+	case "log":
+		return service.NewLogMailer(cfg.Mail.From, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
+	}
+}
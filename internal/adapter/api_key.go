@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type apiKeyRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewAPIKeyRepository(adapter *PostgresAdapter) usecase.APIKeyRepository {
+	return &apiKeyRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *entity.APIKey) error {
+	if key == nil {
+		return &ValidationError{"api key cannot be nil"}
+	}
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("api_keys").
+		Columns("id", "user_id", "name", "prefix", "key_hash", "scopes", "expires_at", "created_at").
+		Values(key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.ExpiresAt, key.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for api key")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", key.UserID).Error("failed to create api key in database")
+		return fmt.Errorf("failed to insert api key: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("api_key_id", returnedID).Info("api key created successfully in database")
+	return nil
+}
+
+func (r *apiKeyRepo) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	if keyHash == "" {
+		return nil, &ValidationError{"key is required"}
+	}
+
+	query, args, err := r.selectKeys().
+		Where(squirrel.Eq{"key_hash": keyHash}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for api key")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	key, err := r.scanKey(r.adapter.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).Debug("api key not found")
+			return nil, &NotFoundError{"api key not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to get api key")
+		return nil, fmt.Errorf("failed to query api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListByUserID возвращает неотозванные ключи пользователя, включая истекшие
+func (r *apiKeyRepo) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error) {
+	if userID == uuid.Nil {
+		return nil, &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.selectKeys().
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		OrderBy("created_at DESC").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for api keys by user ID")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to query api keys by user ID")
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+	for rows.Next() {
+		key, err := r.scanKey(rows)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to scan api key row")
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("error during api key rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debugf("retrieved %d api keys for user", len(keys))
+	return keys, nil
+}
+
+func (r *apiKeyRepo) CountActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	if userID == uuid.Nil {
+		return 0, &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Select("COUNT(*)").
+		From("api_keys").
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		Where(squirrel.Or{squirrel.Eq{"expires_at": nil}, squirrel.Gt{"expires_at": now}}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build count query for api keys")
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to count api keys")
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+
+	return count, nil
+}
+
+// Revoke отзывает ключ, только если он принадлежит пользователю и еще не отозван
+func (r *apiKeyRepo) Revoke(ctx context.Context, id, userID uuid.UUID) error {
+	if id == uuid.Nil || userID == uuid.Nil {
+		return &ValidationError{"invalid api key ID"}
+	}
+
+	query, args, err := r.psql.Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "user_id": userID, "revoked_at": nil}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for api key")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var revokedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&revokedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("api_key_id", id).Warn("api key not found or already revoked")
+			return &NotFoundError{"api key not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("api_key_id", id).Error("failed to revoke api key")
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("api_key_id", revokedID).Info("api key revoked")
+	return nil
+}
+
+// RevokeAllByUserID отзывает все действующие ключи пользователя
+func (r *apiKeyRepo) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for user api keys")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to revoke user api keys")
+		return fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Info("user api keys revoked")
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid api key ID"}
+	}
+
+	query, args, err := r.psql.Update("api_keys").
+		Set("last_used_at", at).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for api key last use")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("api_key_id", id).Error("failed to update api key last use")
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) selectKeys() squirrel.SelectBuilder {
+	return r.psql.Select("id", "user_id", "name", "prefix", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at").
+		From("api_keys")
+}
+
+func (r *apiKeyRepo) scanKey(row pgx.Row) (*entity.APIKey, error) {
+	var key entity.APIKey
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type userBlockRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewUserBlockRepository(adapter *PostgresAdapter) usecase.UserBlockRepository {
+	return &userBlockRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// Create сохраняет блокировку. Повторная блокировка не ошибка и не меняет время создания
+func (r *userBlockRepo) Create(ctx context.Context, block *entity.UserBlock) error {
+	if block == nil {
+		return &ValidationError{"user block cannot be nil"}
+	}
+	if err := block.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("user_blocks").
+		Columns("blocker_id", "blocked_id", "created_at").
+		Values(block.BlockerID, block.BlockedID, block.CreatedAt).
+		Suffix("ON CONFLICT (blocker_id, blocked_id) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for user block")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	logger := r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"blocker_id": block.BlockerID,
+		"blocked_id": block.BlockedID,
+	})
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		logger.WithError(err).Error("failed to create user block in database")
+		return fmt.Errorf("failed to insert user block: %w", err)
+	}
+
+	logger.Info("user block created successfully in database")
+	return nil
+}
+
+func (r *userBlockRepo) Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if blockerID == uuid.Nil || blockedID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("user_blocks").
+		Where(squirrel.Eq{"blocker_id": blockerID, "blocked_id": blockedID}).
+		Suffix("RETURNING blocked_id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for user block")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	logger := r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"blocker_id": blockerID,
+		"blocked_id": blockedID,
+	})
+
+	var deletedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			logger.Warn("user block not found for deletion")
+			return apperror.NotFound(apperror.CodeBlockMissing, "user is not blocked")
+		}
+		logger.WithError(err).Error("failed to delete user block")
+		return fmt.Errorf("failed to delete user block: %w", err)
+	}
+
+	logger.Info("user block deleted successfully")
+	return nil
+}
+
+// ListByBlocker возвращает блокировки пользователя с именами заблокированных, новые первыми
+func (r *userBlockRepo) ListByBlocker(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error) {
+	if blockerID == uuid.Nil {
+		return nil, &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Select("b.blocker_id", "b.blocked_id", "u.username", "b.created_at").
+		From("user_blocks b").
+		Join("users u ON u.id = b.blocked_id").
+		Where(squirrel.Eq{"b.blocker_id": blockerID}).
+		OrderBy("b.created_at DESC").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for user blocks")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to query user blocks")
+		return nil, fmt.Errorf("failed to query user blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := make([]*entity.UserBlock, 0)
+	for rows.Next() {
+		var block entity.UserBlock
+		if err := rows.Scan(&block.BlockerID, &block.BlockedID, &block.BlockedUsername, &block.CreatedAt); err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to scan user block row")
+			return nil, fmt.Errorf("failed to scan user block: %w", err)
+		}
+		blocks = append(blocks, &block)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("error during user block rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// ListBlockedIDs возвращает ID пользователей, заблокированных blockerID
+func (r *userBlockRepo) ListBlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	query, args, err := r.psql.Select("blocked_id").
+		From("user_blocks").
+		Where(squirrel.Eq{"blocker_id": blockerID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for blocked user IDs")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to query blocked user IDs")
+		return nil, fmt.Errorf("failed to query blocked users: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to scan blocked user ID")
+			return nil, fmt.Errorf("failed to scan blocked user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("error during blocked user rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return ids, nil
+}
+
+// FindBlockersByUsername возвращает имена из usernames (без учета регистра), чьи владельцы заблокировали blockedID
+func (r *userBlockRepo) FindBlockersByUsername(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := r.psql.Select("u.username").
+		From("user_blocks b").
+		Join("users u ON u.id = b.blocker_id").
+		Where(squirrel.Eq{"b.blocked_id": blockedID}).
+		Where("LOWER(u.username) = ANY(?)", usernames).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for blockers by username")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocked_id", blockedID).Error("failed to query blockers by username")
+		return nil, fmt.Errorf("failed to query blockers: %w", err)
+	}
+	defer rows.Close()
+
+	blockers := make([]string, 0)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan blocker username")
+			return nil, fmt.Errorf("failed to scan blocker: %w", err)
+		}
+		blockers = append(blockers, username)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during blocker rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return blockers, nil
+}
+
+// FindBlockedByUsername возвращает имена из usernames (без учета регистра), чьих владельцев заблокировал blockerID
+func (r *userBlockRepo) FindBlockedByUsername(ctx context.Context, blockerID uuid.UUID, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := r.psql.Select("u.username").
+		From("user_blocks b").
+		Join("users u ON u.id = b.blocked_id").
+		Where(squirrel.Eq{"b.blocker_id": blockerID}).
+		Where("LOWER(u.username) = ANY(?)", usernames).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for blocked users by username")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to query blocked users by username")
+		return nil, fmt.Errorf("failed to query blocked users: %w", err)
+	}
+	defer rows.Close()
+
+	blocked := make([]string, 0)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan blocked username")
+			return nil, fmt.Errorf("failed to scan blocked user: %w", err)
+		}
+		blocked = append(blocked, username)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during blocked username rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return blocked, nil
+}
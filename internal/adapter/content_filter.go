@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type contentFilterRuleRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewContentFilterRuleRepository(adapter *PostgresAdapter) usecase.ContentFilterRuleRepository {
+	return &contentFilterRuleRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *contentFilterRuleRepo) Create(ctx context.Context, rule *entity.ContentFilterRule) error {
+	if rule == nil {
+		return &ValidationError{"content filter rule cannot be nil"}
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	var description interface{}
+	if rule.Description != "" {
+		description = rule.Description
+	}
+
+	query, args, err := r.psql.Insert("content_filter_rules").
+		Columns("id", "kind", "pattern", "action", "enabled", "description", "created_by", "created_at", "updated_at").
+		Values(rule.ID, rule.Kind, rule.Pattern, rule.Action, rule.Enabled, description, rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for content filter rule")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to create content filter rule in database")
+		return fmt.Errorf("failed to insert content filter rule: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("rule_id", returnedID).Info("content filter rule created successfully in database")
+	return nil
+}
+
+func (r *contentFilterRuleRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.ContentFilterRule, error) {
+	if id == uuid.Nil {
+		return nil, &ValidationError{"invalid content filter rule ID"}
+	}
+
+	query, args, err := r.selectRules().
+		Where(squirrel.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for content filter rule by ID")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rule, err := r.scanRule(r.adapter.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("rule_id", id).Warn("content filter rule not found")
+			return nil, &NotFoundError{"content filter rule not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("rule_id", id).Error("failed to get content filter rule by ID")
+		return nil, fmt.Errorf("failed to query content filter rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// List возвращает все правила, включая выключенные, в порядке создания
+func (r *contentFilterRuleRepo) List(ctx context.Context) ([]*entity.ContentFilterRule, error) {
+	query, args, err := r.selectRules().
+		OrderBy("created_at", "id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for content filter rules")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to query content filter rules")
+		return nil, fmt.Errorf("failed to query content filter rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*entity.ContentFilterRule, 0)
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan content filter rule row")
+			return nil, fmt.Errorf("failed to scan content filter rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during content filter rule rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).Debugf("retrieved %d content filter rules", len(rules))
+	return rules, nil
+}
+
+func (r *contentFilterRuleRepo) Update(ctx context.Context, rule *entity.ContentFilterRule) error {
+	if rule == nil {
+		return &ValidationError{"content filter rule cannot be nil"}
+	}
+	if rule.ID == uuid.Nil {
+		return &ValidationError{"invalid content filter rule ID"}
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	var description interface{}
+	if rule.Description != "" {
+		description = rule.Description
+	}
+
+	query, args, err := r.psql.Update("content_filter_rules").
+		Set("kind", rule.Kind).
+		Set("pattern", rule.Pattern).
+		Set("action", rule.Action).
+		Set("enabled", rule.Enabled).
+		Set("description", description).
+		Set("updated_at", rule.UpdatedAt).
+		Where(squirrel.Eq{"id": rule.ID}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for content filter rule")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("rule_id", rule.ID).Warn("content filter rule not found for update")
+			return &NotFoundError{"content filter rule not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("rule_id", rule.ID).Error("failed to update content filter rule")
+		return fmt.Errorf("failed to update content filter rule: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("rule_id", returnedID).Info("content filter rule updated successfully")
+	return nil
+}
+
+func (r *contentFilterRuleRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid content filter rule ID"}
+	}
+
+	query, args, err := r.psql.Delete("content_filter_rules").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for content filter rule")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var deletedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("rule_id", id).Warn("content filter rule not found for deletion")
+			return &NotFoundError{"content filter rule not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("rule_id", id).Error("failed to delete content filter rule")
+		return fmt.Errorf("failed to delete content filter rule: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("rule_id", deletedID).Info("content filter rule deleted successfully")
+	return nil
+}
+
+func (r *contentFilterRuleRepo) selectRules() squirrel.SelectBuilder {
+	return r.psql.Select(
+		"id", "kind", "pattern", "action", "enabled", "COALESCE(description, '')", "created_by", "created_at", "updated_at",
+	).From("content_filter_rules")
+}
+
+func (r *contentFilterRuleRepo) scanRule(row pgx.Row) (*entity.ContentFilterRule, error) {
+	var rule entity.ContentFilterRule
+	err := row.Scan(
+		&rule.ID, &rule.Kind, &rule.Pattern, &rule.Action, &rule.Enabled, &rule.Description,
+		&rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
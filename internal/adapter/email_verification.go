@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type emailVerificationRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewEmailVerificationRepository(adapter *PostgresAdapter) usecase.EmailVerificationRepository {
+	return &emailVerificationRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *emailVerificationRepo) Create(ctx context.Context, token *entity.EmailVerificationToken) error {
+	if token == nil {
+		return &ValidationError{"verification token cannot be nil"}
+	}
+	if err := token.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("email_verification_tokens").
+		Columns("id", "user_id", "email", "token_hash", "expires_at", "created_at").
+		Values(token.ID, token.UserID, token.Email, token.TokenHash, token.ExpiresAt, token.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for verification token")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", token.UserID).Error("failed to create verification token in database")
+		return fmt.Errorf("failed to insert verification token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", returnedID).Info("verification token created successfully in database")
+	return nil
+}
+
+func (r *emailVerificationRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	if tokenHash == "" {
+		return nil, &ValidationError{"token is required"}
+	}
+
+	query, args, err := r.selectTokens().
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for verification token")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	token, err := r.scanToken(r.adapter.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).Warn("verification token not found")
+			return nil, &NotFoundError{"verification token not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to get verification token")
+		return nil, fmt.Errorf("failed to query verification token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", token.ID).Debug("verification token retrieved")
+	return token, nil
+}
+
+// GetLatestByUserID возвращает последний выпущенный токен пользователя (для ограничения повторной отправки)
+func (r *emailVerificationRepo) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error) {
+	if userID == uuid.Nil {
+		return nil, &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.selectTokens().
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for latest verification token")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	token, err := r.scanToken(r.adapter.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debug("no verification tokens for user")
+			return nil, &NotFoundError{"verification token not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to get latest verification token")
+		return nil, fmt.Errorf("failed to query verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed атомарно помечает токен использованным. Повторный вызов вернет NotFoundError
+func (r *emailVerificationRepo) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid token ID"}
+	}
+
+	query, args, err := r.psql.Update("email_verification_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "used_at": nil}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for verification token")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var usedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&usedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("token_id", id).Warn("verification token not found or already used")
+			return &NotFoundError{"verification token not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("token_id", id).Error("failed to mark verification token as used")
+		return fmt.Errorf("failed to update verification token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", usedID).Info("verification token marked as used")
+	return nil
+}
+
+func (r *emailVerificationRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("email_verification_tokens").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for verification tokens")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete verification tokens")
+		return fmt.Errorf("failed to delete verification tokens: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debug("verification tokens deleted")
+	return nil
+}
+
+func (r *emailVerificationRepo) selectTokens() squirrel.SelectBuilder {
+	return r.psql.Select("id", "user_id", "email", "token_hash", "expires_at", "used_at", "created_at").
+		From("email_verification_tokens")
+}
+
+func (r *emailVerificationRepo) scanToken(row pgx.Row) (*entity.EmailVerificationToken, error) {
+	var token entity.EmailVerificationToken
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Email, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
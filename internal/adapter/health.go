@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"chat-service/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ping проверяет соединение с базой данных
+func (p *PostgresAdapter) Ping(ctx context.Context) error {
+	spanCtx, span := startSpan(ctx, "ping", "ping")
+	err := p.Pool.Ping(spanCtx)
+	tracing.End(span, err)
+
+	if err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// MigrationVersion возвращает версию схемы из таблицы schema_migrations, которую ведет golang-migrate
+func (p *PostgresAdapter) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := p.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// PoolUsage возвращает число занятых соединений и размер пула
+func (p *PostgresAdapter) PoolUsage() (int32, int32) {
+	stat := p.Pool.Stat()
+	return stat.AcquiredConns(), stat.MaxConns()
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type idempotencyRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewIdempotencyRepository(adapter *PostgresAdapter) usecase.IdempotencyRepository {
+	return &idempotencyRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// Create вставляет запись одним запросом, поэтому из параллельных запросов с одним ключом
+// запись создает только один
+func (r *idempotencyRepo) Create(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+	if record == nil {
+		return false, &ValidationError{"idempotency record cannot be nil"}
+	}
+	if err := record.Validate(); err != nil {
+		return false, err
+	}
+
+	query, args, err := r.psql.Insert("idempotency_keys").
+		Columns("user_id", "key", "request_hash", "created_at", "expires_at").
+		Values(record.UserID, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt).
+		Suffix(`ON CONFLICT (user_id, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			body = NULL,
+			created_at = EXCLUDED.created_at,
+			completed_at = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.created_at < ?)
+		RETURNING user_id`, staleBefore).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for idempotency key")
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	logger := r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":         record.UserID,
+		"idempotency_key": record.Key,
+	})
+
+	var userID uuid.UUID
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&userID); err != nil {
+		if err == pgx.ErrNoRows {
+			// Действующая запись уже есть
+			return false, nil
+		}
+		logger.WithError(err).Error("failed to create idempotency key in database")
+		return false, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	logger.Debug("idempotency key created in database")
+	return true, nil
+}
+
+func (r *idempotencyRepo) Get(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error) {
+	if userID == uuid.Nil || key == "" {
+		return nil, &ValidationError{"user ID and key are required"}
+	}
+
+	query, args, err := r.psql.Select("user_id", "key", "request_hash", "status_code", "content_type", "body",
+		"created_at", "completed_at", "expires_at").
+		From("idempotency_keys").
+		Where(squirrel.Eq{"user_id": userID, "key": key}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for idempotency key")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var (
+		record      entity.IdempotencyRecord
+		statusCode  *int
+		contentType *string
+	)
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&contentType,
+		&record.Body,
+		&record.CreatedAt,
+		&record.CompletedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &NotFoundError{"idempotency key not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to get idempotency key")
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		record.ContentType = *contentType
+	}
+
+	return &record, nil
+}
+
+// Complete сохраняет ответ незавершенной записи с тем же хешем запроса. created_at отличает попытку,
+// занявшую ключ: если запись перехватил повтор после in_progress_timeout, ответ прерванной попытки не сохраняется
+func (r *idempotencyRepo) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	if record == nil || record.CompletedAt == nil {
+		return &ValidationError{"completed idempotency record is required"}
+	}
+
+	query, args, err := r.psql.Update("idempotency_keys").
+		Set("status_code", record.StatusCode).
+		Set("content_type", record.ContentType).
+		Set("body", record.Body).
+		Set("completed_at", record.CompletedAt).
+		Where(squirrel.Eq{
+			"user_id":      record.UserID,
+			"key":          record.Key,
+			"request_hash": record.RequestHash,
+			"created_at":   record.CreatedAt,
+			"completed_at": nil,
+		}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for idempotency key")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"user_id":         record.UserID,
+			"idempotency_key": record.Key,
+		}).Error("failed to complete idempotency key")
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete удаляет незавершенную запись той же попытки, чтобы запрос можно было повторить с тем же ключом.
+// Запись, которую уже перехватил повтор, не удаляется
+func (r *idempotencyRepo) Delete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	if record == nil {
+		return &ValidationError{"idempotency record cannot be nil"}
+	}
+	if err := record.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Delete("idempotency_keys").
+		Where(squirrel.Eq{"user_id": record.UserID, "key": record.Key, "created_at": record.CreatedAt, "completed_at": nil}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for idempotency key")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", record.UserID).Error("failed to delete idempotency key")
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	query, args, err := r.psql.Delete("idempotency_keys").
+		Where(squirrel.Lt{"expires_at": before}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for expired idempotency keys")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete expired idempotency keys")
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return nil
+}
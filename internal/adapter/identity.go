@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type userIdentityRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewUserIdentityRepository(adapter *PostgresAdapter) usecase.UserIdentityRepository {
+	return &userIdentityRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *userIdentityRepo) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	if identity == nil {
+		return &ValidationError{"identity cannot be nil"}
+	}
+	if err := identity.Validate(); err != nil {
+		return err
+	}
+
+	var email interface{}
+	if identity.Email != "" {
+		email = identity.Email
+	}
+
+	query, args, err := r.psql.Insert("user_identities").
+		Columns("id", "user_id", "provider", "subject", "email", "created_at", "last_login_at").
+		Values(identity.ID, identity.UserID, identity.Provider, identity.Subject, email, identity.CreatedAt, identity.LastLoginAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for user identity")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"user_id":  identity.UserID,
+			"provider": identity.Provider,
+		}).Error("failed to create user identity in database")
+		return fmt.Errorf("failed to insert user identity: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("identity_id", returnedID).Info("user identity created successfully in database")
+	return nil
+}
+
+func (r *userIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	if provider == "" || subject == "" {
+		return nil, &ValidationError{"provider and subject are required"}
+	}
+
+	query, args, err := r.psql.Select("id", "user_id", "provider", "subject", "COALESCE(email, '')", "created_at", "last_login_at").
+		From("user_identities").
+		Where(squirrel.Eq{"provider": provider, "subject": subject}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for user identity")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var identity entity.UserIdentity
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject,
+		&identity.Email, &identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("provider", provider).Debug("user identity not found")
+			return nil, &NotFoundError{"user identity not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("provider", provider).Error("failed to get user identity")
+		return nil, fmt.Errorf("failed to query user identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+func (r *userIdentityRepo) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid identity ID"}
+	}
+
+	query, args, err := r.psql.Update("user_identities").
+		Set("last_login_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for user identity")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("identity_id", id).Error("failed to update user identity last login")
+		return fmt.Errorf("failed to update user identity: %w", err)
+	}
+
+	return nil
+}
+
+type oidcAuthRequestRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewOIDCAuthRequestRepository(adapter *PostgresAdapter) usecase.OIDCAuthRequestRepository {
+	return &oidcAuthRequestRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *oidcAuthRequestRepo) Create(ctx context.Context, request *entity.OIDCAuthRequest) error {
+	if request == nil {
+		return &ValidationError{"auth request cannot be nil"}
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("oidc_auth_requests").
+		Columns("state_hash", "provider", "nonce", "code_verifier", "expires_at", "created_at").
+		Values(request.StateHash, request.Provider, request.Nonce, request.CodeVerifier, request.ExpiresAt, request.CreatedAt).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for oidc auth request")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("provider", request.Provider).Error("failed to create oidc auth request")
+		return fmt.Errorf("failed to insert oidc auth request: %w", err)
+	}
+
+	return nil
+}
+
+// Consume атомарно удаляет и возвращает запрос авторизации, поэтому state нельзя использовать повторно
+func (r *oidcAuthRequestRepo) Consume(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+	if stateHash == "" {
+		return nil, &ValidationError{"state is required"}
+	}
+
+	query, args, err := r.psql.Delete("oidc_auth_requests").
+		Where(squirrel.Eq{"state_hash": stateHash}).
+		Suffix("RETURNING state_hash, provider, nonce, code_verifier, expires_at, created_at").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for oidc auth request")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var request entity.OIDCAuthRequest
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(
+		&request.StateHash, &request.Provider, &request.Nonce, &request.CodeVerifier, &request.ExpiresAt, &request.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).Warn("oidc auth request not found or already used")
+			return nil, &NotFoundError{"auth request not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to consume oidc auth request")
+		return nil, fmt.Errorf("failed to delete oidc auth request: %w", err)
+	}
+
+	return &request, nil
+}
+
+func (r *oidcAuthRequestRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	query, args, err := r.psql.Delete("oidc_auth_requests").
+		Where(squirrel.Lt{"expires_at": before}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for expired oidc auth requests")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete expired oidc auth requests")
+		return fmt.Errorf("failed to delete expired oidc auth requests: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type loginAttemptRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewLoginAttemptRepository(adapter *PostgresAdapter) usecase.LoginAttemptRepository {
+	return &loginAttemptRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *loginAttemptRepo) Create(ctx context.Context, attempt *entity.LoginAttempt) error {
+	if attempt == nil {
+		return &ValidationError{"login attempt cannot be nil"}
+	}
+	if err := attempt.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("login_attempts").
+		Columns("id", "email", "ip_address", "user_id", "outcome", "created_at").
+		Values(attempt.ID, attempt.Email, attempt.IPAddress, attempt.UserID, attempt.Outcome, attempt.CreatedAt).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for login attempt")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("ip_address", attempt.IPAddress).Error("failed to record login attempt")
+		return fmt.Errorf("failed to insert login attempt: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("outcome", attempt.Outcome).Debug("login attempt recorded")
+	return nil
+}
+
+// GetAccountFailureStats считает неудачные попытки для аккаунта с момента since или последнего успешного входа
+func (r *loginAttemptRepo) GetAccountFailureStats(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+	if email == "" {
+		return nil, &ValidationError{"email is required"}
+	}
+
+	// Подзапрос собираем с плейсхолдерами "?": внешний билдер сам пронумерует их как $N
+	lastSuccess := squirrel.Select("MAX(created_at)").
+		From("login_attempts").
+		Where(squirrel.Eq{"email": email, "outcome": entity.LoginOutcomeSuccess})
+
+	lastSuccessSQL, lastSuccessArgs, err := lastSuccess.ToSql()
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build last success subquery")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.failureStats(ctx, squirrel.And{
+		squirrel.Eq{"email": email},
+		squirrel.Gt{"created_at": since},
+		squirrel.Expr("created_at > COALESCE(("+lastSuccessSQL+"), '-infinity')", lastSuccessArgs...),
+	})
+}
+
+// GetIPFailureStats считает неудачные попытки с IP-адреса. Успешный вход счетчик не сбрасывает,
+// иначе атакующий мог бы обнулять его входом в собственный аккаунт
+func (r *loginAttemptRepo) GetIPFailureStats(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error) {
+	if ipAddress == "" {
+		return nil, &ValidationError{"ip_address is required"}
+	}
+
+	return r.failureStats(ctx, squirrel.And{
+		squirrel.Eq{"ip_address": ipAddress},
+		squirrel.Gt{"created_at": since},
+	})
+}
+
+func (r *loginAttemptRepo) failureStats(ctx context.Context, filter squirrel.Sqlizer) (*entity.LoginFailureStats, error) {
+	query, args, err := r.psql.Select("COUNT(*)", "MAX(created_at)").
+		From("login_attempts").
+		Where(squirrel.Eq{"outcome": entity.LoginOutcomeFailure}).
+		Where(filter).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build login failure stats query")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var stats entity.LoginFailureStats
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&stats.Count, &stats.LastFailureAt); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to query login failure stats")
+		return nil, fmt.Errorf("failed to query login failure stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// DeleteOlderThan удаляет попытки, которые уже не учитываются при проверке блокировки
+func (r *loginAttemptRepo) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	query, args, err := r.psql.Delete("login_attempts").
+		Where(squirrel.Lt{"created_at": before}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for old login attempts")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete old login attempts")
+		return fmt.Errorf("failed to delete old login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"chat-service/internal/entity"
 	"chat-service/internal/usecase"
@@ -10,6 +11,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
 )
 
 type messageRepo struct {
@@ -37,18 +39,18 @@ func (r *messageRepo) Create(ctx context.Context, message *entity.Message) error
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build insert query for message")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for message")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	var returnedID uuid.UUID
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("message_id", message.ID).Error("failed to create message in database")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("message_id", message.ID).Error("failed to create message in database")
 		return fmt.Errorf("failed to insert message: %w", err)
 	}
 
-	r.adapter.logger.WithField("message_id", returnedID).Info("message created successfully in database")
+	r.adapter.logger.WithContext(ctx).WithField("message_id", returnedID).Info("message created successfully in database")
 	return nil
 }
 
@@ -57,33 +59,28 @@ func (r *messageRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Messag
 		return nil, &ValidationError{"invalid message ID"}
 	}
 
-	query, args, err := r.psql.Select("id", "user_id", "content", "created_at", "updated_at").
-		From("messages").
+	query, args, err := r.selectMessages().
 		Where(squirrel.Eq{"id": id}).
 		Limit(1).
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for message by ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for message by ID")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var message entity.Message
-	err = r.adapter.QueryRow(ctx, query, args...).Scan(
-		&message.ID, &message.UserID, &message.Content, &message.CreatedAt, &message.UpdatedAt,
-	)
-
+	message, err := r.scanMessage(r.adapter.QueryRow(ctx, query, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("message_id", id).Warn("message not found")
+			r.adapter.logger.WithContext(ctx).WithField("message_id", id).Warn("message not found")
 			return nil, &NotFoundError{"message not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("message_id", id).Error("failed to get message by ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("message_id", id).Error("failed to get message by ID")
 		return nil, fmt.Errorf("failed to query message: %w", err)
 	}
 
-	r.adapter.logger.WithField("message_id", message.ID).Debug("message retrieved by ID")
-	return &message, nil
+	r.adapter.logger.WithContext(ctx).WithField("message_id", message.ID).Debug("message retrieved by ID")
+	return message, nil
 }
 
 func (r *messageRepo) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Message, error) {
@@ -91,81 +88,79 @@ func (r *messageRepo) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*ent
 		return nil, &ValidationError{"invalid user ID"}
 	}
 
-	query, args, err := r.psql.Select("id", "user_id", "content", "created_at", "updated_at").
-		From("messages").
+	query, args, err := r.selectMessages().
 		Where(squirrel.Eq{"user_id": userID}).
 		OrderBy("created_at DESC").
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for messages by user ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for messages by user ID")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
 	rows, err := r.adapter.Query(ctx, query, args...)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("user_id", userID).Error("failed to query messages by user ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to query messages by user ID")
 		return nil, fmt.Errorf("failed to query messages: %w", err)
 	}
 	defer rows.Close()
 
 	var messages []*entity.Message
 	for rows.Next() {
-		var message entity.Message
-		err := rows.Scan(&message.ID, &message.UserID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+		message, err := r.scanMessage(rows)
 		if err != nil {
-			r.adapter.logger.WithError(err).WithField("user_id", userID).Error("failed to scan message row")
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to scan message row")
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
-		messages = append(messages, &message)
+		messages = append(messages, message)
 	}
 
 	// Проверяем ошибки при итерации
 	if err = rows.Err(); err != nil {
-		r.adapter.logger.WithError(err).WithField("user_id", userID).Error("error during message rows iteration")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("error during message rows iteration")
 		return nil, fmt.Errorf("error during rows iteration: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", userID).Debugf("retrieved %d messages for user", len(messages))
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debugf("retrieved %d messages for user", len(messages))
 	return messages, nil
 }
 
 func (r *messageRepo) GetAll(ctx context.Context) ([]*entity.Message, error) {
-	query, args, err := r.psql.Select("id", "user_id", "content", "created_at", "updated_at").
-		From("messages").
+	// Скрытые модератором сообщения в общую ленту не попадают
+	query, args, err := r.selectMessages().
+		Where(squirrel.Eq{"hidden_at": nil}).
 		OrderBy("created_at DESC").
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for all messages")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for all messages")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
 	rows, err := r.adapter.Query(ctx, query, args...)
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to query all messages")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to query all messages")
 		return nil, fmt.Errorf("failed to query messages: %w", err)
 	}
 	defer rows.Close()
 
 	var messages []*entity.Message
 	for rows.Next() {
-		var message entity.Message
-		err := rows.Scan(&message.ID, &message.UserID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+		message, err := r.scanMessage(rows)
 		if err != nil {
-			r.adapter.logger.WithError(err).Error("failed to scan message row")
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan message row")
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
-		messages = append(messages, &message)
+		messages = append(messages, message)
 	}
 
 	// Проверяем ошибки при итерации
 	if err = rows.Err(); err != nil {
-		r.adapter.logger.WithError(err).Error("error during all messages rows iteration")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during all messages rows iteration")
 		return nil, fmt.Errorf("error during rows iteration: %w", err)
 	}
 
-	r.adapter.logger.Debugf("retrieved %d messages total", len(messages))
+	r.adapter.logger.WithContext(ctx).Debugf("retrieved %d messages total", len(messages))
 	return messages, nil
 }
 
@@ -180,7 +175,7 @@ func (r *messageRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build delete query for message")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for message")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -188,17 +183,69 @@ func (r *messageRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("message_id", id).Warn("message not found for deletion")
+			r.adapter.logger.WithContext(ctx).WithField("message_id", id).Warn("message not found for deletion")
 			return &NotFoundError{"message not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("message_id", id).Error("failed to delete message")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("message_id", id).Error("failed to delete message")
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
 
-	r.adapter.logger.WithField("message_id", deletedID).Info("message deleted successfully")
+	r.adapter.logger.WithContext(ctx).WithField("message_id", deletedID).Info("message deleted successfully")
+	return nil
+}
+
+// SetHidden скрывает сообщение (hiddenAt != nil) или возвращает его в ленту
+func (r *messageRepo) SetHidden(ctx context.Context, id uuid.UUID, hiddenAt *time.Time) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid message ID"}
+	}
+
+	query, args, err := r.psql.Update("messages").
+		Set("hidden_at", hiddenAt).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build hide query for message")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var updatedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&updatedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("message_id", id).Warn("message not found for hiding")
+			return &NotFoundError{"message not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("message_id", id).Error("failed to update message visibility")
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"message_id": id,
+		"hidden":     hiddenAt != nil,
+	}).Info("message visibility updated")
 	return nil
 }
 
+func (r *messageRepo) selectMessages() squirrel.SelectBuilder {
+	return r.psql.Select("id", "user_id", "content", "hidden_at", "created_at", "updated_at").
+		From("messages")
+}
+
+// scanMessage читает строку в порядке колонок selectMessages
+func (r *messageRepo) scanMessage(row pgx.Row) (*entity.Message, error) {
+	var message entity.Message
+	err := row.Scan(
+		&message.ID, &message.UserID, &message.Content, &message.HiddenAt, &message.CreatedAt, &message.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
 // Валидация сообщения
 func (r *messageRepo) validateMessage(message *entity.Message) error {
 	if message == nil {
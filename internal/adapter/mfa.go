@@ -0,0 +1,404 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type mfaRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewMFARepository(adapter *PostgresAdapter) usecase.MFARepository {
+	return &mfaRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *mfaRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	if userID == uuid.Nil {
+		return nil, &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Select("user_id", "secret", "enabled", "last_used_step", "confirmed_at", "created_at", "updated_at").
+		From("user_mfa").
+		Where(squirrel.Eq{"user_id": userID}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for user mfa")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var mfa entity.UserMFA
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(
+		&mfa.UserID, &mfa.Secret, &mfa.Enabled, &mfa.LastUsedStep, &mfa.ConfirmedAt, &mfa.CreatedAt, &mfa.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debug("user mfa settings not found")
+			return nil, &NotFoundError{"mfa settings not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to get user mfa settings")
+		return nil, fmt.Errorf("failed to query user mfa: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debug("user mfa settings retrieved")
+	return &mfa, nil
+}
+
+func (r *mfaRepo) Upsert(ctx context.Context, mfa *entity.UserMFA) error {
+	if mfa == nil {
+		return &ValidationError{"mfa settings cannot be nil"}
+	}
+	if err := mfa.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("user_mfa").
+		Columns("user_id", "secret", "enabled", "last_used_step", "confirmed_at", "created_at", "updated_at").
+		Values(mfa.UserID, mfa.Secret, mfa.Enabled, mfa.LastUsedStep, mfa.ConfirmedAt, mfa.CreatedAt, mfa.UpdatedAt).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			last_used_step = EXCLUDED.last_used_step,
+			confirmed_at = EXCLUDED.confirmed_at,
+			updated_at = EXCLUDED.updated_at`).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build upsert query for user mfa")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", mfa.UserID).Error("failed to save user mfa settings")
+		return fmt.Errorf("failed to upsert user mfa: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", mfa.UserID).Info("user mfa settings saved successfully")
+	return nil
+}
+
+// UpdateLastUsedStep атомарно сдвигает последний принятый шаг TOTP.
+// Если шаг не больше сохраненного, код уже использовался и возвращается NotFoundError.
+func (r *mfaRepo) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Update("user_mfa").
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Lt{"last_used_step": step}).
+		Suffix("RETURNING user_id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for mfa step")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("totp step already used")
+			return &NotFoundError{"totp step already used"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to update mfa step")
+		return fmt.Errorf("failed to update mfa step: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	tx, err := r.adapter.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("transaction rolled back")
+		}
+	}()
+
+	codesQuery, codesArgs, err := r.psql.Delete("mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build delete recovery codes query: %w", err)
+	}
+
+	err = r.adapter.ExecTx(ctx, tx, codesQuery, codesArgs...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete recovery codes")
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	mfaQuery, mfaArgs, err := r.psql.Delete("user_mfa").
+		Where(squirrel.Eq{"user_id": userID}).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build delete user mfa query: %w", err)
+	}
+
+	var deletedID uuid.UUID
+	err = r.adapter.QueryRowTx(ctx, tx, mfaQuery, mfaArgs...).Scan(&deletedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("user mfa settings not found for deletion")
+			return &NotFoundError{"mfa settings not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete user mfa settings")
+		return fmt.Errorf("failed to delete user mfa: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", deletedID).Info("user mfa settings deleted successfully")
+	return nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новым набором
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	tx, err := r.adapter.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("transaction rolled back")
+		}
+	}()
+
+	deleteQuery, deleteArgs, err := r.psql.Delete("mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build delete recovery codes query: %w", err)
+	}
+
+	err = r.adapter.ExecTx(ctx, tx, deleteQuery, deleteArgs...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete old recovery codes")
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if len(codeHashes) > 0 {
+		now := time.Now()
+		insert := r.psql.Insert("mfa_recovery_codes").
+			Columns("id", "user_id", "code_hash", "created_at")
+		for _, hash := range codeHashes {
+			insert = insert.Values(uuid.New(), userID, hash, now)
+		}
+
+		var insertQuery string
+		var insertArgs []interface{}
+		insertQuery, insertArgs, err = insert.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build insert recovery codes query: %w", err)
+		}
+
+		err = r.adapter.ExecTx(ctx, tx, insertQuery, insertArgs...)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to insert recovery codes")
+			return fmt.Errorf("failed to insert recovery codes: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Infof("stored %d recovery codes", len(codeHashes))
+	return nil
+}
+
+// UseRecoveryCode помечает код восстановления использованным; повторное использование невозможно
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+	if codeHash == "" {
+		return &ValidationError{"code is required"}
+	}
+
+	query, args, err := r.psql.Update("mfa_recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for recovery code")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var usedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&usedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("recovery code not found or already used")
+			return &NotFoundError{"recovery code not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to use recovery code")
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Info("recovery code used")
+	return nil
+}
+
+func (r *mfaRepo) CreateChallenge(ctx context.Context, challenge *entity.MFAChallengeState) error {
+	if challenge == nil || challenge.ID == uuid.Nil || challenge.UserID == uuid.Nil {
+		return &ValidationError{"challenge ID and user ID are required"}
+	}
+
+	query, args, err := r.psql.Insert("mfa_challenges").
+		Columns("id", "user_id", "attempts", "expires_at", "created_at").
+		Values(challenge.ID, challenge.UserID, challenge.Attempts, challenge.ExpiresAt, challenge.CreatedAt).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for mfa challenge")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", challenge.UserID).Error("failed to create mfa challenge")
+		return fmt.Errorf("failed to insert mfa challenge: %w", err)
+	}
+
+	return nil
+}
+
+// UseChallengeAttempt увеличивает счетчик одним запросом, поэтому параллельные попытки
+// не могут превысить maxAttempts
+func (r *mfaRepo) UseChallengeAttempt(ctx context.Context, id, userID uuid.UUID, maxAttempts int, now time.Time) (int, error) {
+	if id == uuid.Nil || userID == uuid.Nil {
+		return 0, &ValidationError{"challenge ID and user ID are required"}
+	}
+
+	query, args, err := r.psql.Update("mfa_challenges").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		Where(squirrel.Gt{"expires_at": now}).
+		Where(squirrel.Lt{"attempts": maxAttempts}).
+		Suffix("RETURNING attempts").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for mfa challenge")
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var attempts int
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&attempts); err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("mfa challenge not found, expired or exhausted")
+			return 0, &NotFoundError{"mfa challenge not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to update mfa challenge attempts")
+		return 0, fmt.Errorf("failed to update mfa challenge: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *mfaRepo) DeleteChallenge(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid challenge ID"}
+	}
+
+	query, args, err := r.psql.Delete("mfa_challenges").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for mfa challenge")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var deletedID uuid.UUID
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID); err != nil {
+		if err == pgx.ErrNoRows {
+			return &NotFoundError{"mfa challenge not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete mfa challenge")
+		return fmt.Errorf("failed to delete mfa challenge: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) DeleteChallengesByUserID(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("mfa_challenges").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for user mfa challenges")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete user mfa challenges")
+		return fmt.Errorf("failed to delete user mfa challenges: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	query, args, err := r.psql.Delete("mfa_challenges").
+		Where(squirrel.Lt{"expires_at": before}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for expired mfa challenges")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete expired mfa challenges")
+		return fmt.Errorf("failed to delete expired mfa challenges: %w", err)
+	}
+
+	return nil
+}
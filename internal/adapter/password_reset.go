@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type passwordResetRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewPasswordResetRepository(adapter *PostgresAdapter) usecase.PasswordResetRepository {
+	return &passwordResetRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *passwordResetRepo) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	if token == nil {
+		return &ValidationError{"reset token cannot be nil"}
+	}
+	if err := token.Validate(); err != nil {
+		return err
+	}
+
+	query, args, err := r.psql.Insert("password_reset_tokens").
+		Columns("id", "user_id", "token_hash", "expires_at", "created_at").
+		Values(token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for reset token")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", token.UserID).Error("failed to create reset token in database")
+		return fmt.Errorf("failed to insert reset token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", returnedID).Info("reset token created successfully in database")
+	return nil
+}
+
+func (r *passwordResetRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	if tokenHash == "" {
+		return nil, &ValidationError{"token is required"}
+	}
+
+	query, args, err := r.psql.Select("id", "user_id", "token_hash", "expires_at", "used_at", "created_at").
+		From("password_reset_tokens").
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for reset token")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var token entity.PasswordResetToken
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).Warn("reset token not found")
+			return nil, &NotFoundError{"reset token not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to get reset token")
+		return nil, fmt.Errorf("failed to query reset token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", token.ID).Debug("reset token retrieved")
+	return &token, nil
+}
+
+// MarkUsed атомарно помечает токен использованным. Повторный вызов вернет NotFoundError
+func (r *passwordResetRepo) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid token ID"}
+	}
+
+	query, args, err := r.psql.Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "used_at": nil}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for reset token")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var usedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&usedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("token_id", id).Warn("reset token not found or already used")
+			return &NotFoundError{"reset token not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("token_id", id).Error("failed to mark reset token as used")
+		return fmt.Errorf("failed to update reset token: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("token_id", usedID).Info("reset token marked as used")
+	return nil
+}
+
+func (r *passwordResetRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("password_reset_tokens").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for reset tokens")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete reset tokens")
+		return fmt.Errorf("failed to delete reset tokens: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Debug("reset tokens deleted")
+	return nil
+}
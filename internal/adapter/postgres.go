@@ -4,20 +4,28 @@ import (
 	"context"
 	"time"
 
+	"chat-service/internal/metrics"
+	"chat-service/internal/tracing"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresAdapter struct {
-	Pool   *pgxpool.Pool
-	logger *logrus.Logger
+	Pool    *pgxpool.Pool
+	logger  *logrus.Logger
+	metrics *metrics.Metrics
 }
 
-func NewPostgresAdapter(pool *pgxpool.Pool, logger *logrus.Logger) *PostgresAdapter {
+// NewPostgresAdapter создает адаптер; appMetrics может быть nil, тогда длительность запросов не публикуется
+func NewPostgresAdapter(pool *pgxpool.Pool, appMetrics *metrics.Metrics, logger *logrus.Logger) *PostgresAdapter {
 	return &PostgresAdapter{
-		Pool:   pool,
-		logger: logger,
+		Pool:    pool,
+		logger:  logger,
+		metrics: appMetrics,
 	}
 }
 
@@ -31,10 +39,12 @@ func (p *PostgresAdapter) Close() {
 
 // BeginTx starts a new transaction
 func (p *PostgresAdapter) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	p.logger.Debug("beginning database transaction")
-	tx, err := p.Pool.Begin(ctx)
+	p.logger.WithContext(ctx).Debug("beginning database transaction")
+	spanCtx, span := startSpan(ctx, "begin", "BEGIN")
+	tx, err := p.Pool.Begin(spanCtx)
+	tracing.End(span, err)
 	if err != nil {
-		p.logger.WithError(err).Error("failed to begin transaction")
+		p.logger.WithContext(ctx).WithError(err).Error("failed to begin transaction")
 		return nil, err
 	}
 	return tx, nil
@@ -42,17 +52,20 @@ func (p *PostgresAdapter) BeginTx(ctx context.Context) (pgx.Tx, error) {
 
 // Exec executes a query
 func (p *PostgresAdapter) Exec(ctx context.Context, query string, args ...interface{}) error {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("executing database query")
 
+	spanCtx, span := startSpan(ctx, "exec", query)
 	start := time.Now()
-	_, err := p.Pool.Exec(ctx, query, args...)
+	_, err := p.Pool.Exec(spanCtx, query, args...)
 	duration := time.Since(start)
+	tracing.End(span, err)
+	p.metrics.ObserveQuery("exec", duration, err)
 
 	if err != nil {
-		p.logger.WithError(err).WithFields(logrus.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"query":      query,
 			"duration":   duration,
 			"args_count": len(args),
@@ -60,7 +73,7 @@ func (p *PostgresAdapter) Exec(ctx context.Context, query string, args ...interf
 		return err
 	}
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("database query executed successfully")
@@ -70,17 +83,20 @@ func (p *PostgresAdapter) Exec(ctx context.Context, query string, args ...interf
 
 // ExecTx executes a query within a transaction
 func (p *PostgresAdapter) ExecTx(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) error {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("executing database query in transaction")
 
+	spanCtx, span := startSpan(ctx, "exec", query)
 	start := time.Now()
-	_, err := tx.Exec(ctx, query, args...)
+	_, err := tx.Exec(spanCtx, query, args...)
 	duration := time.Since(start)
+	tracing.End(span, err)
+	p.metrics.ObserveQuery("exec", duration, err)
 
 	if err != nil {
-		p.logger.WithError(err).WithFields(logrus.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"query":      query,
 			"duration":   duration,
 			"args_count": len(args),
@@ -88,7 +104,7 @@ func (p *PostgresAdapter) ExecTx(ctx context.Context, tx pgx.Tx, query string, a
 		return err
 	}
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("database query in transaction executed successfully")
@@ -98,16 +114,19 @@ func (p *PostgresAdapter) ExecTx(ctx context.Context, tx pgx.Tx, query string, a
 
 // QueryRow executes a query that returns a single row
 func (p *PostgresAdapter) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("querying single row from database")
 
+	spanCtx, span := startSpan(ctx, "query_row", query)
 	start := time.Now()
-	row := p.Pool.QueryRow(ctx, query, args...)
+	row := p.Pool.QueryRow(spanCtx, query, args...)
 	duration := time.Since(start)
+	span.End()
+	p.metrics.ObserveQuery("query_row", duration, nil)
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("single row query executed")
@@ -117,16 +136,19 @@ func (p *PostgresAdapter) QueryRow(ctx context.Context, query string, args ...in
 
 // QueryRowTx executes a query that returns a single row within a transaction
 func (p *PostgresAdapter) QueryRowTx(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) pgx.Row {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("querying single row from database in transaction")
 
+	spanCtx, span := startSpan(ctx, "query_row", query)
 	start := time.Now()
-	row := tx.QueryRow(ctx, query, args...)
+	row := tx.QueryRow(spanCtx, query, args...)
 	duration := time.Since(start)
+	span.End()
+	p.metrics.ObserveQuery("query_row", duration, nil)
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("single row query in transaction executed")
@@ -136,17 +158,20 @@ func (p *PostgresAdapter) QueryRowTx(ctx context.Context, tx pgx.Tx, query strin
 
 // Query executes a query that returns multiple rows
 func (p *PostgresAdapter) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("querying multiple rows from database")
 
+	spanCtx, span := startSpan(ctx, "query", query)
 	start := time.Now()
-	rows, err := p.Pool.Query(ctx, query, args...)
+	rows, err := p.Pool.Query(spanCtx, query, args...)
 	duration := time.Since(start)
+	tracing.End(span, err)
+	p.metrics.ObserveQuery("query", duration, err)
 
 	if err != nil {
-		p.logger.WithError(err).WithFields(logrus.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"query":      query,
 			"duration":   duration,
 			"args_count": len(args),
@@ -154,7 +179,7 @@ func (p *PostgresAdapter) Query(ctx context.Context, query string, args ...inter
 		return nil, err
 	}
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("multiple rows query executed successfully")
@@ -164,17 +189,20 @@ func (p *PostgresAdapter) Query(ctx context.Context, query string, args ...inter
 
 // QueryTx executes a query that returns multiple rows within a transaction
 func (p *PostgresAdapter) QueryTx(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) (pgx.Rows, error) {
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"query":      query,
 		"args_count": len(args),
 	}).Debug("querying multiple rows from database in transaction")
 
+	spanCtx, span := startSpan(ctx, "query", query)
 	start := time.Now()
-	rows, err := tx.Query(ctx, query, args...)
+	rows, err := tx.Query(spanCtx, query, args...)
 	duration := time.Since(start)
+	tracing.End(span, err)
+	p.metrics.ObserveQuery("query", duration, err)
 
 	if err != nil {
-		p.logger.WithError(err).WithFields(logrus.Fields{
+		p.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
 			"query":      query,
 			"duration":   duration,
 			"args_count": len(args),
@@ -182,10 +210,18 @@ func (p *PostgresAdapter) QueryTx(ctx context.Context, tx pgx.Tx, query string,
 		return nil, err
 	}
 
-	p.logger.WithFields(logrus.Fields{
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"duration":   duration,
 		"args_count": len(args),
 	}).Debug("multiple rows query in transaction executed successfully")
 
 	return rows, nil
 }
+
+// startSpan начинает спан запроса к БД с текстом SQL; значения параметров в спан не попадают
+func startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "postgres."+operation,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBQueryText(query),
+	)
+}
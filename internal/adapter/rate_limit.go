@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type rateLimitRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+// NewRateLimitRepository хранит счетчики в БД, чтобы лимиты были общими для всех экземпляров сервиса
+func NewRateLimitRepository(adapter *PostgresAdapter) usecase.RateLimitRepository {
+	return &rateLimitRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// Increment атомарно увеличивает счетчик окна, создавая его при первом запросе
+func (r *rateLimitRepo) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	if key == "" {
+		return 0, &ValidationError{"rate limit key is required"}
+	}
+
+	query, args, err := r.psql.Insert("rate_limit_counters").
+		Columns("key", "window_start", "count", "expires_at").
+		Values(key, windowStart, 1, expiresAt).
+		Suffix("ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + 1 RETURNING count").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build upsert query for rate limit counter")
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to increment rate limit counter")
+		return 0, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *rateLimitRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	query, args, err := r.psql.Delete("rate_limit_counters").
+		Where(squirrel.Lt{"expires_at": before}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for expired rate limit counters")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to delete expired rate limit counters")
+		return fmt.Errorf("failed to delete expired rate limit counters: %w", err)
+	}
+
+	return nil
+}
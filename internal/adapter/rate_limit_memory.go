@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"chat-service/internal/usecase"
+)
+
+// memoryRateLimitCounter счетчик одного окна
+type memoryRateLimitCounter struct {
+	windowStart time.Time
+	expiresAt   time.Time
+	count       int
+}
+
+// memoryRateLimitRepo хранит счетчики в памяти процесса: при нескольких экземплярах сервиса
+// лимиты действуют на каждом экземпляре отдельно
+type memoryRateLimitRepo struct {
+	mu       sync.Mutex
+	counters map[string]*memoryRateLimitCounter
+}
+
+// NewMemoryRateLimitRepository создает хранилище счетчиков в памяти для одного экземпляра сервиса
+func NewMemoryRateLimitRepository() usecase.RateLimitRepository {
+	return &memoryRateLimitRepo{counters: make(map[string]*memoryRateLimitCounter)}
+}
+
+func (r *memoryRateLimitRepo) Increment(_ context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Хранится только текущее окно ключа: предыдущие на решение уже не влияют
+	counter, ok := r.counters[key]
+	if !ok || !counter.windowStart.Equal(windowStart) {
+		counter = &memoryRateLimitCounter{windowStart: windowStart, expiresAt: expiresAt}
+		r.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, nil
+}
+
+func (r *memoryRateLimitRepo) DeleteExpired(_ context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, counter := range r.counters {
+		if counter.expiresAt.Before(before) {
+			delete(r.counters, key)
+		}
+	}
+	return nil
+}
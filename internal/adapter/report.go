@@ -0,0 +1,318 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type reportRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewReportRepository(adapter *PostgresAdapter) usecase.ReportRepository {
+	return &reportRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// Create сохраняет жалобу. Повторная жалоба того же пользователя на то же сообщение отклоняется
+func (r *reportRepo) Create(ctx context.Context, report *entity.Report) error {
+	if report == nil {
+		return &ValidationError{"report cannot be nil"}
+	}
+	if err := report.Validate(); err != nil {
+		return err
+	}
+
+	var comment interface{}
+	if report.Comment != "" {
+		comment = report.Comment
+	}
+
+	query, args, err := r.psql.Insert("reports").
+		Columns("id", "message_id", "reporter_id", "reason", "comment", "status", "created_at", "updated_at").
+		Values(report.ID, report.MessageID, report.ReporterID, report.Reason, comment, report.Status, report.CreatedAt, report.UpdatedAt).
+		Suffix("ON CONFLICT (message_id, reporter_id) DO NOTHING RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for report")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"message_id":  report.MessageID,
+				"reporter_id": report.ReporterID,
+			}).Warn("duplicate report rejected")
+			return apperror.Conflict(apperror.CodeReportDuplicate, "you have already reported this message")
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("message_id", report.MessageID).Error("failed to create report in database")
+		return fmt.Errorf("failed to insert report: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("report_id", returnedID).Info("report created successfully in database")
+	return nil
+}
+
+func (r *reportRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+	if id == uuid.Nil {
+		return nil, &ValidationError{"invalid report ID"}
+	}
+
+	query, args, err := r.selectReports().
+		Where(squirrel.Eq{"r.id": id}).
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for report by ID")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	report, err := r.scanReport(r.adapter.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("report_id", id).Warn("report not found")
+			return nil, &NotFoundError{"report not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("report_id", id).Error("failed to get report by ID")
+		return nil, fmt.Errorf("failed to query report: %w", err)
+	}
+
+	return report, nil
+}
+
+// List возвращает страницу очереди модерации, старые жалобы первыми
+func (r *reportRepo) List(ctx context.Context, filter entity.ReportFilter) ([]*entity.Report, int, error) {
+	where := squirrel.And{}
+	if filter.Status != nil {
+		where = append(where, squirrel.Eq{"r.status": *filter.Status})
+	}
+
+	countQuery, countArgs, err := r.psql.Select("COUNT(*)").From("reports r").Where(where).ToSql()
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build count query for reports")
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var total int
+	if err := r.adapter.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to count reports")
+		return nil, 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	query, args, err := r.selectReports().
+		Where(where).
+		OrderBy("r.created_at", "r.id").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for reports")
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to query reports")
+		return nil, 0, fmt.Errorf("failed to query reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]*entity.Report, 0)
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan report row")
+			return nil, 0, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during report rows iteration")
+		return nil, 0, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).Debugf("retrieved %d of %d reports", len(reports), total)
+	return reports, total, nil
+}
+
+// UpdateStatus меняет статус, только если жалоба все еще в статусе from.
+// Так два модератора не могут одновременно принять разные решения по одной жалобе
+func (r *reportRepo) UpdateStatus(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid report ID"}
+	}
+
+	// Возврат в очередь снимает отметку о решении
+	var resolvedByValue, resolvedAtValue interface{}
+	if to != entity.ReportStatusOpen {
+		resolvedByValue, resolvedAtValue = resolvedBy, at
+	}
+
+	query, args, err := r.psql.Update("reports").
+		Set("status", to).
+		Set("resolved_by", resolvedByValue).
+		Set("resolved_at", resolvedAtValue).
+		Set("updated_at", at).
+		Where(squirrel.Eq{"id": id, "status": from}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for report status")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("report_id", id).Warn("report not found in expected status")
+			return &NotFoundError{"report not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("report_id", id).Error("failed to update report status")
+		return fmt.Errorf("failed to update report status: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"report_id": id,
+		"status":    to,
+	}).Info("report status updated")
+	return nil
+}
+
+// selectReports выбирает жалобы вместе с сообщением, на которое они поданы
+func (r *reportRepo) selectReports() squirrel.SelectBuilder {
+	return r.psql.Select(
+		"r.id", "r.message_id", "r.reporter_id", "r.reason", "COALESCE(r.comment, '')", "r.status",
+		"r.resolved_by", "r.resolved_at", "r.created_at", "r.updated_at",
+		"m.user_id", "m.content", "m.hidden_at", "m.created_at", "m.updated_at",
+	).
+		From("reports r").
+		Join("messages m ON m.id = r.message_id")
+}
+
+func (r *reportRepo) scanReport(row pgx.Row) (*entity.Report, error) {
+	var report entity.Report
+	var message entity.Message
+	err := row.Scan(
+		&report.ID, &report.MessageID, &report.ReporterID, &report.Reason, &report.Comment, &report.Status,
+		&report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt, &report.UpdatedAt,
+		&message.UserID, &message.Content, &message.HiddenAt, &message.CreatedAt, &message.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	message.ID = report.MessageID
+	report.Message = &message
+	return &report, nil
+}
+
+type moderationActionRepo struct {
+	adapter *PostgresAdapter
+	psql    squirrel.StatementBuilderType
+}
+
+func NewModerationActionRepository(adapter *PostgresAdapter) usecase.ModerationActionRepository {
+	return &moderationActionRepo{
+		adapter: adapter,
+		psql:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *moderationActionRepo) Create(ctx context.Context, action *entity.ModerationAction) error {
+	if action == nil {
+		return &ValidationError{"moderation action cannot be nil"}
+	}
+
+	var note interface{}
+	if action.Note != "" {
+		note = action.Note
+	}
+
+	query, args, err := r.psql.Insert("moderation_actions").
+		Columns("id", "report_id", "moderator_id", "action", "message_id", "target_user_id", "note", "created_at").
+		Values(action.ID, action.ReportID, action.ModeratorID, action.Action, action.MessageID, action.TargetUserID, note, action.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for moderation action")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("action", action.Action).Error("failed to create moderation action in database")
+		return fmt.Errorf("failed to insert moderation action: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("moderation_action_id", returnedID).Info("moderation action recorded")
+	return nil
+}
+
+func (r *moderationActionRepo) ListByReportID(ctx context.Context, reportID uuid.UUID) ([]*entity.ModerationAction, error) {
+	if reportID == uuid.Nil {
+		return nil, &ValidationError{"invalid report ID"}
+	}
+
+	query, args, err := r.psql.Select(
+		"id", "report_id", "moderator_id", "action", "message_id", "target_user_id", "COALESCE(note, '')", "created_at",
+	).
+		From("moderation_actions").
+		Where(squirrel.Eq{"report_id": reportID}).
+		OrderBy("created_at").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for moderation actions")
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Error("failed to query moderation actions")
+		return nil, fmt.Errorf("failed to query moderation actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := make([]*entity.ModerationAction, 0)
+	for rows.Next() {
+		var action entity.ModerationAction
+		err := rows.Scan(
+			&action.ID, &action.ReportID, &action.ModeratorID, &action.Action,
+			&action.MessageID, &action.TargetUserID, &action.Note, &action.CreatedAt,
+		)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Error("failed to scan moderation action row")
+			return nil, fmt.Errorf("failed to scan moderation action: %w", err)
+		}
+		actions = append(actions, &action)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Error("error during moderation action rows iteration")
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return actions, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
 )
 
 type sessionRepo struct {
@@ -38,18 +39,18 @@ func (r *sessionRepo) Create(ctx context.Context, session *entity.Session) error
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build insert query for session")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for session")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	var returnedID uuid.UUID
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("session_id", session.ID).Error("failed to create session in database")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("session_id", session.ID).Error("failed to create session in database")
 		return fmt.Errorf("failed to insert session: %w", err)
 	}
 
-	r.adapter.logger.WithField("session_id", returnedID).Info("session created successfully in database")
+	r.adapter.logger.WithContext(ctx).WithField("session_id", returnedID).Info("session created successfully in database")
 	return nil
 }
 
@@ -65,7 +66,7 @@ func (r *sessionRepo) GetByToken(ctx context.Context, token string) (*entity.Ses
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for session by token")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for session by token")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -76,20 +77,20 @@ func (r *sessionRepo) GetByToken(ctx context.Context, token string) (*entity.Ses
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("token", r.maskToken(token)).Warn("session not found by token")
+			r.adapter.logger.WithContext(ctx).WithField("token", r.maskToken(token)).Warn("session not found by token")
 			return nil, &NotFoundError{"session not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("token", r.maskToken(token)).Error("failed to get session by token")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("token", r.maskToken(token)).Error("failed to get session by token")
 		return nil, fmt.Errorf("failed to query session: %w", err)
 	}
 
 	// Проверяем срок действия
 	if session.ExpiresAt.Before(time.Now()) {
-		r.adapter.logger.WithField("session_id", session.ID).Warn("session expired")
+		r.adapter.logger.WithContext(ctx).WithField("session_id", session.ID).Warn("session expired")
 		return nil, &ValidationError{"session expired"}
 	}
 
-	r.adapter.logger.WithField("session_id", session.ID).Debug("session retrieved by token")
+	r.adapter.logger.WithContext(ctx).WithField("session_id", session.ID).Debug("session retrieved by token")
 	return &session, nil
 }
 
@@ -106,7 +107,7 @@ func (r *sessionRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*entit
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for session by user ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for session by user ID")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -117,20 +118,20 @@ func (r *sessionRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*entit
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("user_id", userID).Warn("session not found by user ID")
+			r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Warn("session not found by user ID")
 			return nil, &NotFoundError{"session not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("user_id", userID).Error("failed to get session by user ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to get session by user ID")
 		return nil, fmt.Errorf("failed to query session: %w", err)
 	}
 
 	// Проверяем срок действия
 	if session.ExpiresAt.Before(time.Now()) {
-		r.adapter.logger.WithField("session_id", session.ID).Warn("session expired")
+		r.adapter.logger.WithContext(ctx).WithField("session_id", session.ID).Warn("session expired")
 		return nil, &ValidationError{"session expired"}
 	}
 
-	r.adapter.logger.WithField("session_id", session.ID).Debug("session retrieved by user ID")
+	r.adapter.logger.WithContext(ctx).WithField("session_id", session.ID).Debug("session retrieved by user ID")
 	return &session, nil
 }
 
@@ -145,7 +146,7 @@ func (r *sessionRepo) Delete(ctx context.Context, id uuid.UUID) error {
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build delete query for session")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for session")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -153,14 +154,14 @@ func (r *sessionRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("session_id", id).Warn("session not found for deletion")
+			r.adapter.logger.WithContext(ctx).WithField("session_id", id).Warn("session not found for deletion")
 			return &NotFoundError{"session not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("session_id", id).Error("failed to delete session")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("session_id", id).Error("failed to delete session")
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
-	r.adapter.logger.WithField("session_id", deletedID).Info("session deleted successfully")
+	r.adapter.logger.WithContext(ctx).WithField("session_id", deletedID).Info("session deleted successfully")
 	return nil
 }
 
@@ -175,7 +176,7 @@ func (r *sessionRepo) DeleteByToken(ctx context.Context, token string) error {
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build delete query for session by token")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for session by token")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -183,14 +184,87 @@ func (r *sessionRepo) DeleteByToken(ctx context.Context, token string) error {
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&deletedID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("token", r.maskToken(token)).Warn("session not found for deletion by token")
+			r.adapter.logger.WithContext(ctx).WithField("token", r.maskToken(token)).Warn("session not found for deletion by token")
 			return &NotFoundError{"session not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("token", r.maskToken(token)).Error("failed to delete session by token")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("token", r.maskToken(token)).Error("failed to delete session by token")
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
-	r.adapter.logger.WithField("session_id", deletedID).Info("session deleted successfully by token")
+	r.adapter.logger.WithContext(ctx).WithField("session_id", deletedID).Info("session deleted successfully by token")
+	return nil
+}
+
+// DeleteByUserID удаляет все сессии пользователя (например, после смены пароля)
+func (r *sessionRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for sessions by user ID")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete sessions by user ID")
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", userID).Info("all user sessions deleted successfully")
+	return nil
+}
+
+// CountActive возвращает число сессий, срок действия которых еще не истек
+func (r *sessionRepo) CountActive(ctx context.Context, now time.Time) (int, error) {
+	query, args, err := r.psql.Select("COUNT(*)").
+		From("sessions").
+		Where(squirrel.Gt{"expires_at": now}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build count query for active sessions")
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.adapter.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to count active sessions")
+		return 0, fmt.Errorf("failed to count active sessions: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteByUserIDExcept удаляет все сессии пользователя, кроме указанной (текущей)
+func (r *sessionRepo) DeleteByUserIDExcept(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Delete("sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.NotEq{"id": keepSessionID}).
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build delete query for other user sessions")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err := r.adapter.Exec(ctx, query, args...); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete other user sessions")
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":         userID,
+		"kept_session_id": keepSessionID,
+	}).Info("other user sessions deleted successfully")
 	return nil
 }
 
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"chat-service/internal/entity"
 	"chat-service/internal/usecase"
@@ -10,6 +12,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
 )
 
 type userRepo struct {
@@ -30,25 +33,30 @@ func (r *userRepo) Create(ctx context.Context, user *entity.User) error {
 		return err
 	}
 
+	role := user.Role
+	if role == "" {
+		role = entity.RoleUser
+	}
+
 	query, args, err := r.psql.Insert("users").
-		Columns("id", "username", "email", "password", "created_at", "updated_at").
-		Values(user.ID, user.Username, user.Email, user.Password, user.CreatedAt, user.UpdatedAt).
+		Columns("id", "username", "email", "password", "role", "created_at", "updated_at").
+		Values(user.ID, user.Username, user.Email, user.Password, role, user.CreatedAt, user.UpdatedAt).
 		Suffix("RETURNING id").
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build insert query for user")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build insert query for user")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
 	var returnedID uuid.UUID
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to create user in database")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to create user in database")
 		return fmt.Errorf("failed to insert user: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", returnedID).Info("user created successfully in database")
+	r.adapter.logger.WithContext(ctx).WithField("user_id", returnedID).Info("user created successfully in database")
 	return nil
 }
 
@@ -57,33 +65,29 @@ func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, err
 		return nil, &ValidationError{"invalid user ID"}
 	}
 
-	query, args, err := r.psql.Select("id", "username", "email", "password", "created_at", "updated_at").
-		From("users").
+	query, args, err := r.selectUsers().
 		Where(squirrel.Eq{"id": id}).
 		Limit(1).
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for user by ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for user by ID")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var user entity.User
-	err = r.adapter.QueryRow(ctx, query, args...).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := r.scanUser(r.adapter.QueryRow(ctx, query, args...))
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("user_id", id).Warn("user not found")
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("user not found")
 			return nil, &NotFoundError{"user not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("user_id", id).Error("failed to get user by ID")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to get user by ID")
 		return nil, fmt.Errorf("failed to query user: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", user.ID).Debug("user retrieved by ID")
-	return &user, nil
+	r.adapter.logger.WithContext(ctx).WithField("user_id", user.ID).Debug("user retrieved by ID")
+	return user, nil
 }
 
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
@@ -91,33 +95,29 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*entity.User,
 		return nil, &ValidationError{"email is required"}
 	}
 
-	query, args, err := r.psql.Select("id", "username", "email", "password", "created_at", "updated_at").
-		From("users").
+	query, args, err := r.selectUsers().
 		Where(squirrel.Eq{"email": email}).
 		Limit(1).
 		ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build select query for user by email")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for user by email")
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	var user entity.User
-	err = r.adapter.QueryRow(ctx, query, args...).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := r.scanUser(r.adapter.QueryRow(ctx, query, args...))
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("email", email).Warn("user not found by email")
+			r.adapter.logger.WithContext(ctx).WithField("email", email).Warn("user not found by email")
 			return nil, &NotFoundError{"user not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("email", email).Error("failed to get user by email")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("email", email).Error("failed to get user by email")
 		return nil, fmt.Errorf("failed to query user: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", user.ID).Debug("user retrieved by email")
-	return &user, nil
+	r.adapter.logger.WithContext(ctx).WithField("user_id", user.ID).Debug("user retrieved by email")
+	return user, nil
 }
 
 func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
@@ -125,6 +125,8 @@ func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
 	queryBuilder := r.psql.Update("users").
 		Set("username", user.Username).
 		Set("email", user.Email).
+		// При смене email подтверждение сбрасывается
+		Set("email_verified_at", squirrel.Expr("CASE WHEN email = ? THEN email_verified_at ELSE NULL END", user.Email)).
 		Set("updated_at", user.UpdatedAt).
 		Where(squirrel.Eq{"id": user.ID}).
 		Suffix("RETURNING id")
@@ -137,7 +139,7 @@ func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
 	query, args, err := queryBuilder.ToSql()
 
 	if err != nil {
-		r.adapter.logger.WithError(err).Error("failed to build update query for user")
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for user")
 		return fmt.Errorf("failed to build query: %w", err)
 	}
 
@@ -145,14 +147,84 @@ func (r *userRepo) Update(ctx context.Context, user *entity.User) error {
 	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("user_id", user.ID).Warn("user not found for update")
+			r.adapter.logger.WithContext(ctx).WithField("user_id", user.ID).Warn("user not found for update")
 			return &NotFoundError{"user not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("user_id", user.ID).Error("failed to update user")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to update user")
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", returnedID).Info("user updated successfully")
+	r.adapter.logger.WithContext(ctx).WithField("user_id", returnedID).Info("user updated successfully")
+	return nil
+}
+
+// UpdateRole меняет глобальную роль пользователя
+func (r *userRepo) UpdateRole(ctx context.Context, id uuid.UUID, role entity.Role) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+	if !role.IsValid() {
+		return &ValidationError{"invalid role"}
+	}
+
+	query, args, err := r.psql.Update("users").
+		Set("role", role).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for user role")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("user not found for role update")
+			return &NotFoundError{"user not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to update user role")
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": returnedID,
+		"role":    role,
+	}).Info("user role updated")
+	return nil
+}
+
+// MarkEmailVerified отмечает email подтвержденным, если он не менялся с момента выпуска токена
+func (r *userRepo) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	query, args, err := r.psql.Update("users").
+		Set("email_verified_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"id": id, "email": email}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build email verification query")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("user with given email not found for verification")
+			return &NotFoundError{"user not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to mark email verified")
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithField("user_id", returnedID).Info("user email verified")
 	return nil
 }
 
@@ -169,7 +241,7 @@ func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	defer func() {
 		if err != nil {
 			tx.Rollback(ctx)
-			r.adapter.logger.WithField("user_id", id).Warn("transaction rolled back")
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("transaction rolled back")
 		}
 	}()
 
@@ -183,7 +255,7 @@ func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 	err = r.adapter.ExecTx(ctx, tx, msgQuery, msgArgs...)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("user_id", id).Error("failed to delete user messages")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to delete user messages")
 		return fmt.Errorf("failed to delete user messages: %w", err)
 	}
 
@@ -197,7 +269,7 @@ func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 	err = r.adapter.ExecTx(ctx, tx, sessQuery, sessArgs...)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("user_id", id).Error("failed to delete user sessions")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to delete user sessions")
 		return fmt.Errorf("failed to delete user sessions: %w", err)
 	}
 
@@ -214,24 +286,162 @@ func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	err = r.adapter.QueryRowTx(ctx, tx, userQuery, userArgs...).Scan(&deletedID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			r.adapter.logger.WithField("user_id", id).Warn("user not found for deletion")
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("user not found for deletion")
 			return &NotFoundError{"user not found"}
 		}
-		r.adapter.logger.WithError(err).WithField("user_id", id).Error("failed to delete user")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to delete user")
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	// Коммитим транзакцию
 	err = tx.Commit(ctx)
 	if err != nil {
-		r.adapter.logger.WithError(err).WithField("user_id", id).Error("failed to commit transaction")
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to commit transaction")
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	r.adapter.logger.WithField("user_id", deletedID).Info("user and all related data deleted successfully")
+	r.adapter.logger.WithContext(ctx).WithField("user_id", deletedID).Info("user and all related data deleted successfully")
 	return nil
 }
 
+// List возвращает страницу пользователей по фильтру и общее количество подходящих пользователей
+func (r *userRepo) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	where := squirrel.And{}
+	if filter.Email != "" {
+		where = append(where, squirrel.ILike{"email": "%" + escapeLike(filter.Email) + "%"})
+	}
+	if filter.Username != "" {
+		where = append(where, squirrel.ILike{"username": "%" + escapeLike(filter.Username) + "%"})
+	}
+	if filter.CreatedFrom != nil {
+		where = append(where, squirrel.GtOrEq{"created_at": *filter.CreatedFrom})
+	}
+	if filter.CreatedTo != nil {
+		where = append(where, squirrel.Lt{"created_at": *filter.CreatedTo})
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			where = append(where, squirrel.NotEq{"suspended_at": nil})
+		} else {
+			where = append(where, squirrel.Eq{"suspended_at": nil})
+		}
+	}
+
+	countQuery, countArgs, err := r.psql.Select("COUNT(*)").From("users").Where(where).ToSql()
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build count query for users")
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var total int
+	if err := r.adapter.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to count users")
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query, args, err := r.selectUsers().
+		Where(where).
+		OrderBy("created_at DESC", "id").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build select query for users")
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.adapter.Query(ctx, query, args...)
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to query users")
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*entity.User, 0)
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to scan user row")
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		user.Password = ""
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("error during user rows iteration")
+		return nil, 0, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).Debugf("retrieved %d of %d users", len(users), total)
+	return users, total, nil
+}
+
+// SetSuspended блокирует пользователя (suspendedAt != nil) или снимает блокировку (suspendedAt == nil)
+func (r *userRepo) SetSuspended(ctx context.Context, id uuid.UUID, suspendedAt *time.Time, reason string) error {
+	if id == uuid.Nil {
+		return &ValidationError{"invalid user ID"}
+	}
+
+	var reasonValue interface{}
+	if suspendedAt != nil && reason != "" {
+		reasonValue = reason
+	}
+
+	query, args, err := r.psql.Update("users").
+		Set("suspended_at", suspendedAt).
+		Set("suspension_reason", reasonValue).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		r.adapter.logger.WithContext(ctx).WithError(err).Error("failed to build update query for user suspension")
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var returnedID uuid.UUID
+	err = r.adapter.QueryRow(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.adapter.logger.WithContext(ctx).WithField("user_id", id).Warn("user not found for suspension update")
+			return &NotFoundError{"user not found"}
+		}
+		r.adapter.logger.WithContext(ctx).WithError(err).WithField("user_id", id).Error("failed to update user suspension")
+		return fmt.Errorf("failed to update user suspension: %w", err)
+	}
+
+	r.adapter.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":   returnedID,
+		"suspended": suspendedAt != nil,
+	}).Info("user suspension updated")
+	return nil
+}
+
+func (r *userRepo) selectUsers() squirrel.SelectBuilder {
+	return r.psql.Select(
+		"id", "username", "email", "password", "role", "email_verified_at",
+		"suspended_at", "COALESCE(suspension_reason, '')", "created_at", "updated_at",
+	).From("users")
+}
+
+func (r *userRepo) scanUser(row pgx.Row) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt,
+		&user.SuspendedAt, &user.SuspensionReason, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE во вводе пользователя
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 // Валидация пользователя
 func (r *userRepo) validateUser(user *entity.User) error {
 	if user == nil {
@@ -276,3 +486,7 @@ type NotFoundError struct {
 func (e *NotFoundError) Error() string {
 	return e.Message
 }
+
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
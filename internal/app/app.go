@@ -7,6 +7,7 @@ import (
 
 	postgres "chat-service/internal/adapter"
 	"chat-service/internal/handler"
+	"chat-service/internal/health"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,19 +16,27 @@ type App struct {
 	httpServer *http.Server
 	dbAdapter  *postgres.PostgresAdapter
 	handler    *handler.Handler
+	health     *health.Health
+	drainDelay time.Duration
 	logger     *logrus.Logger
 }
 
+// NewApp создает приложение. При остановке готовность сначала переключается в fail,
+// и HTTP сервер закрывается только через drainDelay, чтобы балансировщик успел убрать экземпляр
 func NewApp(
 	httpServer *http.Server,
 	dbAdapter *postgres.PostgresAdapter,
 	handler *handler.Handler,
+	appHealth *health.Health,
+	drainDelay time.Duration,
 	logger *logrus.Logger,
 ) *App {
 	return &App{
 		httpServer: httpServer,
 		dbAdapter:  dbAdapter,
 		handler:    handler,
+		health:     appHealth,
+		drainDelay: drainDelay,
 		logger:     logger,
 	}
 }
@@ -46,6 +55,15 @@ func (a *App) Start() error {
 func (a *App) Stop(ctx context.Context) error {
 	a.logger.Info("shutting down application gracefully")
 
+	// Перестаем быть готовыми, но продолжаем обслуживать запросы, пока балансировщик не заметит
+	if a.health != nil {
+		a.health.StartDraining()
+		select {
+		case <-time.After(a.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
 	// Закрываем HTTP сервер с таймаутом
 	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
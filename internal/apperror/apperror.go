@@ -0,0 +1,141 @@
+// Package apperror общие ошибки предметной области. Каждая ошибка несет машиночитаемый код,
+// по которому клиент может отличить одну причину от другой, и категорию, по которой
+// выбирается HTTP статус ответа
+package apperror
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kind категория ошибки
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindTooManyRequests
+)
+
+// FieldError ошибка конкретного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error ошибка с кодом. Message показывается клиенту, поэтому не должен содержать внутренних подробностей
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Fields ошибки отдельных полей запроса, если они известны
+	Fields []FieldError
+	// Wait через сколько можно повторить запрос; только для KindTooManyRequests
+	Wait time.Duration
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Internal ошибка сервера; message не должен раскрывать причину
+func Internal(message string) *Error {
+	return New(KindInternal, CodeInternal, message)
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Unprocessable запрос корректен, но не может быть выполнен в текущем состоянии
+func Unprocessable(code, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func TooManyRequests(code, message string, wait time.Duration) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message, Wait: wait}
+}
+
+// InvalidField ошибка валидации одного поля запроса
+func InvalidField(code, field, message string) *Error {
+	return &Error{
+		Kind:    KindValidation,
+		Code:    code,
+		Message: message,
+		Fields:  []FieldError{{Field: field, Message: message}},
+	}
+}
+
+// InvalidFields ошибка валидации нескольких полей; сообщение собирается из сообщений полей
+func InvalidFields(code string, fields []FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return &Error{
+		Kind:    KindValidation,
+		Code:    code,
+		Message: strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NotFound позволяет проверкам isNotFound в usecase распознавать ошибку так же,
+// как NotFoundError репозиториев
+func (e *Error) NotFound() bool {
+	return e.Kind == KindNotFound
+}
+
+// RetryAfter позволяет обработчику выставить заголовок Retry-After
+func (e *Error) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// As находит *Error в цепочке ошибок
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeOf возвращает код ошибки или пустую строку, если ошибка не из этого пакета
+func CodeOf(err error) string {
+	if appErr, ok := As(err); ok {
+		return appErr.Code
+	}
+	return ""
+}
+
+// IsKind проверяет категорию ошибки
+func IsKind(err error, kind Kind) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Kind == kind
+}
@@ -0,0 +1,56 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAs_WrappedError(t *testing.T) {
+	// Arrange
+	err := fmt.Errorf("register: %w", Conflict(CodeUserEmailTaken, "user with this email already exists"))
+
+	// Act
+	appErr, ok := As(err)
+
+	// Assert
+	require.True(t, ok)
+	assert.Equal(t, KindConflict, appErr.Kind)
+	assert.Equal(t, CodeUserEmailTaken, CodeOf(err))
+	assert.True(t, IsKind(err, KindConflict))
+	assert.False(t, IsKind(err, KindValidation))
+}
+
+func TestCodeOf_ForeignError(t *testing.T) {
+	assert.Empty(t, CodeOf(errors.New("connection refused")))
+	assert.Empty(t, CodeOf(nil))
+}
+
+func TestInvalidFields(t *testing.T) {
+	// Arrange
+	fields := []FieldError{
+		{Field: "email", Message: "email is required"},
+		{Field: "password", Message: "password must be at least 8 characters"},
+	}
+
+	// Act
+	err := InvalidFields(CodeValidationFailed, fields)
+
+	// Assert
+	assert.Equal(t, KindValidation, err.Kind)
+	assert.Equal(t, "email is required; password must be at least 8 characters", err.Error())
+	assert.Equal(t, fields, err.Fields)
+}
+
+func TestError_LegacyInterfaces(t *testing.T) {
+	notFound := NotFound(CodeMessageNotFound, "message not found")
+	tooMany := TooManyRequests(CodeMessageSlowMode, "slow mode is enabled", 30*time.Second)
+
+	assert.True(t, notFound.NotFound())
+	assert.False(t, tooMany.NotFound())
+	assert.Equal(t, 30*time.Second, tooMany.RetryAfter())
+}
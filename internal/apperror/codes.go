@@ -0,0 +1,91 @@
+package apperror
+
+// Коды ошибок — часть API: клиенты опираются на них, поэтому существующие коды не переименовываются.
+// Формат: <область>.<причина>
+const (
+	// Общие коды, когда более точная причина не известна
+	CodeInternal         = "internal.error"
+	CodeInvalidRequest   = "request.invalid"
+	CodeValidationFailed = "validation.failed"
+	CodeNotFound         = "resource.not_found"
+	CodeUnauthorized     = "auth.unauthorized"
+	CodeForbidden        = "auth.forbidden"
+	CodeRateLimited      = "rate_limit.exceeded"
+
+	// Аутентификация и сессии
+	CodeInvalidCredentials  = "auth.invalid_credentials"
+	CodeAccountSuspended    = "auth.account_suspended"
+	CodeSessionInvalid      = "auth.session_invalid"
+	CodeSessionExpired      = "auth.session_expired"
+	CodeTokenInvalid        = "auth.token_invalid"
+	CodeAPIKeyInvalid       = "auth.api_key_invalid"
+	CodeAPIKeyRevoked       = "auth.api_key_revoked"
+	CodeSessionRequired     = "auth.session_required"
+	CodeScopeMissing        = "auth.scope_missing"
+	CodePermissionDenied    = "auth.permission_denied"
+	CodeLoginLocked         = "auth.login_locked"
+	CodeAuthorizationHeader = "auth.header_invalid"
+
+	// Пользователи
+	CodeUserNotFound               = "user.not_found"
+	CodeUserEmailTaken             = "user.email_taken"
+	CodeUserCurrentPasswordInvalid = "user.current_password_invalid"
+	CodeUserPasswordUnchanged      = "user.password_unchanged"
+	CodeUserEmailNotVerified       = "user.email_not_verified"
+	CodeUserSelfAction             = "user.self_action"
+
+	// Двухфакторная аутентификация
+	CodeMFAAlreadyEnabled      = "mfa.already_enabled"
+	CodeMFANotEnabled          = "mfa.not_enabled"
+	CodeMFAEnrollmentMissing   = "mfa.enrollment_not_started"
+	CodeMFAInvalidCode         = "mfa.invalid_code"
+	CodeMFAChallengeInvalid    = "mfa.challenge_invalid"
+	CodePasswordResetInvalid   = "password.reset_token_invalid"
+	CodeVerificationInvalid    = "verification.token_invalid"
+	CodeVerificationDone       = "verification.already_verified"
+	CodeVerificationResendWait = "verification.resend_too_soon"
+
+	// Вход через OpenID Connect
+	CodeOIDCProviderNotFound        = "oidc.provider_not_found"
+	CodeOIDCLoginInvalid            = "oidc.login_request_invalid"
+	CodeOIDCAuthFailed              = "oidc.authentication_failed"
+	CodeOIDCEmailMissing            = "oidc.email_missing"
+	CodeOIDCProviderEmailUnverified = "oidc.provider_email_unverified"
+	CodeOIDCAccountEmailUnverified  = "oidc.account_email_unverified"
+
+	// API ключи
+	CodeAPIKeyLimitReached = "api_key.limit_reached"
+
+	// Роли
+	CodeRoleInvalid = "role.invalid"
+
+	// Сообщения
+	CodeMessageNotFound          = "message.not_found"
+	CodeMessageNotOwner          = "message.not_owner"
+	CodeMessageProhibitedContent = "message.prohibited_content"
+	CodeMessageMentionBlocked    = "message.mention_blocked"
+	CodeMessageTooManyMentions   = "message.too_many_mentions"
+	CodeMessageRateLimited       = "message.rate_limited"
+	CodeMessageDuplicate         = "message.duplicate"
+	CodeMessageSlowMode          = "message.slow_mode"
+
+	// Жалобы и модерация
+	CodeReportDuplicate       = "report.duplicate"
+	CodeReportOwnMessage      = "report.own_message"
+	CodeReportTransition      = "report.invalid_transition"
+	CodeReportConflict        = "report.concurrent_update"
+	CodeModerationStaffTarget = "moderation.staff_target"
+
+	// Блокировки
+	CodeBlockSelf    = "block.self"
+	CodeBlockMissing = "block.not_found"
+
+	// Ключи идемпотентности
+	CodeIdempotencyKeyInvalid = "idempotency.key_invalid"
+	CodeIdempotencyKeyReused  = "idempotency.key_reused"
+	CodeIdempotencyInProgress = "idempotency.request_in_progress"
+
+	// Администрирование сервиса
+	CodeLogLevelInvalid  = "log_level.invalid"
+	CodeLogLevelDuration = "log_level.duration_invalid"
+)
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Права, которые можно выдать API ключу
+const (
+	ScopeMessagesRead  = "messages:read"
+	ScopeMessagesWrite = "messages:write"
+	ScopeProfileRead   = "profile:read"
+)
+
+// APIKeyScopes все известные права API ключей
+var APIKeyScopes = []string{ScopeMessagesRead, ScopeMessagesWrite, ScopeProfileRead}
+
+// APIKey персональный ключ доступа для ботов и скриптов. Хранится только хэш ключа
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive проверяет, что ключ не отозван и не истек
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasScope проверяет, выдано ли ключу право
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func (k *APIKey) Validate() error {
+	if k.UserID == uuid.Nil {
+		return &ValidationError{"user_id is required"}
+	}
+	if k.Name == "" {
+		return &ValidationError{"name is required"}
+	}
+	if utf8.RuneCountInString(k.Name) > 100 {
+		return &ValidationError{"name must be at most 100 characters"}
+	}
+	if k.Prefix == "" || k.KeyHash == "" {
+		return &ValidationError{"prefix and key_hash are required"}
+	}
+	if len(k.Scopes) == 0 {
+		return &ValidationError{"at least one scope is required"}
+	}
+	for _, scope := range k.Scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return &ValidationError{"unknown scope: " + scope}
+		}
+	}
+	return nil
+}
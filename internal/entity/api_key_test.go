@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_Validate_NameLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		wantErr bool
+	}{
+		{name: "ascii at limit", keyName: strings.Repeat("b", 100)},
+		{name: "ascii over limit", keyName: strings.Repeat("b", 101), wantErr: true},
+		{name: "cyrillic at limit", keyName: strings.Repeat("б", 100)},
+		{name: "cyrillic over limit", keyName: strings.Repeat("б", 101), wantErr: true},
+		{name: "emoji at limit", keyName: strings.Repeat("🤖", 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			key := &APIKey{
+				UserID:  uuid.New(),
+				Name:    tt.keyName,
+				Prefix:  "chk_abcdefgh",
+				KeyHash: "hash",
+				Scopes:  []string{ScopeMessagesRead},
+			}
+
+			// Act
+			err := key.Validate()
+
+			// Assert
+			if tt.wantErr {
+				assert.EqualError(t, err, "name must be at most 100 characters")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserBlock блокировка одного пользователя другим: сообщения заблокированного скрыты
+// от блокирующего, а упоминать блокирующего заблокированный не может
+type UserBlock struct {
+	BlockerID uuid.UUID `json:"-"`
+	BlockedID uuid.UUID `json:"user_id"`
+	// BlockedUsername заполняется при выдаче списка блокировок
+	BlockedUsername string    `json:"username,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (b *UserBlock) Validate() error {
+	if b.BlockerID == uuid.Nil || b.BlockedID == uuid.Nil {
+		return &ValidationError{"blocker and blocked user are required"}
+	}
+	if b.BlockerID == b.BlockedID {
+		return &ValidationError{"you cannot block yourself"}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ContentFilterRuleKind способ сопоставления правила с текстом
+type ContentFilterRuleKind string
+
+const (
+	// ContentFilterKindWord слово или фраза целиком, после нормализации и свертки похожих символов
+	ContentFilterKindWord ContentFilterRuleKind = "word"
+	// ContentFilterKindRegex регулярное выражение без учета регистра
+	ContentFilterKindRegex ContentFilterRuleKind = "regex"
+)
+
+// ContentFilterAction что сделать с сообщением при срабатывании правила
+type ContentFilterAction string
+
+const (
+	ContentFilterActionReject ContentFilterAction = "reject"
+	ContentFilterActionMask   ContentFilterAction = "mask"
+	ContentFilterActionFlag   ContentFilterAction = "flag"
+)
+
+const maxContentFilterPatternLength = 500
+
+// ContentFilterRule правило фильтрации содержимого сообщений
+type ContentFilterRule struct {
+	ID          uuid.UUID             `json:"id"`
+	Kind        ContentFilterRuleKind `json:"kind"`
+	Pattern     string                `json:"pattern"`
+	Action      ContentFilterAction   `json:"action"`
+	Enabled     bool                  `json:"enabled"`
+	Description string                `json:"description,omitempty"`
+	CreatedBy   *uuid.UUID            `json:"created_by,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+func (r *ContentFilterRule) Validate() error {
+	switch r.Kind {
+	case ContentFilterKindWord, ContentFilterKindRegex:
+	default:
+		return &ValidationError{"kind must be word or regex"}
+	}
+	switch r.Action {
+	case ContentFilterActionReject, ContentFilterActionMask, ContentFilterActionFlag:
+	default:
+		return &ValidationError{"action must be reject, mask or flag"}
+	}
+	if r.Pattern == "" {
+		return &ValidationError{"pattern is required"}
+	}
+	if utf8.RuneCountInString(r.Pattern) > maxContentFilterPatternLength {
+		return &ValidationError{"pattern must be at most 500 characters"}
+	}
+	if r.Kind == ContentFilterKindRegex {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return &ValidationError{"invalid regular expression: " + err.Error()}
+		}
+	}
+	return nil
+}
+
+// ContentFilterResult итог проверки текста фильтром
+type ContentFilterResult struct {
+	// Content текст после маскирования
+	Content  string
+	Rejected bool
+	Flagged  bool
+	// MatchedRules ID сработавших правил, по одному разу
+	MatchedRules []uuid.UUID
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationToken одноразовый токен подтверждения email. Хранится только хэш токена
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *EmailVerificationToken) Validate() error {
+	if t.UserID == uuid.Nil {
+		return &ValidationError{"user_id is required"}
+	}
+	if t.Email == "" {
+		return &ValidationError{"email is required"}
+	}
+	if t.TokenHash == "" {
+		return &ValidationError{"token_hash is required"}
+	}
+	if t.ExpiresAt.IsZero() {
+		return &ValidationError{"expires_at is required"}
+	}
+	return nil
+}
+
+// IsUsable проверяет, что токен не использован и не истек
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord первый ответ на запрос с заголовком Idempotency-Key. Пока запрос выполняется,
+// CompletedAt пуст, и повторы с тем же ключом отклоняются
+type IdempotencyRecord struct {
+	UserID      uuid.UUID
+	Key         string
+	RequestHash string // SHA-256 метода, пути и тела запроса
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   time.Time
+}
+
+// IsCompleted сохранен ли уже ответ
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.CompletedAt != nil
+}
+
+func (r *IdempotencyRecord) Validate() error {
+	if r.UserID == uuid.Nil || r.Key == "" || r.RequestHash == "" {
+		return &ValidationError{"user, key and request hash are required"}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity учетная запись внешнего провайдера (OIDC), привязанная к пользователю
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+func (i *UserIdentity) Validate() error {
+	if i.UserID == uuid.Nil {
+		return &ValidationError{"user_id is required"}
+	}
+	if i.Provider == "" {
+		return &ValidationError{"provider is required"}
+	}
+	if i.Subject == "" {
+		return &ValidationError{"subject is required"}
+	}
+	return nil
+}
+
+// OIDCAuthRequest незавершенный запрос авторизации у OIDC провайдера. Хранится только хэш state
+type OIDCAuthRequest struct {
+	StateHash    string    `json:"-"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (r *OIDCAuthRequest) Validate() error {
+	if r.StateHash == "" {
+		return &ValidationError{"state_hash is required"}
+	}
+	if r.Provider == "" {
+		return &ValidationError{"provider is required"}
+	}
+	if r.Nonce == "" || r.CodeVerifier == "" {
+		return &ValidationError{"nonce and code_verifier are required"}
+	}
+	if r.ExpiresAt.IsZero() {
+		return &ValidationError{"expires_at is required"}
+	}
+	return nil
+}
+
+// OIDCLoginStart начатый вход через OIDC провайдера
+type OIDCLoginStart struct {
+	AuthURL string
+	// Binding привязывает вход к браузеру, который его начал: клиент хранит его в cookie
+	// и предъявляет при возврате от провайдера
+	Binding   string
+	ExpiresAt time.Time
+}
+
+// OIDCLoginResult результат входа через OIDC
+type OIDCLoginResult struct {
+	User    *User
+	Created bool // Пользователь создан при этом входе
+	Linked  bool // Внешний аккаунт привязан к существующему пользователю при этом входе
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Результаты попытки входа
+const (
+	LoginOutcomeSuccess = "success"
+	LoginOutcomeFailure = "failure"
+	LoginOutcomeLocked  = "locked"
+)
+
+// LoginAttempt запись аудита попытки входа
+type LoginAttempt struct {
+	ID        uuid.UUID  `json:"id"`
+	Email     string     `json:"email"`
+	IPAddress string     `json:"ip_address"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Outcome   string     `json:"outcome"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (a *LoginAttempt) Validate() error {
+	if a.Email == "" {
+		return &ValidationError{"email is required"}
+	}
+	if a.IPAddress == "" {
+		return &ValidationError{"ip_address is required"}
+	}
+	switch a.Outcome {
+	case LoginOutcomeSuccess, LoginOutcomeFailure, LoginOutcomeLocked:
+	default:
+		return &ValidationError{"invalid login outcome"}
+	}
+	return nil
+}
+
+// LoginFailureStats сводка неудачных попыток входа за окно
+type LoginFailureStats struct {
+	Count         int
+	LastFailureAt *time.Time
+}
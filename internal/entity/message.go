@@ -7,11 +7,17 @@ import (
 )
 
 type Message struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Content   string     `json:"content"`
+	HiddenAt  *time.Time `json:"hidden_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsHidden проверяет, скрыто ли сообщение модератором
+func (m *Message) IsHidden() bool {
+	return m.HiddenAt != nil
 }
 
 func (m *Message) Validate() error {
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA настройки двухфакторной аутентификации (TOTP) пользователя
+type UserMFA struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (m *UserMFA) Validate() error {
+	if m.UserID == uuid.Nil {
+		return &ValidationError{"user_id is required"}
+	}
+	if m.Secret == "" {
+		return &ValidationError{"secret is required"}
+	}
+	return nil
+}
+
+// MFAEnrollment данные для подключения приложения-аутентификатора
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAChallenge промежуточный результат логина, когда требуется второй фактор
+type MFAChallenge struct {
+	Token     string    `json:"mfa_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MFAChallengeState состояние челленджа на сервере. ID совпадает с jti токена челленджа
+type MFAChallengeState struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
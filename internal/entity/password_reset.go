@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken одноразовый токен сброса пароля. Хранится только хэш токена
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *PasswordResetToken) Validate() error {
+	if t.UserID == uuid.Nil {
+		return &ValidationError{"user_id is required"}
+	}
+	if t.TokenHash == "" {
+		return &ValidationError{"token_hash is required"}
+	}
+	if t.ExpiresAt.IsZero() {
+		return &ValidationError{"expires_at is required"}
+	}
+	return nil
+}
+
+// IsUsable проверяет, что токен не использован и не истек
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// RateLimitStatus состояние лимита запросов после учета текущего запроса
+type RateLimitStatus struct {
+	Policy     string
+	Limit      int
+	Remaining  int
+	Window     time.Duration
+	ResetAfter time.Duration // через сколько начнется новое окно
+}
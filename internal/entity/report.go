@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportReason категория жалобы
+type ReportReason string
+
+const (
+	ReportReasonSpam       ReportReason = "spam"
+	ReportReasonHarassment ReportReason = "harassment"
+	ReportReasonHate       ReportReason = "hate"
+	ReportReasonIllegal    ReportReason = "illegal"
+	ReportReasonOther      ReportReason = "other"
+
+	// ReportReasonContentFilter жалоба от фильтра содержимого, без пользователя-автора
+	ReportReasonContentFilter ReportReason = "content_filter"
+)
+
+// ReportReasons все допустимые причины жалобы
+var ReportReasons = []ReportReason{
+	ReportReasonSpam, ReportReasonHarassment, ReportReasonHate, ReportReasonIllegal, ReportReasonOther,
+}
+
+// ReportStatus состояние жалобы в очереди модерации
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusDismissed ReportStatus = "dismissed"
+	ReportStatusActioned  ReportStatus = "actioned"
+)
+
+// reportTransitions допустимые переходы статусов. Отклоненную жалобу можно вернуть в очередь,
+// по жалобе с принятыми мерами можно принять дополнительные меры, но не отменить их
+var reportTransitions = map[ReportStatus][]ReportStatus{
+	ReportStatusOpen:      {ReportStatusDismissed, ReportStatusActioned},
+	ReportStatusDismissed: {ReportStatusOpen},
+	ReportStatusActioned:  {ReportStatusActioned},
+}
+
+// IsValid проверяет, что статус известен
+func (s ReportStatus) IsValid() bool {
+	_, ok := reportTransitions[s]
+	return ok
+}
+
+// CanTransitionTo проверяет, разрешен ли переход в статус next
+func (s ReportStatus) CanTransitionTo(next ReportStatus) bool {
+	return slices.Contains(reportTransitions[s], next)
+}
+
+// ModerationActionType решение модератора по жалобе
+type ModerationActionType string
+
+const (
+	ModerationActionDismiss       ModerationActionType = "dismiss"
+	ModerationActionReopen        ModerationActionType = "reopen"
+	ModerationActionHideMessage   ModerationActionType = "hide_message"
+	ModerationActionWarnAuthor    ModerationActionType = "warn_author"
+	ModerationActionSuspendAuthor ModerationActionType = "suspend_author"
+)
+
+// ModerationEnforcements меры, которые модератор может применить по жалобе
+var ModerationEnforcements = []ModerationActionType{
+	ModerationActionHideMessage, ModerationActionWarnAuthor, ModerationActionSuspendAuthor,
+}
+
+// Report жалоба пользователя на сообщение
+type Report struct {
+	ID         uuid.UUID    `json:"id"`
+	MessageID  uuid.UUID    `json:"message_id"`
+	ReporterID *uuid.UUID   `json:"reporter_id,omitempty"` // nil для жалоб от фильтра содержимого
+	Reason     ReportReason `json:"reason"`
+	Comment    string       `json:"comment,omitempty"`
+	Status     ReportStatus `json:"status"`
+	ResolvedBy *uuid.UUID   `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+
+	// Заполняются при выдаче жалобы модератору
+	Message *Message            `json:"message,omitempty"`
+	Actions []*ModerationAction `json:"actions,omitempty"`
+}
+
+func (r *Report) Validate() error {
+	if r.MessageID == uuid.Nil {
+		return &ValidationError{"message_id is required"}
+	}
+	// Без автора жалобу может создать только фильтр содержимого
+	if r.ReporterID == nil || *r.ReporterID == uuid.Nil {
+		if r.Reason != ReportReasonContentFilter || r.ReporterID != nil {
+			return &ValidationError{"reporter_id is required"}
+		}
+	} else if !slices.Contains(ReportReasons, r.Reason) {
+		return &ValidationError{"unknown report reason: " + string(r.Reason)}
+	}
+	if len([]rune(r.Comment)) > 1000 {
+		return &ValidationError{"comment must be at most 1000 characters"}
+	}
+	if !r.Status.IsValid() {
+		return &ValidationError{"unknown report status: " + string(r.Status)}
+	}
+	return nil
+}
+
+// ModerationAction запись журнала модерации: кто, что и по какой жалобе сделал
+type ModerationAction struct {
+	ID           uuid.UUID            `json:"id"`
+	ReportID     *uuid.UUID           `json:"report_id,omitempty"`
+	ModeratorID  *uuid.UUID           `json:"moderator_id,omitempty"`
+	Action       ModerationActionType `json:"action"`
+	MessageID    uuid.UUID            `json:"message_id"`
+	TargetUserID uuid.UUID            `json:"target_user_id"`
+	Note         string               `json:"note,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// ReportFilter параметры выборки очереди модерации
+type ReportFilter struct {
+	Status *ReportStatus
+	Limit  int
+	Offset int
+}
+
+// ReportList страница жалоб и их общее количество по фильтру
+type ReportList struct {
+	Reports []*Report `json:"reports"`
+	Total   int       `json:"total"`
+	Limit   int       `json:"limit"`
+	Offset  int       `json:"offset"`
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "slices"
+
+// Role глобальная роль пользователя
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission действие, на которое нужно право
+type Permission string
+
+const (
+	PermissionMessagesDeleteAny   Permission = "messages:delete_any"
+	PermissionReportsReview       Permission = "reports:review"
+	PermissionRolesManage         Permission = "roles:manage"
+	PermissionUsersManage         Permission = "users:manage"
+	PermissionContentFilterManage Permission = "content_filter:manage"
+	PermissionSystemManage        Permission = "system:manage"
+)
+
+// rolePermissions права ролей; каждая роль включает права предыдущей
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionMessagesDeleteAny,
+		PermissionReportsReview,
+	},
+	RoleAdmin: {
+		PermissionMessagesDeleteAny,
+		PermissionReportsReview,
+		PermissionRolesManage,
+		PermissionUsersManage,
+		PermissionContentFilterManage,
+		PermissionSystemManage,
+	},
+}
+
+// IsValid проверяет, что роль известна
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can проверяет, есть ли у роли право. Неизвестная роль не имеет прав
+func (r Role) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
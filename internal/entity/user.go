@@ -1,18 +1,60 @@
 package entity
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID  `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Password         string     `json:"password"`
+	Role             Role       `json:"role"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// IsEmailVerified проверяет, подтвержден ли текущий email пользователя
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsSuspended проверяет, заблокирован ли пользователь администратором
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// EffectiveRole роль пользователя; у пользователей без назначенной роли — базовая
+func (u *User) EffectiveRole() Role {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+// UserFilter параметры поиска пользователей для администрирования
+type UserFilter struct {
+	Email       string // Подстрока email без учета регистра
+	Username    string // Подстрока имени без учета регистра
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Suspended   *bool
+	Limit       int
+	Offset      int
+}
+
+// UserList страница пользователей и их общее количество по фильтру
+type UserList struct {
+	Users  []*User `json:"users"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
 }
 
 func (u *User) Validate() error {
@@ -56,3 +98,39 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Message
 }
+
+func (e *ValidationError) ValidationError() bool {
+	return true
+}
+
+// FieldError ошибка валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldValidationError набор ошибок валидации с привязкой к полям
+type FieldValidationError struct {
+	Errors []FieldError
+}
+
+func (e *FieldValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *FieldValidationError) ValidationError() bool {
+	return true
+}
+
+// FieldErrors возвращает ошибки, сгруппированные по полям
+func (e *FieldValidationError) FieldErrors() map[string][]string {
+	fields := make(map[string][]string, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		fields[fieldErr.Field] = append(fields[fieldErr.Field], fieldErr.Message)
+	}
+	return fields
+}
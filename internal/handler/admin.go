@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/admin"
+	"chat-service/internal/usecase/password"
+	"chat-service/internal/usecase/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminHandler struct {
+	adminUsecase    admin.AdminUsecase
+	rbacUsecase     rbac.RBACUsecase
+	passwordUsecase password.PasswordUsecase
+	logger          *logrus.Logger
+}
+
+func NewAdminHandler(
+	adminUsecase admin.AdminUsecase,
+	rbacUsecase rbac.RBACUsecase,
+	passwordUsecase password.PasswordUsecase,
+	logger *logrus.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		adminUsecase:    adminUsecase,
+		rbacUsecase:     rbacUsecase,
+		passwordUsecase: passwordUsecase,
+		logger:          logger,
+	}
+}
+
+// GrantRoleRequest запрос на назначение роли
+// swagger:model GrantRoleRequest
+type GrantRoleRequest struct {
+	// Роль: user, moderator или admin
+	// required: true
+	Role entity.Role `json:"role" binding:"required"`
+}
+
+// SuspendUserRequest запрос на блокировку пользователя
+// swagger:model SuspendUserRequest
+type SuspendUserRequest struct {
+	// Причина блокировки, видна администраторам
+	Reason string `json:"reason"`
+}
+
+// UserListResponse страница списка пользователей
+// swagger:model UserListResponse
+type UserListResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Data    *entity.UserList `json:"data"`
+}
+
+// ListUsers возвращает список пользователей
+// @Summary Список пользователей
+// @Description Возвращает пользователей постранично, новые первыми. Email и имя ищутся по подстроке без учета регистра
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param email query string false "Подстрока email"
+// @Param username query string false "Подстрока имени пользователя"
+// @Param created_from query string false "Зарегистрирован не раньше (RFC 3339)"
+// @Param created_to query string false "Зарегистрирован не позже (RFC 3339)"
+// @Param suspended query bool false "Только заблокированные (true) или только активные (false)"
+// @Param limit query int false "Размер страницы (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} UserListResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid user list query")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	list, err := h.adminUsecase.ListUsers(c.Request.Context(), actorID, filter)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, list, "Users retrieved successfully", http.StatusOK)
+}
+
+// GetUser возвращает пользователя по ID
+// @Summary Пользователь по ID
+// @Description Возвращает пользователя, включая роль и статус блокировки
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminUsecase.GetUser(c.Request.Context(), actorID, targetID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, user, "User retrieved successfully", http.StatusOK)
+}
+
+// SuspendUser блокирует пользователя
+// @Summary Блокировка пользователя
+// @Description Блокирует пользователя и завершает его сессии. Заблокированный пользователь не может войти, его API ключи перестают работать
+// @Tags admin
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Param request body SuspendUserRequest false "Причина блокировки"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	// Тело необязательно: блокировать можно и без причины
+	var req SuspendUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithContext(c).WithError(err).Warn("invalid suspend request body")
+			SendBindError(c, err)
+			return
+		}
+	}
+
+	user, err := h.adminUsecase.SuspendUser(c.Request.Context(), actorID, targetID, req.Reason)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, user, "User suspended successfully", http.StatusOK)
+}
+
+// UnsuspendUser снимает блокировку пользователя
+// @Summary Разблокировка пользователя
+// @Description Снимает блокировку; пользователь снова может войти
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/unsuspend [post]
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminUsecase.UnsuspendUser(c.Request.Context(), actorID, targetID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, user, "User unsuspended successfully", http.StatusOK)
+}
+
+// ForceLogout завершает все сессии пользователя и отзывает его API ключи
+// @Summary Принудительный выход
+// @Description Завершает все сессии пользователя на всех устройствах и отзывает его API ключи
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/logout [post]
+func (h *AdminHandler) ForceLogout(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUsecase.ForceLogout(c.Request.Context(), actorID, targetID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "User logged out successfully", http.StatusOK)
+}
+
+// ForcePasswordReset принудительно сбрасывает пароль пользователя
+// @Summary Принудительный сброс пароля
+// @Description Делает текущий пароль недействительным, завершает сессии, отзывает API ключи и отправляет пользователю письмо для сброса пароля
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminUsecase.ForcePasswordReset(c.Request.Context(), actorID, targetID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	// Пароль уже недействителен, поэтому ошибка отправки письма не отменяет сброс:
+	// пользователь может запросить письмо сам
+	if err := h.passwordUsecase.RequestReset(c.Request.Context(), user.Email); err != nil {
+		h.logger.WithContext(c).WithError(err).WithField("user_id", user.ID).Error("failed to send password reset email after forced reset")
+	}
+
+	SendSuccess(c, nil, "Password reset successfully", http.StatusOK)
+}
+
+// DeleteUser безвозвратно удаляет пользователя
+// @Summary Удаление пользователя
+// @Description Безвозвратно удаляет пользователя вместе с его сообщениями и сессиями. Удалить себя нельзя
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id} [delete]
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUsecase.DeleteUser(c.Request.Context(), actorID, targetID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "User deleted successfully", http.StatusOK)
+}
+
+// GrantRole назначает пользователю роль
+// @Summary Назначение роли
+// @Description Назначает пользователю глобальную роль (user, moderator, admin). Доступно администраторам; свою роль менять нельзя
+// @Tags admin
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Param request body GrantRoleRequest true "Роль"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) GrantRole(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	var req GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid grant role request body")
+		SendBindError(c, err)
+		return
+	}
+
+	user, err := h.rbacUsecase.GrantRole(c.Request.Context(), actorID, targetID, req.Role)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, user, "Role granted successfully", http.StatusOK)
+}
+
+// RevokeRole возвращает пользователю базовую роль
+// @Summary Отзыв роли
+// @Description Снимает с пользователя роль модератора или администратора (роль становится user)
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/users/{id}/role [delete]
+func (h *AdminHandler) RevokeRole(c *gin.Context) {
+	actorID, targetID, ok := h.parseActorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.rbacUsecase.RevokeRole(c.Request.Context(), actorID, targetID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, user, "Role revoked successfully", http.StatusOK)
+}
+
+// parseActorAndTarget извлекает текущего пользователя и ID пользователя из пути
+func (h *AdminHandler) parseActorAndTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid user ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "user ID must be a valid UUID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID, targetID, true
+}
+
+// parseUserFilter читает фильтр списка пользователей из query параметров
+func parseUserFilter(c *gin.Context) (entity.UserFilter, error) {
+	filter := entity.UserFilter{
+		Email:    c.Query("email"),
+		Username: c.Query("username"),
+	}
+
+	for name, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, apperror.InvalidField(apperror.CodeInvalidRequest, name, name+" must be an RFC 3339 timestamp")
+			}
+			*target = &parsed
+		}
+	}
+
+	if value := c.Query("suspended"); value != "" {
+		suspended, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, apperror.InvalidField(apperror.CodeInvalidRequest, "suspended", "suspended must be true or false")
+		}
+		filter.Suspended = &suspended
+	}
+
+	for name, target := range map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, apperror.InvalidField(apperror.CodeInvalidRequest, name, name+" must be an integer")
+			}
+			*target = parsed
+		}
+	}
+
+	return filter, nil
+}
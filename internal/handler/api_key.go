@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/apikey"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyHandler struct {
+	apiKeyUsecase apikey.APIKeyUsecase
+	logger        *logrus.Logger
+}
+
+func NewAPIKeyHandler(apiKeyUsecase apikey.APIKeyUsecase, logger *logrus.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+		logger:        logger,
+	}
+}
+
+// CreateAPIKeyRequest запрос на создание API ключа
+// swagger:model CreateAPIKeyRequest
+type CreateAPIKeyRequest struct {
+	// Название ключа, например имя бота
+	// required: true
+	Name string `json:"name" binding:"required,max=100"`
+	// Права ключа: messages:read, messages:write, profile:read
+	// required: true
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// Срок действия; без него ключ бессрочный, если это разрешено конфигурацией
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAPIKeyResponse созданный ключ. Значение key показывается только один раз
+// swagger:model CreateAPIKeyResponse
+type CreateAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey *entity.APIKey `json:"api_key"`
+}
+
+// APIKeysResponse список ключей пользователя
+// swagger:model APIKeysResponse
+type APIKeysResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Data    []*entity.APIKey `json:"data"`
+}
+
+// CreateAPIKey выпускает персональный API ключ
+// @Summary Создание API ключа
+// @Description Выпускает ключ для ботов и скриптов. Ключ передается как "Authorization: Bearer csk_..." и дает доступ только к endpoint'ам из выданных прав.
+// @Description Значение ключа возвращается один раз, на сервере хранится только его хэш
+// @Tags api-keys
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param request body CreateAPIKeyRequest true "Параметры ключа"
+// @Success 201 {object} CreateAPIKeyResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid create api key request body")
+		SendBindError(c, err)
+		return
+	}
+
+	key, token, err := h.apiKeyUsecase.CreateKey(c.Request.Context(), userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).WithField("user_id", userID).Warn("failed to create api key")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, CreateAPIKeyResponse{Key: token, APIKey: key}, "API key created successfully", http.StatusCreated)
+}
+
+// ListAPIKeys возвращает ключи текущего пользователя
+// @Summary Список API ключей
+// @Description Возвращает неотозванные ключи пользователя (без значений, только видимый префикс)
+// @Tags api-keys
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} APIKeysResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	keys, err := h.apiKeyUsecase.ListKeys(c.Request.Context(), userID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, keys, "API keys retrieved successfully", http.StatusOK)
+}
+
+// RevokeAPIKey отзывает ключ
+// @Summary Отзыв API ключа
+// @Description Отзывает ключ; запросы с ним сразу перестают приниматься
+// @Tags api-keys
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID ключа"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid api key ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "API key ID must be a valid UUID"))
+		return
+	}
+
+	if err := h.apiKeyUsecase.RevokeKey(c.Request.Context(), userID, keyID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "API key revoked successfully", http.StatusOK)
+}
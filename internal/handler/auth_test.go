@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/apikey"
+	"chat-service/internal/usecase/mocks"
+	"chat-service/internal/usecase/session"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIKey = apikey.TokenPrefix + "secret"
+
+// newAuthRouter маршрут, доступный только модераторам; userLoads считает загрузки пользователя из БД
+func newAuthRouter(role entity.Role, userLoads *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userID := uuid.New()
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			*userLoads++
+			return &entity.User{ID: id, Role: role}, nil
+		},
+	}
+	sessionRepo := &mocks.SessionRepoMock{
+		GetByTokenFunc: func(ctx context.Context, token string) (*entity.Session, error) {
+			return &entity.Session{ID: uuid.New(), UserID: userID, Token: token, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+	}
+	jwtService := &mocks.JWTServiceMock{
+		ValidateTokenFunc: func(token string) (uuid.UUID, error) {
+			return userID, nil
+		},
+	}
+	apiKeyRepo := &mocks.APIKeyRepoMock{
+		GetByHashFunc: func(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+			return &entity.APIKey{ID: uuid.New(), UserID: userID}, nil
+		},
+	}
+
+	middleware := &Middleware{
+		sessionUsecase: session.NewSessionUsecase(sessionRepo, userRepo, jwtService, logger),
+		apiKeyUsecase:  apikey.NewAPIKeyUsecase(apiKeyRepo, userRepo, apikey.Config{}, logger),
+		logger:         logger,
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/reports", middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermissionReportsReview), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestAuthMiddleware_RoleFromAuthenticatedUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		role     entity.Role
+		wantCode int
+	}{
+		{name: "сессия модератора", token: "session_token", role: entity.RoleModerator, wantCode: http.StatusNoContent},
+		{name: "сессия пользователя", token: "session_token", role: entity.RoleUser, wantCode: http.StatusForbidden},
+		{name: "пользователь без роли", token: "session_token", role: "", wantCode: http.StatusForbidden},
+		{name: "API ключ модератора", token: testAPIKey, role: entity.RoleModerator, wantCode: http.StatusNoContent},
+		{name: "API ключ пользователя", token: testAPIKey, role: entity.RoleUser, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			userLoads := 0
+			router := newAuthRouter(tt.role, &userLoads)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, 1, userLoads, "пользователь загружается из БД один раз за запрос")
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/block"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type BlockHandler struct {
+	blockUsecase block.BlockUsecase
+	logger       *logrus.Logger
+}
+
+func NewBlockHandler(blockUsecase block.BlockUsecase, logger *logrus.Logger) *BlockHandler {
+	return &BlockHandler{
+		blockUsecase: blockUsecase,
+		logger:       logger,
+	}
+}
+
+// UserBlockResponse блокировка пользователя
+// swagger:model UserBlockResponse
+type UserBlockResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Data    *entity.UserBlock `json:"data"`
+}
+
+// UserBlocksResponse список заблокированных пользователей
+// swagger:model UserBlocksResponse
+type UserBlocksResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Data    []*entity.UserBlock `json:"data"`
+}
+
+// BlockUser блокирует пользователя
+// @Summary Блокировка пользователя
+// @Description Скрывает сообщения пользователя из ленты текущего пользователя и запрещает заблокированному упоминать его. Повторная блокировка не ошибка
+// @Tags blocks
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param user_id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} UserBlockResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /blocks/{user_id} [post]
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	blockerID, blockedID, ok := h.parseUsers(c)
+	if !ok {
+		return
+	}
+
+	userBlock, err := h.blockUsecase.BlockUser(c.Request.Context(), blockerID, blockedID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, userBlock, "User blocked successfully", http.StatusOK)
+}
+
+// UnblockUser снимает блокировку пользователя
+// @Summary Разблокировка пользователя
+// @Description Снимает блокировку; сообщения пользователя снова видны в ленте
+// @Tags blocks
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param user_id path string true "ID пользователя" Format(uuid)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /blocks/{user_id} [delete]
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	blockerID, blockedID, ok := h.parseUsers(c)
+	if !ok {
+		return
+	}
+
+	if err := h.blockUsecase.UnblockUser(c.Request.Context(), blockerID, blockedID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "User unblocked successfully", http.StatusOK)
+}
+
+// ListBlocks возвращает заблокированных пользователей
+// @Summary Список заблокированных пользователей
+// @Description Возвращает пользователей, заблокированных текущим пользователем, новые первыми
+// @Tags blocks
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} UserBlocksResponse
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /blocks [get]
+func (h *BlockHandler) ListBlocks(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	blocks, err := h.blockUsecase.ListBlocks(c.Request.Context(), userID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, blocks, "Blocked users retrieved successfully", http.StatusOK)
+}
+
+// parseUsers извлекает текущего пользователя и ID блокируемого пользователя из пути
+func (h *BlockHandler) parseUsers(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	blockerID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	blockedID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid user ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "user_id", "user ID must be a valid UUID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return blockerID, blockedID, true
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/contentfilter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ContentFilterHandler struct {
+	contentFilterUsecase contentfilter.ContentFilterUsecase
+	logger               *logrus.Logger
+}
+
+func NewContentFilterHandler(contentFilterUsecase contentfilter.ContentFilterUsecase, logger *logrus.Logger) *ContentFilterHandler {
+	return &ContentFilterHandler{
+		contentFilterUsecase: contentFilterUsecase,
+		logger:               logger,
+	}
+}
+
+// ContentFilterRuleRequest правило фильтра содержимого
+// swagger:model ContentFilterRuleRequest
+type ContentFilterRuleRequest struct {
+	// Тип: word (слово или фраза целиком) или regex (регулярное выражение, без учета регистра)
+	// required: true
+	Kind entity.ContentFilterRuleKind `json:"kind" binding:"required"`
+	// Слово, фраза или регулярное выражение
+	// required: true
+	// max length: 500
+	Pattern string `json:"pattern" binding:"required,max=500"`
+	// Действие: reject (отклонить сообщение), mask (заменить совпадение звездочками) или flag (отправить модераторам)
+	// required: true
+	Action entity.ContentFilterAction `json:"action" binding:"required"`
+	// Включено ли правило, по умолчанию true
+	Enabled *bool `json:"enabled"`
+	// Описание для администраторов
+	// max length: 500
+	Description string `json:"description" binding:"max=500"`
+}
+
+// ContentFilterRuleResponse правило фильтра содержимого
+// swagger:model ContentFilterRuleResponse
+type ContentFilterRuleResponse struct {
+	Success bool                      `json:"success"`
+	Message string                    `json:"message"`
+	Data    *entity.ContentFilterRule `json:"data"`
+}
+
+// ContentFilterRuleListResponse список правил фильтра содержимого
+// swagger:model ContentFilterRuleListResponse
+type ContentFilterRuleListResponse struct {
+	Success bool                        `json:"success"`
+	Message string                      `json:"message"`
+	Data    []*entity.ContentFilterRule `json:"data"`
+}
+
+// ListRules возвращает правила фильтра содержимого
+// @Summary Правила фильтра содержимого
+// @Description Возвращает все правила, включая выключенные, в порядке создания
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} ContentFilterRuleListResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/content-filter/rules [get]
+func (h *ContentFilterHandler) ListRules(c *gin.Context) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	rules, err := h.contentFilterUsecase.ListRules(c.Request.Context(), actorID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, rules, "Content filter rules retrieved successfully", http.StatusOK)
+}
+
+// CreateRule добавляет правило фильтра содержимого
+// @Summary Новое правило фильтра
+// @Description Добавляет правило и сразу применяет его к новым сообщениям. Слова сравниваются после нормализации: без учета регистра и диакритики, с заменой похожих символов других алфавитов и leetspeak
+// @Tags admin
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param request body ContentFilterRuleRequest true "Правило"
+// @Success 201 {object} ContentFilterRuleResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/content-filter/rules [post]
+func (h *ContentFilterHandler) CreateRule(c *gin.Context) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	draft, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.contentFilterUsecase.CreateRule(c.Request.Context(), actorID, draft)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, rule, "Content filter rule created successfully", http.StatusCreated)
+}
+
+// UpdateRule заменяет правило фильтра содержимого
+// @Summary Изменение правила фильтра
+// @Description Заменяет все поля правила и сразу применяет изменения
+// @Tags admin
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID правила" Format(uuid)
+// @Param request body ContentFilterRuleRequest true "Правило"
+// @Success 200 {object} ContentFilterRuleResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/content-filter/rules/{id} [put]
+func (h *ContentFilterHandler) UpdateRule(c *gin.Context) {
+	actorID, ruleID, ok := h.parseActorAndRule(c)
+	if !ok {
+		return
+	}
+
+	changes, ok := h.bindRule(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.contentFilterUsecase.UpdateRule(c.Request.Context(), actorID, ruleID, changes)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, rule, "Content filter rule updated successfully", http.StatusOK)
+}
+
+// DeleteRule удаляет правило фильтра содержимого
+// @Summary Удаление правила фильтра
+// @Description Удаляет правило; уже опубликованные сообщения не меняются
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID правила" Format(uuid)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/content-filter/rules/{id} [delete]
+func (h *ContentFilterHandler) DeleteRule(c *gin.Context) {
+	actorID, ruleID, ok := h.parseActorAndRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.contentFilterUsecase.DeleteRule(c.Request.Context(), actorID, ruleID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "Content filter rule deleted successfully", http.StatusOK)
+}
+
+// ReloadRules перечитывает правила из базы данных
+// @Summary Перезагрузка правил фильтра
+// @Description Перечитывает правила из базы данных без перезапуска сервиса. Правила также перечитываются периодически
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/content-filter/reload [post]
+func (h *ContentFilterHandler) ReloadRules(c *gin.Context) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	if err := h.contentFilterUsecase.ReloadRules(c.Request.Context(), actorID); err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "Content filter rules reloaded successfully", http.StatusOK)
+}
+
+// bindRule читает правило из тела запроса; отсутствующий enabled означает включенное правило
+func (h *ContentFilterHandler) bindRule(c *gin.Context) (*entity.ContentFilterRule, bool) {
+	var req ContentFilterRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid content filter rule request body")
+		SendBindError(c, err)
+		return nil, false
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return &entity.ContentFilterRule{
+		Kind:        req.Kind,
+		Pattern:     req.Pattern,
+		Action:      req.Action,
+		Enabled:     enabled,
+		Description: req.Description,
+	}, true
+}
+
+// parseActorAndRule извлекает текущего пользователя и ID правила из пути
+func (h *ContentFilterHandler) parseActorAndRule(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid content filter rule ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "rule ID must be a valid UUID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorID, ruleID, true
+}
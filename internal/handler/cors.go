@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CORSConfig политика CORS для браузерных клиентов
+type CORSConfig struct {
+	// AllowedOrigins точные источники, шаблоны поддоменов вида https://*.example.com или * для любого источника
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge сколько браузер кеширует ответ на preflight; 0 - не передавать Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+// originPattern разрешенный источник. Для шаблона поддоменов prefix - схема с "://",
+// suffix - домен с портом, а между ними должна стоять хотя бы одна метка
+type originPattern struct {
+	prefix   string
+	suffix   string
+	wildcard bool
+}
+
+// corsPolicy заголовки CORS; список источников можно заменить без перезапуска
+type corsPolicy struct {
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+
+	mu       sync.RWMutex
+	allowAny bool
+	origins  []originPattern
+}
+
+func newCORSPolicy(config CORSConfig) *corsPolicy {
+	policy := &corsPolicy{
+		methods:     strings.Join(config.AllowedMethods, ", "),
+		headers:     strings.Join(config.AllowedHeaders, ", "),
+		exposed:     strings.Join(config.ExposedHeaders, ", "),
+		credentials: config.AllowCredentials,
+	}
+	if config.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	policy.setOrigins(config.AllowedOrigins)
+	return policy
+}
+
+// setOrigins заменяет список разрешенных источников
+func (p *corsPolicy) setOrigins(origins []string) {
+	allowAny := false
+	patterns := make([]originPattern, 0, len(origins))
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		if origin == "*" {
+			allowAny = true
+			continue
+		}
+		if scheme, host, ok := strings.Cut(origin, "://*."); ok {
+			patterns = append(patterns, originPattern{prefix: scheme + "://", suffix: "." + host, wildcard: true})
+			continue
+		}
+		patterns = append(patterns, originPattern{prefix: origin})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.allowAny = allowAny
+	p.origins = patterns
+}
+
+// allowed проверяет источник запроса; anyOrigin - разрешены все источники
+func (p *corsPolicy) allowed(origin string) (ok, anyOrigin bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.allowAny {
+		return true, true
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range p.origins {
+		if pattern.matches(origin) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+func (o originPattern) matches(origin string) bool {
+	if !o.wildcard {
+		return origin == o.prefix
+	}
+	if len(origin) <= len(o.prefix)+len(o.suffix) || !strings.HasPrefix(origin, o.prefix) || !strings.HasSuffix(origin, o.suffix) {
+		return false
+	}
+	// Поддомен не может содержать порт, путь или учетные данные: https://evil.com/.example.com не подходит
+	subdomain := origin[len(o.prefix) : len(origin)-len(o.suffix)]
+	return !strings.ContainsAny(subdomain, "/:@?#")
+}
+
+// apply добавляет заголовки CORS к ответу на запрос с заголовком Origin.
+// Для неразрешенного источника заголовки не добавляются, и браузер не отдаст ответ скрипту
+func (p *corsPolicy) apply(header http.Header, origin string, preflight bool) {
+	ok, anyOrigin := p.allowed(origin)
+	// С учетными данными браузер требует точный источник вместо *
+	reflectOrigin := !anyOrigin || p.credentials
+	if reflectOrigin {
+		// Ответ зависит от Origin, и кеши не должны отдавать его другим источникам
+		header.Add("Vary", "Origin")
+	}
+	if !ok {
+		return
+	}
+
+	if reflectOrigin {
+		header.Set("Access-Control-Allow-Origin", origin)
+	} else {
+		header.Set("Access-Control-Allow-Origin", "*")
+	}
+	if p.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if p.exposed != "" {
+			header.Set("Access-Control-Expose-Headers", p.exposed)
+		}
+		return
+	}
+	header.Set("Access-Control-Allow-Methods", p.methods)
+	if p.headers != "" {
+		header.Set("Access-Control-Allow-Headers", p.headers)
+	}
+	if p.maxAge != "" {
+		header.Set("Access-Control-Max-Age", p.maxAge)
+	}
+}
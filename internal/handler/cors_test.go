@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSRouter(config CORSConfig) (*gin.Engine, *Middleware) {
+	gin.SetMode(gin.TestMode)
+	middleware := &Middleware{cors: newCORSPolicy(config)}
+
+	router := gin.New()
+	router.Use(middleware.CORSMiddleware())
+	router.GET("/api/v1/messages", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router, middleware
+}
+
+func corsRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/messages", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSMiddleware_Origins(t *testing.T) {
+	router, _ := newCORSRouter(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		ExposedHeaders:   []string{RequestIDHeader},
+		AllowCredentials: true,
+	})
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "exact", origin: "https://app.example.com", allowed: true},
+		{name: "exact case insensitive", origin: "https://APP.example.com", allowed: true},
+		{name: "other scheme", origin: "http://app.example.com", allowed: false},
+		{name: "subdomain", origin: "https://chat.example.org", allowed: true},
+		{name: "nested subdomain", origin: "https://eu.chat.example.org", allowed: true},
+		{name: "bare domain", origin: "https://example.org", allowed: false},
+		{name: "suffix attack", origin: "https://evilexample.org", allowed: false},
+		{name: "port", origin: "https://chat.example.org:8443", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec := corsRequest(router, http.MethodGet, tt.origin)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+			if tt.allowed {
+				assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+				assert.Equal(t, RequestIDHeader, rec.Header().Get("Access-Control-Expose-Headers"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	// Arrange
+	router, _ := newCORSRouter(CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PATCH"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         10 * time.Minute,
+	})
+
+	// Act
+	rec := corsRequest(router, http.MethodOptions, "https://any.example.net")
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Vary"))
+	assert.Equal(t, "GET, POST, PATCH", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSMiddleware_SetOrigins(t *testing.T) {
+	// Arrange
+	router, middleware := newCORSRouter(CORSConfig{
+		AllowedOrigins: []string{"https://old.example.com"},
+		AllowedMethods: []string{"GET"},
+	})
+
+	// Act
+	middleware.cors.setOrigins([]string{"https://new.example.com"})
+
+	// Assert
+	assert.Empty(t, corsRequest(router, http.MethodGet, "https://old.example.com").Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "https://new.example.com",
+		corsRequest(router, http.MethodGet, "https://new.example.com").Header().Get("Access-Control-Allow-Origin"))
+}
@@ -1,13 +1,30 @@
 package handler
 
 import (
-	"net/http"
-
+	"chat-service/internal/entity"
+	"chat-service/internal/health"
+	"chat-service/internal/metrics"
+	"chat-service/internal/usecase/admin"
+	"chat-service/internal/usecase/apikey"
+	"chat-service/internal/usecase/block"
+	"chat-service/internal/usecase/contentfilter"
+	"chat-service/internal/usecase/idempotency"
+	"chat-service/internal/usecase/loginguard"
 	"chat-service/internal/usecase/message"
+	"chat-service/internal/usecase/mfa"
+	"chat-service/internal/usecase/moderation"
+	"chat-service/internal/usecase/oidc"
+	"chat-service/internal/usecase/password"
+	"chat-service/internal/usecase/ratelimit"
+	"chat-service/internal/usecase/rbac"
 	"chat-service/internal/usecase/session"
 	"chat-service/internal/usecase/user"
+	"chat-service/internal/usecase/verification"
+	"chat-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -17,37 +34,96 @@ import (
 )
 
 type Handler struct {
-	router         *gin.Engine
-	userHandler    *UserHandler
-	messageHandler *MessageHandler
-	middleware     *Middleware
-	logger         *logrus.Logger
+	router               *gin.Engine
+	userHandler          *UserHandler
+	messageHandler       *MessageHandler
+	mfaHandler           *MFAHandler
+	passwordHandler      *PasswordHandler
+	verificationHandler  *VerificationHandler
+	oidcHandler          *OIDCHandler
+	apiKeyHandler        *APIKeyHandler
+	adminHandler         *AdminHandler
+	moderationHandler    *ModerationHandler
+	contentFilterHandler *ContentFilterHandler
+	blockHandler         *BlockHandler
+	healthHandler        *HealthHandler
+	logLevelHandler      *LogLevelHandler
+	middleware           *Middleware
+	metrics              *metrics.Metrics
+	logger               *logrus.Logger
 }
 
 func NewHandler(
 	userUsecase user.UserUsecase,
 	messageUsecase message.MessageUsecase,
 	sessionUsecase session.SessionUsecase,
+	mfaUsecase mfa.MFAUsecase,
+	passwordUsecase password.PasswordUsecase,
+	verificationUsecase verification.VerificationUsecase,
+	loginGuard loginguard.LoginGuardUsecase,
+	oidcUsecase oidc.OIDCUsecase,
+	apiKeyUsecase apikey.APIKeyUsecase,
+	rbacUsecase rbac.RBACUsecase,
+	adminUsecase admin.AdminUsecase,
+	moderationUsecase moderation.ModerationUsecase,
+	contentFilterUsecase contentfilter.ContentFilterUsecase,
+	blockUsecase block.BlockUsecase,
+	rateLimitUsecase ratelimit.RateLimitUsecase,
+	idempotencyUsecase idempotency.IdempotencyUsecase,
+	appHealth *health.Health,
+	appMetrics *metrics.Metrics,
+	corsConfig CORSConfig,
+	logLevels *logger.LevelController,
 	logger *logrus.Logger,
 ) *Handler {
 	// Устанавливаем режим Gin
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
+	// gin.Context отдает значения контекста запроса, поэтому его можно передавать в logger.WithContext
+	router.ContextWithFallback = true
+
+	// Ошибки binding называют поля так же, как они называются в JSON
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
 
 	// Middleware
-	middleware := NewMiddleware(sessionUsecase, logger)
+	middleware := NewMiddleware(sessionUsecase, apiKeyUsecase, rateLimitUsecase, idempotencyUsecase, appMetrics, corsConfig, logger)
 
 	// Handlers
-	userHandler := NewUserHandler(userUsecase, sessionUsecase, logger)
-	messageHandler := NewMessageHandler(messageUsecase, logger)
+	userHandler := NewUserHandler(userUsecase, sessionUsecase, mfaUsecase, verificationUsecase, loginGuard, appMetrics, logger)
+	messageHandler := NewMessageHandler(messageUsecase, appMetrics, logger)
+	mfaHandler := NewMFAHandler(mfaUsecase, userUsecase, sessionUsecase, loginGuard, appMetrics, logger)
+	passwordHandler := NewPasswordHandler(passwordUsecase, logger)
+	verificationHandler := NewVerificationHandler(verificationUsecase, logger)
+	oidcHandler := NewOIDCHandler(oidcUsecase, sessionUsecase, mfaUsecase, appMetrics, logger)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyUsecase, logger)
+	adminHandler := NewAdminHandler(adminUsecase, rbacUsecase, passwordUsecase, logger)
+	moderationHandler := NewModerationHandler(moderationUsecase, logger)
+	contentFilterHandler := NewContentFilterHandler(contentFilterUsecase, logger)
+	blockHandler := NewBlockHandler(blockUsecase, logger)
+	healthHandler := NewHealthHandler(appHealth, logger)
+	logLevelHandler := NewLogLevelHandler(logLevels, logger)
 
 	handler := &Handler{
-		router:         router,
-		userHandler:    userHandler,
-		messageHandler: messageHandler,
-		middleware:     middleware,
-		logger:         logger,
+		router:               router,
+		userHandler:          userHandler,
+		messageHandler:       messageHandler,
+		mfaHandler:           mfaHandler,
+		passwordHandler:      passwordHandler,
+		verificationHandler:  verificationHandler,
+		oidcHandler:          oidcHandler,
+		apiKeyHandler:        apiKeyHandler,
+		adminHandler:         adminHandler,
+		moderationHandler:    moderationHandler,
+		contentFilterHandler: contentFilterHandler,
+		blockHandler:         blockHandler,
+		healthHandler:        healthHandler,
+		logLevelHandler:      logLevelHandler,
+		middleware:           middleware,
+		metrics:              appMetrics,
+		logger:               logger,
 	}
 
 	handler.setupRoutes()
@@ -55,44 +131,129 @@ func NewHandler(
 }
 
 func (h *Handler) setupRoutes() {
-	// Global middleware
+	// Global middleware; ID запроса назначается первым, чтобы попасть во все записи лога
+	h.router.Use(h.middleware.RequestIDMiddleware())
+	h.router.Use(h.middleware.TracingMiddleware())
+	h.router.Use(h.middleware.MetricsMiddleware())
 	h.router.Use(h.middleware.LoggingMiddleware())
 	h.router.Use(h.middleware.CORSMiddleware())
 	h.router.Use(gin.Recovery())
 
-	// Health check
-	h.router.GET("/health", func(c *gin.Context) {
-		SendSuccess(c, gin.H{"status": "ok"}, "Service is running", http.StatusOK)
-	})
+	// Пробы живости и готовности; /health оставлен для совместимости и равен /readyz
+	h.router.GET("/livez", h.healthHandler.Live)
+	h.router.GET("/readyz", h.healthHandler.Ready)
+	h.router.GET("/health", h.healthHandler.Ready)
+
+	// Метрики Prometheus; маршрут есть, только если метрики включены в конфигурации
+	if h.metrics != nil {
+		h.router.GET("/metrics", gin.WrapH(h.metrics.Handler()))
+	}
 
 	// Swagger documentation
 	h.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Public routes
 	public := h.router.Group("/api/v1")
+	public.Use(h.middleware.RateLimitMiddleware())
 	{
 		public.POST("/register", h.userHandler.Register)
 		public.POST("/login", h.userHandler.Login)
-		public.GET("/messages", h.messageHandler.GetAllMessages)
+		public.POST("/login/mfa", h.mfaHandler.LoginMFA)
+		public.POST("/password/forgot", h.passwordHandler.ForgotPassword)
+		public.POST("/password/reset", h.passwordHandler.ResetPassword)
+		public.GET("/verify-email", h.verificationHandler.VerifyEmail)
+		public.GET("/auth/oidc/providers", h.oidcHandler.ListProviders)
+		public.GET("/auth/oidc/:provider/login", h.oidcHandler.Login)
+		public.GET("/auth/oidc/:provider/callback", h.oidcHandler.Callback)
+		public.GET("/messages", h.middleware.OptionalAuth(), h.messageHandler.GetAllMessages)
 	}
 
-	// Protected routes
+	// Protected routes: только сессии, API ключи сюда не допускаются
 	protected := h.router.Group("/api/v1")
-	protected.Use(h.middleware.AuthMiddleware())
+	protected.Use(h.middleware.AuthMiddleware(), h.middleware.RequireSession(), h.middleware.RateLimitMiddleware())
 	{
-		protected.GET("/profile", h.userHandler.GetProfile)
 		protected.PUT("/profile", h.userHandler.UpdateProfile)
+		protected.PUT("/profile/password", h.userHandler.ChangePassword)
 		protected.POST("/logout", h.userHandler.Logout)
 		protected.DELETE("/profile", h.userHandler.DeleteUser)
-		protected.POST("/messages", h.messageHandler.CreateMessage)
-		protected.GET("/messages/my", h.messageHandler.GetMessagesByUser)
-		protected.GET("/messages/:id", h.messageHandler.GetMessageByID)
-		protected.DELETE("/messages/:id", h.messageHandler.DeleteMessage)
+		protected.POST("/profile/mfa/enroll", h.mfaHandler.Enroll)
+		protected.POST("/profile/mfa/confirm", h.mfaHandler.Confirm)
+		protected.POST("/profile/mfa/recovery-codes", h.mfaHandler.RegenerateRecoveryCodes)
+		protected.DELETE("/profile/mfa", h.mfaHandler.Disable)
+		protected.POST("/verify-email/resend", h.verificationHandler.ResendVerification)
+		protected.POST("/profile/api-keys", h.apiKeyHandler.CreateAPIKey)
+		protected.GET("/profile/api-keys", h.apiKeyHandler.ListAPIKeys)
+		protected.DELETE("/profile/api-keys/:id", h.apiKeyHandler.RevokeAPIKey)
+		protected.GET("/blocks", h.blockHandler.ListBlocks)
+		protected.POST("/blocks/:user_id", h.blockHandler.BlockUser)
+		protected.DELETE("/blocks/:user_id", h.blockHandler.UnblockUser)
+	}
+
+	// Scoped routes: сессии или API ключи с нужным правом
+	scoped := h.router.Group("/api/v1")
+	scoped.Use(h.middleware.AuthMiddleware(), h.middleware.RateLimitMiddleware())
+	{
+		scoped.GET("/profile", h.middleware.RequireScope(entity.ScopeProfileRead), h.userHandler.GetProfile)
+		scoped.POST("/messages", h.middleware.RequireScope(entity.ScopeMessagesWrite), h.middleware.IdempotencyMiddleware(), h.messageHandler.CreateMessage)
+		scoped.GET("/messages/my", h.middleware.RequireScope(entity.ScopeMessagesRead), h.messageHandler.GetMessagesByUser)
+		scoped.GET("/messages/:id", h.middleware.RequireScope(entity.ScopeMessagesRead), h.messageHandler.GetMessageByID)
+		scoped.DELETE("/messages/:id", h.middleware.RequireScope(entity.ScopeMessagesWrite), h.messageHandler.DeleteMessage)
+		scoped.POST("/messages/:id/report", h.middleware.RequireScope(entity.ScopeMessagesWrite), h.middleware.IdempotencyMiddleware(), h.moderationHandler.ReportMessage)
+	}
+
+	// Admin routes: только сессии, права проверяются по роли
+	adminGroup := h.router.Group("/api/v1/admin")
+	adminGroup.Use(h.middleware.AuthMiddleware(), h.middleware.RequireSession(), h.middleware.RateLimitMiddleware())
+	{
+		usersManage := h.middleware.RequirePermission(entity.PermissionUsersManage)
+		adminGroup.GET("/users", usersManage, h.adminHandler.ListUsers)
+		adminGroup.GET("/users/:id", usersManage, h.adminHandler.GetUser)
+		adminGroup.DELETE("/users/:id", usersManage, h.adminHandler.DeleteUser)
+		adminGroup.POST("/users/:id/suspend", usersManage, h.adminHandler.SuspendUser)
+		adminGroup.POST("/users/:id/unsuspend", usersManage, h.adminHandler.UnsuspendUser)
+		adminGroup.POST("/users/:id/logout", usersManage, h.adminHandler.ForceLogout)
+		adminGroup.POST("/users/:id/password-reset", usersManage, h.adminHandler.ForcePasswordReset)
+
+		adminGroup.PUT("/users/:id/role", h.middleware.RequirePermission(entity.PermissionRolesManage), h.adminHandler.GrantRole)
+		adminGroup.DELETE("/users/:id/role", h.middleware.RequirePermission(entity.PermissionRolesManage), h.adminHandler.RevokeRole)
+
+		contentFilterManage := h.middleware.RequirePermission(entity.PermissionContentFilterManage)
+		adminGroup.GET("/content-filter/rules", contentFilterManage, h.contentFilterHandler.ListRules)
+		adminGroup.POST("/content-filter/rules", contentFilterManage, h.contentFilterHandler.CreateRule)
+		adminGroup.PUT("/content-filter/rules/:id", contentFilterManage, h.contentFilterHandler.UpdateRule)
+		adminGroup.DELETE("/content-filter/rules/:id", contentFilterManage, h.contentFilterHandler.DeleteRule)
+		adminGroup.POST("/content-filter/reload", contentFilterManage, h.contentFilterHandler.ReloadRules)
+
+		systemManage := h.middleware.RequirePermission(entity.PermissionSystemManage)
+		adminGroup.GET("/log-level", systemManage, h.logLevelHandler.GetLogLevel)
+		adminGroup.PUT("/log-level", systemManage, h.logLevelHandler.SetLogLevel)
+		adminGroup.DELETE("/log-level", systemManage, h.logLevelHandler.ResetLogLevel)
+	}
+
+	// Moderation routes: очередь жалоб для модераторов и администраторов
+	moderationGroup := h.router.Group("/api/v1/moderation")
+	moderationGroup.Use(
+		h.middleware.AuthMiddleware(),
+		h.middleware.RequireSession(),
+		h.middleware.RequirePermission(entity.PermissionReportsReview),
+		h.middleware.RateLimitMiddleware(),
+	)
+	{
+		moderationGroup.GET("/reports", h.moderationHandler.ListReports)
+		moderationGroup.GET("/reports/:id", h.moderationHandler.GetReport)
+		moderationGroup.POST("/reports/:id/dismiss", h.moderationHandler.DismissReport)
+		moderationGroup.POST("/reports/:id/reopen", h.moderationHandler.ReopenReport)
+		moderationGroup.POST("/reports/:id/actions", h.moderationHandler.TakeAction)
 	}
 
 	h.logger.Info("routes configured successfully")
 }
 
+// SetCORSOrigins заменяет разрешенные источники CORS без перезапуска
+func (h *Handler) SetCORSOrigins(origins []string) {
+	h.middleware.cors.setOrigins(origins)
+}
+
 func (h *Handler) GetRouter() *gin.Engine {
 	return h.router
 }
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthHandler struct {
+	health *health.Health
+	logger *logrus.Logger
+}
+
+func NewHealthHandler(health *health.Health, logger *logrus.Logger) *HealthHandler {
+	return &HealthHandler{
+		health: health,
+		logger: logger,
+	}
+}
+
+// Live отвечает на пробу живости: 200, пока процесс способен обрабатывать запросы
+func (h *HealthHandler) Live(c *gin.Context) {
+	sendReport(c, h.health.Live(c.Request.Context()))
+}
+
+// Ready отвечает на пробу готовности: 503, если недоступна зависимость или сервис завершает работу
+func (h *HealthHandler) Ready(c *gin.Context) {
+	sendReport(c, h.health.Ready(c.Request.Context()))
+}
+
+// sendReport отдает отчет пробы как есть, без обертки успешного ответа: оркестраторам нужен только статус
+func sendReport(c *gin.Context, report *health.Report) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	// Результат пробы не должен оседать в кешах прокси
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader заголовок с ключом идемпотентности POST запроса
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader отмечает ответ, повторенный из сохраненного
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// Тело запроса с ключом идемпотентности читается целиком, поэтому его размер ограничен
+	maxIdempotentBodySize = 1 << 20
+)
+
+// capturingWriter копирует тело ответа, чтобы сохранить его для повторов
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash отпечаток запроса: повтор с тем же ключом должен совпадать с первым запросом по методу, пути и телу
+func requestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/idempotency"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMemoryIdempotencyRepo репозиторий ключей в памяти; Create не заменяет существующие записи,
+// Complete и Delete меняют запись только той же попытки
+func newMemoryIdempotencyRepo() *mocks.IdempotencyRepoMock {
+	records := make(map[string]*entity.IdempotencyRecord)
+	id := func(userID uuid.UUID, key string) string { return userID.String() + ":" + key }
+	return &mocks.IdempotencyRepoMock{
+		CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+			if _, ok := records[id(record.UserID, record.Key)]; ok {
+				return false, nil
+			}
+			records[id(record.UserID, record.Key)] = record
+			return true, nil
+		},
+		GetFunc: func(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error) {
+			return records[id(userID, key)], nil
+		},
+		CompleteFunc: func(ctx context.Context, record *entity.IdempotencyRecord) error {
+			if existing, ok := records[id(record.UserID, record.Key)]; ok && existing.CreatedAt.Equal(record.CreatedAt) {
+				records[id(record.UserID, record.Key)] = record
+			}
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, record *entity.IdempotencyRecord) error {
+			if existing, ok := records[id(record.UserID, record.Key)]; ok && existing.CreatedAt.Equal(record.CreatedAt) {
+				delete(records, id(record.UserID, record.Key))
+			}
+			return nil
+		},
+	}
+}
+
+// newIdempotencyRouter маршрут, который отвечает status и считает вызовы
+func newIdempotencyRouter(userID uuid.UUID, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	middleware := &Middleware{
+		idempotency: idempotency.NewIdempotencyUsecase(newMemoryIdempotencyRepo(),
+			idempotency.Config{TTL: time.Hour, InProgressTimeout: time.Minute}, logger),
+		logger: logger,
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", userID) })
+	router.POST("/api/v1/messages", middleware.IdempotencyMiddleware(), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func idempotentRequest(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/messages", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyMiddleware_ReplaysResponse(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotencyRouter(uuid.New(), http.StatusCreated, &calls)
+
+	// Act
+	first := idempotentRequest(router, "key-1", `{"content":"hi"}`)
+	retry := idempotentRequest(router, "key-1", `{"content":"hi"}`)
+	other := idempotentRequest(router, "key-2", `{"content":"hi"}`)
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	assert.JSONEq(t, `{"call":2}`, other.Body.String())
+}
+
+func TestIdempotencyMiddleware_DifferentPayload(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotencyRouter(uuid.New(), http.StatusCreated, &calls)
+	idempotentRequest(router, "key-1", `{"content":"hi"}`)
+
+	// Act
+	rec := idempotentRequest(router, "key-1", `{"content":"bye"}`)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), apperror.CodeIdempotencyKeyReused)
+}
+
+func TestIdempotencyMiddleware_ServerErrorReleasesKey(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotencyRouter(uuid.New(), http.StatusInternalServerError, &calls)
+
+	// Act
+	idempotentRequest(router, "key-1", `{"content":"hi"}`)
+	rec := idempotentRequest(router, "key-1", `{"content":"hi"}`)
+
+	// Assert: ответ 5xx не сохранен, повтор выполняется заново
+	require.Equal(t, 2, calls)
+	assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type LogLevelHandler struct {
+	levels *logger.LevelController
+	logger *logrus.Logger
+}
+
+func NewLogLevelHandler(levels *logger.LevelController, logger *logrus.Logger) *LogLevelHandler {
+	return &LogLevelHandler{
+		levels: levels,
+		logger: logger,
+	}
+}
+
+// LogLevelRequest запрос на временную смену уровня логирования
+// swagger:model LogLevelRequest
+type LogLevelRequest struct {
+	// Уровень: trace, debug, info, warn, error
+	// required: true
+	Level string `json:"level" binding:"required" example:"debug"`
+	// Срок действия в формате Go duration, не больше logger.max_level_override
+	// required: true
+	Duration string `json:"duration" binding:"required" example:"15m"`
+}
+
+// LogLevelResponse текущий уровень логирования
+// swagger:model LogLevelResponse
+type LogLevelResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Data    logger.LevelStatus `json:"data"`
+}
+
+// GetLogLevel возвращает текущий уровень логирования
+// @Summary Уровень логирования
+// @Description Возвращает действующий уровень, уровень из конфигурации и срок временного уровня, если он задан
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} LogLevelResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	SendSuccess(c, h.levels.Status(), "Log level retrieved successfully", http.StatusOK)
+}
+
+// SetLogLevel временно меняет уровень логирования
+// @Summary Временный уровень логирования
+// @Description Меняет уровень логирования на указанный срок, после чего возвращается уровень из конфигурации. Повторный запрос заменяет предыдущий временный уровень
+// @Tags admin
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param request body LogLevelRequest true "Уровень и срок"
+// @Success 200 {object} LogLevelResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid log level request body")
+		SendBindError(c, err)
+		return
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+	if err != nil || level < logrus.ErrorLevel {
+		SendError(c, apperror.InvalidField(apperror.CodeLogLevelInvalid, "level", "level must be one of trace, debug, info, warn, error"))
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 || duration > h.levels.MaxOverride() {
+		SendError(c, apperror.InvalidField(
+			apperror.CodeLogLevelDuration,
+			"duration",
+			"duration must be a positive Go duration not longer than "+h.levels.MaxOverride().String(),
+		))
+		return
+	}
+
+	status, err := h.levels.Override(level, duration)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	actorID, _ := GetUserFromContext(c)
+	h.logger.WithContext(c).WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"level":    status.Level,
+		"duration": duration,
+	}).Warn("log level override set by admin")
+
+	SendSuccess(c, status, "Log level overridden successfully", http.StatusOK)
+}
+
+// ResetLogLevel отменяет временный уровень логирования
+// @Summary Отмена временного уровня логирования
+// @Description Сразу возвращает уровень из конфигурации. Если временный уровень не задан, ничего не меняет
+// @Tags admin
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} LogLevelResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /admin/log-level [delete]
+func (h *LogLevelHandler) ResetLogLevel(c *gin.Context) {
+	status := h.levels.Reset()
+
+	actorID, _ := GetUserFromContext(c)
+	h.logger.WithContext(c).WithField("actor_id", actorID).Info("log level override reset by admin")
+
+	SendSuccess(c, status, "Log level reset successfully", http.StatusOK)
+}
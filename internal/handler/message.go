@@ -3,7 +3,9 @@ package handler
 import (
 	"net/http"
 
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
+	"chat-service/internal/metrics"
 	"chat-service/internal/usecase/message"
 
 	"github.com/gin-gonic/gin"
@@ -13,15 +15,18 @@ import (
 
 type MessageHandler struct {
 	messageUsecase message.MessageUsecase
+	metrics        *metrics.Metrics
 	logger         *logrus.Logger
 }
 
 func NewMessageHandler(
 	messageUsecase message.MessageUsecase,
+	appMetrics *metrics.Metrics,
 	logger *logrus.Logger,
 ) *MessageHandler {
 	return &MessageHandler{
 		messageUsecase: messageUsecase,
+		metrics:        appMetrics,
 		logger:         logger,
 	}
 }
@@ -54,80 +59,93 @@ type MessagesResponse struct {
 
 // CreateMessage создает новое сообщение
 // @Summary Создание нового сообщения
-// @Description Создает новое сообщение от авторизованного пользователя
+// @Description Создает новое сообщение от авторизованного пользователя. Частота публикации ограничена: при превышении лимита, повторе недавнего текста или в slow mode возвращается 429 с Retry-After. Упоминание (@имя) пользователя, который заблокировал автора, отклоняется с 403. Повтор с тем же Idempotency-Key возвращает сохраненный ответ с заголовком Idempotent-Replayed
 // @Tags messages
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Param message body CreateMessageRequest true "Текст сообщения"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, до 255 печатных ASCII символов"
 // @Success 201 {object} MessageResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /messages [post]
 func (h *MessageHandler) CreateMessage(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
 
 	var req CreateMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Warn("invalid create message request body")
-		SendError(c, "Invalid request", err.Error(), http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid create message request body")
+		SendBindError(c, err)
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
+	h.logger.WithContext(c).WithFields(logrus.Fields{
 		"user_id": userID,
 		"content": req.Content[:min(50, len(req.Content))] + "...",
 	}).Info("creating new message")
 
 	message, err := h.messageUsecase.CreateMessage(c.Request.Context(), userID, req.Content)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to create message")
+		h.logger.WithContext(c).WithError(err).Error("failed to create message")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("message_id", message.ID).Info("message created successfully")
+	h.metrics.MessageCreated()
+	h.logger.WithContext(c).WithField("message_id", message.ID).Info("message created successfully")
 	SendSuccess(c, message, "Message created successfully", http.StatusCreated)
 }
 
 // GetMessageByID возвращает сообщение по ID
 // @Summary Получение сообщения по ID
-// @Description Возвращает конкретное сообщение по его идентификатору
+// @Description Возвращает конкретное сообщение по его идентификатору. Сообщения заблокированных пользователем авторов не возвращаются (404)
 // @Tags messages
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Param id path string true "ID сообщения" Format(uuid)
 // @Success 200 {object} MessageResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /messages/{id} [get]
 func (h *MessageHandler) GetMessageByID(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
 	messageID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		h.logger.WithError(err).Warn("invalid message ID format")
-		SendError(c, "Invalid message ID", "Message ID must be a valid UUID", http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid message ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "message ID must be a valid UUID"))
 		return
 	}
 
-	h.logger.WithField("message_id", messageID).Debug("fetching message by ID")
+	h.logger.WithContext(c).WithField("message_id", messageID).Debug("fetching message by ID")
 
-	message, err := h.messageUsecase.GetMessageByID(c.Request.Context(), messageID)
+	message, err := h.messageUsecase.GetMessageByID(c.Request.Context(), messageID, userID, GetRoleFromContext(c))
 	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch message by ID")
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch message by ID")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("message_id", messageID).Debug("message fetched successfully")
+	h.logger.WithContext(c).WithField("message_id", messageID).Debug("message fetched successfully")
 	SendSuccess(c, message, "Message retrieved successfully", http.StatusOK)
 }
 
@@ -136,120 +154,105 @@ func (h *MessageHandler) GetMessageByID(c *gin.Context) {
 // @Description Возвращает все сообщения авторизованного пользователя
 // @Tags messages
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Success 200 {object} MessagesResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /messages/my [get]
 func (h *MessageHandler) GetMessagesByUser(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Debug("fetching messages for user")
+	h.logger.WithContext(c).WithField("user_id", userID).Debug("fetching messages for user")
 
 	messages, err := h.messageUsecase.GetMessagesByUser(c.Request.Context(), userID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch user messages")
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch user messages")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Debugf("fetched %d messages for user", len(messages))
+	h.logger.WithContext(c).WithField("user_id", userID).Debugf("fetched %d messages for user", len(messages))
 	SendSuccess(c, messages, "Messages retrieved successfully", http.StatusOK)
 }
 
 // GetAllMessages возвращает все сообщения
 // @Summary Получение всех сообщений
-// @Description Возвращает все сообщения в системе (публичный доступ)
+// @Description Возвращает все сообщения в системе (публичный доступ). Если передан токен, сообщения заблокированных пользователем авторов исключаются
 // @Tags messages
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
+// @Security Bearer
 // @Success 200 {object} MessagesResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /messages [get]
 func (h *MessageHandler) GetAllMessages(c *gin.Context) {
-	h.logger.Debug("fetching all messages")
+	h.logger.WithContext(c).Debug("fetching all messages")
 
-	messages, err := h.messageUsecase.GetAllMessages(c.Request.Context())
+	// Анонимный запрос: uuid.Nil, фильтрация по блокировкам не применяется
+	viewerID, _ := GetUserFromContext(c)
+
+	messages, err := h.messageUsecase.GetAllMessages(c.Request.Context(), viewerID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch all messages")
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch all messages")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.Debugf("fetched %d messages total", len(messages))
+	h.logger.WithContext(c).Debugf("fetched %d messages total", len(messages))
 	SendSuccess(c, messages, "Messages retrieved successfully", http.StatusOK)
 }
 
 // DeleteMessage удаляет сообщение
 // @Summary Удаление сообщения
-// @Description Удаляет сообщение авторизованного пользователя
+// @Description Удаляет сообщение авторизованного пользователя. Модераторы и администраторы могут удалять любые сообщения
 // @Tags messages
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Param id path string true "ID сообщения" Format(uuid)
 // @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /messages/{id} [delete]
 func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
 
 	messageID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		h.logger.WithError(err).Warn("invalid message ID format")
-		SendError(c, "Invalid message ID", "Message ID must be a valid UUID", http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid message ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "message ID must be a valid UUID"))
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
+	h.logger.WithContext(c).WithFields(logrus.Fields{
 		"user_id":    userID,
 		"message_id": messageID,
 	}).Warn("message deletion requested")
 
-	// TODO: Проверить права доступа (владелец сообщения или админ)
-	// Пока что разрешаем владельцу удалять свои сообщения
-
-	// Получаем сообщение для проверки владельца
-	message, err := h.messageUsecase.GetMessageByID(c.Request.Context(), messageID)
-	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch message for deletion check")
-		HandleError(c, err, h.logger)
-		return
-	}
-
-	if message.UserID != userID {
-		h.logger.WithFields(logrus.Fields{
-			"user_id":    userID,
-			"message_id": messageID,
-			"owner_id":   message.UserID,
-		}).Warn("user trying to delete another user's message")
-		SendError(c, "Forbidden", "You can only delete your own messages", http.StatusForbidden)
-		return
-	}
-
-	err = h.messageUsecase.DeleteMessage(c.Request.Context(), messageID)
+	// Автор удаляет свое сообщение, модератор и администратор — любое
+	err = h.messageUsecase.DeleteMessage(c.Request.Context(), messageID, userID, GetRoleFromContext(c))
 	if err != nil {
-		h.logger.WithError(err).Error("failed to delete message")
+		h.logger.WithContext(c).WithError(err).Warn("failed to delete message")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("message_id", messageID).Info("message deleted successfully")
+	h.logger.WithContext(c).WithField("message_id", messageID).Info("message deleted successfully")
 	SendSuccess(c, nil, "Message deleted successfully", http.StatusOK)
 }
 
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/metrics"
+	"chat-service/internal/usecase/loginguard"
+	"chat-service/internal/usecase/mfa"
+	"chat-service/internal/usecase/session"
+	"chat-service/internal/usecase/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MFAHandler struct {
+	mfaUsecase     mfa.MFAUsecase
+	userUsecase    user.UserUsecase
+	sessionUsecase session.SessionUsecase
+	loginGuard     loginguard.LoginGuardUsecase
+	metrics        *metrics.Metrics
+	logger         *logrus.Logger
+}
+
+func NewMFAHandler(
+	mfaUsecase mfa.MFAUsecase,
+	userUsecase user.UserUsecase,
+	sessionUsecase session.SessionUsecase,
+	loginGuard loginguard.LoginGuardUsecase,
+	appMetrics *metrics.Metrics,
+	logger *logrus.Logger,
+) *MFAHandler {
+	return &MFAHandler{
+		mfaUsecase:     mfaUsecase,
+		userUsecase:    userUsecase,
+		sessionUsecase: sessionUsecase,
+		loginGuard:     loginGuard,
+		metrics:        appMetrics,
+		logger:         logger,
+	}
+}
+
+// MFACodeRequest структура с кодом подтверждения
+// swagger:model MFACodeRequest
+type MFACodeRequest struct {
+	// TOTP-код из приложения или код восстановления
+	// required: true
+	Code string `json:"code" binding:"required"`
+}
+
+// LoginMFARequest структура для второго шага логина
+// swagger:model LoginMFARequest
+type LoginMFARequest struct {
+	// Токен, полученный от /login
+	// required: true
+	MFAToken string `json:"mfa_token" binding:"required"`
+
+	// TOTP-код из приложения или код восстановления
+	// required: true
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAEnrollmentResponse структура ответа с данными для подключения 2FA
+// swagger:model MFAEnrollmentResponse
+type MFAEnrollmentResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Data    *entity.MFAEnrollment `json:"data"`
+}
+
+// RecoveryCodesResponse структура ответа с кодами восстановления
+// swagger:model RecoveryCodesResponse
+type RecoveryCodesResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Data    []string `json:"data"`
+}
+
+// Enroll начинает подключение двухфакторной аутентификации
+// @Summary Подключение 2FA
+// @Description Генерирует TOTP-секрет и otpauth URI. 2FA включается только после подтверждения кодом
+// @Tags mfa
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} MFAEnrollmentResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	enrollment, err := h.mfaUsecase.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("mfa enrollment failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("mfa enrollment started")
+	SendSuccess(c, enrollment, "Scan the URI with an authenticator app and confirm with a code", http.StatusOK)
+}
+
+// Confirm подтверждает подключение 2FA
+// @Summary Подтверждение 2FA
+// @Description Включает 2FA по коду из приложения и возвращает одноразовые коды восстановления (показываются один раз)
+// @Tags mfa
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param code body MFACodeRequest true "Код из приложения"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid mfa confirm request body")
+		SendBindError(c, err)
+		return
+	}
+
+	codes, err := h.mfaUsecase.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("mfa confirmation failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("mfa enabled")
+	SendSuccess(c, codes, "Two-factor authentication enabled", http.StatusOK)
+}
+
+// Disable отключает 2FA
+// @Summary Отключение 2FA
+// @Description Отключает 2FA после проверки текущего кода или кода восстановления
+// @Tags mfa
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param code body MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/mfa [delete]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid mfa disable request body")
+		SendBindError(c, err)
+		return
+	}
+
+	if err := h.mfaUsecase.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("mfa disable failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("mfa disabled")
+	SendSuccess(c, nil, "Two-factor authentication disabled", http.StatusOK)
+}
+
+// RegenerateRecoveryCodes выпускает новый набор кодов восстановления
+// @Summary Новые коды восстановления
+// @Description Аннулирует старые коды восстановления и возвращает новые
+// @Tags mfa
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param code body MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid recovery codes request body")
+		SendBindError(c, err)
+		return
+	}
+
+	codes, err := h.mfaUsecase.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("recovery codes regeneration failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("recovery codes regenerated")
+	SendSuccess(c, codes, "Recovery codes regenerated", http.StatusOK)
+}
+
+// LoginMFA завершает вход с двухфакторной аутентификацией
+// @Summary Второй шаг входа (2FA)
+// @Description Проверяет mfa_token из /login и код 2FA, создает сессию. mfa_token одноразовый, и по нему можно ввести ограниченное число кодов.
+// @Description Новый вход с паролем отменяет прежний mfa_token. Неверные коды учитываются защитой от перебора вместе с неверными паролями (429 с Retry-After)
+// @Tags users
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Param credentials body LoginMFARequest true "Токен challenge и код"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 429 {object} Problem
+// @Router /login/mfa [post]
+func (h *MFAHandler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid mfa login request body")
+		SendBindError(c, err)
+		return
+	}
+
+	userID, err := h.mfaUsecase.ChallengeUser(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		h.metrics.LoginAttempt(metrics.LoginMethodMFA, metrics.LoginResultFailure)
+		h.logger.WithContext(c).WithError(err).Warn("mfa login failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	user, err := h.userUsecase.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch user for mfa login")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	// Коды 2FA перебираются так же, как пароли, поэтому подчиняются тем же блокировкам
+	clientIP := c.ClientIP()
+	if err := h.loginGuard.Check(c.Request.Context(), user.Email, clientIP); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("mfa login blocked by brute-force protection")
+		if apperror.IsKind(err, apperror.KindTooManyRequests) {
+			h.metrics.LoginAttempt(metrics.LoginMethodMFA, metrics.LoginResultLocked)
+		}
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	if _, err := h.mfaUsecase.VerifyChallenge(c.Request.Context(), req.MFAToken, req.Code); err != nil {
+		if apperror.IsKind(err, apperror.KindUnauthorized) {
+			h.loginGuard.RecordFailure(c.Request.Context(), user.Email, clientIP)
+		}
+		h.metrics.LoginAttempt(metrics.LoginMethodMFA, metrics.LoginResultFailure)
+		h.logger.WithContext(c).WithError(err).Warn("mfa login failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.loginGuard.RecordSuccess(c.Request.Context(), user.Email, clientIP, user.ID)
+
+	session, err := h.sessionUsecase.CreateSession(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to create session after mfa login")
+		SendError(c, apperror.Internal("failed to create session"))
+		return
+	}
+
+	response := struct {
+		User    *entity.User    `json:"user"`
+		Session *entity.Session `json:"session"`
+	}{
+		User:    user,
+		Session: session,
+	}
+
+	h.metrics.LoginAttempt(metrics.LoginMethodMFA, metrics.LoginResultSuccess)
+	h.logger.WithContext(c).WithField("user_id", user.ID).Info("user logged in with mfa successfully")
+	SendSuccess(c, response, "Login successful", http.StatusOK)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/loginguard"
+	"chat-service/internal/usecase/mfa"
+	"chat-service/internal/usecase/mocks"
+	"chat-service/internal/usecase/session"
+	"chat-service/internal/usecase/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMFAEmail = "alice@example.com"
+	testMFACode  = "123456"
+)
+
+// mfaLoginEnv маршруты /login и /login/mfa для пользователя с включенной 2FA
+type mfaLoginEnv struct {
+	router    *gin.Engine
+	attempts  []*entity.LoginAttempt
+	codeCalls int
+}
+
+func newMFALoginEnv(maxFailures int) *mfaLoginEnv {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	env := &mfaLoginEnv{}
+
+	account := entity.User{ID: uuid.New(), Email: testMFAEmail, Password: "hashed_password"}
+	userRepo := &mocks.UserRepoMock{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			found := account
+			return &found, nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			found := account
+			return &found, nil
+		},
+	}
+
+	// Неудачи аккаунта считаются после последнего успешного входа, как в репозитории
+	attemptRepo := &mocks.LoginAttemptRepoMock{
+		CreateFunc: func(ctx context.Context, attempt *entity.LoginAttempt) error {
+			env.attempts = append(env.attempts, attempt)
+			return nil
+		},
+		GetAccountFailureStatsFunc: func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+			stats := &entity.LoginFailureStats{}
+			for _, attempt := range env.attempts {
+				switch attempt.Outcome {
+				case entity.LoginOutcomeSuccess:
+					stats = &entity.LoginFailureStats{}
+				case entity.LoginOutcomeFailure:
+					createdAt := attempt.CreatedAt
+					stats.Count++
+					stats.LastFailureAt = &createdAt
+				}
+			}
+			return stats, nil
+		},
+	}
+
+	mfaRepo := &mocks.MFARepoMock{
+		GetByUserIDFunc: func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+			return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+		},
+		UseRecoveryCodeFunc: func(ctx context.Context, userID uuid.UUID, codeHash string) error {
+			return &testNotFoundError{"recovery code not found"}
+		},
+	}
+	totpService := &mocks.TOTPServiceMock{
+		ValidateFunc: func(code, secret string, at time.Time) (int64, bool) {
+			env.codeCalls++
+			return int64(env.codeCalls), code == testMFACode
+		},
+	}
+	jwtService := &mocks.JWTServiceMock{
+		ValidateMFATokenFunc: func(token string) (uuid.UUID, uuid.UUID, error) {
+			return account.ID, uuid.New(), nil
+		},
+	}
+
+	userUsecase := user.NewUserUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.HashServiceMock{}, jwtService, &mocks.PasswordPolicyMock{}, logger)
+	sessionUsecase := session.NewSessionUsecase(&mocks.SessionRepoMock{}, userRepo, jwtService, logger)
+	mfaUsecase := mfa.NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, mfa.Config{
+		Issuer:               "Chat Service",
+		ChallengeTTL:         5 * time.Minute,
+		RecoveryCodeCount:    10,
+		MaxChallengeAttempts: 5,
+	}, logger)
+	loginGuard := loginguard.NewLoginGuardUsecase(attemptRepo, loginguard.Config{
+		Window:             time.Hour,
+		AccountMaxFailures: maxFailures,
+		BaseLockout:        time.Minute,
+		MaxLockout:         time.Hour,
+	}, logger)
+
+	userHandler := NewUserHandler(userUsecase, sessionUsecase, mfaUsecase, nil, loginGuard, nil, logger)
+	mfaHandler := NewMFAHandler(mfaUsecase, userUsecase, sessionUsecase, loginGuard, nil, logger)
+
+	env.router = gin.New()
+	env.router.POST("/api/v1/login", userHandler.Login)
+	env.router.POST("/api/v1/login/mfa", mfaHandler.LoginMFA)
+	return env
+}
+
+func (e *mfaLoginEnv) post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (e *mfaLoginEnv) loginMFA(code string) *httptest.ResponseRecorder {
+	return e.post("/api/v1/login/mfa", `{"mfa_token":"test_mfa_token","code":"`+code+`"}`)
+}
+
+func (e *mfaLoginEnv) outcomes() []string {
+	outcomes := make([]string, 0, len(e.attempts))
+	for _, attempt := range e.attempts {
+		outcomes = append(outcomes, attempt.Outcome)
+	}
+	return outcomes
+}
+
+func TestLogin_PasswordWithMFADoesNotResetFailures(t *testing.T) {
+	// Arrange
+	env := newMFALoginEnv(3)
+
+	// Act
+	rec := env.post("/api/v1/login", `{"email":"`+testMFAEmail+`","password":"secret"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, env.outcomes(), "успешный вход учитывается только после проверки кода 2FA")
+}
+
+func TestLoginMFA_RecordsOutcomes(t *testing.T) {
+	// Arrange
+	env := newMFALoginEnv(3)
+
+	// Act
+	failed := env.loginMFA("000000")
+	passed := env.loginMFA(testMFACode)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, failed.Code)
+	assert.Equal(t, http.StatusOK, passed.Code)
+	assert.Equal(t, []string{entity.LoginOutcomeFailure, entity.LoginOutcomeSuccess}, env.outcomes())
+	for _, attempt := range env.attempts {
+		assert.Equal(t, testMFAEmail, attempt.Email)
+	}
+}
+
+func TestLoginMFA_InvalidCodesLockAccount(t *testing.T) {
+	// Arrange: каждый раз новый challenge, как после повторного входа с паролем
+	env := newMFALoginEnv(3)
+	for i := 0; i < 3; i++ {
+		rec := env.loginMFA("000000")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+	codeCalls := env.codeCalls
+
+	// Act
+	rec := env.loginMFA(testMFACode)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, codeCalls, env.codeCalls, "при блокировке код не проверяется")
+}
@@ -1,35 +1,80 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/metrics"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase/apikey"
+	"chat-service/internal/usecase/idempotency"
+	"chat-service/internal/usecase/ratelimit"
 	"chat-service/internal/usecase/session"
+	"chat-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader заголовок с ID запроса
+	RequestIDHeader = "X-Request-ID"
+	// Более длинный X-Request-ID от клиента заменяется новым
+	maxRequestIDLength = 128
 )
 
 type Middleware struct {
 	sessionUsecase session.SessionUsecase
+	apiKeyUsecase  apikey.APIKeyUsecase
+	rateLimiter    ratelimit.RateLimitUsecase
+	idempotency    idempotency.IdempotencyUsecase
+	metrics        *metrics.Metrics
+	cors           *corsPolicy
 	logger         *logrus.Logger
 }
 
-func NewMiddleware(sessionUsecase session.SessionUsecase, logger *logrus.Logger) *Middleware {
+// NewMiddleware создает middleware. rateLimiter может быть nil - тогда частота запросов не ограничивается;
+// idempotencyUsecase может быть nil - тогда заголовок Idempotency-Key игнорируется
+func NewMiddleware(
+	sessionUsecase session.SessionUsecase,
+	apiKeyUsecase apikey.APIKeyUsecase,
+	rateLimiter ratelimit.RateLimitUsecase,
+	idempotencyUsecase idempotency.IdempotencyUsecase,
+	appMetrics *metrics.Metrics,
+	corsConfig CORSConfig,
+	logger *logrus.Logger,
+) *Middleware {
 	return &Middleware{
 		sessionUsecase: sessionUsecase,
+		apiKeyUsecase:  apiKeyUsecase,
+		rateLimiter:    rateLimiter,
+		idempotency:    idempotencyUsecase,
+		metrics:        appMetrics,
+		cors:           newCORSPolicy(corsConfig),
 		logger:         logger,
 	}
 }
 
-// AuthMiddleware проверяет JWT токен и устанавливает userID в контекст
+// AuthMiddleware проверяет JWT токен сессии или персональный API ключ и устанавливает userID и роль в контекст.
+// Запросы с API ключом дополнительно ограничиваются через RequireSession и RequireScope
 func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			m.logger.Warn("authorization header is missing")
-			SendError(c, "Authorization required", "No authorization header provided", http.StatusUnauthorized)
+			m.logger.WithContext(c).Warn("authorization header is missing")
+			SendError(c, apperror.Unauthorized(apperror.CodeAuthorizationHeader, "authorization header is required"))
 			c.Abort()
 			return
 		}
@@ -37,41 +82,306 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 		// Ожидаем формат: "Bearer <token>"
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			m.logger.Warn("invalid authorization header format")
-			SendError(c, "Invalid authorization format", "Use 'Bearer <token>' format", http.StatusUnauthorized)
+			m.logger.WithContext(c).Warn("invalid authorization header format")
+			SendError(c, apperror.Unauthorized(apperror.CodeAuthorizationHeader, "authorization header must use the 'Bearer <token>' format"))
 			c.Abort()
 			return
 		}
 
+		// API ключ отличается от JWT префиксом
+		if strings.HasPrefix(tokenString, apikey.TokenPrefix) {
+			key, owner, err := m.apiKeyUsecase.Authenticate(c.Request.Context(), tokenString)
+			if err != nil {
+				m.logger.WithContext(c).WithError(err).Warn("api key validation failed")
+				HandleError(c, err, m.logger)
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", key.UserID)
+			c.Set("role", owner.EffectiveRole())
+			c.Set("apiKey", key)
+			trace.SpanFromContext(c.Request.Context()).SetAttributes(tracing.UserID(key.UserID))
+			c.Next()
+			return
+		}
+
 		// Валидируем сессию
-		session, err := m.sessionUsecase.ValidateSession(c.Request.Context(), tokenString)
+		session, user, err := m.sessionUsecase.ValidateSession(c.Request.Context(), tokenString)
 		if err != nil {
-			m.logger.WithError(err).Warn("session validation failed")
-			SendError(c, "Invalid session", "Session is invalid or expired", http.StatusUnauthorized)
+			m.logger.WithContext(c).WithError(err).Warn("session validation failed")
+			HandleError(c, err, m.logger)
 			c.Abort()
 			return
 		}
 
-		// Устанавливаем userID в контекст
+		// Роль берется из пользователя, загруженного при проверке сессии, поэтому ее отзыв действует сразу
 		c.Set("userID", session.UserID)
+		c.Set("role", user.EffectiveRole())
 		c.Set("session", session)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(tracing.UserID(session.UserID))
 		c.Next()
 	}
 }
 
-// CORSMiddleware добавляет CORS заголовки
+// OptionalAuth аутентифицирует запрос, только если передан заголовок Authorization.
+// Для публичных маршрутов, ответ которых зависит от пользователя; неверный токен по-прежнему отклоняется
+func (m *Middleware) OptionalAuth() gin.HandlerFunc {
+	auth := m.AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// RequirePermission пропускает запрос, только если роль пользователя дает право permission
+func (m *Middleware) RequirePermission(permission entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRoleFromContext(c)
+		if !role.Can(permission) {
+			m.logger.WithContext(c).WithFields(logrus.Fields{
+				"role":       role,
+				"permission": permission,
+			}).Warn("permission denied")
+			SendError(c, apperror.Forbidden(apperror.CodePermissionDenied, "permission denied"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession запрещает доступ по API ключу: управление аккаунтом доступно только из сессии
+func (m *Middleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := getAPIKeyFromContext(c); key != nil {
+			m.logger.WithContext(c).WithField("api_key_id", key.ID).Warn("api key used for session-only endpoint")
+			SendError(c, apperror.Forbidden(apperror.CodeSessionRequired, "API keys cannot access this endpoint"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope пропускает запрос по API ключу, только если ключу выдано право scope. Сессии не ограничиваются
+func (m *Middleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := getAPIKeyFromContext(c); key != nil && !key.HasScope(scope) {
+			m.logger.WithContext(c).WithFields(logrus.Fields{
+				"api_key_id": key.ID,
+				"scope":      scope,
+			}).Warn("api key lacks required scope")
+			SendError(c, apperror.Forbidden(apperror.CodeScopeMissing, "API key lacks required scope: "+scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequestIDMiddleware принимает ID запроса из заголовка X-Request-ID или создает новый,
+// сохраняет его в контексте запроса для логов и возвращает клиенту в том же заголовке
+func (m *Middleware) RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// validRequestID принимает только короткие ID из безопасных символов, чтобы клиент не мог
+// подделать записи лога переводом строки или раздуть их длинным значением
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum && !strings.ContainsRune("-_.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// CORSMiddleware добавляет заголовки CORS по настроенной политике и отвечает на preflight запросы
 func (m *Middleware) CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if origin := c.GetHeader("Origin"); origin != "" {
+			m.cors.apply(c.Writer.Header(), origin, preflight)
+		}
+
+		// Маршрутов OPTIONS нет, поэтому любой OPTIONS запрос завершается здесь
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware ограничивает частоту запросов по политике маршрута и сообщает состояние лимита
+// в заголовках RateLimit-*. Подключается после аутентификации, чтобы политики могли считать запросы
+// по пользователю или API ключу
+func (m *Middleware) RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.rateLimiter == nil {
+			c.Next()
+			return
+		}
+
+		client := ratelimit.Client{IP: c.ClientIP()}
+		if userID, err := GetUserFromContext(c); err == nil {
+			client.UserID = userID
+		}
+		if key := getAPIKeyFromContext(c); key != nil {
+			client.APIKeyID = key.ID
+		}
+
+		status, err := m.rateLimiter.Allow(c.Request.Context(), c.Request.Method+" "+c.FullPath(), client)
+		if status != nil {
+			setRateLimitHeaders(c.Writer.Header(), status)
+		}
+		if err != nil {
+			HandleError(c, err, m.logger)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders заголовки RateLimit-* по проекту стандарта IETF; время в целых секундах
+func setRateLimitHeaders(header http.Header, status *entity.RateLimitStatus) {
+	header.Set("RateLimit-Limit", strconv.Itoa(status.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(status.ResetAfter.Seconds()))))
+	header.Set("RateLimit-Policy", strconv.Itoa(status.Limit)+";w="+strconv.Itoa(int(status.Window.Seconds())))
+}
+
+// IdempotencyMiddleware сохраняет первый ответ на запрос с заголовком Idempotency-Key и повторяет его
+// для запросов с тем же ключом, не выполняя их. Ключи принадлежат пользователю, поэтому middleware
+// подключается после аутентификации и проверки прав. Ответы 429 и 5xx не сохраняются: такой запрос
+// можно повторить с тем же ключом
+func (m *Middleware) IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, err := GetUserFromContext(c)
+		if m.idempotency == nil || key == "" || err != nil {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusOK)
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			message := "failed to read request body"
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				message = "request body is too large"
+			}
+			SendError(c, apperror.Validation(apperror.CodeInvalidRequest, message))
+			c.Abort()
 			return
 		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+		record, err := m.idempotency.Begin(ctx, userID, key, hash)
+		if err != nil {
+			HandleError(c, err, m.logger)
+			c.Abort()
+			return
+		}
+		if record.IsCompleted() {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		completed := false
+		defer func() {
+			// Запрос завершился паникой или ответ не сохраняется: освобождаем ключ для повтора
+			if completed {
+				return
+			}
+			if err := m.idempotency.Release(ctx, record); err != nil {
+				m.logger.WithContext(ctx).WithError(err).Error("failed to release idempotency key")
+			}
+		}()
 
 		c.Next()
+
+		status := writer.Status()
+		if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+			return
+		}
+		completed = true
+		record.StatusCode = status
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.Body = writer.body.Bytes()
+		if err := m.idempotency.Complete(ctx, record); err != nil {
+			// Ответ уже отправлен; без сохраненного ответа повтор получит 409 до истечения in_progress_timeout
+			m.logger.WithContext(ctx).WithError(err).Error("failed to store idempotent response")
+		}
+	}
+}
+
+// TracingMiddleware начинает спан запроса, продолжая трассу из заголовка traceparent, если он передан.
+// ID пользователя добавляется в спан после аутентификации
+func (m *Middleware) TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracing.StartServer(c.Request, c.Request.Method+" "+route,
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(c.Request.URL.Path),
+			semconv.ClientAddress(c.ClientIP()),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// MetricsMiddleware учитывает число и длительность запросов по шаблонам маршрутов
+func (m *Middleware) MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			// Без шаблона каждый несуществующий путь создал бы отдельный временной ряд
+			route = "unmatched"
+		}
+		m.metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
 	}
 }
 
@@ -79,7 +389,7 @@ func (m *Middleware) CORSMiddleware() gin.HandlerFunc {
 func (m *Middleware) LoggingMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// До обработки запроса
-		m.logger.WithFields(logrus.Fields{
+		m.logger.WithContext(c).WithFields(logrus.Fields{
 			"method": c.Request.Method,
 			"path":   c.Request.URL.Path,
 			"client": c.ClientIP(),
@@ -89,7 +399,7 @@ func (m *Middleware) LoggingMiddleware() gin.HandlerFunc {
 		c.Next()
 
 		// После обработки запроса
-		m.logger.WithFields(logrus.Fields{
+		m.logger.WithContext(c).WithFields(logrus.Fields{
 			"method":  c.Request.Method,
 			"path":    c.Request.URL.Path,
 			"status":  c.Writer.Status(),
@@ -103,24 +413,45 @@ func (m *Middleware) LoggingMiddleware() gin.HandlerFunc {
 func GetUserFromContext(c *gin.Context) (uuid.UUID, error) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		return uuid.Nil, &UnauthorizedErrorImpl{"user not authenticated"}
+		return uuid.Nil, apperror.Unauthorized(apperror.CodeUnauthorized, "user not authenticated")
 	}
 
 	if id, ok := userID.(uuid.UUID); ok {
 		return id, nil
 	}
 
-	return uuid.Nil, &UnauthorizedErrorImpl{"invalid user ID in context"}
+	return uuid.Nil, apperror.Unauthorized(apperror.CodeUnauthorized, "invalid user ID in context")
 }
 
-type UnauthorizedErrorImpl struct {
-	Message string
+// GetSessionFromContext извлекает текущую сессию из контекста
+func GetSessionFromContext(c *gin.Context) (*entity.Session, error) {
+	value, exists := c.Get("session")
+	if !exists {
+		return nil, apperror.Unauthorized(apperror.CodeUnauthorized, "session not found in context")
+	}
+
+	if session, ok := value.(*entity.Session); ok && session != nil {
+		return session, nil
+	}
+
+	return nil, apperror.Unauthorized(apperror.CodeUnauthorized, "invalid session in context")
 }
 
-func (e *UnauthorizedErrorImpl) Error() string {
-	return e.Message
+// GetRoleFromContext возвращает роль текущего пользователя; без роли в контексте — базовая роль
+func GetRoleFromContext(c *gin.Context) entity.Role {
+	if value, exists := c.Get("role"); exists {
+		if role, ok := value.(entity.Role); ok {
+			return role
+		}
+	}
+	return entity.RoleUser
 }
 
-func (e *UnauthorizedErrorImpl) Unauthorized() bool {
-	return true
+func getAPIKeyFromContext(c *gin.Context) *entity.APIKey {
+	value, exists := c.Get("apiKey")
+	if !exists {
+		return nil
+	}
+	key, _ := value.(*entity.APIKey)
+	return key
 }
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/moderation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ModerationHandler struct {
+	moderationUsecase moderation.ModerationUsecase
+	logger            *logrus.Logger
+}
+
+func NewModerationHandler(moderationUsecase moderation.ModerationUsecase, logger *logrus.Logger) *ModerationHandler {
+	return &ModerationHandler{
+		moderationUsecase: moderationUsecase,
+		logger:            logger,
+	}
+}
+
+// ReportMessageRequest жалоба на сообщение
+// swagger:model ReportMessageRequest
+type ReportMessageRequest struct {
+	// Причина: spam, harassment, hate, illegal или other
+	// required: true
+	Reason entity.ReportReason `json:"reason" binding:"required"`
+	// Комментарий для модераторов
+	// max length: 1000
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// ModerationDecisionRequest решение модератора по жалобе
+// swagger:model ModerationDecisionRequest
+type ModerationDecisionRequest struct {
+	// Заметка модератора, сохраняется в журнале
+	// max length: 1000
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ModerationActionRequest мера по жалобе
+// swagger:model ModerationActionRequest
+type ModerationActionRequest struct {
+	// Мера: hide_message, warn_author или suspend_author
+	// required: true
+	Action entity.ModerationActionType `json:"action" binding:"required"`
+	// Заметка модератора; для suspend_author становится причиной блокировки, для warn_author попадает в письмо
+	// max length: 1000
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ReportResponse жалоба
+// swagger:model ReportResponse
+type ReportResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Data    *entity.Report `json:"data"`
+}
+
+// ReportListResponse страница очереди модерации
+// swagger:model ReportListResponse
+type ReportListResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Data    *entity.ReportList `json:"data"`
+}
+
+// ReportMessage создает жалобу на сообщение
+// @Summary Жалоба на сообщение
+// @Description Отправляет сообщение в очередь модерации. На одно сообщение можно пожаловаться один раз, на свое — нельзя
+// @Tags moderation
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID сообщения" Format(uuid)
+// @Param request body ReportMessageRequest true "Причина жалобы"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, до 255 печатных ASCII символов"
+// @Success 201 {object} ReportResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /messages/{id}/report [post]
+func (h *ModerationHandler) ReportMessage(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	messageID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid message ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "message ID must be a valid UUID"))
+		return
+	}
+
+	var req ReportMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid report request body")
+		SendBindError(c, err)
+		return
+	}
+
+	report, err := h.moderationUsecase.ReportMessage(c.Request.Context(), userID, messageID, req.Reason, req.Comment)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	// Репортеру не нужны данные модерации, возвращаем жалобу без сообщения
+	report.Message = nil
+	SendSuccess(c, report, "Message reported successfully", http.StatusCreated)
+}
+
+// ListReports возвращает очередь модерации
+// @Summary Очередь модерации
+// @Description Возвращает жалобы постранично, старые первыми. По умолчанию только открытые; status=all — все жалобы
+// @Tags moderation
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param status query string false "Статус: open (по умолчанию), dismissed, actioned или all"
+// @Param limit query int false "Размер страницы (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} ReportListResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /moderation/reports [get]
+func (h *ModerationHandler) ListReports(c *gin.Context) {
+	moderatorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	filter := entity.ReportFilter{}
+	if status := c.DefaultQuery("status", string(entity.ReportStatusOpen)); status != "all" {
+		reportStatus := entity.ReportStatus(status)
+		filter.Status = &reportStatus
+	}
+
+	for name, target := range map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, name, name+" must be an integer"))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	list, err := h.moderationUsecase.ListReports(c.Request.Context(), moderatorID, filter)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, list, "Reports retrieved successfully", http.StatusOK)
+}
+
+// GetReport возвращает жалобу с журналом решений
+// @Summary Жалоба по ID
+// @Description Возвращает жалобу, сообщение, на которое она подана, и все решения модераторов по ней
+// @Tags moderation
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID жалобы" Format(uuid)
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /moderation/reports/{id} [get]
+func (h *ModerationHandler) GetReport(c *gin.Context) {
+	moderatorID, reportID, ok := h.parseModeratorAndReport(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.moderationUsecase.GetReport(c.Request.Context(), moderatorID, reportID)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, report, "Report retrieved successfully", http.StatusOK)
+}
+
+// DismissReport отклоняет жалобу
+// @Summary Отклонение жалобы
+// @Description Закрывает открытую жалобу без мер (статус dismissed)
+// @Tags moderation
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID жалобы" Format(uuid)
+// @Param request body ModerationDecisionRequest false "Заметка модератора"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /moderation/reports/{id}/dismiss [post]
+func (h *ModerationHandler) DismissReport(c *gin.Context) {
+	h.decide(c, h.moderationUsecase.DismissReport, "Report dismissed successfully")
+}
+
+// ReopenReport возвращает жалобу в очередь
+// @Summary Возврат жалобы в очередь
+// @Description Возвращает отклоненную жалобу в статус open
+// @Tags moderation
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID жалобы" Format(uuid)
+// @Param request body ModerationDecisionRequest false "Заметка модератора"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /moderation/reports/{id}/reopen [post]
+func (h *ModerationHandler) ReopenReport(c *gin.Context) {
+	h.decide(c, h.moderationUsecase.ReopenReport, "Report reopened successfully")
+}
+
+// TakeAction применяет меру по жалобе
+// @Summary Мера по жалобе
+// @Description Скрывает сообщение, предупреждает автора по email или блокирует его. Жалоба переходит в статус actioned; по ней можно применить несколько мер
+// @Tags moderation
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param id path string true "ID жалобы" Format(uuid)
+// @Param request body ModerationActionRequest true "Мера"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /moderation/reports/{id}/actions [post]
+func (h *ModerationHandler) TakeAction(c *gin.Context) {
+	moderatorID, reportID, ok := h.parseModeratorAndReport(c)
+	if !ok {
+		return
+	}
+
+	var req ModerationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid moderation action request body")
+		SendBindError(c, err)
+		return
+	}
+
+	report, err := h.moderationUsecase.TakeAction(c.Request.Context(), moderatorID, reportID, req.Action, req.Note)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, report, "Moderation action applied successfully", http.StatusOK)
+}
+
+// decide выполняет смену статуса жалобы с необязательной заметкой
+func (h *ModerationHandler) decide(
+	c *gin.Context,
+	action func(ctx context.Context, moderatorID, reportID uuid.UUID, note string) (*entity.Report, error),
+	successMessage string,
+) {
+	moderatorID, reportID, ok := h.parseModeratorAndReport(c)
+	if !ok {
+		return
+	}
+
+	var req ModerationDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithContext(c).WithError(err).Warn("invalid moderation decision request body")
+			SendBindError(c, err)
+			return
+		}
+	}
+
+	report, err := action(c.Request.Context(), moderatorID, reportID, req.Note)
+	if err != nil {
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, report, successMessage, http.StatusOK)
+}
+
+// parseModeratorAndReport извлекает текущего пользователя и ID жалобы из пути
+func (h *ModerationHandler) parseModeratorAndReport(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	moderatorID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid report ID format")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "id", "report ID must be a valid UUID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return moderatorID, reportID, true
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/metrics"
+	"chat-service/internal/usecase/mfa"
+	"chat-service/internal/usecase/oidc"
+	"chat-service/internal/usecase/session"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Cookie, которая привязывает вход через провайдера к браузеру, начавшему его
+const oidcBindingCookie = "oidc_login"
+
+// Коды ошибок авторизации OAuth 2.0 (RFC 6749) и OpenID Connect, которые провайдер может вернуть
+// в параметре error. Остальные значения не показываются клиенту и не пишутся в лог как есть
+var oidcProviderErrors = map[string]string{
+	"access_denied":              "access was denied at the identity provider",
+	"login_required":             "identity provider requires the user to log in",
+	"consent_required":           "identity provider requires the user's consent",
+	"interaction_required":       "identity provider requires user interaction",
+	"account_selection_required": "identity provider requires the user to select an account",
+	"invalid_request":            "identity provider rejected the authorization request",
+	"invalid_request_uri":        "identity provider rejected the authorization request",
+	"invalid_request_object":     "identity provider rejected the authorization request",
+	"request_not_supported":      "identity provider rejected the authorization request",
+	"request_uri_not_supported":  "identity provider rejected the authorization request",
+	"registration_not_supported": "identity provider rejected the authorization request",
+	"unauthorized_client":        "identity provider rejected the authorization request",
+	"unsupported_response_type":  "identity provider rejected the authorization request",
+	"invalid_scope":              "identity provider rejected the authorization request",
+	"server_error":               "identity provider is unavailable",
+	"temporarily_unavailable":    "identity provider is unavailable",
+}
+
+type OIDCHandler struct {
+	oidcUsecase    oidc.OIDCUsecase
+	sessionUsecase session.SessionUsecase
+	mfaUsecase     mfa.MFAUsecase
+	metrics        *metrics.Metrics
+	logger         *logrus.Logger
+}
+
+func NewOIDCHandler(
+	oidcUsecase oidc.OIDCUsecase,
+	sessionUsecase session.SessionUsecase,
+	mfaUsecase mfa.MFAUsecase,
+	appMetrics *metrics.Metrics,
+	logger *logrus.Logger,
+) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUsecase:    oidcUsecase,
+		sessionUsecase: sessionUsecase,
+		mfaUsecase:     mfaUsecase,
+		metrics:        appMetrics,
+		logger:         logger,
+	}
+}
+
+// OIDCProvidersResponse список настроенных провайдеров
+// swagger:model OIDCProvidersResponse
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// OIDCLoginResponse ответ успешного входа через внешнего провайдера
+// swagger:model OIDCLoginResponse
+type OIDCLoginResponse struct {
+	User    *entity.User    `json:"user"`
+	Session *entity.Session `json:"session"`
+	Created bool            `json:"created"`
+	Linked  bool            `json:"linked"`
+}
+
+// ListProviders возвращает провайдеров, через которых можно войти
+// @Summary Список OIDC провайдеров
+// @Description Возвращает имена настроенных провайдеров OpenID Connect
+// @Tags oidc
+// @Produce  json,application/problem+json
+// @Success 200 {object} OIDCProvidersResponse
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	SendSuccess(c, OIDCProvidersResponse{Providers: h.oidcUsecase.Providers()}, "Providers retrieved successfully", http.StatusOK)
+}
+
+// Login перенаправляет пользователя на страницу входа провайдера
+// @Summary Вход через OIDC провайдера
+// @Description Начинает authorization code flow с PKCE и перенаправляет на провайдера
+// @Tags oidc
+// @Param provider path string true "Имя провайдера"
+// @Success 302
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider := c.Param("provider")
+
+	start, err := h.oidcUsecase.BeginLogin(c.Request.Context(), provider)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).WithField("provider", provider).Warn("failed to start oidc login")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	// Lax: cookie отправляется при возврате от провайдера обычным переходом по ссылке
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, start.Binding, int(time.Until(start.ExpiresAt).Seconds()), oidcCookiePath(provider), "", isSecureRequest(c), true)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// Callback завершает вход после возврата от провайдера
+// @Summary Завершение входа через OIDC
+// @Description Проверяет state и cookie браузера, начавшего вход, обменивает код на токены, проверяет ID токен и создает сессию.
+// @Description Внешний аккаунт привязывается к пользователю с тем же подтвержденным email или создается новый пользователь.
+// @Description Если у пользователя включена 2FA, возвращает mfa_token для завершения входа через /login/mfa
+// @Tags oidc
+// @Produce  json,application/problem+json
+// @Param provider path string true "Имя провайдера"
+// @Param state query string true "State из запроса авторизации"
+// @Param code query string true "Код авторизации"
+// @Success 200 {object} OIDCLoginResponse
+// @Success 202 {object} MFAChallengeResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	// Cookie нужна только для одного возврата от провайдера
+	binding, _ := c.Cookie(oidcBindingCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, "", -1, oidcCookiePath(provider), "", isSecureRequest(c), true)
+
+	// Провайдер сообщает об отказе пользователя или ошибке через параметр error. Значение приходит
+	// из ссылки, поэтому клиенту и в лог попадает только известный код
+	if providerErr := c.Query("error"); providerErr != "" {
+		message, known := oidcProviderErrors[providerErr]
+		errorCode := providerErr
+		if !known {
+			message = "identity provider returned an error"
+			errorCode = "unknown"
+		}
+		h.logger.WithContext(c).WithFields(logrus.Fields{
+			"provider":       provider,
+			"provider_error": errorCode,
+		}).Warn("identity provider returned an error")
+		h.metrics.LoginAttempt(metrics.LoginMethodOIDC, metrics.LoginResultFailure)
+		SendError(c, apperror.Unauthorized(apperror.CodeOIDCAuthFailed, message))
+		return
+	}
+
+	result, err := h.oidcUsecase.CompleteLogin(c.Request.Context(), provider, c.Query("state"), c.Query("code"), binding)
+	if err != nil {
+		h.metrics.LoginAttempt(metrics.LoginMethodOIDC, metrics.LoginResultFailure)
+		h.logger.WithContext(c).WithError(err).WithField("provider", provider).Warn("oidc login failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+	user := result.User
+
+	// Внешний провайдер заменяет пароль, но не второй фактор
+	mfaEnabled, err := h.mfaUsecase.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to check mfa status")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	if mfaEnabled {
+		challenge, err := h.mfaUsecase.CreateChallenge(c.Request.Context(), user.ID)
+		if err != nil {
+			h.logger.WithContext(c).WithError(err).Error("failed to create mfa challenge")
+			HandleError(c, err, h.logger)
+			return
+		}
+
+		h.logger.WithContext(c).WithField("user_id", user.ID).Info("mfa challenge issued after oidc login")
+		SendSuccess(c, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresAt:   challenge.ExpiresAt,
+		}, "Two-factor authentication required", http.StatusAccepted)
+		return
+	}
+
+	session, err := h.sessionUsecase.CreateSession(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to create session after oidc login")
+		SendError(c, apperror.Internal("failed to create session"))
+		return
+	}
+
+	h.metrics.LoginAttempt(metrics.LoginMethodOIDC, metrics.LoginResultSuccess)
+	h.logger.WithContext(c).WithFields(logrus.Fields{
+		"user_id":  user.ID,
+		"provider": provider,
+	}).Info("user logged in via oidc")
+	SendSuccess(c, OIDCLoginResponse{
+		User:    user,
+		Session: session,
+		Created: result.Created,
+		Linked:  result.Linked,
+	}, "Login successful", http.StatusOK)
+}
+
+// oidcCookiePath ограничивает cookie входа адресами провайдера: она нужна только в callback
+func oidcCookiePath(provider string) string {
+	return "/api/v1/auth/oidc/" + provider
+}
+
+// isSecureRequest сообщает, пришел ли запрос по HTTPS напрямую или через прокси, завершающий TLS
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+	"chat-service/internal/usecase/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOIDCRouter маршруты входа через провайдера "corp"; обмен кода всегда отклоняется провайдером
+func newOIDCRouter(state *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := &mocks.OIDCClientMock{
+		AuthCodeURLFunc: func(ctx context.Context, s, nonce, codeVerifier string) (string, error) {
+			*state = s
+			return "https://idp.example.com/authorize?state=" + url.QueryEscape(s), nil
+		},
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return nil, service.ErrOIDCAuthentication
+		},
+	}
+	requests := make(map[string]*entity.OIDCAuthRequest)
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{
+		CreateFunc: func(ctx context.Context, request *entity.OIDCAuthRequest) error {
+			requests[request.StateHash] = request
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+			request, ok := requests[stateHash]
+			if !ok {
+				return nil, &testNotFoundError{"auth request not found"}
+			}
+			delete(requests, stateHash)
+			return request, nil
+		},
+	}
+	oidcUsecase := oidc.NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, requestRepo, &mocks.HashServiceMock{},
+		oidc.Config{StateTTL: 10 * time.Minute}, logger)
+	oidcHandler := NewOIDCHandler(oidcUsecase, nil, nil, nil, logger)
+
+	router := gin.New()
+	router.GET("/api/v1/auth/oidc/:provider/login", oidcHandler.Login)
+	router.GET("/api/v1/auth/oidc/:provider/callback", oidcHandler.Callback)
+	return router
+}
+
+func oidcRequest(router *gin.Engine, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func bindingCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcBindingCookie {
+			return cookie
+		}
+	}
+	require.FailNow(t, "oidc binding cookie is not set")
+	return nil
+}
+
+func TestOIDCLogin_SetsBindingCookie(t *testing.T) {
+	// Arrange
+	var state string
+	router := newOIDCRouter(&state)
+
+	// Act
+	rec := oidcRequest(router, "/api/v1/auth/oidc/corp/login")
+
+	// Assert
+	assert.Equal(t, http.StatusFound, rec.Code)
+	cookie := bindingCookie(t, rec)
+	assert.Equal(t, service.HashToken(state), cookie.Value)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, "/api/v1/auth/oidc/corp", cookie.Path)
+	assert.InDelta(t, (10 * time.Minute).Seconds(), cookie.MaxAge, 5)
+}
+
+func TestOIDCCallback_RequiresBrowserThatStartedLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		ownState bool
+		wantCode string
+	}{
+		// Провайдер отклоняет код, но до обмена доходит только браузер, начавший вход
+		{name: "браузер, начавший вход", ownState: true, wantCode: apperror.CodeOIDCAuthFailed},
+		{name: "ссылка возврата из чужого входа", ownState: false, wantCode: apperror.CodeOIDCLoginInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: атакующий и жертва начинают вход каждый в своем браузере
+			var state string
+			router := newOIDCRouter(&state)
+			oidcRequest(router, "/api/v1/auth/oidc/corp/login")
+			attackerState := state
+			victimLogin := oidcRequest(router, "/api/v1/auth/oidc/corp/login")
+			victimState := state
+
+			callbackState := attackerState
+			if tt.ownState {
+				callbackState = victimState
+			}
+
+			// Act
+			rec := oidcRequest(router, "/api/v1/auth/oidc/corp/callback?code=code&state="+url.QueryEscape(callbackState), bindingCookie(t, victimLogin))
+
+			// Assert
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, -1, bindingCookie(t, rec).MaxAge, "cookie удаляется после возврата")
+		})
+	}
+}
+
+func TestOIDCCallback_ProviderError(t *testing.T) {
+	tests := []struct {
+		name       string
+		error      string
+		wantDetail string
+	}{
+		{name: "известный код", error: "access_denied", wantDetail: "access was denied at the identity provider"},
+		{name: "произвольный текст", error: "<script>alert(1)</script>", wantDetail: "identity provider returned an error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var state string
+			router := newOIDCRouter(&state)
+
+			// Act
+			rec := oidcRequest(router, "/api/v1/auth/oidc/corp/callback?error="+url.QueryEscape(tt.error))
+
+			// Assert
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, apperror.CodeOIDCAuthFailed, problem.Code)
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+			assert.NotContains(t, rec.Body.String(), "script")
+		})
+	}
+}
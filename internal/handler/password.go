@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/usecase/password"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordHandler struct {
+	passwordUsecase password.PasswordUsecase
+	logger          *logrus.Logger
+}
+
+func NewPasswordHandler(
+	passwordUsecase password.PasswordUsecase,
+	logger *logrus.Logger,
+) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUsecase: passwordUsecase,
+		logger:          logger,
+	}
+}
+
+// ForgotPasswordRequest структура запроса на сброс пароля
+// swagger:model ForgotPasswordRequest
+type ForgotPasswordRequest struct {
+	// Email пользователя
+	// required: true
+	// format: email
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest структура для установки нового пароля
+// swagger:model ResetPasswordRequest
+type ResetPasswordRequest struct {
+	// Токен из письма
+	// required: true
+	Token string `json:"token" binding:"required"`
+
+	// Новый пароль (требования задаются политикой паролей)
+	// required: true
+	Password string `json:"password" binding:"required"`
+}
+
+// ForgotPassword отправляет письмо для сброса пароля
+// @Summary Запрос на сброс пароля
+// @Description Отправляет на email ссылку для сброса пароля. Ответ не зависит от того, зарегистрирован ли адрес
+// @Tags password
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Param request body ForgotPasswordRequest true "Email пользователя"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /password/forgot [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid forgot password request body")
+		SendBindError(c, err)
+		return
+	}
+
+	if err := h.passwordUsecase.RequestReset(c.Request.Context(), req.Email); err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to process password reset request")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	SendSuccess(c, nil, "If the email is registered, a password reset link has been sent", http.StatusOK)
+}
+
+// ResetPassword устанавливает новый пароль по токену из письма
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену и завершает все сессии пользователя
+// @Tags password
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Param request body ResetPasswordRequest true "Токен и новый пароль"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /password/reset [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid reset password request body")
+		SendBindError(c, err)
+		return
+	}
+
+	if err := h.passwordUsecase.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("password reset failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).Info("password reset completed")
+	SendSuccess(c, nil, "Password has been reset, please login with the new password", http.StatusOK)
+}
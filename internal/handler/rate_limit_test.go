@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+	"chat-service/internal/usecase/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRateLimitRouter маршрут POST /api/v1/login; limiter nil отключает лимиты
+func newRateLimitRouter(limiter ratelimit.RateLimitUsecase, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	middleware := &Middleware{rateLimiter: limiter, logger: logger}
+
+	router := gin.New()
+	router.Use(middleware.RateLimitMiddleware())
+	router.POST("/api/v1/login", func(c *gin.Context) {
+		*calls++
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// newTestRateLimiter лимитер со счетчиками в памяти
+func newTestRateLimiter(config ratelimit.Config) ratelimit.RateLimitUsecase {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	counters := make(map[string]int)
+	store := &mocks.RateLimitRepoMock{
+		IncrementFunc: func(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+			counterKey := key + "|" + windowStart.String()
+			counters[counterKey]++
+			return counters[counterKey], nil
+		},
+	}
+	return ratelimit.NewRateLimitUsecase(store, config, logger)
+}
+
+// loginRateLimitConfig 2 запроса в час: окно не успевает смениться за время теста
+func loginRateLimitConfig() ratelimit.Config {
+	return ratelimit.Config{
+		Policies: []ratelimit.Policy{
+			{Name: "auth", Routes: []string{"POST /api/v1/login"}, Key: ratelimit.KeyIP, Limit: 2, Window: time.Hour},
+		},
+	}
+}
+
+func loginRequest(router *gin.Engine) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/login", nil))
+	return rec
+}
+
+// assertSeconds проверяет, что заголовок содержит целое число секунд в пределах окна
+func assertSeconds(t *testing.T, header http.Header, name string) int {
+	seconds, err := strconv.Atoi(header.Get(name))
+	require.NoError(t, err, name)
+	assert.GreaterOrEqual(t, seconds, 1, name)
+	assert.LessOrEqual(t, seconds, 3600, name)
+	return seconds
+}
+
+func TestRateLimitMiddleware_Headers(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newRateLimitRouter(newTestRateLimiter(loginRateLimitConfig()), &calls)
+
+	// Act
+	first := loginRequest(router)
+	second := loginRequest(router)
+
+	// Assert
+	for i, rec := range []*httptest.ResponseRecorder{first, second} {
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(1-i), rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=3600", rec.Header().Get("RateLimit-Policy"))
+		assertSeconds(t, rec.Header(), "RateLimit-Reset")
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestRateLimitMiddleware_Exceeded(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newRateLimitRouter(newTestRateLimiter(loginRateLimitConfig()), &calls)
+	loginRequest(router)
+	loginRequest(router)
+
+	// Act
+	rec := loginRequest(router)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	reset := assertSeconds(t, rec.Header(), "RateLimit-Reset")
+	retryAfter := assertSeconds(t, rec.Header(), "Retry-After")
+	assert.InDelta(t, reset, retryAfter, 1)
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, apperror.CodeRateLimited, problem.Code)
+	assert.Equal(t, 2, calls, "обработчик не должен вызываться после превышения лимита")
+}
+
+// stubRateLimiter возвращает заданное состояние лимита
+type stubRateLimiter struct {
+	status *entity.RateLimitStatus
+	err    error
+}
+
+func (s *stubRateLimiter) Allow(ctx context.Context, route string, client ratelimit.Client) (*entity.RateLimitStatus, error) {
+	return s.status, s.err
+}
+
+func (s *stubRateLimiter) UpdateConfig(config ratelimit.Config) {}
+
+func TestRateLimitMiddleware_RoundsSecondsUp(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newRateLimitRouter(&stubRateLimiter{
+		status: &entity.RateLimitStatus{Policy: "auth", Limit: 10, Remaining: 0, Window: 5 * time.Minute, ResetAfter: 44200 * time.Millisecond},
+		err:    apperror.TooManyRequests(apperror.CodeRateLimited, "too many requests", 44200*time.Millisecond),
+	}, &calls)
+
+	// Act
+	rec := loginRequest(router)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "45", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "10;w=300", rec.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "45", rec.Header().Get("Retry-After"))
+	assert.Zero(t, calls)
+}
+
+func TestRateLimitMiddleware_NoHeadersWithoutPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter ratelimit.RateLimitUsecase
+	}{
+		{name: "лимиты отключены", limiter: nil},
+		{name: "маршрут без политики", limiter: newTestRateLimiter(ratelimit.Config{})},
+		{name: "адрес из allowlist", limiter: newTestRateLimiter(ratelimit.Config{
+			Policies:  loginRateLimitConfig().Policies,
+			Allowlist: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			calls := 0
+			router := newRateLimitRouter(tt.limiter, &calls)
+
+			// Act
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < 3; i++ {
+				rec = loginRequest(router)
+			}
+
+			// Assert
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, 3, calls)
+			for _, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"} {
+				assert.Empty(t, rec.Header().Get(name), name)
+			}
+		})
+	}
+}
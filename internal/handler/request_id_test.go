@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"chat-service/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRequestIDRouter маршрут, который всегда отвечает ошибкой, чтобы ID был виден и в заголовке, и в теле
+func newRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	middleware := &Middleware{}
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.GET("/api/v1/messages/:id", func(c *gin.Context) {
+		SendError(c, apperror.NotFound(apperror.CodeMessageNotFound, "message not found"))
+	})
+	return router
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{name: "missing", requestID: "", keep: false},
+		{name: "valid", requestID: "client-req_42.retry:1", keep: true},
+		{name: "max length", requestID: strings.Repeat("a", maxRequestIDLength), keep: true},
+		{name: "oversized", requestID: strings.Repeat("a", maxRequestIDLength+1), keep: false},
+		{name: "log injection", requestID: "abc\nlevel=error", keep: false},
+		{name: "spaces", requestID: "abc def", keep: false},
+	}
+
+	router := newRequestIDRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/messages/1", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			requestID := rec.Header().Get(RequestIDHeader)
+			if tt.keep {
+				assert.Equal(t, tt.requestID, requestID)
+			} else {
+				_, err := uuid.Parse(requestID)
+				assert.NoError(t, err, "вместо отсутствующего или некорректного ID создается новый UUID")
+			}
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, requestID, problem.RequestID)
+		})
+	}
+}
+
+func TestRequestIDMiddleware_UniquePerRequest(t *testing.T) {
+	// Arrange
+	router := newRequestIDRouter()
+
+	// Act
+	ids := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/messages/1", nil))
+		ids[rec.Header().Get(RequestIDHeader)] = true
+	}
+
+	// Assert
+	assert.Len(t, ids, 3)
+}
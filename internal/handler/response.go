@@ -1,12 +1,30 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 )
 
+// Тип содержимого ответов с ошибкой (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// Префикс URI типа ошибки; за ним следует код ошибки
+const problemTypePrefix = "urn:chat-service:problem:"
+
 type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message,omitempty"`
@@ -14,10 +32,27 @@ type Response struct {
 	Error   string      `json:"error,omitempty"`
 }
 
-type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Error   string `json:"error"`
+// Problem описание ошибки в формате RFC 7807 (application/problem+json)
+// swagger:model Problem
+type Problem struct {
+	// URI типа ошибки, однозначно соответствует code
+	Type string `json:"type" example:"urn:chat-service:problem:user.email_taken"`
+	// Краткое описание HTTP статуса
+	Title string `json:"title" example:"Conflict"`
+	// HTTP статус ответа
+	Status int `json:"status" example:"409"`
+	// Описание конкретной ошибки для человека
+	Detail string `json:"detail" example:"user with this email already exists"`
+	// Путь запроса, вызвавшего ошибку
+	Instance string `json:"instance" example:"/api/v1/register"`
+	// Машиночитаемый код ошибки; клиентам следует опираться на него, а не на detail
+	Code string `json:"code" example:"user.email_taken"`
+	// Ошибки отдельных полей запроса
+	Errors []apperror.FieldError `json:"errors,omitempty"`
+	// ID запроса из заголовка X-Request-ID; по нему ошибку можно найти в логах
+	RequestID string `json:"request_id,omitempty" example:"0f8e2d4c-5b7a-4e0a-9c3d-1a2b3c4d5e6f"`
+	// Всегда false; оставлено для совместимости с форматом успешных ответов
+	Success bool `json:"success" example:"false"`
 }
 
 type SuccessResponse struct {
@@ -34,11 +69,18 @@ func NewSuccessResponse(data interface{}, message string) *SuccessResponse {
 	}
 }
 
-func NewErrorResponse(message, errorStr string) *ErrorResponse {
-	return &ErrorResponse{
-		Success: false,
-		Message: message,
-		Error:   errorStr,
+// NewProblem описывает ошибку для ответа на запрос c
+func NewProblem(c *gin.Context, err *apperror.Error) *Problem {
+	status := statusOf(err.Kind)
+	return &Problem{
+		Type:      problemTypePrefix + err.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      err.Code,
+		Errors:    err.Fields,
+		RequestID: logger.RequestIDFromContext(c.Request.Context()),
 	}
 }
 
@@ -46,28 +88,151 @@ func SendSuccess(c *gin.Context, data interface{}, message string, statusCode in
 	c.JSON(statusCode, NewSuccessResponse(data, message))
 }
 
-func SendError(c *gin.Context, message, errorStr string, statusCode int) {
-	c.JSON(statusCode, NewErrorResponse(message, errorStr))
+// SendError отправляет ошибку в формате application/problem+json; статус выбирается по категории ошибки
+func SendError(c *gin.Context, err *apperror.Error) {
+	problem := NewProblem(c, err)
+	if err.Kind == apperror.KindTooManyRequests {
+		// Retry-After в целых секундах, округляем вверх
+		retryAfter := int(math.Ceil(err.RetryAfter().Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+	}
+	// Gin не перезаписывает уже выставленный Content-Type
+	c.Header("Content-Type", problemContentType)
+	c.JSON(problem.Status, problem)
 }
 
-// Обработчик ошибок по типам
-func HandleError(c *gin.Context, err error, logger *logrus.Logger) {
-	logger.WithError(err).Error("handler error occurred")
+// SendBindError отвечает на тело запроса, которое не удалось разобрать или которое не прошло проверки binding
+func SendBindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		SendError(c, apperror.Validation(apperror.CodeInvalidRequest, "request body is malformed: "+err.Error()))
+		return
+	}
 
-	// Проверяем тип ошибки через type assertion
-	switch e := err.(type) {
-	case ValidationError:
-		SendError(c, "Validation failed", e.Error(), http.StatusBadRequest)
-	case NotFoundError:
-		SendError(c, "Resource not found", e.Error(), http.StatusNotFound)
-	case UnauthorizedError:
-		SendError(c, "Unauthorized", e.Error(), http.StatusUnauthorized)
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldErr.Field(),
+			Message: bindingMessage(fieldErr),
+		})
+	}
+	SendError(c, apperror.InvalidFields(apperror.CodeValidationFailed, fields))
+}
+
+// Обработчик ошибок: находит в цепочке ошибку с кодом и отправляет ее клиенту
+func HandleError(c *gin.Context, err error, log *logrus.Logger) {
+	appErr := toAppError(err)
+
+	entry := log.WithContext(c).WithError(err).WithField("code", appErr.Code)
+	if statusOf(appErr.Kind) >= http.StatusInternalServerError {
+		entry.Error("handler error occurred")
+	} else {
+		entry.Warn("handler error occurred")
+	}
+
+	SendError(c, appErr)
+}
+
+// toAppError приводит ошибку к *apperror.Error. Ошибки сущностей и репозиториев, у которых нет
+// собственного кода, получают общий код своей категории; неизвестные ошибки скрываются за internal.error
+func toAppError(err error) *apperror.Error {
+	if appErr, ok := apperror.As(err); ok {
+		return appErr
+	}
+
+	var fieldErr *entity.FieldValidationError
+	if errors.As(err, &fieldErr) {
+		fields := make([]apperror.FieldError, 0, len(fieldErr.Errors))
+		for _, e := range fieldErr.Errors {
+			fields = append(fields, apperror.FieldError{Field: e.Field, Message: e.Message})
+		}
+		return apperror.InvalidFields(apperror.CodeValidationFailed, fields)
+	}
+
+	var validationErr ValidationError
+	if errors.As(err, &validationErr) && validationErr.ValidationError() {
+		return apperror.Validation(apperror.CodeValidationFailed, validationErr.Error())
+	}
+	var notFoundErr NotFoundError
+	if errors.As(err, &notFoundErr) && notFoundErr.NotFound() {
+		return apperror.NotFound(apperror.CodeNotFound, notFoundErr.Error())
+	}
+	var unauthorizedErr UnauthorizedError
+	if errors.As(err, &unauthorizedErr) && unauthorizedErr.Unauthorized() {
+		return apperror.Unauthorized(apperror.CodeUnauthorized, unauthorizedErr.Error())
+	}
+	var forbiddenErr ForbiddenError
+	if errors.As(err, &forbiddenErr) && forbiddenErr.Forbidden() {
+		return apperror.Forbidden(apperror.CodeForbidden, forbiddenErr.Error())
+	}
+	var tooManyErr TooManyRequestsError
+	if errors.As(err, &tooManyErr) {
+		return apperror.TooManyRequests(apperror.CodeRateLimited, tooManyErr.Error(), tooManyErr.RetryAfter())
+	}
+
+	return apperror.Internal("something went wrong")
+}
+
+func statusOf(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case apperror.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindingMessage текст ошибки для правил binding, используемых в запросах
+func bindingMessage(fieldErr validator.FieldError) string {
+	unit := "characters"
+	if kind := fieldErr.Kind(); kind == reflect.Slice || kind == reflect.Map {
+		unit = "items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldErr.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fieldErr.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s %s", fieldErr.Field(), fieldErr.Param(), unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s %s", fieldErr.Field(), fieldErr.Param(), unit)
 	default:
-		SendError(c, "Internal server error", "Something went wrong", http.StatusInternalServerError)
+		return fmt.Sprintf("%s is invalid", fieldErr.Field())
 	}
 }
 
-// Интерфейсы для типизации ошибок
+// jsonFieldName имя поля для ошибок binding: как в JSON, а для параметров запроса - как в form
+func jsonFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Интерфейсы для типизации ошибок без кода: ошибки сущностей и репозиториев
 type ValidationError interface {
 	ValidationError() bool
 	Error() string
@@ -82,3 +247,13 @@ type UnauthorizedError interface {
 	Unauthorized() bool
 	Error() string
 }
+
+type ForbiddenError interface {
+	Forbidden() bool
+	Error() string
+}
+
+type TooManyRequestsError interface {
+	RetryAfter() time.Duration
+	Error() string
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ошибки репозитория без кода, как в пакете адаптера
+type testNotFoundError struct{ message string }
+
+func (e *testNotFoundError) Error() string  { return e.message }
+func (e *testNotFoundError) NotFound() bool { return true }
+
+type testUnauthorizedError struct{ message string }
+
+func (e *testUnauthorizedError) Error() string      { return e.message }
+func (e *testUnauthorizedError) Unauthorized() bool { return true }
+
+type testForbiddenError struct{ message string }
+
+func (e *testForbiddenError) Error() string   { return e.message }
+func (e *testForbiddenError) Forbidden() bool { return true }
+
+type testTooManyRequestsError struct{ wait time.Duration }
+
+func (e *testTooManyRequestsError) Error() string             { return "slow down" }
+func (e *testTooManyRequestsError) RetryAfter() time.Duration { return e.wait }
+
+// serveError отвечает на запрос через HandleError и возвращает ответ с разобранным Problem
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, Problem) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := gin.New()
+	router.GET("/api/v1/test", func(c *gin.Context) {
+		HandleError(c, err, logger)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/test", nil))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	return rec, problem
+}
+
+func TestHandleError_Mapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "app error",
+			err:        apperror.Conflict(apperror.CodeUserEmailTaken, "user with this email already exists"),
+			wantStatus: http.StatusConflict,
+			wantCode:   apperror.CodeUserEmailTaken,
+			wantDetail: "user with this email already exists",
+		},
+		{
+			name:       "wrapped app error",
+			err:        fmt.Errorf("create user: %w", apperror.Unprocessable(apperror.CodeIdempotencyKeyReused, "key reused")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   apperror.CodeIdempotencyKeyReused,
+			wantDetail: "key reused",
+		},
+		{
+			name:       "entity validation",
+			err:        &entity.ValidationError{Message: "content is required"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperror.CodeValidationFailed,
+			wantDetail: "content is required",
+		},
+		{
+			name:       "not found",
+			err:        fmt.Errorf("get message: %w", &testNotFoundError{"message not found"}),
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperror.CodeNotFound,
+			wantDetail: "message not found",
+		},
+		{
+			name:       "unauthorized",
+			err:        &testUnauthorizedError{"token expired"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperror.CodeUnauthorized,
+			wantDetail: "token expired",
+		},
+		{
+			name:       "forbidden",
+			err:        &testForbiddenError{"not allowed"},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperror.CodeForbidden,
+			wantDetail: "not allowed",
+		},
+		{
+			name:       "unknown error hides details",
+			err:        errors.New("pq: connection refused to 10.0.0.5:5432"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   apperror.CodeInternal,
+			wantDetail: "something went wrong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec, problem := serveError(t, tt.err)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.Equal(t, http.StatusText(tt.wantStatus), problem.Title)
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, "urn:chat-service:problem:"+tt.wantCode, problem.Type)
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+			assert.Equal(t, "/api/v1/test", problem.Instance)
+			assert.False(t, problem.Success)
+			assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+		})
+	}
+}
+
+func TestHandleError_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "rounded up", err: apperror.TooManyRequests(apperror.CodeRateLimited, "too many requests", 1500*time.Millisecond), want: "2"},
+		{name: "at least one second", err: apperror.TooManyRequests(apperror.CodeLoginLocked, "locked", 0), want: "1"},
+		{name: "legacy error", err: &testTooManyRequestsError{wait: 30 * time.Second}, want: "30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec, _ := serveError(t, tt.err)
+
+			// Assert
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Equal(t, tt.want, rec.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestHandleError_FieldValidation(t *testing.T) {
+	// Arrange
+	err := &entity.FieldValidationError{Errors: []entity.FieldError{
+		{Field: "username", Message: "username is too short"},
+		{Field: "email", Message: "email is invalid"},
+	}}
+
+	// Act
+	rec, problem := serveError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, apperror.CodeValidationFailed, problem.Code)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "username", Message: "username is too short"},
+		{Field: "email", Message: "email is invalid"},
+	}, problem.Errors)
+}
+
+type testBindRequest struct {
+	Email string   `json:"email" binding:"required,email"`
+	Name  string   `json:"display_name" binding:"min=3,max=5"`
+	Tags  []string `json:"tags" binding:"max=1"`
+}
+
+func TestSendBindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+	router := gin.New()
+	router.POST("/api/v1/test", func(c *gin.Context) {
+		var req testBindRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			SendBindError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		// Act
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/test",
+			strings.NewReader(`{"email":"not-an-email","display_name":"ab","tags":["a","b"]}`)))
+
+		// Assert
+		var problem Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, apperror.CodeValidationFailed, problem.Code)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "email", Message: "email must be a valid email address"},
+			{Field: "display_name", Message: "display_name must be at least 3 characters"},
+			{Field: "tags", Message: "tags must be at most 1 items"},
+		}, problem.Errors)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		// Act
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/test", strings.NewReader(`{"email":`)))
+
+		// Assert
+		var problem Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apperror.CodeInvalidRequest, problem.Code)
+		assert.Empty(t, problem.Errors)
+	})
+}
@@ -3,30 +3,48 @@ package handler
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
+	"chat-service/internal/metrics"
+	"chat-service/internal/usecase/loginguard"
+	"chat-service/internal/usecase/mfa"
 	"chat-service/internal/usecase/session"
 	"chat-service/internal/usecase/user"
+	"chat-service/internal/usecase/verification"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 type UserHandler struct {
-	userUsecase    user.UserUsecase
-	sessionUsecase session.SessionUsecase
-	logger         *logrus.Logger
+	userUsecase         user.UserUsecase
+	sessionUsecase      session.SessionUsecase
+	mfaUsecase          mfa.MFAUsecase
+	verificationUsecase verification.VerificationUsecase
+	loginGuard          loginguard.LoginGuardUsecase
+	metrics             *metrics.Metrics
+	logger              *logrus.Logger
 }
 
 func NewUserHandler(
 	userUsecase user.UserUsecase,
 	sessionUsecase session.SessionUsecase,
+	mfaUsecase mfa.MFAUsecase,
+	verificationUsecase verification.VerificationUsecase,
+	loginGuard loginguard.LoginGuardUsecase,
+	appMetrics *metrics.Metrics,
 	logger *logrus.Logger,
 ) *UserHandler {
 	return &UserHandler{
-		userUsecase:    userUsecase,
-		sessionUsecase: sessionUsecase,
-		logger:         logger,
+		userUsecase:         userUsecase,
+		sessionUsecase:      sessionUsecase,
+		mfaUsecase:          mfaUsecase,
+		verificationUsecase: verificationUsecase,
+		loginGuard:          loginGuard,
+		metrics:             appMetrics,
+		logger:              logger,
 	}
 }
 
@@ -43,10 +61,9 @@ type RegisterRequest struct {
 	// format: email
 	Email string `json:"email" binding:"required,email"`
 
-	// Пароль пользователя
+	// Пароль пользователя (требования задаются политикой паролей)
 	// required: true
-	// min length: 6
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 }
 
 // LoginRequest структура для логина
@@ -62,6 +79,26 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ChangePasswordRequest структура для смены пароля
+// swagger:model ChangePasswordRequest
+type ChangePasswordRequest struct {
+	// Текущий пароль
+	// required: true
+	CurrentPassword string `json:"current_password" binding:"required"`
+
+	// Новый пароль (требования задаются политикой паролей)
+	// required: true
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// MFAChallengeResponse ответ логина, когда требуется второй фактор
+// swagger:model MFAChallengeResponse
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
 // UserResponse структура ответа с пользователем
 // swagger:model UserResponse
 type UserResponse struct {
@@ -72,38 +109,45 @@ type UserResponse struct {
 
 // Register регистрирует нового пользователя
 // @Summary Регистрация нового пользователя
-// @Description Создает нового пользователя в системе
+// @Description Создает нового пользователя в системе и отправляет письмо для подтверждения email
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Param user body RegisterRequest true "Данные для регистрации"
 // @Success 201 {object} UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Warn("invalid register request body")
-		SendError(c, "Invalid request", err.Error(), http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid register request body")
+		SendBindError(c, err)
 		return
 	}
 
-	h.logger.WithField("email", req.Email).Info("user registration attempt")
+	h.logger.WithContext(c).WithField("email", req.Email).Info("user registration attempt")
 
 	user, err := h.userUsecase.Register(c.Request.Context(), req.Username, req.Email, req.Password)
 	if err != nil {
-		h.logger.WithError(err).Warn("user registration failed")
+		h.logger.WithContext(c).WithError(err).Warn("user registration failed")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	// Создаем сессию для нового пользователя
+	// Ошибка отправки письма не мешает регистрации: письмо можно запросить повторно
+	if err := h.verificationUsecase.SendVerification(c.Request.Context(), user.ID); err != nil {
+		h.logger.WithContext(c).WithError(err).WithField("user_id", user.ID).Warn("failed to send verification email after registration")
+	}
+
+	// Создаем сессию для нового пользователя. Без сессии регистрация все равно успешна:
+	// пользователь войдет через /login
+	message := "User registered successfully"
 	session, err := h.sessionUsecase.CreateSession(c.Request.Context(), user.ID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to create session after registration")
-		SendError(c, "Registration successful but login failed", "Please login manually", http.StatusOK)
-		return
+		h.logger.WithContext(c).WithError(err).Error("failed to create session after registration")
+		message = "User registered successfully, please login manually"
 	}
 
 	response := struct {
@@ -114,43 +158,88 @@ func (h *UserHandler) Register(c *gin.Context) {
 		Session: session,
 	}
 
-	h.logger.WithField("user_id", user.ID).Info("user registered successfully")
-	SendSuccess(c, response, "User registered successfully", http.StatusCreated)
+	h.logger.WithContext(c).WithField("user_id", user.ID).Info("user registered successfully")
+	SendSuccess(c, response, message, http.StatusCreated)
 }
 
 // Login аутентифицирует пользователя
 // @Summary Вход в систему
-// @Description Аутентифицирует пользователя и возвращает токен
+// @Description Аутентифицирует пользователя и возвращает токен.
+// @Description Если у пользователя включена 2FA, возвращает mfa_token для завершения входа через /login/mfa.
+// @Description После серии неудачных попыток аккаунт и IP-адрес временно блокируются (429 с Retry-After)
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Param credentials body LoginRequest true "Учетные данные"
 // @Success 200 {object} UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 202 {object} MFAChallengeResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 429 {object} Problem
 // @Router /login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Warn("invalid login request body")
-		SendError(c, "Invalid request", err.Error(), http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid login request body")
+		SendBindError(c, err)
 		return
 	}
 
-	h.logger.WithField("email", req.Email).Info("user login attempt")
+	h.logger.WithContext(c).WithField("email", req.Email).Info("user login attempt")
+
+	clientIP := c.ClientIP()
+	if err := h.loginGuard.Check(c.Request.Context(), req.Email, clientIP); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("login blocked by brute-force protection")
+		if apperror.IsKind(err, apperror.KindTooManyRequests) {
+			h.metrics.LoginAttempt(metrics.LoginMethodPassword, metrics.LoginResultLocked)
+		}
+		HandleError(c, err, h.logger)
+		return
+	}
 
 	user, err := h.userUsecase.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		h.logger.WithError(err).Warn("user login failed")
+		h.loginGuard.RecordFailure(c.Request.Context(), req.Email, clientIP)
+		h.metrics.LoginAttempt(metrics.LoginMethodPassword, metrics.LoginResultFailure)
+		h.logger.WithContext(c).WithError(err).Warn("user login failed")
 		HandleError(c, err, h.logger)
 		return
 	}
 
+	// Пароль верный, но при включенной 2FA сессию выдаем только после второго шага.
+	// Счетчик неудач сбрасывается тоже только после него, иначе вход с паролем
+	// позволял бы перебирать коды 2FA без блокировки
+	mfaEnabled, err := h.mfaUsecase.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Error("failed to check mfa status")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	if mfaEnabled {
+		challenge, err := h.mfaUsecase.CreateChallenge(c.Request.Context(), user.ID)
+		if err != nil {
+			h.logger.WithContext(c).WithError(err).Error("failed to create mfa challenge")
+			HandleError(c, err, h.logger)
+			return
+		}
+
+		h.logger.WithContext(c).WithField("user_id", user.ID).Info("mfa challenge issued")
+		SendSuccess(c, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresAt:   challenge.ExpiresAt,
+		}, "Two-factor authentication required", http.StatusAccepted)
+		return
+	}
+
+	h.loginGuard.RecordSuccess(c.Request.Context(), req.Email, clientIP, user.ID)
+
 	// Создаем сессию
 	session, err := h.sessionUsecase.CreateSession(c.Request.Context(), user.ID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to create session after login")
-		SendError(c, "Login failed", "Failed to create session", http.StatusInternalServerError)
+		h.logger.WithContext(c).WithError(err).Error("failed to create session after login")
+		SendError(c, apperror.Internal("failed to create session"))
 		return
 	}
 
@@ -162,7 +251,8 @@ func (h *UserHandler) Login(c *gin.Context) {
 		Session: session,
 	}
 
-	h.logger.WithField("user_id", user.ID).Info("user logged in successfully")
+	h.metrics.LoginAttempt(metrics.LoginMethodPassword, metrics.LoginResultSuccess)
+	h.logger.WithContext(c).WithField("user_id", user.ID).Info("user logged in successfully")
 	SendSuccess(c, response, "Login successful", http.StatusOK)
 }
 
@@ -171,30 +261,30 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Description Возвращает профиль авторизованного пользователя
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Success 200 {object} UserResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Debug("fetching user profile")
+	h.logger.WithContext(c).WithField("user_id", userID).Debug("fetching user profile")
 
 	user, err := h.userUsecase.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch user profile")
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch user profile")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Debug("user profile fetched successfully")
+	h.logger.WithContext(c).WithField("user_id", userID).Debug("user profile fetched successfully")
 	SendSuccess(c, user, "Profile retrieved successfully", http.StatusOK)
 }
 
@@ -203,15 +293,15 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Description Завершает сессию пользователя
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Success 200 {object} SuccessResponse
-// @Failure 401 {object} ErrorResponse
+// @Failure 401 {object} Problem
 // @Router /logout [post]
 func (h *UserHandler) Logout(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
@@ -224,31 +314,32 @@ func (h *UserHandler) Logout(c *gin.Context) {
 		// Удаляем сессию
 		err = h.sessionUsecase.DeleteSession(c.Request.Context(), tokenString)
 		if err != nil {
-			h.logger.WithError(err).Warn("failed to delete session")
+			h.logger.WithContext(c).WithError(err).Warn("failed to delete session")
 		}
 	}
 
-	h.logger.WithField("user_id", userID).Info("user logged out successfully")
+	h.logger.WithContext(c).WithField("user_id", userID).Info("user logged out successfully")
 	SendSuccess(c, nil, "Logged out successfully", http.StatusOK)
 }
 
 // UpdateProfile обновляет профиль пользователя
 // @Summary Обновление профиля пользователя
-// @Description Обновляет данные профиля авторизованного пользователя
+// @Description Обновляет данные профиля авторизованного пользователя.
+// @Description При смене email подтверждение сбрасывается и на новый адрес отправляется письмо
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Param user body object{username=string,email=string} false "Данные для обновления"
 // @Success 200 {object} UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /profile [put]
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
@@ -259,20 +350,21 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Warn("invalid update profile request body")
-		SendError(c, "Invalid request", err.Error(), http.StatusBadRequest)
+		h.logger.WithContext(c).WithError(err).Warn("invalid update profile request body")
+		SendBindError(c, err)
 		return
 	}
 
 	// Получаем текущего пользователя
 	user, err := h.userUsecase.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to fetch user for update")
+		h.logger.WithContext(c).WithError(err).Error("failed to fetch user for update")
 		HandleError(c, err, h.logger)
 		return
 	}
 
 	// Обновляем только переданные поля
+	emailChanged := req.Email != "" && req.Email != user.Email
 	if req.Username != "" {
 		user.Username = req.Username
 	}
@@ -282,43 +374,90 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	err = h.userUsecase.UpdateProfile(c.Request.Context(), user)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to update user profile")
+		h.logger.WithContext(c).WithError(err).Error("failed to update user profile")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Info("user profile updated successfully")
+	if emailChanged {
+		// Репозиторий сбросил подтверждение, отражаем это в ответе и отправляем письмо на новый адрес
+		user.EmailVerifiedAt = nil
+		if err := h.verificationUsecase.SendVerification(c.Request.Context(), userID); err != nil {
+			h.logger.WithContext(c).WithError(err).WithField("user_id", userID).Warn("failed to send verification email after email change")
+		}
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("user profile updated successfully")
 	SendSuccess(c, user, "Profile updated successfully", http.StatusOK)
 }
 
+// ChangePassword меняет пароль текущего пользователя
+// @Summary Смена пароля
+// @Description Меняет пароль после проверки текущего. Все сессии, кроме текущей, завершаются
+// @Tags users
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Param passwords body ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /profile/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	session, err := GetSessionFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get session from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("invalid change password request body")
+		SendBindError(c, err)
+		return
+	}
+
+	err = h.userUsecase.ChangePassword(c.Request.Context(), session.UserID, session.ID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("password change failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", session.UserID).Info("password changed successfully")
+	SendSuccess(c, nil, "Password changed successfully", http.StatusOK)
+}
+
 // DeleteUser удаляет аккаунт пользователя
 // @Summary Удаление аккаунта пользователя
 // @Description Удаляет аккаунт авторизованного пользователя
 // @Tags users
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/problem+json
 // @Security Bearer
 // @Success 200 {object} SuccessResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /profile [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID, err := GetUserFromContext(c)
 	if err != nil {
-		h.logger.WithError(err).Warn("failed to get user from context")
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Warn("user account deletion requested")
+	h.logger.WithContext(c).WithField("user_id", userID).Warn("user account deletion requested")
 
 	err = h.userUsecase.DeleteUser(c.Request.Context(), userID)
 	if err != nil {
-		h.logger.WithError(err).Error("failed to delete user account")
+		h.logger.WithContext(c).WithError(err).Error("failed to delete user account")
 		HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.WithField("user_id", userID).Info("user account deleted successfully")
+	h.logger.WithContext(c).WithField("user_id", userID).Info("user account deleted successfully")
 	SendSuccess(c, nil, "Account deleted successfully", http.StatusOK)
 }
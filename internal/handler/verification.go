@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/usecase/verification"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type VerificationHandler struct {
+	verificationUsecase verification.VerificationUsecase
+	logger              *logrus.Logger
+}
+
+func NewVerificationHandler(
+	verificationUsecase verification.VerificationUsecase,
+	logger *logrus.Logger,
+) *VerificationHandler {
+	return &VerificationHandler{
+		verificationUsecase: verificationUsecase,
+		logger:              logger,
+	}
+}
+
+// VerifyEmail подтверждает email по токену из письма
+// @Summary Подтверждение email
+// @Description Подтверждает email пользователя по одноразовому токену из письма
+// @Tags verification
+// @Produce  json,application/problem+json
+// @Param token query string true "Токен из письма"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /verify-email [get]
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		h.logger.WithContext(c).Warn("email verification request without token")
+		SendError(c, apperror.InvalidField(apperror.CodeInvalidRequest, "token", "token is required"))
+		return
+	}
+
+	if err := h.verificationUsecase.VerifyEmail(c.Request.Context(), token); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("email verification failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).Info("email verified via link")
+	SendSuccess(c, nil, "Email verified successfully", http.StatusOK)
+}
+
+// ResendVerification повторно отправляет письмо для подтверждения email
+// @Summary Повторная отправка письма подтверждения
+// @Description Отправляет новое письмо для подтверждения email. Частота отправки ограничена
+// @Tags verification
+// @Accept  json
+// @Produce  json,application/problem+json
+// @Security Bearer
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /verify-email/resend [post]
+func (h *VerificationHandler) ResendVerification(c *gin.Context) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("failed to get user from context")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	if err := h.verificationUsecase.ResendVerification(c.Request.Context(), userID); err != nil {
+		h.logger.WithContext(c).WithError(err).Warn("verification email resend failed")
+		HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.WithContext(c).WithField("user_id", userID).Info("verification email resent")
+	SendSuccess(c, nil, "Verification email sent", http.StatusOK)
+}
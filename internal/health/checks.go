@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Pinger проверяет соединение с базой данных
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// MigrationSource возвращает версию схемы, примененную к базе данных
+type MigrationSource interface {
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// PoolStats возвращает число занятых соединений и размер пула
+type PoolStats interface {
+	PoolUsage() (acquired, max int32)
+}
+
+type databaseCheck struct {
+	db Pinger
+}
+
+// NewDatabaseCheck проверяет, что база данных отвечает на ping
+func NewDatabaseCheck(db Pinger) Checker {
+	return &databaseCheck{db: db}
+}
+
+func (c *databaseCheck) Name() string {
+	return "database"
+}
+
+func (c *databaseCheck) Check(ctx context.Context) error {
+	return c.db.Ping(ctx)
+}
+
+type migrationCheck struct {
+	source   MigrationSource
+	expected uint
+}
+
+// NewMigrationCheck проверяет, что к базе применены миграции до версии expected и ни одна не оборвалась
+func NewMigrationCheck(source MigrationSource, expected uint) Checker {
+	return &migrationCheck{source: source, expected: expected}
+}
+
+func (c *migrationCheck) Name() string {
+	return "migrations"
+}
+
+func (c *migrationCheck) Check(ctx context.Context) error {
+	version, dirty, err := c.source.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version != c.expected {
+		return fmt.Errorf("schema version %d, expected %d", version, c.expected)
+	}
+	return nil
+}
+
+type poolCheck struct {
+	pool           PoolStats
+	maxUtilization float64
+}
+
+// NewPoolCheck проваливается, когда занято не меньше maxUtilization соединений пула (доля от 0 до 1)
+func NewPoolCheck(pool PoolStats, maxUtilization float64) Checker {
+	return &poolCheck{pool: pool, maxUtilization: maxUtilization}
+}
+
+func (c *poolCheck) Name() string {
+	return "db_pool"
+}
+
+func (c *poolCheck) Check(ctx context.Context) error {
+	acquired, max := c.pool.PoolUsage()
+	if max <= 0 {
+		return nil
+	}
+	if float64(acquired)/float64(max) >= c.maxUtilization {
+		return fmt.Errorf("pool saturated: %d of %d connections in use", acquired, max)
+	}
+	return nil
+}
+
+// LatestMigrationVersion возвращает наибольшую версию среди файлов миграций в dir
+// (формат golang-migrate: <версия>_<название>.up.sql)
+func LatestMigrationVersion(dir string) (uint, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no migrations found in %s: %w", dir, os.ErrNotExist)
+	}
+
+	var latest uint
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s: %w", filepath.Base(file), err)
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
@@ -0,0 +1,164 @@
+// Package health отвечает на пробы живости (liveness) и готовности (readiness) сервиса.
+// Проверки подключаются через интерфейс Checker, а результаты готовности кешируются,
+// чтобы частые пробы балансировщика не нагружали базу данных
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Статусы проверки и отчета
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+)
+
+// Checker одна проверка состояния зависимости. Check должен уважать ctx: по истечении
+// таймаута проверка считается проваленной
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type Config struct {
+	CheckTimeout time.Duration // время на одну проверку
+	CacheTTL     time.Duration // сколько переиспользовать результат проверок готовности
+}
+
+// CheckResult результат одной проверки
+type CheckResult struct {
+	Name       string    `json:"name" example:"database"`
+	Status     string    `json:"status" example:"pass"`
+	Error      string    `json:"error,omitempty" example:"context deadline exceeded"`
+	DurationMS float64   `json:"duration_ms" example:"1.7"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report итог пробы; Status равен fail, если провалилась хотя бы одна проверка или сервис завершает работу
+type Report struct {
+	Status   string        `json:"status" example:"pass"`
+	Draining bool          `json:"draining,omitempty"`
+	Checks   []CheckResult `json:"checks"`
+}
+
+// Healthy сообщает, прошла ли проба
+func (r *Report) Healthy() bool {
+	return r.Status == StatusPass
+}
+
+type Health struct {
+	liveness  []Checker
+	readiness []Checker
+	config    Config
+	logger    *logrus.Logger
+	now       func() time.Time
+
+	draining atomic.Bool
+
+	// mu удерживается на время проверок, чтобы одновременные пробы дождались
+	// одного результата, а не запускали проверки параллельно
+	mu       sync.Mutex
+	cached   *Report
+	cachedAt time.Time
+}
+
+func NewHealth(liveness, readiness []Checker, config Config, logger *logrus.Logger) *Health {
+	return &Health{
+		liveness:  liveness,
+		readiness: readiness,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Live проверяет, что процесс работает. Результат не кешируется: проверки живости не должны
+// обращаться к внешним зависимостям, иначе сбой базы приведет к перезапуску всех экземпляров
+func (h *Health) Live(ctx context.Context) *Report {
+	return h.run(ctx, h.liveness)
+}
+
+// Ready проверяет, может ли сервис принимать трафик. После StartDraining всегда возвращает fail
+func (h *Health) Ready(ctx context.Context) *Report {
+	if h.draining.Load() {
+		return &Report{Status: StatusFail, Draining: true, Checks: []CheckResult{}}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && h.now().Sub(h.cachedAt) < h.config.CacheTTL {
+		return h.cached
+	}
+
+	// Результат разделяют все пробы, поэтому отмена запроса одной из них не должна его испортить
+	report := h.run(context.WithoutCancel(ctx), h.readiness)
+	if !report.Healthy() {
+		h.logger.WithContext(ctx).WithField("checks", report.Checks).Warn("readiness check failed")
+	}
+
+	h.cached = report
+	h.cachedAt = h.now()
+	return report
+}
+
+// StartDraining переводит готовность в fail, чтобы балансировщик перестал направлять
+// новые запросы до остановки HTTP сервера
+func (h *Health) StartDraining() {
+	if h.draining.CompareAndSwap(false, true) {
+		h.logger.Info("readiness switched to failing, draining connections")
+	}
+}
+
+// run выполняет проверки параллельно, каждую со своим таймаутом
+func (h *Health) run(ctx context.Context, checkers []Checker) *Report {
+	results := make([]CheckResult, len(checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = h.check(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusPass, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusPass {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
+
+func (h *Health) check(ctx context.Context, checker Checker) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.CheckTimeout)
+	defer cancel()
+
+	start := h.now()
+	err := checker.Check(checkCtx)
+	// Проверка, не уважающая ctx, могла успешно завершиться уже после таймаута; это тоже провал
+	if err == nil && checkCtx.Err() != nil {
+		err = checkCtx.Err()
+	}
+
+	result := CheckResult{
+		Name:       checker.Name(),
+		Status:     StatusPass,
+		DurationMS: float64(h.now().Sub(start).Microseconds()) / 1000,
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
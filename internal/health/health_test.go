@@ -0,0 +1,210 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCheck проверка с заданным результатом, считающая свои вызовы
+type stubCheck struct {
+	name  string
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (s *stubCheck) Name() string {
+	return s.name
+}
+
+func (s *stubCheck) Check(ctx context.Context) error {
+	s.calls.Add(1)
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return s.err
+}
+
+func newTestHealth(readiness ...Checker) *Health {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	return NewHealth(nil, readiness, Config{
+		CheckTimeout: 50 * time.Millisecond,
+		CacheTTL:     time.Minute,
+	}, logger)
+}
+
+func TestHealth_Ready_AllPass(t *testing.T) {
+	// Arrange
+	h := newTestHealth(&stubCheck{name: "database"}, &stubCheck{name: "db_pool"})
+
+	// Act
+	report := h.Ready(context.Background())
+
+	// Assert
+	assert.True(t, report.Healthy())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, StatusPass, report.Checks[0].Status)
+	assert.Equal(t, "db_pool", report.Checks[1].Name)
+}
+
+func TestHealth_Ready_FailingCheck(t *testing.T) {
+	// Arrange
+	h := newTestHealth(
+		&stubCheck{name: "database", err: errors.New("connection refused")},
+		&stubCheck{name: "db_pool"},
+	)
+
+	// Act
+	report := h.Ready(context.Background())
+
+	// Assert: провал одной проверки проваливает пробу, остальные результаты сохраняются
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+	assert.Equal(t, StatusPass, report.Checks[1].Status)
+}
+
+func TestHealth_Ready_Timeout(t *testing.T) {
+	// Arrange
+	h := newTestHealth(&stubCheck{name: "database", delay: time.Second})
+
+	// Act
+	start := time.Now()
+	report := h.Ready(context.Background())
+
+	// Assert
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestHealth_Ready_Cached(t *testing.T) {
+	// Arrange
+	check := &stubCheck{name: "database"}
+	h := newTestHealth(check)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	// Act
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+	now = now.Add(2 * time.Minute)
+	h.Ready(context.Background())
+
+	// Assert: повторная проба в пределах CacheTTL не обращается к зависимости
+	assert.Equal(t, int32(2), check.calls.Load())
+}
+
+func TestHealth_Ready_Draining(t *testing.T) {
+	// Arrange
+	check := &stubCheck{name: "database"}
+	h := newTestHealth(check)
+	require.True(t, h.Ready(context.Background()).Healthy())
+
+	// Act
+	h.StartDraining()
+	report := h.Ready(context.Background())
+
+	// Assert: кешированный успешный результат не маскирует остановку
+	assert.False(t, report.Healthy())
+	assert.True(t, report.Draining)
+}
+
+func TestHealth_Live_IgnoresReadiness(t *testing.T) {
+	// Arrange
+	h := newTestHealth(&stubCheck{name: "database", err: errors.New("connection refused")})
+	h.StartDraining()
+
+	// Act
+	report := h.Live(context.Background())
+
+	// Assert
+	assert.True(t, report.Healthy())
+	assert.Empty(t, report.Checks)
+}
+
+type stubMigrations struct {
+	version uint
+	dirty   bool
+}
+
+func (s stubMigrations) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return s.version, s.dirty, nil
+}
+
+func TestMigrationCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  stubMigrations
+		wantErr string
+	}{
+		{name: "up to date", source: stubMigrations{version: 15}},
+		{name: "behind", source: stubMigrations{version: 14}, wantErr: "schema version 14, expected 15"},
+		{name: "dirty", source: stubMigrations{version: 15, dirty: true}, wantErr: "migration 15 is dirty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewMigrationCheck(tt.source, 15).Check(context.Background())
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type stubPool struct {
+	acquired, max int32
+}
+
+func (s stubPool) PoolUsage() (int32, int32) {
+	return s.acquired, s.max
+}
+
+func TestPoolCheck(t *testing.T) {
+	assert.NoError(t, NewPoolCheck(stubPool{acquired: 8, max: 10}, 0.9).Check(context.Background()))
+	assert.EqualError(t, NewPoolCheck(stubPool{acquired: 9, max: 10}, 0.9).Check(context.Background()),
+		"pool saturated: 9 of 10 connections in use")
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	for _, name := range []string{
+		"000001_create_users_table.up.sql",
+		"000001_create_users_table.down.sql",
+		"000012_create_api_keys_table.up.sql",
+		"000003_create_sessions_table.up.sql",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	// Act
+	version, err := LatestMigrationVersion(dir)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(12), version)
+
+	_, err = LatestMigrationVersion(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
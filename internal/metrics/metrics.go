@@ -0,0 +1,156 @@
+// Package metrics собирает метрики сервиса и отдает их в текстовом формате Prometheus.
+// Все методы *Metrics допускают nil-получатель, чтобы слои, которым метрики не переданы
+// (например, в тестах), работали без дополнительных проверок
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const namespace = "chat"
+
+// Способы входа для LoginAttempt
+const (
+	LoginMethodPassword = "password"
+	LoginMethodMFA      = "mfa"
+	LoginMethodOIDC     = "oidc"
+)
+
+// Результаты входа для LoginAttempt
+const (
+	LoginResultSuccess = "success"
+	LoginResultFailure = "failure"
+	LoginResultLocked  = "locked"
+)
+
+// Сколько ждать подсчета активных сессий при сборе метрик
+const activeSessionsTimeout = 2 * time.Second
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	dbQueryDuration *prometheus.HistogramVec
+	messagesCreated prometheus.Counter
+	logins          *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Database query latency by operation and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "outcome"}),
+		messagesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_created_total",
+			Help:      "Messages successfully created.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by method and result.",
+		}, []string{"method", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueryDuration,
+		m.messagesCreated,
+		m.logins,
+	)
+	return m
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest учитывает обработанный запрос. route - шаблон маршрута, а не фактический путь,
+// чтобы ID в пути не раздували число временных рядов
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveQuery учитывает запрос к базе данных
+func (m *Metrics) ObserveQuery(operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.dbQueryDuration.WithLabelValues(operation, outcome).Observe(duration.Seconds())
+}
+
+func (m *Metrics) MessageCreated() {
+	if m == nil {
+		return
+	}
+	m.messagesCreated.Inc()
+}
+
+// LoginAttempt учитывает попытку входа; method и result - константы LoginMethod* и LoginResult*
+func (m *Metrics) LoginAttempt(method, result string) {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(method, result).Inc()
+}
+
+// TrackActiveSessions публикует число активных сессий; count вызывается при каждом сборе метрик
+func (m *Metrics) TrackActiveSessions(count func(ctx context.Context) (int, error), logger *logrus.Logger) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Sessions that have not expired yet.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), activeSessionsTimeout)
+		defer cancel()
+
+		active, err := count(ctx)
+		if err != nil {
+			logger.WithError(err).Warn("failed to count active sessions for metrics")
+			// NaN вместо нуля, чтобы сбой подсчета не выглядел как отсутствие сессий
+			return math.NaN()
+		}
+		return float64(active)
+	}))
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	// Arrange
+	m := New()
+
+	// Act
+	m.ObserveHTTPRequest(http.MethodGet, "/api/v1/messages/:id", http.StatusOK, 15*time.Millisecond)
+	m.ObserveQuery("query", 2*time.Millisecond, nil)
+	m.ObserveQuery("exec", time.Millisecond, errors.New("connection reset"))
+	m.MessageCreated()
+	m.LoginAttempt(LoginMethodPassword, LoginResultFailure)
+	m.LoginAttempt(LoginMethodPassword, LoginResultSuccess)
+	m.LoginAttempt(LoginMethodPassword, LoginResultSuccess)
+	body := scrape(t, m)
+
+	// Assert
+	assert.Contains(t, body, `chat_http_requests_total{method="GET",route="/api/v1/messages/:id",status="200"} 1`)
+	assert.Contains(t, body, `chat_http_request_duration_seconds_count{method="GET",route="/api/v1/messages/:id",status="200"} 1`)
+	assert.Contains(t, body, `chat_db_query_duration_seconds_count{operation="query",outcome="success"} 1`)
+	assert.Contains(t, body, `chat_db_query_duration_seconds_count{operation="exec",outcome="error"} 1`)
+	assert.Contains(t, body, "chat_messages_created_total 1")
+	assert.Contains(t, body, `chat_logins_total{method="password",result="failure"} 1`)
+	assert.Contains(t, body, `chat_logins_total{method="password",result="success"} 2`)
+}
+
+func TestMetrics_ActiveSessions(t *testing.T) {
+	// Arrange
+	m := New()
+	m.TrackActiveSessions(func(ctx context.Context) (int, error) {
+		return 7, nil
+	}, logrus.New())
+
+	// Act
+	body := scrape(t, m)
+
+	// Assert
+	assert.Contains(t, body, "chat_active_sessions 7")
+}
+
+func TestMetrics_ActiveSessionsCountError(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	m := New()
+	m.TrackActiveSessions(func(ctx context.Context) (int, error) {
+		return 0, errors.New("database is down")
+	}, logger)
+
+	// Act
+	body := scrape(t, m)
+
+	// Assert
+	assert.Contains(t, body, "chat_active_sessions NaN")
+}
+
+func TestMetrics_NilReceiver(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveHTTPRequest(http.MethodGet, "/health", http.StatusOK, time.Millisecond)
+		m.ObserveQuery("exec", time.Millisecond, nil)
+		m.MessageCreated()
+		m.LoginAttempt(LoginMethodOIDC, LoginResultSuccess)
+	})
+}
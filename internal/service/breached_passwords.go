@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Длина префикса SHA-1 в схеме k-anonymity (как в Have I Been Pwned range API)
+const breachedHashPrefixLength = 5
+
+type breachedPasswordList struct {
+	dir    string
+	hashes map[string]struct{}
+	logger *logrus.Logger
+}
+
+// NewBreachedPasswordList загружает список утекших паролей.
+// path может быть каталогом с файлами диапазонов <PREFIX>.txt (строки "SUFFIX:COUNT", формат HIBP range API) -
+// тогда при проверке читается только файл нужного префикса - или одиночным файлом со строками "SHA1[:COUNT]",
+// который целиком загружается в память
+func NewBreachedPasswordList(path string, logger *logrus.Logger) (BreachedPasswordChecker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+
+	if info.IsDir() {
+		logger.WithField("dir", path).Info("breached password range files configured")
+		return &breachedPasswordList{dir: path, logger: logger}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	hashes := make(map[string]struct{})
+	err = scanHashLines(file, func(hash string) bool {
+		if len(hash) == sha1.Size*2 {
+			hashes[hash] = struct{}{}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	logger.WithField("count", len(hashes)).Info("breached password list loaded")
+	return &breachedPasswordList{hashes: hashes, logger: logger}, nil
+}
+
+func (b *breachedPasswordList) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if b.hashes != nil {
+		_, found := b.hashes[hash]
+		return found, nil
+	}
+
+	prefix, suffix := hash[:breachedHashPrefixLength], hash[breachedHashPrefixLength:]
+	file, err := b.openRange(prefix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	found := false
+	err = scanHashLines(file, func(candidate string) bool {
+		found = candidate == suffix
+		return !found
+	})
+	return found, err
+}
+
+func (b *breachedPasswordList) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(b.dir, prefix))
+	}
+	return file, err
+}
+
+// scanHashLines вызывает fn для хэша из каждой строки "HASH[:COUNT]", пока fn возвращает true
+func scanHashLines(r io.Reader, fn func(hash string) bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash == "" {
+			continue
+		}
+		if !fn(strings.ToUpper(hash)) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
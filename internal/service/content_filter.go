@@ -0,0 +1,230 @@
+package service
+
+import (
+	"chat-service/internal/entity"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables символы, которыми подменяют латинские буквы, чтобы обойти фильтр:
+// кириллица и греческий, похожие по начертанию, и leetspeak. Применяются только к правилам-словам
+var confusables = map[rune]rune{
+	// Кириллица
+	'а': 'a', 'в': 'b', 'е': 'e', 'і': 'i', 'ј': 'j', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	// Греческий
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x',
+	// Leetspeak
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's', '!': 'i',
+}
+
+type compiledRule struct {
+	rule *entity.ContentFilterRule
+	// word нормализованное слово для правил kind=word
+	word string
+	// re скомпилированное выражение для правил kind=regex
+	re *regexp.Regexp
+}
+
+type contentFilter struct {
+	mu     sync.RWMutex
+	rules  []compiledRule
+	logger *logrus.Logger
+}
+
+// NewContentFilter создает пустой фильтр. Правила загружаются через Load и могут заменяться на лету
+func NewContentFilter(logger *logrus.Logger) ContentFilter {
+	return &contentFilter{logger: logger}
+}
+
+// Load атомарно заменяет набор правил. Выключенные правила пропускаются, некорректные - с предупреждением,
+// чтобы одно испорченное правило не отключало весь фильтр
+func (f *contentFilter) Load(rules []*entity.ContentFilterRule) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		logger := f.logger.WithField("rule_id", rule.ID)
+		if err := rule.Validate(); err != nil {
+			logger.WithError(err).Warn("skipping invalid content filter rule")
+			continue
+		}
+
+		switch rule.Kind {
+		case entity.ContentFilterKindWord:
+			word, _ := normalize(rule.Pattern, true)
+			word = strings.TrimSpace(word)
+			if word == "" {
+				logger.Warn("skipping content filter rule with empty normalized pattern")
+				continue
+			}
+			compiled = append(compiled, compiledRule{rule: rule, word: word})
+		case entity.ContentFilterKindRegex:
+			re, err := regexp.Compile("(?i)" + rule.Pattern)
+			if err != nil {
+				logger.WithError(err).Warn("skipping content filter rule with invalid regex")
+				continue
+			}
+			compiled = append(compiled, compiledRule{rule: rule, re: re})
+		}
+	}
+
+	f.mu.Lock()
+	f.rules = compiled
+	f.mu.Unlock()
+
+	f.logger.WithField("rules", len(compiled)).Info("content filter rules loaded")
+}
+
+// Apply проверяет текст всеми правилами. Если сработало хотя бы одно правило reject, сообщение
+// отклоняется целиком; иначе совпадения правил mask заменяются звездочками, а flag помечает сообщение
+func (f *contentFilter) Apply(content string) *entity.ContentFilterResult {
+	result := &entity.ContentFilterResult{Content: content}
+
+	f.mu.RLock()
+	rules := f.rules
+	f.mu.RUnlock()
+	if len(rules) == 0 {
+		return result
+	}
+
+	original := []rune(content)
+	skeleton, skeletonOrigin := normalize(content, true)
+	light, lightOrigin := normalize(content, false)
+	masked := make([]bool, len(original))
+
+	for _, rule := range rules {
+		var spans [][2]int
+		if rule.re != nil {
+			spans = regexSpans(rule.re, light, lightOrigin)
+		} else {
+			spans = wordSpans(rule.word, skeleton, skeletonOrigin, original)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		result.MatchedRules = append(result.MatchedRules, rule.rule.ID)
+		switch rule.rule.Action {
+		case entity.ContentFilterActionReject:
+			result.Rejected = true
+		case entity.ContentFilterActionFlag:
+			result.Flagged = true
+		case entity.ContentFilterActionMask:
+			for _, span := range spans {
+				for i := span[0]; i <= span[1]; i++ {
+					masked[i] = true
+				}
+			}
+		}
+	}
+
+	if result.Rejected {
+		return result
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(content))
+	for i, r := range original {
+		if masked[i] && !unicode.IsSpace(r) {
+			r = '*'
+		}
+		builder.WriteRune(r)
+	}
+	result.Content = builder.String()
+	return result
+}
+
+// normalize приводит текст к виду для сопоставления: убирает невидимые символы форматирования
+// (zero-width и т.п.), раскладывает совместимые формы (NFKD), отбрасывает диакритику и понижает регистр.
+// С fold дополнительно сворачивает похожие символы в латиницу. origin[i] - индекс исходной руны,
+// из которой получен i-й байт результата
+func normalize(text string, fold bool) (string, []int) {
+	var builder strings.Builder
+	origin := make([]int, 0, len(text))
+
+	index := 0
+	for _, r := range text {
+		if !unicode.Is(unicode.Cf, r) {
+			for _, d := range norm.NFKD.String(string(r)) {
+				if unicode.Is(unicode.Mn, d) {
+					continue
+				}
+				d = unicode.ToLower(d)
+				if fold {
+					if replacement, ok := confusables[d]; ok {
+						d = replacement
+					}
+				}
+				builder.WriteRune(d)
+				for range utf8.RuneLen(d) {
+					origin = append(origin, index)
+				}
+			}
+		}
+		index++
+	}
+
+	return builder.String(), origin
+}
+
+// wordSpans находит вхождения слова, окруженные не буквами и не цифрами.
+// Возвращает диапазоны исходных рун включительно
+func wordSpans(word, text string, origin []int, original []rune) [][2]int {
+	var spans [][2]int
+	for offset := 0; offset < len(text); {
+		pos := strings.Index(text[offset:], word)
+		if pos < 0 {
+			break
+		}
+		start, end := offset+pos, offset+pos+len(word)
+		if isWordBoundary(origin, original, start, end) {
+			spans = append(spans, [2]int{origin[start], origin[end-1]})
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return spans
+}
+
+// isWordBoundary проверяет соседей совпадения по исходным символам: после свертки
+// знаки вроде "!" или "@" становятся буквами и иначе склеивались бы со словом.
+// Совпадение не может начинаться или заканчиваться посреди разложенного символа (лигатуры)
+func isWordBoundary(origin []int, original []rune, start, end int) bool {
+	if start > 0 {
+		prev := origin[start-1]
+		if prev == origin[start] || isWordRune(original[prev]) {
+			return false
+		}
+	}
+	if end < len(origin) {
+		next := origin[end]
+		if next == origin[end-1] || isWordRune(original[next]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func regexSpans(re *regexp.Regexp, text string, origin []int) [][2]int {
+	var spans [][2]int
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		spans = append(spans, [2]int{origin[loc[0]], origin[loc[1]-1]})
+	}
+	return spans
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"testing"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContentFilter(rules ...*entity.ContentFilterRule) ContentFilter {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	filter := NewContentFilter(logger)
+	filter.Load(rules)
+	return filter
+}
+
+func newRule(kind entity.ContentFilterRuleKind, pattern string, action entity.ContentFilterAction) *entity.ContentFilterRule {
+	return &entity.ContentFilterRule{
+		ID:      uuid.New(),
+		Kind:    kind,
+		Pattern: pattern,
+		Action:  action,
+		Enabled: true,
+	}
+}
+
+func TestContentFilter_WordMatching(t *testing.T) {
+	filter := newTestContentFilter(newRule(entity.ContentFilterKindWord, "darn", entity.ContentFilterActionMask))
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"точное совпадение", "oh darn it", "oh **** it"},
+		{"регистр", "DaRn", "****"},
+		{"знак препинания после слова", "darn!", "****!"},
+		{"часть другого слова не маскируется", "darning socks", "darning socks"},
+		{"диакритика", "dárn", "****"},
+		{"кириллические двойники", "dаrn", "****"},
+		{"leetspeak", "d4rn", "****"},
+		{"невидимые символы внутри слова", "da​rn", "*****"},
+		{"полноширинные символы", "ｄａｒｎ", "****"},
+		{"несколько вхождений", "darn, darn", "****, ****"},
+		{"без совпадений", "hello", "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := filter.Apply(tt.content)
+
+			// Assert
+			assert.Equal(t, tt.want, result.Content)
+			assert.False(t, result.Rejected)
+		})
+	}
+}
+
+func TestContentFilter_PhraseKeepsSpaces(t *testing.T) {
+	// Arrange
+	filter := newTestContentFilter(newRule(entity.ContentFilterKindWord, "bad word", entity.ContentFilterActionMask))
+
+	// Act
+	result := filter.Apply("a bad word here")
+
+	// Assert
+	assert.Equal(t, "a *** **** here", result.Content)
+}
+
+func TestContentFilter_Regex(t *testing.T) {
+	// Arrange
+	filter := newTestContentFilter(newRule(entity.ContentFilterKindRegex, `buy\s+now`, entity.ContentFilterActionFlag))
+
+	// Act
+	flagged := filter.Apply("BUY  NOW cheap")
+	clean := filter.Apply("buy it later")
+
+	// Assert
+	assert.True(t, flagged.Flagged)
+	assert.Equal(t, "BUY  NOW cheap", flagged.Content)
+	assert.False(t, clean.Flagged)
+	assert.Empty(t, clean.MatchedRules)
+}
+
+func TestContentFilter_RejectTakesPrecedence(t *testing.T) {
+	// Arrange
+	reject := newRule(entity.ContentFilterKindWord, "scam", entity.ContentFilterActionReject)
+	mask := newRule(entity.ContentFilterKindWord, "darn", entity.ContentFilterActionMask)
+	filter := newTestContentFilter(mask, reject)
+
+	// Act
+	result := filter.Apply("darn this scam")
+
+	// Assert
+	assert.True(t, result.Rejected)
+	assert.Equal(t, []uuid.UUID{mask.ID, reject.ID}, result.MatchedRules)
+}
+
+func TestContentFilter_LoadSkipsDisabledAndInvalidRules(t *testing.T) {
+	// Arrange
+	disabled := newRule(entity.ContentFilterKindWord, "darn", entity.ContentFilterActionReject)
+	disabled.Enabled = false
+	invalid := newRule(entity.ContentFilterKindRegex, "(", entity.ContentFilterActionReject)
+	invisible := newRule(entity.ContentFilterKindWord, "​", entity.ContentFilterActionReject)
+	filter := newTestContentFilter(disabled, invalid, invisible)
+
+	// Act
+	result := filter.Apply("darn (")
+
+	// Assert
+	assert.False(t, result.Rejected)
+	assert.Empty(t, result.MatchedRules)
+}
+
+func TestContentFilter_ReloadReplacesRules(t *testing.T) {
+	// Arrange
+	filter := newTestContentFilter(newRule(entity.ContentFilterKindWord, "darn", entity.ContentFilterActionReject))
+	assert.True(t, filter.Apply("darn").Rejected)
+
+	// Act
+	filter.Load(nil)
+
+	// Assert
+	assert.False(t, filter.Apply("darn").Rejected)
+}
@@ -1,16 +1,25 @@
 package service
 
 import (
+	"strings"
+
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type hashService struct {
+	cost   int
 	logger *logrus.Logger
 }
 
 func NewHashService(logger *logrus.Logger) HashService {
+	return NewBcryptHashService(bcrypt.DefaultCost, logger)
+}
+
+// NewBcryptHashService создает bcrypt-реализацию HashService с заданной стоимостью
+func NewBcryptHashService(cost int, logger *logrus.Logger) HashService {
 	return &hashService{
+		cost:   cost,
 		logger: logger,
 	}
 }
@@ -18,7 +27,7 @@ func NewHashService(logger *logrus.Logger) HashService {
 func (h *hashService) HashPassword(password string) (string, error) {
 	h.logger.WithField("component", "hash_service").Debug("hashing password")
 
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		h.logger.WithError(err).Error("failed to hash password")
 		return "", err
@@ -40,3 +49,16 @@ func (h *hashService) CheckPasswordHash(password, hash string) bool {
 	h.logger.WithField("component", "hash_service").Debug("password hash check successful")
 	return true
 }
+
+// NeedsRehash сообщает, что хэш создан не bcrypt или с другой стоимостью
+func (h *hashService) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2Params параметры Argon2id. Memory задается в KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params рекомендованные OWASP параметры для Argon2id
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Верхняя граница памяти при разборе хэша, чтобы испорченная запись в БД не съела всю память
+const maxArgon2Memory = 1024 * 1024
+
+type argon2HashService struct {
+	params Argon2Params
+	logger *logrus.Logger
+}
+
+// NewArgon2HashService создает Argon2id-реализацию HashService.
+// Хэши bcrypt, созданные до перехода, по-прежнему проверяются и помечаются как требующие перехэширования
+func NewArgon2HashService(params Argon2Params, logger *logrus.Logger) HashService {
+	return &argon2HashService{
+		params: params,
+		logger: logger,
+	}
+}
+
+// HashPassword возвращает хэш в формате PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (h *argon2HashService) HashPassword(password string) (string, error) {
+	h.logger.WithField("component", "hash_service").Debug("hashing password with argon2id")
+
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		h.logger.WithError(err).Error("failed to generate salt")
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2HashService) CheckPasswordHash(password, hash string) bool {
+	h.logger.WithField("component", "hash_service").Debug("checking password hash")
+
+	if isBcryptHash(hash) {
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			h.logger.WithError(err).Debug("legacy bcrypt hash check failed")
+			return false
+		}
+		return true
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		h.logger.WithError(err).Debug("failed to decode argon2 hash")
+		return false
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+// NeedsRehash сообщает, что хэш создан другим алгоритмом или с другими параметрами
+func (h *argon2HashService) NeedsRehash(hash string) bool {
+	params, salt, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if params.Memory == 0 || params.Memory > maxArgon2Memory || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("argon2id parameters out of range")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id key")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Облегченные параметры, чтобы тесты выполнялись быстро
+var testArgon2Params = Argon2Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2HashService_HashPassword_PHCFormat(t *testing.T) {
+	// Arrange
+	service := NewArgon2HashService(testArgon2Params, newTestLogger())
+
+	// Act
+	hash, err := service.HashPassword("test_password_123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, hash)
+
+	// Соль случайная - одинаковые пароли дают разные хэши
+	other, err := service.HashPassword("test_password_123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestArgon2HashService_CheckPasswordHash(t *testing.T) {
+	// Arrange
+	service := NewArgon2HashService(testArgon2Params, newTestLogger())
+	hash, err := service.HashPassword("correct_password")
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, service.CheckPasswordHash("correct_password", hash))
+	assert.False(t, service.CheckPasswordHash("wrong_password", hash))
+	assert.False(t, service.CheckPasswordHash("correct_password", ""))
+	assert.False(t, service.CheckPasswordHash("correct_password", "$argon2id$v=19$m=1024,t=1,p=1$bad"))
+}
+
+func TestArgon2HashService_LongPasswordNotTruncated(t *testing.T) {
+	// Arrange - bcrypt учитывает только первые 72 байта
+	service := NewArgon2HashService(testArgon2Params, newTestLogger())
+	prefix := strings.Repeat("a", 72)
+
+	hash, err := service.HashPassword(prefix + "first")
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, service.CheckPasswordHash(prefix+"first", hash))
+	assert.False(t, service.CheckPasswordHash(prefix+"second", hash))
+}
+
+func TestArgon2HashService_VerifiesLegacyBcrypt(t *testing.T) {
+	// Arrange
+	service := NewArgon2HashService(testArgon2Params, newTestLogger())
+	legacy, err := bcrypt.GenerateFromPassword([]byte("legacy_password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, service.CheckPasswordHash("legacy_password", string(legacy)))
+	assert.False(t, service.CheckPasswordHash("wrong_password", string(legacy)))
+	assert.True(t, service.NeedsRehash(string(legacy)))
+}
+
+func TestArgon2HashService_NeedsRehash(t *testing.T) {
+	// Arrange
+	service := NewArgon2HashService(testArgon2Params, newTestLogger())
+	current, err := service.HashPassword("password")
+	require.NoError(t, err)
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	upgraded := NewArgon2HashService(stronger, newTestLogger())
+
+	// Act & Assert
+	assert.False(t, service.NeedsRehash(current))
+	assert.True(t, upgraded.NeedsRehash(current)) // Параметры изменились
+	assert.True(t, upgraded.CheckPasswordHash("password", current))
+	assert.True(t, service.NeedsRehash("garbage"))
+}
+
+func TestHashService_NeedsRehash_BcryptCost(t *testing.T) {
+	// Arrange
+	service := NewBcryptHashService(bcrypt.MinCost+1, newTestLogger())
+	cheap, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	current, err := service.HashPassword("password")
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, service.NeedsRehash(string(cheap)))
+	assert.False(t, service.NeedsRehash(current))
+}
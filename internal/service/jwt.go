@@ -21,8 +21,15 @@ func NewJWTService(secretKey string, logger *logrus.Logger) JWTService {
 	}
 }
 
+// Назначения токенов: сессионный токен и токен второго шага логина
+const (
+	TokenPurposeSession = ""
+	TokenPurposeMFA     = "mfa"
+)
+
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -51,6 +58,56 @@ func (j *jwtService) GenerateToken(userID uuid.UUID) (string, error) {
 }
 
 func (j *jwtService) ValidateToken(tokenString string) (uuid.UUID, error) {
+	claims, err := j.validate(tokenString, TokenPurposeSession)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// GenerateMFAToken выпускает короткоживущий токен, подтверждающий, что пароль уже проверен.
+// challengeID записывается в jti, чтобы токен можно было использовать только один раз
+func (j *jwtService) GenerateMFAToken(userID, challengeID uuid.UUID, ttl time.Duration) (string, error) {
+	j.logger.WithField("user_id", userID).Debug("generating MFA challenge token")
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: TokenPurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        challengeID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, err := token.SignedString([]byte(j.secretKey))
+	if err != nil {
+		j.logger.WithError(err).Error("failed to generate MFA challenge token")
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// ValidateMFAToken возвращает пользователя и ID челленджа из jti
+func (j *jwtService) ValidateMFAToken(tokenString string) (uuid.UUID, uuid.UUID, error) {
+	claims, err := j.validate(tokenString, TokenPurposeMFA)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	challengeID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		j.logger.Warn("MFA challenge token has no valid jti")
+		return uuid.Nil, uuid.Nil, errors.New("invalid token id")
+	}
+	return claims.UserID, challengeID, nil
+}
+
+func (j *jwtService) validate(tokenString, purpose string) (*Claims, error) {
 	j.logger.WithField("token", j.maskToken(tokenString)).Debug("validating JWT token")
 
 	claims := &Claims{}
@@ -60,16 +117,22 @@ func (j *jwtService) ValidateToken(tokenString string) (uuid.UUID, error) {
 
 	if err != nil {
 		j.logger.WithError(err).Warn("failed to parse JWT token")
-		return uuid.Nil, err
+		return nil, err
 	}
 
 	if !token.Valid {
 		j.logger.Warn("invalid JWT token")
-		return uuid.Nil, errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+
+	// Токен одного назначения нельзя использовать вместо другого
+	if claims.Purpose != purpose {
+		j.logger.WithField("purpose", claims.Purpose).Warn("JWT token purpose mismatch")
+		return nil, errors.New("invalid token purpose")
 	}
 
 	j.logger.WithField("user_id", claims.UserID).Debug("JWT token validated successfully")
-	return claims.UserID, nil
+	return claims, nil
 }
 
 func (j *jwtService) maskToken(token string) string {
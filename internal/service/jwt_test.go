@@ -2,6 +2,7 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -161,3 +162,58 @@ func TestJWTService_ValidateToken_MalformedToken(t *testing.T) {
 		assert.Equal(t, uuid.Nil, userID, "Expected uuid.Nil for token: %s", token)
 	}
 }
+
+func TestJWTService_MFAToken_RoundTrip(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewJWTService("test_secret_key_for_testing", logger)
+	userID := uuid.New()
+	challengeID := uuid.New()
+
+	// Act
+	token, err := service.GenerateMFAToken(userID, challengeID, 5*time.Minute)
+	assert.NoError(t, err)
+
+	parsedUserID, parsedChallengeID, err := service.ValidateMFAToken(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, userID, parsedUserID)
+	assert.Equal(t, challengeID, parsedChallengeID)
+}
+
+func TestJWTService_MFAToken_NotAcceptedAsSessionToken(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewJWTService("test_secret_key_for_testing", logger)
+	userID := uuid.New()
+
+	mfaToken, err := service.GenerateMFAToken(userID, uuid.New(), 5*time.Minute)
+	assert.NoError(t, err)
+	sessionToken, err := service.GenerateToken(userID)
+	assert.NoError(t, err)
+
+	// Act
+	_, errSession := service.ValidateToken(mfaToken)
+	_, _, errMFA := service.ValidateMFAToken(sessionToken)
+
+	// Assert
+	assert.Error(t, errSession)
+	assert.Error(t, errMFA)
+}
+
+func TestJWTService_MFAToken_Expired(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewJWTService("test_secret_key_for_testing", logger)
+
+	token, err := service.GenerateMFAToken(uuid.New(), uuid.New(), -time.Minute)
+	assert.NoError(t, err)
+
+	// Act
+	userID, _, err := service.ValidateMFAToken(token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, userID)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MailMessage письмо в виде простого текста
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// buildMIME собирает письмо в формате RFC 5322 с телом text/plain в UTF-8
+func buildMIME(from string, msg *MailMessage) []byte {
+	var buf bytes.Buffer
+
+	writeHeader := func(name, value string) {
+		buf.WriteString(name)
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteString("\r\n")
+	}
+
+	writeHeader("From", from)
+	writeHeader("To", msg.To)
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@%s>", uuid.New(), mailDomain(from)))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+	writeHeader("Content-Transfer-Encoding", "8bit")
+	buf.WriteString("\r\n")
+
+	// Нормализуем переводы строк к CRLF, как требует SMTP
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\r\n")
+	}
+
+	return buf.Bytes()
+}
+
+func mailDomain(address string) string {
+	address = strings.TrimSuffix(address, ">")
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
+
+// validateMailMessage защищает от внедрения заголовков через адрес или тему
+func validateMailMessage(msg *MailMessage) error {
+	if msg == nil {
+		return fmt.Errorf("mail message cannot be nil")
+	}
+	if msg.To == "" {
+		return fmt.Errorf("mail recipient is required")
+	}
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("mail headers must not contain line breaks")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// logMailer пишет письма в лог. Только для разработки: ссылки со сбросом пароля попадут в логи
+type logMailer struct {
+	from   string
+	logger *logrus.Logger
+}
+
+func NewLogMailer(from string, logger *logrus.Logger) Mailer {
+	return &logMailer{
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *MailMessage) error {
+	if err := validateMailMessage(msg); err != nil {
+		return err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"component": "log_mailer",
+		"from":      m.from,
+		"to":        msg.To,
+		"subject":   msg.Subject,
+		"body":      msg.Body,
+	}).Info("email captured by log mailer")
+
+	return nil
+}
+
+// fileMailer складывает письма в каталог outbox в виде .eml файлов
+type fileMailer struct {
+	from   string
+	dir    string
+	logger *logrus.Logger
+}
+
+func NewFileMailer(from, dir string, logger *logrus.Logger) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail outbox directory: %w", err)
+	}
+	return &fileMailer{
+		from:   from,
+		dir:    dir,
+		logger: logger,
+	}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg *MailMessage) error {
+	if err := validateMailMessage(msg); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000"), uuid.New())
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, buildMIME(m.from, msg), 0o640); err != nil {
+		m.logger.WithError(err).WithField("path", path).Error("failed to write email to outbox")
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"component": "file_mailer",
+		"path":      path,
+	}).Info("email written to outbox")
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SMTPConfig параметры подключения к SMTP-серверу
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+	logger *logrus.Logger
+}
+
+func NewSMTPMailer(config SMTPConfig, logger *logrus.Logger) Mailer {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &smtpMailer{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *MailMessage) error {
+	if err := validateMailMessage(msg); err != nil {
+		return err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"component": "smtp_mailer",
+		"subject":   msg.Subject,
+	}).Debug("sending email")
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		m.logger.WithError(err).WithField("address", addr).Error("failed to connect to smtp server")
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if m.config.Username != "" {
+		// PlainAuth сам откажется передавать пароль без TLS на не-localhost адрес
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	// В конверте нужен голый адрес, отображаемое имя остается только в заголовке From
+	sender, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := writer.Write(buildMIME(m.config.From, msg)); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write email body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish email body: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		m.logger.WithError(err).Warn("smtp QUIT failed")
+	}
+
+	m.logger.WithField("component", "smtp_mailer").Info("email sent successfully")
+	return nil
+}
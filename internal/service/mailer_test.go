@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer минимальный SMTP-сервер для тестов: принимает одно письмо на соединение
+type fakeSMTPServer struct {
+	listener net.Listener
+	messages chan fakeSMTPMessage
+}
+
+type fakeSMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{
+		listener: listener,
+		messages: make(chan fakeSMTPMessage, 10),
+	}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	var msg fakeSMTPMessage
+	reply("220 localhost fake smtp")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg.From = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.Data = data.String()
+			s.messages <- msg
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send_Success(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	server := newFakeSMTPServer(t)
+
+	mailer := NewSMTPMailer(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "Chat <noreply@chat.local>",
+	}, logger)
+
+	// Act
+	err := mailer.Send(context.Background(), &MailMessage{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Body:    "Перейдите по ссылке\nhttps://chat.local/reset?token=abc",
+	})
+
+	// Assert
+	require.NoError(t, err)
+
+	msg := <-server.messages
+	assert.Equal(t, "noreply@chat.local", msg.From)
+	assert.Equal(t, []string{"user@example.com"}, msg.To)
+	assert.Contains(t, msg.Data, "To: user@example.com\r\n")
+	assert.Contains(t, msg.Data, "Subject: =?utf-8?q?")
+	assert.Contains(t, msg.Data, "https://chat.local/reset?token=abc\r\n")
+}
+
+func TestSMTPMailer_Send_ConnectionRefused(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+
+	// Получаем свободный порт и сразу его закрываем
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mailer := NewSMTPMailer(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: port,
+		From: "noreply@chat.local",
+	}, logger)
+
+	// Act
+	err = mailer.Send(context.Background(), &MailMessage{To: "user@example.com", Subject: "Test", Body: "Body"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to smtp server")
+}
+
+func TestMailer_Send_RejectsHeaderInjection(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	mailer := NewLogMailer("noreply@chat.local", logger)
+
+	// Act
+	err := mailer.Send(context.Background(), &MailMessage{
+		To:      "user@example.com\r\nBcc: victim@example.com",
+		Subject: "Test",
+		Body:    "Body",
+	})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestFileMailer_Send_WritesToOutbox(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	mailer, err := NewFileMailer("noreply@chat.local", dir, logger)
+	require.NoError(t, err)
+
+	// Act
+	err = mailer.Send(context.Background(), &MailMessage{To: "user@example.com", Subject: "Test", Body: "Hello"})
+
+	// Assert
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, strings.HasSuffix(entries[0].Name(), ".eml"))
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: noreply@chat.local\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nHello\r\n")
+}
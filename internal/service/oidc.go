@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProviderConfig настройки одного OpenID Connect провайдера
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCIdentity проверенные утверждения из ID токена
+type OIDCIdentity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// ErrOIDCAuthentication ошибка проверки ответа провайдера: код не обменялся, токен невалиден или nonce не совпал
+var ErrOIDCAuthentication = errors.New("oidc authentication failed")
+
+type oidcClient struct {
+	config     OIDCProviderConfig
+	httpClient *http.Client
+	logger     *logrus.Logger
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCClient создает клиента провайдера. Discovery выполняется лениво при первом обращении,
+// поэтому недоступный провайдер не мешает запуску сервиса
+func NewOIDCClient(config OIDCProviderConfig, httpClient *http.Client, logger *logrus.Logger) OIDCClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	return &oidcClient{
+		config:     config,
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+// AuthCodeURL возвращает адрес авторизации с state, nonce и PKCE challenge (S256)
+func (c *oidcClient) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauth2Config, _, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauth2Config.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(codeVerifier),
+	), nil
+}
+
+// Exchange обменивает код на токены и проверяет подпись, issuer, audience, срок действия и nonce ID токена
+func (c *oidcClient) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	oauth2Config, verifier, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, c.httpClient)
+
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		c.logger.WithError(err).WithField("provider", c.config.Name).Warn("failed to exchange oidc authorization code")
+		return nil, fmt.Errorf("%w: code exchange failed", ErrOIDCAuthentication)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		c.logger.WithField("provider", c.config.Name).Warn("token response has no id_token")
+		return nil, fmt.Errorf("%w: id_token missing", ErrOIDCAuthentication)
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		c.logger.WithError(err).WithField("provider", c.config.Name).Warn("failed to verify id_token")
+		return nil, fmt.Errorf("%w: invalid id_token", ErrOIDCAuthentication)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		c.logger.WithField("provider", c.config.Name).Warn("id_token nonce mismatch")
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCAuthentication)
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		c.logger.WithError(err).WithField("provider", c.config.Name).Warn("failed to decode id_token claims")
+		return nil, fmt.Errorf("%w: invalid claims", ErrOIDCAuthentication)
+	}
+
+	return &OIDCIdentity{
+		Subject:           idToken.Subject,
+		Email:             claims.Email,
+		EmailVerified:     parseEmailVerified(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+func (c *oidcClient) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider != nil {
+		return c.oauth2, c.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, c.httpClient), c.config.Issuer)
+	if err != nil {
+		c.logger.WithError(err).WithField("provider", c.config.Name).Error("oidc discovery failed")
+		return nil, nil, fmt.Errorf("oidc discovery failed for %s: %w", c.config.Name, err)
+	}
+
+	c.provider = provider
+	c.oauth2 = &oauth2.Config{
+		ClientID:     c.config.ClientID,
+		ClientSecret: c.config.ClientSecret,
+		RedirectURL:  c.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       c.config.Scopes,
+	}
+	c.verifier = provider.Verifier(&oidc.Config{ClientID: c.config.ClientID})
+
+	c.logger.WithField("provider", c.config.Name).Info("oidc provider discovered")
+	return c.oauth2, c.verifier, nil
+}
+
+// parseEmailVerified учитывает провайдеров, которые отдают email_verified строкой
+func parseEmailVerified(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
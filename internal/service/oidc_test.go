@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDCProvider минимальный OpenID провайдер: discovery, JWKS и token endpoint с проверкой PKCE
+type mockOIDCProvider struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	signingKey *rsa.PrivateKey // Ключ подписи ID токена; отличается от key, чтобы проверить отказ
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+const (
+	mockClientID     = "chat-service"
+	mockClientSecret = "secret"
+	mockKeyID        = "test-key"
+)
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{
+		key:        key,
+		signingKey: key,
+		codes:      make(map[string]mockAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *mockOIDCProvider) issuer() string {
+	return p.server.URL
+}
+
+// authorize имитирует согласие пользователя: запоминает challenge и nonce из адреса авторизации и выдает код
+func (p *mockOIDCProvider) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	code, err := GenerateRandomToken(16)
+	require.NoError(t, err)
+
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		claims:    claims,
+	}
+	p.mu.Unlock()
+
+	return code
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer(),
+		"authorization_endpoint":                p.issuer() + "/authorize",
+		"token_endpoint":                        p.issuer() + "/token",
+		"jwks_uri":                              p.issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *mockOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != mockClientID || clientSecret != mockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	auth, found := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !found || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.issuer(),
+		"aud":   mockClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": auth.nonce,
+	}
+	for k, v := range auth.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockKeyID
+	idToken, err := token.SignedString(p.signingKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestOIDCClient(p *mockOIDCProvider) OIDCClient {
+	return NewOIDCClient(OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       p.issuer(),
+		ClientID:     mockClientID,
+		ClientSecret: mockClientSecret,
+		RedirectURL:  "http://localhost/callback",
+	}, p.server.Client(), newTestLogger())
+}
+
+func TestOIDCClient_Flow(t *testing.T) {
+	ctx := context.Background()
+	verifier, err := GenerateRandomToken(32)
+	require.NoError(t, err)
+
+	t.Run("успешный вход", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		client := newTestOIDCClient(provider)
+
+		authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/authorize", parsed.Path)
+		assert.Equal(t, "state-1", parsed.Query().Get("state"))
+		assert.Equal(t, mockClientID, parsed.Query().Get("client_id"))
+		assert.Contains(t, parsed.Query().Get("scope"), "openid")
+
+		code := provider.authorize(t, authURL, jwt.MapClaims{
+			"sub":                "user-42",
+			"email":              "user@example.com",
+			"email_verified":     true,
+			"preferred_username": "user42",
+		})
+
+		identity, err := client.Exchange(ctx, code, verifier, "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-42", identity.Subject)
+		assert.Equal(t, "user@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+		assert.Equal(t, "user42", identity.PreferredUsername)
+	})
+
+	t.Run("неверный code verifier", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		client := newTestOIDCClient(provider)
+
+		authURL, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+		require.NoError(t, err)
+		code := provider.authorize(t, authURL, jwt.MapClaims{"sub": "user-42"})
+
+		_, err = client.Exchange(ctx, code, verifier+"x", "nonce")
+		assert.ErrorIs(t, err, ErrOIDCAuthentication)
+	})
+
+	t.Run("nonce не совпадает", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		client := newTestOIDCClient(provider)
+
+		authURL, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+		require.NoError(t, err)
+		code := provider.authorize(t, authURL, jwt.MapClaims{"sub": "user-42"})
+
+		_, err = client.Exchange(ctx, code, verifier, "other-nonce")
+		assert.ErrorIs(t, err, ErrOIDCAuthentication)
+	})
+
+	t.Run("подпись чужим ключом", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		provider.signingKey = otherKey
+		client := newTestOIDCClient(provider)
+
+		authURL, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+		require.NoError(t, err)
+		code := provider.authorize(t, authURL, jwt.MapClaims{"sub": "user-42"})
+
+		_, err = client.Exchange(ctx, code, verifier, "nonce")
+		assert.ErrorIs(t, err, ErrOIDCAuthentication)
+	})
+
+	t.Run("токен для другого клиента", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		client := newTestOIDCClient(provider)
+
+		authURL, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+		require.NoError(t, err)
+		code := provider.authorize(t, authURL, jwt.MapClaims{"sub": "user-42", "aud": "another-client"})
+
+		_, err = client.Exchange(ctx, code, verifier, "nonce")
+		assert.ErrorIs(t, err, ErrOIDCAuthentication)
+	})
+
+	t.Run("провайдер недоступен", func(t *testing.T) {
+		provider := newMockOIDCProvider(t)
+		client := newTestOIDCClient(provider)
+		provider.server.Close()
+
+		_, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrOIDCAuthentication)
+	})
+}
+
+func TestParseEmailVerified(t *testing.T) {
+	assert.True(t, parseEmailVerified(true))
+	assert.True(t, parseEmailVerified("true"))
+	assert.False(t, parseEmailVerified("false"))
+	assert.False(t, parseEmailVerified(nil))
+}
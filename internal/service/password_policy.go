@@ -0,0 +1,120 @@
+package service
+
+import (
+	"chat-service/internal/entity"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordPolicyConfig требования к паролю. Длина считается в символах, а не байтах
+type PasswordPolicyConfig struct {
+	MinLength        int
+	MaxLength        int
+	RequireUpper     bool
+	RequireLower     bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DisallowUserInfo bool
+}
+
+// Минимальная длина имени или локальной части email, которую имеет смысл искать в пароле
+const minUserInfoLength = 3
+
+type passwordPolicy struct {
+	config   PasswordPolicyConfig
+	breached BreachedPasswordChecker
+	logger   *logrus.Logger
+}
+
+// NewPasswordPolicy создает политику паролей. breached может быть nil - тогда проверка по утечкам отключена
+func NewPasswordPolicy(config PasswordPolicyConfig, breached BreachedPasswordChecker, logger *logrus.Logger) PasswordPolicy {
+	return &passwordPolicy{
+		config:   config,
+		breached: breached,
+		logger:   logger,
+	}
+}
+
+// Validate проверяет пароль и возвращает все нарушения сразу в виде FieldValidationError.
+// user используется для запрета имени и email в пароле и может быть nil
+func (p *passwordPolicy) Validate(password string, user *entity.User) error {
+	var violations []entity.FieldError
+	violate := func(format string, args ...interface{}) {
+		violations = append(violations, entity.FieldError{Field: "password", Message: fmt.Sprintf(format, args...)})
+	}
+
+	if password == "" {
+		violate("password is required")
+		return &entity.FieldValidationError{Errors: violations}
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violate("password must be at least %d characters", p.config.MinLength)
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		violate("password must be at most %d characters", p.config.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.config.RequireUpper && !hasUpper {
+		violate("password must contain an uppercase letter")
+	}
+	if p.config.RequireLower && !hasLower {
+		violate("password must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		violate("password must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		violate("password must contain a special character")
+	}
+
+	if p.config.DisallowUserInfo && user != nil {
+		lowered := strings.ToLower(password)
+		if containsUserInfo(lowered, user.Username) {
+			violate("password must not contain the username")
+		}
+		localPart, _, _ := strings.Cut(user.Email, "@")
+		if containsUserInfo(lowered, localPart) {
+			violate("password must not contain the email address")
+		}
+	}
+
+	// Проверку по утечкам делаем, только если пароль прошел остальные правила
+	if len(violations) == 0 && p.breached != nil {
+		breached, err := p.breached.IsBreached(password)
+		if err != nil {
+			// Недоступный список утечек не должен блокировать регистрацию и смену пароля
+			p.logger.WithError(err).Warn("failed to check password against breached list")
+		} else if breached {
+			violate("password has appeared in a data breach, choose a different one")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &entity.FieldValidationError{Errors: violations}
+	}
+	return nil
+}
+
+func containsUserInfo(loweredPassword, info string) bool {
+	info = strings.ToLower(strings.TrimSpace(info))
+	return utf8.RuneCountInString(info) >= minUserInfoLength && strings.Contains(loweredPassword, info)
+}
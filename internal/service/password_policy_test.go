@@ -0,0 +1,163 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chat-service/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubBreachedChecker struct {
+	breached bool
+	err      error
+}
+
+func (s *stubBreachedChecker) IsBreached(password string) (bool, error) {
+	return s.breached, s.err
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestPasswordPolicy_Validate_Success(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{
+		MinLength:        8,
+		MaxLength:        64,
+		RequireUpper:     true,
+		RequireLower:     true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		DisallowUserInfo: true,
+	}, &stubBreachedChecker{}, newTestLogger())
+
+	// Act
+	err := policy.Validate("Correct-Horse-42", &entity.User{Username: "alice", Email: "alice@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestPasswordPolicy_Validate_ReportsAllViolations(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}, nil, newTestLogger())
+
+	// Act
+	err := policy.Validate("short", nil)
+
+	// Assert
+	var fieldErr *entity.FieldValidationError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, []string{
+		"password must be at least 10 characters",
+		"password must contain an uppercase letter",
+		"password must contain a digit",
+		"password must contain a special character",
+	}, fieldErr.FieldErrors()["password"])
+}
+
+func TestPasswordPolicy_Validate_LengthInCharacters(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8, MaxLength: 10}, nil, newTestLogger())
+
+	// Act & Assert - 8 кириллических символов занимают 16 байт
+	assert.NoError(t, policy.Validate("пароль12", nil))
+	assert.Error(t, policy.Validate("очень-длинный-пароль", nil))
+}
+
+func TestPasswordPolicy_Validate_DisallowUserInfo(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8, DisallowUserInfo: true}, nil, newTestLogger())
+	user := &entity.User{Username: "JohnDoe", Email: "john.smith@example.com"}
+
+	// Act
+	usernameErr := policy.Validate("my-johndoe-pass", user)
+	emailErr := policy.Validate("John.Smith-2024", user)
+
+	// Assert
+	assert.ErrorContains(t, usernameErr, "must not contain the username")
+	assert.ErrorContains(t, emailErr, "must not contain the email address")
+}
+
+func TestPasswordPolicy_Validate_Breached(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8}, &stubBreachedChecker{breached: true}, newTestLogger())
+
+	// Act
+	err := policy.Validate("password123", nil)
+
+	// Assert
+	assert.ErrorContains(t, err, "appeared in a data breach")
+}
+
+func TestPasswordPolicy_Validate_BreachedCheckFailsOpen(t *testing.T) {
+	// Arrange
+	policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8}, &stubBreachedChecker{err: errors.New("disk error")}, newTestLogger())
+
+	// Act
+	err := policy.Validate("password123", nil)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBreachedPasswordList_SingleFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := sha1Hex("password123") + ":24000\n" + strings.ToLower(sha1Hex("qwerty")) + "\n\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	list, err := NewBreachedPasswordList(path, newTestLogger())
+	require.NoError(t, err)
+
+	// Act & Assert
+	for password, expected := range map[string]bool{"password123": true, "qwerty": true, "unique-pass-xyz": false} {
+		breached, err := list.IsBreached(password)
+		require.NoError(t, err)
+		assert.Equal(t, expected, breached, password)
+	}
+}
+
+func TestBreachedPasswordList_RangeDirectory(t *testing.T) {
+	// Arrange - формат HIBP range: файл <PREFIX>.txt со строками SUFFIX:COUNT
+	dir := t.TempDir()
+	hash := sha1Hex("password123")
+	content := "0000000000000000000000000000000000A:1\n" + hash[5:] + ":24000\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600))
+
+	list, err := NewBreachedPasswordList(dir, newTestLogger())
+	require.NoError(t, err)
+
+	// Act
+	breached, err := list.IsBreached("password123")
+	require.NoError(t, err)
+	notBreached, err := list.IsBreached("unique-pass-xyz") // Файла для префикса нет
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, breached)
+	assert.False(t, notBreached)
+}
+
+func TestBreachedPasswordList_MissingPath(t *testing.T) {
+	// Act
+	_, err := NewBreachedPasswordList(filepath.Join(t.TempDir(), "missing"), newTestLogger())
+
+	// Assert
+	assert.Error(t, err)
+}
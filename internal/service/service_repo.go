@@ -1,13 +1,50 @@
 package service
 
-import "github.com/google/uuid"
+import (
+	"chat-service/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type HashService interface {
 	HashPassword(password string) (string, error)
 	CheckPasswordHash(password, hash string) bool
+	NeedsRehash(hash string) bool
 }
 
 type JWTService interface {
 	GenerateToken(userID uuid.UUID) (string, error)
 	ValidateToken(token string) (uuid.UUID, error)
+	GenerateMFAToken(userID, challengeID uuid.UUID, ttl time.Duration) (string, error)
+	ValidateMFAToken(token string) (userID, challengeID uuid.UUID, err error)
+}
+
+type TOTPService interface {
+	GenerateSecret() (string, error)
+	BuildURI(issuer, account, secret string) string
+	Validate(code, secret string, at time.Time) (int64, bool)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *MailMessage) error
+}
+
+type PasswordPolicy interface {
+	Validate(password string, user *entity.User) error
+}
+
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+type OIDCClient interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error)
+}
+
+type ContentFilter interface {
+	Load(rules []*entity.ContentFilterRule)
+	Apply(content string) *entity.ContentFilterResult
 }
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Секрет из RFC 6238 ("12345678901234567890") в base32
+const rfcTestSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPService_Validate_RFCVectors(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+
+	// Последние 6 цифр 8-значных значений из приложения B RFC 6238 (SHA1)
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, code := range vectors {
+		// Act
+		step, ok := service.Validate(code, rfcTestSecret, time.Unix(unix, 0))
+
+		// Assert
+		assert.True(t, ok, "expected code %s to be valid at %d", code, unix)
+		assert.Equal(t, unix/30, step)
+	}
+}
+
+func TestTOTPService_Validate_AllowsClockSkew(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+
+	// Act - код для t=59 проверяется на 30 секунд позже
+	step, ok := service.Validate("287082", rfcTestSecret, time.Unix(89, 0))
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+}
+
+func TestTOTPService_Validate_RejectsOutsideWindow(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+
+	// Act
+	_, ok := service.Validate("287082", rfcTestSecret, time.Unix(59+120, 0))
+
+	// Assert
+	assert.False(t, ok)
+}
+
+func TestTOTPService_Validate_InvalidInput(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+	now := time.Unix(59, 0)
+
+	// Act & Assert
+	_, ok := service.Validate("", rfcTestSecret, now)
+	assert.False(t, ok)
+
+	_, ok = service.Validate("28708", rfcTestSecret, now)
+	assert.False(t, ok)
+
+	_, ok = service.Validate("287082", "not-base32!", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPService_GenerateSecret(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+
+	// Act
+	secret1, err1 := service.GenerateSecret()
+	secret2, err2 := service.GenerateSecret()
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Len(t, secret1, 32) // 20 байт в base32 без паддинга
+	assert.NotEqual(t, secret1, secret2)
+	assert.Regexp(t, `^[A-Z2-7]+$`, secret1)
+}
+
+func TestTOTPService_BuildURI(t *testing.T) {
+	// Arrange
+	logger := newTestLogger()
+	service := NewTOTPService(logger)
+
+	// Act
+	uri := service.BuildURI("Chat Service", "user@example.com", "JBSWY3DPEHPK3PXP")
+
+	// Assert
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Chat%20Service:user@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Chat+Service")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,115 @@
+// Package tracing настраивает OpenTelemetry и создает спаны для обработчиков, usecase и адаптера БД.
+// Пока провайдер не установлен через Install, спаны ничего не записывают, поэтому слои можно
+// использовать без трассировки (например, в тестах)
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName имя инструментации, под которым сервис создает свои спаны
+const tracerName = "chat-service"
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Endpoint       string  // host:port коллектора OTLP/HTTP
+	URLPath        string  // путь приема спанов, по умолчанию /v1/traces
+	Insecure       bool    // отправлять по HTTP без TLS
+	SampleRatio    float64 // доля записываемых трасс для запросов без родительского спана
+	ExportTimeout  time.Duration
+}
+
+// NewOTLPExporter создает экспортер спанов в коллектор по протоколу OTLP/HTTP
+func NewOTLPExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(cfg.URLPath))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if cfg.ExportTimeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(cfg.ExportTimeout))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+	return exporter, nil
+}
+
+// NewProvider создает провайдер, который пакетами отправляет спаны в exporter.
+// Решение о записи трассы принимается по доле SampleRatio, а входящий traceparent с флагом
+// sampled сохраняет решение вызывающего сервиса
+func NewProvider(cfg Config, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	), nil
+}
+
+// Install делает provider глобальным и включает распространение контекста в формате W3C
+// (заголовки traceparent, tracestate и baggage)
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Start начинает дочерний спан; его нужно завершить через End
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer начинает серверный спан входящего HTTP запроса. Если клиент передал заголовок
+// traceparent, спан становится продолжением его трассы
+func StartServer(r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// End завершает спан, отмечая его ошибкой, если err не nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// UserID атрибут с ID пользователя, от имени которого выполняется операция
+func UserID(id uuid.UUID) attribute.KeyValue {
+	return semconv.EnduserID(id.String())
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// installTestProvider устанавливает провайдер, который синхронно пишет спаны в память
+func installTestProvider(t *testing.T, sampler sdktrace.Sampler) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSampler(sampler),
+	)
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	Install(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestStart_ChildSpan(t *testing.T) {
+	// Arrange
+	exporter := installTestProvider(t, sdktrace.AlwaysSample())
+	userID := uuid.New()
+
+	// Act
+	ctx, parent := Start(context.Background(), "MessageUsecase.CreateMessage", UserID(userID))
+	_, child := Start(ctx, "postgres.exec")
+	End(child, nil)
+	End(parent, nil)
+
+	// Assert
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	childSpan, parentSpan := spans[0], spans[1]
+	assert.Equal(t, "postgres.exec", childSpan.Name)
+	assert.Equal(t, parentSpan.SpanContext.SpanID(), childSpan.Parent.SpanID())
+	assert.Equal(t, parentSpan.SpanContext.TraceID(), childSpan.SpanContext.TraceID())
+	assert.Equal(t, userID.String(), attributeValue(parentSpan, "enduser.id"))
+	assert.Equal(t, codes.Unset, parentSpan.Status.Code)
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	// Arrange
+	exporter := installTestProvider(t, sdktrace.AlwaysSample())
+
+	// Act
+	_, span := Start(context.Background(), "postgres.query")
+	End(span, errors.New("connection reset"))
+
+	// Assert
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "connection reset", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
+
+func TestStartServer_ContinuesTraceparent(t *testing.T) {
+	// Arrange
+	exporter := installTestProvider(t, sdktrace.AlwaysSample())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	_, span := StartServer(req, "GET /api/v1/messages")
+	span.End()
+
+	// Assert
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.True(t, spans[0].Parent.IsRemote())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+}
+
+func TestSampler_RespectsParentDecision(t *testing.T) {
+	// Arrange: новые трассы не записываются, но решение клиента sampled сохраняется
+	exporter := installTestProvider(t, sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0)))
+
+	unsampled := httptest.NewRequest(http.MethodGet, "/health", nil)
+	sampled := httptest.NewRequest(http.MethodGet, "/health", nil)
+	sampled.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	_, span := StartServer(unsampled, "GET /health")
+	span.End()
+	_, span = StartServer(sampled, "GET /health")
+	span.End()
+
+	// Assert
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+}
@@ -0,0 +1,366 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminUsecase_ListUsers_AppliesDefaultsAndClearsPasswords(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	var gotFilter entity.UserFilter
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(admin),
+		ListFunc: func(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+			gotFilter = filter
+			return []*entity.User{{ID: uuid.New(), Password: "hash"}}, 7, nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	list, err := usecase.ListUsers(context.Background(), admin.ID, entity.UserFilter{Email: "  alice "})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, defaultListLimit, gotFilter.Limit)
+	assert.Equal(t, "alice", gotFilter.Email)
+	assert.Equal(t, 7, list.Total)
+	assert.Equal(t, defaultListLimit, list.Limit)
+	require.Len(t, list.Users, 1)
+	assert.Empty(t, list.Users[0].Password)
+}
+
+func TestAdminUsecase_ListUsers_InvalidFilter(t *testing.T) {
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		filter entity.UserFilter
+	}{
+		{"слишком большой лимит", entity.UserFilter{Limit: maxListLimit + 1}},
+		{"отрицательный лимит", entity.UserFilter{Limit: -1}},
+		{"отрицательное смещение", entity.UserFilter{Offset: -1}},
+		{"перепутан диапазон дат", entity.UserFilter{CreatedFrom: &from, CreatedTo: &to}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			userRepo := &mocks.UserRepoMock{
+				GetByIDFunc: mocks.UsersByID(admin),
+				ListFunc: func(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+					t.Fatal("list is not expected for invalid filter")
+					return nil, 0, nil
+				},
+			}
+
+			usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+			// Act
+			_, err := usecase.ListUsers(context.Background(), admin.ID, tt.filter)
+
+			// Assert
+			assert.Equal(t, apperror.CodeValidationFailed, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestAdminUsecase_RequiresPermission(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	target := &entity.User{ID: uuid.New()}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator, target)}
+
+	usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	_, listErr := usecase.ListUsers(context.Background(), moderator.ID, entity.UserFilter{})
+	_, suspendErr := usecase.SuspendUser(context.Background(), moderator.ID, target.ID, "spam")
+	deleteErr := usecase.DeleteUser(context.Background(), moderator.ID, target.ID)
+
+	// Assert
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(listErr))
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(suspendErr))
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(deleteErr))
+}
+
+func TestAdminUsecase_SuspendUser_RevokesSessions(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New(), Password: "hash"}
+
+	var suspendedAt *time.Time
+	var suspendedReason string
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(admin, target),
+		SetSuspendedFunc: func(ctx context.Context, id uuid.UUID, at *time.Time, reason string) error {
+			assert.Equal(t, target.ID, id)
+			suspendedAt, suspendedReason = at, reason
+			return nil
+		},
+	}
+	var revokedFor uuid.UUID
+	sessionRepo := &mocks.SessionRepoMock{
+		DeleteByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			revokedFor = userID
+			return nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, sessionRepo, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger).(*adminUsecase)
+	now := time.Now()
+	usecase.now = func() time.Time { return now }
+
+	// Act
+	user, err := usecase.SuspendUser(context.Background(), admin.ID, target.ID, " spam ")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, suspendedAt)
+	assert.Equal(t, now, *suspendedAt)
+	assert.Equal(t, "spam", suspendedReason)
+	assert.Equal(t, target.ID, revokedFor)
+	assert.True(t, user.IsSuspended())
+	assert.Empty(t, user.Password)
+}
+
+func TestAdminUsecase_SuspendUser_Self(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(admin),
+		SetSuspendedFunc: func(ctx context.Context, id uuid.UUID, at *time.Time, reason string) error {
+			t.Fatal("admin must not suspend own account")
+			return nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	_, err := usecase.SuspendUser(context.Background(), admin.ID, admin.ID, "")
+
+	// Assert
+	assert.Equal(t, apperror.CodeUserSelfAction, apperror.CodeOf(err))
+}
+
+func TestAdminUsecase_UnsuspendUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	suspendedAt := time.Now().Add(-time.Hour)
+	target := &entity.User{ID: uuid.New(), SuspendedAt: &suspendedAt, SuspensionReason: "spam"}
+
+	cleared := false
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(admin, target),
+		SetSuspendedFunc: func(ctx context.Context, id uuid.UUID, at *time.Time, reason string) error {
+			cleared = at == nil && reason == ""
+			return nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	user, err := usecase.UnsuspendUser(context.Background(), admin.ID, target.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	assert.False(t, user.IsSuspended())
+}
+
+func TestAdminUsecase_ForcePasswordReset(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New(), Email: "bob@example.com", Password: "old_hash"}
+
+	hashService := &mocks.HashServiceMock{
+		HashPasswordFunc: func(password string) (string, error) {
+			assert.NotEmpty(t, password)
+			return "new_hash", nil
+		},
+	}
+	var storedPassword string
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(admin, target),
+		UpdateFunc: func(ctx context.Context, user *entity.User) error {
+			storedPassword = user.Password
+			return nil
+		},
+	}
+	revoked := false
+	sessionRepo := &mocks.SessionRepoMock{
+		DeleteByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			revoked = userID == target.ID
+			return nil
+		},
+	}
+	var keysRevokedFor uuid.UUID
+	apiKeyRepo := &mocks.APIKeyRepoMock{
+		RevokeAllByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			keysRevokedFor = userID
+			return nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, sessionRepo, apiKeyRepo, hashService, logger)
+
+	// Act
+	user, err := usecase.ForcePasswordReset(context.Background(), admin.ID, target.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_hash", storedPassword)
+	assert.True(t, revoked)
+	assert.Equal(t, target.ID, keysRevokedFor)
+	assert.Equal(t, target.Email, user.Email)
+	assert.Empty(t, user.Password)
+}
+
+func TestAdminUsecase_DeleteUser(t *testing.T) {
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New()}
+
+	tests := []struct {
+		name         string
+		targetID     uuid.UUID
+		wantCode     string
+		wantNotFound bool
+	}{
+		{name: "удаление пользователя", targetID: target.ID},
+		{name: "удаление себя", targetID: admin.ID, wantCode: apperror.CodeUserSelfAction},
+		{name: "неизвестный пользователь", targetID: uuid.New(), wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var deletedID uuid.UUID
+			userRepo := &mocks.UserRepoMock{
+				GetByIDFunc: mocks.UsersByID(admin, target),
+				DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+					deletedID = id
+					return nil
+				},
+			}
+
+			usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, &mocks.APIKeyRepoMock{}, &mocks.HashServiceMock{}, logger)
+
+			// Act
+			err := usecase.DeleteUser(context.Background(), admin.ID, tt.targetID)
+
+			// Assert
+			if tt.wantNotFound {
+				var notFound *mocks.NotFoundError
+				assert.ErrorAs(t, err, &notFound)
+				assert.Equal(t, uuid.Nil, deletedID)
+				return
+			}
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+				assert.Equal(t, uuid.Nil, deletedID)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, target.ID, deletedID)
+		})
+	}
+}
+
+func TestAdminUsecase_ForceLogout(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New()}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(admin, target)}
+
+	revoked := false
+	sessionRepo := &mocks.SessionRepoMock{
+		DeleteByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			revoked = userID == target.ID
+			return nil
+		},
+	}
+	var keysRevokedFor uuid.UUID
+	apiKeyRepo := &mocks.APIKeyRepoMock{
+		RevokeAllByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			keysRevokedFor = userID
+			return nil
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, sessionRepo, apiKeyRepo, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	err := usecase.ForceLogout(context.Background(), admin.ID, target.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, target.ID, keysRevokedFor)
+}
+
+func TestAdminUsecase_ForceLogout_KeyRevocationFails(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New()}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(admin, target)}
+	apiKeyRepo := &mocks.APIKeyRepoMock{
+		RevokeAllByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+			return errors.New("database is down")
+		},
+	}
+
+	usecase := NewAdminUsecase(userRepo, &mocks.SessionRepoMock{}, apiKeyRepo, &mocks.HashServiceMock{}, logger)
+
+	// Act
+	err := usecase.ForceLogout(context.Background(), admin.ID, target.ID)
+
+	// Assert
+	assert.Error(t, err, "выход без отзыва ключей не считается выполненным")
+}
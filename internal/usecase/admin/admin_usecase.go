@@ -0,0 +1,18 @@
+package admin
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type AdminUsecase interface {
+	ListUsers(ctx context.Context, actorID uuid.UUID, filter entity.UserFilter) (*entity.UserList, error)
+	GetUser(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error)
+	SuspendUser(ctx context.Context, actorID, targetID uuid.UUID, reason string) (*entity.User, error)
+	UnsuspendUser(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error)
+	ForceLogout(ctx context.Context, actorID, targetID uuid.UUID) error
+	ForcePasswordReset(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error)
+	DeleteUser(ctx context.Context, actorID, targetID uuid.UUID) error
+}
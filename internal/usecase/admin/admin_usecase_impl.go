@@ -0,0 +1,326 @@
+package admin
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultListLimit   = 50
+	maxListLimit       = 100
+	maxReasonRunes     = 500
+	resetPasswordBytes = 32
+)
+
+type adminUsecase struct {
+	userRepo    usecase.UserRepository
+	sessionRepo usecase.SessionRepository
+	apiKeyRepo  usecase.APIKeyRepository
+	hashService service.HashService
+	logger      *logrus.Logger
+	now         func() time.Time
+}
+
+func NewAdminUsecase(
+	userRepo usecase.UserRepository,
+	sessionRepo usecase.SessionRepository,
+	apiKeyRepo usecase.APIKeyRepository,
+	hashService service.HashService,
+	logger *logrus.Logger,
+) AdminUsecase {
+	return &adminUsecase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		apiKeyRepo:  apiKeyRepo,
+		hashService: hashService,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// ListUsers возвращает страницу пользователей по фильтру, новые первыми
+func (a *adminUsecase) ListUsers(ctx context.Context, actorID uuid.UUID, filter entity.UserFilter) (*entity.UserList, error) {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.ListUsers", tracing.UserID(actorID))
+	defer span.End()
+
+	if err := a.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxListLimit {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "limit", fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	if filter.Offset < 0 {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "offset", "offset must not be negative")
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "created_from", "created_from must not be after created_to")
+	}
+	filter.Email = strings.TrimSpace(filter.Email)
+	filter.Username = strings.TrimSpace(filter.Username)
+
+	users, total, err := a.userRepo.List(ctx, filter)
+	if err != nil {
+		a.logger.WithContext(ctx).WithError(err).Error("failed to list users")
+		return nil, err
+	}
+
+	for _, user := range users {
+		user.Password = ""
+	}
+
+	return &entity.UserList{
+		Users:  users,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// GetUser возвращает пользователя по ID
+func (a *adminUsecase) GetUser(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.GetUser", tracing.UserID(actorID))
+	defer span.End()
+
+	if err := a.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		a.logger.WithContext(ctx).WithError(err).WithField("target_id", targetID).Warn("failed to fetch user")
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// SuspendUser блокирует пользователя и завершает все его сессии
+func (a *adminUsecase) SuspendUser(ctx context.Context, actorID, targetID uuid.UUID, reason string) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.SuspendUser", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+	})
+	logger.Info("user suspension requested")
+
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > maxReasonRunes {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "reason", fmt.Sprintf("reason must be at most %d characters", maxReasonRunes))
+	}
+
+	user, err := a.prepareTargetAction(ctx, actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	if err := a.userRepo.SetSuspended(ctx, targetID, &now, reason); err != nil {
+		logger.WithError(err).Error("failed to suspend user")
+		return nil, err
+	}
+
+	// Проверка блокировки в ValidateSession уже отсекает сессии, но удалять их все равно нужно,
+	// чтобы после разблокировки старые токены не заработали снова
+	if err := a.sessionRepo.DeleteByUserID(ctx, targetID); err != nil {
+		logger.WithError(err).Warn("failed to revoke sessions of suspended user")
+	}
+
+	user.SuspendedAt = &now
+	user.SuspensionReason = reason
+	user.Password = ""
+	logger.Info("user suspended")
+	return user, nil
+}
+
+// UnsuspendUser снимает блокировку
+func (a *adminUsecase) UnsuspendUser(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.UnsuspendUser", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+	})
+
+	if err := a.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to fetch user for unsuspension")
+		return nil, err
+	}
+
+	if user.IsSuspended() {
+		if err := a.userRepo.SetSuspended(ctx, targetID, nil, ""); err != nil {
+			logger.WithError(err).Error("failed to unsuspend user")
+			return nil, err
+		}
+		logger.Info("user unsuspended")
+	}
+
+	user.SuspendedAt = nil
+	user.SuspensionReason = ""
+	user.Password = ""
+	return user, nil
+}
+
+// ForceLogout завершает все сессии пользователя и отзывает его API ключи
+func (a *adminUsecase) ForceLogout(ctx context.Context, actorID, targetID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.ForceLogout", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+	})
+
+	if _, err := a.prepareTargetAction(ctx, actorID, targetID); err != nil {
+		return err
+	}
+
+	if err := a.sessionRepo.DeleteByUserID(ctx, targetID); err != nil {
+		logger.WithError(err).Error("failed to revoke user sessions")
+		return err
+	}
+
+	// Ключ действует как сессия без срока действия, иначе выход не отрезал бы доступ
+	if err := a.apiKeyRepo.RevokeAllByUserID(ctx, targetID); err != nil {
+		logger.WithError(err).Error("failed to revoke user api keys")
+		return err
+	}
+
+	logger.Info("user logged out by admin")
+	return nil
+}
+
+// ForcePasswordReset заменяет пароль случайным, завершает сессии и отзывает API ключи.
+// Войти по старому паролю больше нельзя, новый пользователь задает через сброс пароля
+func (a *adminUsecase) ForcePasswordReset(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.ForcePasswordReset", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+	})
+
+	user, err := a.prepareTargetAction(ctx, actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := service.GenerateRandomToken(resetPasswordBytes)
+	if err != nil {
+		logger.WithError(err).Error("failed to generate password")
+		return nil, err
+	}
+	hashedPassword, err := a.hashService.HashPassword(password)
+	if err != nil {
+		logger.WithError(err).Error("failed to hash password")
+		return nil, err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = a.now()
+	if err := a.userRepo.Update(ctx, user); err != nil {
+		logger.WithError(err).Error("failed to invalidate user password")
+		return nil, err
+	}
+
+	if err := a.sessionRepo.DeleteByUserID(ctx, targetID); err != nil {
+		logger.WithError(err).Warn("failed to revoke sessions after forced password reset")
+	}
+	if err := a.apiKeyRepo.RevokeAllByUserID(ctx, targetID); err != nil {
+		logger.WithError(err).Warn("failed to revoke api keys after forced password reset")
+	}
+
+	user.Password = ""
+	logger.Info("user password reset by admin")
+	return user, nil
+}
+
+// DeleteUser безвозвратно удаляет пользователя вместе с его данными
+func (a *adminUsecase) DeleteUser(ctx context.Context, actorID, targetID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "AdminUsecase.DeleteUser", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+	})
+
+	if _, err := a.prepareTargetAction(ctx, actorID, targetID); err != nil {
+		return err
+	}
+
+	if err := a.userRepo.Delete(ctx, targetID); err != nil {
+		logger.WithError(err).Error("failed to delete user")
+		return err
+	}
+
+	logger.Info("user deleted by admin")
+	return nil
+}
+
+// authorize проверяет право администратора по текущей роли из БД
+func (a *adminUsecase) authorize(ctx context.Context, actorID uuid.UUID) error {
+	actor, err := a.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		if isNotFound(err) {
+			return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+		}
+		a.logger.WithContext(ctx).WithError(err).WithField("actor_id", actorID).Error("failed to fetch actor")
+		return err
+	}
+	if !actor.Role.Can(entity.PermissionUsersManage) {
+		a.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"actor_id": actorID,
+			"role":     actor.Role,
+		}).Warn("permission denied")
+		return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+// prepareTargetAction проверяет права и загружает пользователя для действия над ним.
+// Над собой такие действия запрещены, чтобы администратор не заблокировал себя случайно
+func (a *adminUsecase) prepareTargetAction(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error) {
+	if err := a.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+	if actorID == targetID {
+		return nil, apperror.Validation(apperror.CodeUserSelfAction, "this action cannot be applied to your own account")
+	}
+
+	user, err := a.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		a.logger.WithContext(ctx).WithError(err).WithField("target_id", targetID).Warn("failed to fetch target user")
+		return nil, err
+	}
+	return user, nil
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
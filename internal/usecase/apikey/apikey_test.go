@@ -0,0 +1,205 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUsecase(repo *mocks.APIKeyRepoMock, config Config) *apiKeyUsecase {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id}, nil
+		},
+	}
+
+	return NewAPIKeyUsecase(repo, userRepo, config, logger).(*apiKeyUsecase)
+}
+
+func TestAPIKeyUsecase_CreateKey_StoresHash(t *testing.T) {
+	// Arrange
+	repo := &mocks.APIKeyRepoMock{}
+	uc := newTestUsecase(repo, Config{MaxKeysPerUser: 5})
+
+	userID := uuid.New()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	var stored *entity.APIKey
+
+	repo.CreateFunc = func(ctx context.Context, key *entity.APIKey) error {
+		stored = key
+		return nil
+	}
+
+	// Act
+	key, token, err := uc.CreateKey(context.Background(), userID, " deploy bot ",
+		[]string{entity.ScopeMessagesWrite, entity.ScopeMessagesWrite, entity.ScopeMessagesRead}, &expiresAt)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, key, stored)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+	assert.Equal(t, service.HashToken(token), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, token)
+	assert.True(t, strings.HasPrefix(token, stored.Prefix))
+	assert.Len(t, stored.Prefix, len(TokenPrefix)+8)
+	assert.Equal(t, "deploy bot", stored.Name)
+	assert.Equal(t, []string{entity.ScopeMessagesWrite, entity.ScopeMessagesRead}, stored.Scopes)
+	assert.Equal(t, userID, stored.UserID)
+}
+
+func TestAPIKeyUsecase_CreateKey_Validation(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	farFuture := time.Now().Add(365 * 24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		config    Config
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		active    int
+		wantCode  string
+	}{
+		{"пустое имя", Config{}, " ", []string{entity.ScopeMessagesRead}, nil, 0, apperror.CodeValidationFailed},
+		{"без прав", Config{}, "bot", nil, nil, 0, apperror.CodeValidationFailed},
+		{"неизвестное право", Config{}, "bot", []string{"admin"}, nil, 0, apperror.CodeValidationFailed},
+		{"срок в прошлом", Config{}, "bot", []string{entity.ScopeMessagesRead}, &past, 0, apperror.CodeValidationFailed},
+		{"бессрочный ключ при MaxTTL", Config{MaxTTL: 90 * 24 * time.Hour}, "bot", []string{entity.ScopeMessagesRead}, nil, 0, apperror.CodeValidationFailed},
+		{"срок больше MaxTTL", Config{MaxTTL: 90 * 24 * time.Hour}, "bot", []string{entity.ScopeMessagesRead}, &farFuture, 0, apperror.CodeValidationFailed},
+		{"превышен лимит ключей", Config{MaxKeysPerUser: 2}, "bot", []string{entity.ScopeMessagesRead}, nil, 2, apperror.CodeAPIKeyLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mocks.APIKeyRepoMock{}
+			uc := newTestUsecase(repo, tt.config)
+
+			repo.CountActiveByUserIDFunc = func(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+				return tt.active, nil
+			}
+			repo.CreateFunc = func(ctx context.Context, key *entity.APIKey) error {
+				t.Fatal("key must not be stored")
+				return nil
+			}
+
+			// Act
+			_, _, err := uc.CreateKey(context.Background(), uuid.New(), tt.keyName, tt.scopes, tt.expiresAt)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	recent := now.Add(-10 * time.Second)
+
+	tests := []struct {
+		name        string
+		token       string
+		key         *entity.APIKey
+		suspended   bool
+		wantCode    string
+		wantTouched bool
+	}{
+		{"активный ключ", TokenPrefix + "secret", &entity.APIKey{ID: uuid.New()}, false, "", true},
+		{"недавно использованный ключ", TokenPrefix + "secret", &entity.APIKey{ID: uuid.New(), LastUsedAt: &recent}, false, "", false},
+		{"отозванный ключ", TokenPrefix + "secret", &entity.APIKey{ID: uuid.New(), RevokedAt: &past}, false, apperror.CodeAPIKeyRevoked, false},
+		{"истекший ключ", TokenPrefix + "secret", &entity.APIKey{ID: uuid.New(), ExpiresAt: &past}, false, apperror.CodeAPIKeyRevoked, false},
+		{"владелец заблокирован", TokenPrefix + "secret", &entity.APIKey{ID: uuid.New()}, true, apperror.CodeAccountSuspended, false},
+		{"неизвестный ключ", TokenPrefix + "secret", nil, false, apperror.CodeAPIKeyInvalid, false},
+		{"не API ключ", "eyJhbGciOiJIUzI1NiJ9", nil, false, apperror.CodeAPIKeyInvalid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mocks.APIKeyRepoMock{}
+			uc := newTestUsecase(repo, Config{})
+			uc.now = func() time.Time { return now }
+			if tt.suspended {
+				uc.userRepo = &mocks.UserRepoMock{
+					GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+						return &entity.User{ID: id, SuspendedAt: &past}, nil
+					},
+				}
+			}
+
+			touched := false
+			repo.GetByHashFunc = func(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+				assert.Equal(t, service.HashToken(tt.token), keyHash)
+				if tt.key == nil {
+					return nil, &NotFoundError{"api key not found"}
+				}
+				return tt.key, nil
+			}
+			repo.TouchLastUsedFunc = func(ctx context.Context, id uuid.UUID, at time.Time) error {
+				touched = true
+				return nil
+			}
+
+			// Act
+			key, owner, err := uc.Authenticate(context.Background(), tt.token)
+
+			// Assert
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+				assert.Nil(t, key)
+				assert.Nil(t, owner)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.key.ID, key.ID)
+				require.NotNil(t, owner)
+				assert.Equal(t, tt.key.UserID, owner.ID)
+			}
+			assert.Equal(t, tt.wantTouched, touched)
+		})
+	}
+}
+
+func TestAPIKeyUsecase_RevokeKey_NotOwned(t *testing.T) {
+	// Arrange
+	repo := &mocks.APIKeyRepoMock{}
+	uc := newTestUsecase(repo, Config{})
+
+	repo.RevokeFunc = func(ctx context.Context, id, userID uuid.UUID) error {
+		return &NotFoundError{"api key not found"}
+	}
+
+	// Act
+	err := uc.RevokeKey(context.Background(), uuid.New(), uuid.New())
+
+	// Assert
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
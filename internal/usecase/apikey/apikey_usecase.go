@@ -0,0 +1,16 @@
+package apikey
+
+import (
+	"chat-service/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyUsecase interface {
+	CreateKey(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error)
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error)
+	RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error
+	Authenticate(ctx context.Context, token string) (*entity.APIKey, *entity.User, error)
+}
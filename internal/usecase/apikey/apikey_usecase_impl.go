@@ -0,0 +1,227 @@
+package apikey
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config ограничения на персональные API ключи
+type Config struct {
+	MaxKeysPerUser int
+	MaxTTL         time.Duration // 0 — ключи без срока действия разрешены
+}
+
+const (
+	// TokenPrefix отличает API ключ от JWT сессии в заголовке Authorization
+	TokenPrefix = "csk_"
+
+	keySecretSize   = 32
+	visiblePrefix   = len(TokenPrefix) + 8
+	lastUsedEvery   = time.Minute // last_used_at обновляется не чаще, чтобы не писать в БД на каждый запрос
+	maxKeyNameRunes = 100
+)
+
+type apiKeyUsecase struct {
+	apiKeyRepo usecase.APIKeyRepository
+	userRepo   usecase.UserRepository
+	config     Config
+	logger     *logrus.Logger
+	now        func() time.Time
+}
+
+func NewAPIKeyUsecase(
+	apiKeyRepo usecase.APIKeyRepository,
+	userRepo usecase.UserRepository,
+	config Config,
+	logger *logrus.Logger,
+) APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// CreateKey выпускает ключ. Открытое значение возвращается только здесь, в БД хранится его хэш
+func (a *apiKeyUsecase) CreateKey(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.CreateKey", tracing.UserID(userID))
+	defer span.End()
+
+	logger := a.logger.WithContext(ctx).WithField("user_id", userID)
+	logger.Info("creating api key")
+
+	now := a.now()
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxKeyNameRunes {
+		return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "name", fmt.Sprintf("name is required and must be at most %d characters", maxKeyNameRunes))
+	}
+
+	scopes = normalizeScopes(scopes)
+	if len(scopes) == 0 {
+		return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "scopes", "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(entity.APIKeyScopes, scope) {
+			return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "scopes", "unknown scope: "+scope)
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "expires_at", "expires_at must be in the future")
+	}
+	if a.config.MaxTTL > 0 {
+		limit := now.Add(a.config.MaxTTL)
+		if expiresAt == nil || expiresAt.After(limit) {
+			return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "expires_at", fmt.Sprintf("expires_at must be set and be at most %s from now", a.config.MaxTTL))
+		}
+	}
+
+	if a.config.MaxKeysPerUser > 0 {
+		count, err := a.apiKeyRepo.CountActiveByUserID(ctx, userID, now)
+		if err != nil {
+			logger.WithError(err).Error("failed to count api keys")
+			return nil, "", err
+		}
+		if count >= a.config.MaxKeysPerUser {
+			logger.Warn("api key limit reached")
+			return nil, "", apperror.Conflict(apperror.CodeAPIKeyLimitReached, fmt.Sprintf("api key limit reached: at most %d active keys", a.config.MaxKeysPerUser))
+		}
+	}
+
+	secret, err := service.GenerateRandomToken(keySecretSize)
+	if err != nil {
+		logger.WithError(err).Error("failed to generate api key")
+		return nil, "", err
+	}
+	token := TokenPrefix + secret
+
+	key := &entity.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:visiblePrefix],
+		KeyHash:   service.HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if err := a.apiKeyRepo.Create(ctx, key); err != nil {
+		logger.WithError(err).Error("failed to store api key")
+		return nil, "", err
+	}
+
+	logger.WithField("api_key_id", key.ID).Info("api key created")
+	return key, token, nil
+}
+
+func (a *apiKeyUsecase) ListKeys(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.ListKeys", tracing.UserID(userID))
+	defer span.End()
+
+	keys, err := a.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		a.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to list api keys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (a *apiKeyUsecase) RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.RevokeKey", tracing.UserID(userID))
+	defer span.End()
+
+	if err := a.apiKeyRepo.Revoke(ctx, keyID, userID); err != nil {
+		a.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"user_id":    userID,
+			"api_key_id": keyID,
+		}).Warn("failed to revoke api key")
+		return err
+	}
+
+	a.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":    userID,
+		"api_key_id": keyID,
+	}).Info("api key revoked")
+	return nil
+}
+
+// Authenticate находит активный ключ по его значению и возвращает его вместе с владельцем
+func (a *apiKeyUsecase) Authenticate(ctx context.Context, token string) (*entity.APIKey, *entity.User, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUsecase.Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, nil, apperror.Unauthorized(apperror.CodeAPIKeyInvalid, "invalid api key")
+	}
+
+	key, err := a.apiKeyRepo.GetByHash(ctx, service.HashToken(token))
+	if err != nil {
+		if isNotFound(err) {
+			a.logger.WithContext(ctx).Warn("unknown api key presented")
+			return nil, nil, apperror.Unauthorized(apperror.CodeAPIKeyInvalid, "invalid api key")
+		}
+		a.logger.WithContext(ctx).WithError(err).Error("failed to look up api key")
+		return nil, nil, err
+	}
+
+	now := a.now()
+	if !key.IsActive(now) {
+		a.logger.WithContext(ctx).WithField("api_key_id", key.ID).Warn("revoked or expired api key presented")
+		return nil, nil, apperror.Unauthorized(apperror.CodeAPIKeyRevoked, "api key is revoked or expired")
+	}
+
+	// Ключи заблокированного пользователя не отзываются, но перестают работать до разблокировки
+	owner, err := a.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil, apperror.Unauthorized(apperror.CodeAPIKeyInvalid, "invalid api key")
+		}
+		a.logger.WithContext(ctx).WithError(err).WithField("api_key_id", key.ID).Error("failed to fetch api key owner")
+		return nil, nil, err
+	}
+	if owner.IsSuspended() {
+		a.logger.WithContext(ctx).WithField("user_id", owner.ID).Warn("api key of suspended user presented")
+		return nil, nil, apperror.Forbidden(apperror.CodeAccountSuspended, "account is suspended")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedEvery {
+		if err := a.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			a.logger.WithContext(ctx).WithError(err).WithField("api_key_id", key.ID).Warn("failed to update api key last use")
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, owner, nil
+}
+
+// normalizeScopes убирает пробелы и дубликаты, сохраняя порядок
+func normalizeScopes(scopes []string) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope != "" && !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
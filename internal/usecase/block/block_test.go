@@ -0,0 +1,168 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBlockUsecase_BlockUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	blocker := &entity.User{ID: uuid.New(), Username: "alice"}
+	target := &entity.User{ID: uuid.New(), Username: "mallory"}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(blocker, target)}
+	var created *entity.UserBlock
+	blockRepo := &mocks.UserBlockRepoMock{
+		CreateFunc: func(ctx context.Context, block *entity.UserBlock) error {
+			created = block
+			return nil
+		},
+	}
+
+	usecase := NewBlockUsecase(blockRepo, userRepo, logger).(*blockUsecase)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	usecase.now = func() time.Time { return now }
+
+	// Act
+	block, err := usecase.BlockUser(context.Background(), blocker.ID, target.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created, block)
+	assert.Equal(t, blocker.ID, block.BlockerID)
+	assert.Equal(t, target.ID, block.BlockedID)
+	assert.Equal(t, "mallory", block.BlockedUsername)
+	assert.Equal(t, now, block.CreatedAt)
+}
+
+func TestBlockUsecase_BlockUser_Errors(t *testing.T) {
+	blocker := &entity.User{ID: uuid.New(), Username: "alice"}
+
+	tests := []struct {
+		name      string
+		blockedID uuid.UUID
+		wantCode  string
+	}{
+		{"блокировка самого себя", blocker.ID, apperror.CodeBlockSelf},
+		{"несуществующий пользователь", uuid.New(), apperror.CodeUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(blocker)}
+			blockRepo := &mocks.UserBlockRepoMock{
+				CreateFunc: func(ctx context.Context, block *entity.UserBlock) error {
+					t.Fatal("block must not be saved")
+					return nil
+				},
+			}
+
+			usecase := NewBlockUsecase(blockRepo, userRepo, logger)
+
+			// Act
+			block, err := usecase.BlockUser(context.Background(), blocker.ID, tt.blockedID)
+
+			// Assert
+			assert.Nil(t, block)
+			assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestBlockUsecase_UnblockUser_NotBlocked(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	blockRepo := &mocks.UserBlockRepoMock{
+		DeleteFunc: func(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+			return &mocks.NotFoundError{Message: "user is not blocked"}
+		},
+	}
+
+	usecase := NewBlockUsecase(blockRepo, &mocks.UserRepoMock{}, logger)
+
+	// Act
+	err := usecase.UnblockUser(context.Background(), uuid.New(), uuid.New())
+
+	// Assert
+	assert.True(t, isNotFound(err))
+}
+
+func TestBlockUsecase_ListBlocks_RepositoryError(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	blockRepo := &mocks.UserBlockRepoMock{
+		ListByBlockerFunc: func(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	usecase := NewBlockUsecase(blockRepo, &mocks.UserRepoMock{}, logger)
+
+	// Act
+	blocks, err := usecase.ListBlocks(context.Background(), uuid.New())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, blocks)
+}
+
+func TestBlockUsecase_BlockUser_Span(t *testing.T) {
+	// Arrange: спаны пишутся в память вместо коллектора
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	blocker := &entity.User{ID: uuid.New(), Username: "alice"}
+	target := &entity.User{ID: uuid.New(), Username: "mallory"}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(blocker, target)}
+	var repoSpan trace.SpanContext
+	blockRepo := &mocks.UserBlockRepoMock{
+		CreateFunc: func(ctx context.Context, block *entity.UserBlock) error {
+			repoSpan = trace.SpanContextFromContext(ctx)
+			return nil
+		},
+	}
+
+	usecase := NewBlockUsecase(blockRepo, userRepo, logger)
+
+	// Act
+	_, err := usecase.BlockUser(context.Background(), blocker.ID, target.ID)
+
+	// Assert: репозиторий получает контекст спана usecase
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "BlockUsecase.BlockUser", spans[0].Name)
+	assert.Equal(t, spans[0].SpanContext.SpanID(), repoSpan.SpanID())
+	assert.Contains(t, spans[0].Attributes, tracing.UserID(blocker.ID))
+}
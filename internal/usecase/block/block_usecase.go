@@ -0,0 +1,14 @@
+package block
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type BlockUsecase interface {
+	BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) (*entity.UserBlock, error)
+	UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error)
+}
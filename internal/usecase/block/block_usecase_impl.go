@@ -0,0 +1,111 @@
+package block
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type blockUsecase struct {
+	blockRepo usecase.UserBlockRepository
+	userRepo  usecase.UserRepository
+	logger    *logrus.Logger
+	now       func() time.Time
+}
+
+func NewBlockUsecase(
+	blockRepo usecase.UserBlockRepository,
+	userRepo usecase.UserRepository,
+	logger *logrus.Logger,
+) BlockUsecase {
+	return &blockUsecase{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// BlockUser блокирует пользователя. Повторная блокировка не ошибка
+func (b *blockUsecase) BlockUser(ctx context.Context, blockerID, blockedID uuid.UUID) (*entity.UserBlock, error) {
+	ctx, span := tracing.Start(ctx, "BlockUsecase.BlockUser", tracing.UserID(blockerID))
+	defer span.End()
+
+	logger := b.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"blocker_id": blockerID,
+		"blocked_id": blockedID,
+	})
+
+	if blockerID == blockedID {
+		logger.Warn("user trying to block themselves")
+		return nil, apperror.Validation(apperror.CodeBlockSelf, "you cannot block yourself")
+	}
+
+	blocked, err := b.userRepo.GetByID(ctx, blockedID)
+	if err != nil {
+		if isNotFound(err) {
+			logger.Warn("user to block not found")
+			return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found")
+		}
+		logger.WithError(err).Error("failed to fetch user to block")
+		return nil, err
+	}
+
+	block := &entity.UserBlock{
+		BlockerID:       blockerID,
+		BlockedID:       blockedID,
+		BlockedUsername: blocked.Username,
+		CreatedAt:       b.now(),
+	}
+	if err := b.blockRepo.Create(ctx, block); err != nil {
+		logger.WithError(err).Error("failed to block user")
+		return nil, err
+	}
+
+	logger.Info("user blocked")
+	return block, nil
+}
+
+// UnblockUser снимает блокировку; если пользователь не заблокирован, возвращает NotFound
+func (b *blockUsecase) UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "BlockUsecase.UnblockUser", tracing.UserID(blockerID))
+	defer span.End()
+
+	logger := b.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"blocker_id": blockerID,
+		"blocked_id": blockedID,
+	})
+
+	if err := b.blockRepo.Delete(ctx, blockerID, blockedID); err != nil {
+		logger.WithError(err).Warn("failed to unblock user")
+		return err
+	}
+
+	logger.Info("user unblocked")
+	return nil
+}
+
+// ListBlocks возвращает пользователей, заблокированных blockerID, новые первыми
+func (b *blockUsecase) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error) {
+	ctx, span := tracing.Start(ctx, "BlockUsecase.ListBlocks", tracing.UserID(blockerID))
+	defer span.End()
+
+	blocks, err := b.blockRepo.ListByBlocker(ctx, blockerID)
+	if err != nil {
+		b.logger.WithContext(ctx).WithError(err).WithField("blocker_id", blockerID).Error("failed to list user blocks")
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
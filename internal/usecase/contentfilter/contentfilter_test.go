@@ -0,0 +1,284 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentFilterUsecase_RequiresPermission(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator)}
+	var loaded [][]*entity.ContentFilterRule
+	filter := &mocks.ContentFilterMock{
+		LoadFunc: func(rules []*entity.ContentFilterRule) {
+			loaded = append(loaded, rules)
+		},
+	}
+
+	usecase := NewContentFilterUsecase(&mocks.ContentFilterRuleRepoMock{}, userRepo, filter, logger)
+
+	// Act
+	_, listErr := usecase.ListRules(context.Background(), moderator.ID)
+	_, createErr := usecase.CreateRule(context.Background(), moderator.ID, &entity.ContentFilterRule{})
+	reloadErr := usecase.ReloadRules(context.Background(), uuid.New())
+
+	// Assert
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(listErr))
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(createErr))
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(reloadErr))
+	assert.Empty(t, loaded)
+}
+
+func TestContentFilterUsecase_CreateRule(t *testing.T) {
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		draft   *entity.ContentFilterRule
+		wantErr interface{}
+	}{
+		{
+			"слово",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindWord, Pattern: "darn", Action: entity.ContentFilterActionMask, Enabled: true},
+			nil,
+		},
+		{
+			"регулярное выражение",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindRegex, Pattern: `buy\s+now`, Action: entity.ContentFilterActionFlag},
+			nil,
+		},
+		{
+			"слово из 500 букв кириллицы",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindWord, Pattern: strings.Repeat("ж", 500), Action: entity.ContentFilterActionReject},
+			nil,
+		},
+		{
+			"слишком длинный шаблон",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindWord, Pattern: strings.Repeat("ж", 501), Action: entity.ContentFilterActionReject},
+			&entity.ValidationError{},
+		},
+		{
+			"некорректное выражение",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindRegex, Pattern: "(", Action: entity.ContentFilterActionReject},
+			&entity.ValidationError{},
+		},
+		{
+			"неизвестное действие",
+			&entity.ContentFilterRule{Kind: entity.ContentFilterKindWord, Pattern: "darn", Action: "ban"},
+			&entity.ValidationError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(admin)}
+			var created *entity.ContentFilterRule
+			ruleRepo := &mocks.ContentFilterRuleRepoMock{
+				CreateFunc: func(ctx context.Context, rule *entity.ContentFilterRule) error {
+					created = rule
+					return nil
+				},
+			}
+			var loaded [][]*entity.ContentFilterRule
+			filter := &mocks.ContentFilterMock{
+				LoadFunc: func(rules []*entity.ContentFilterRule) {
+					loaded = append(loaded, rules)
+				},
+			}
+
+			usecase := NewContentFilterUsecase(ruleRepo, userRepo, filter, logger)
+
+			// Act
+			rule, err := usecase.CreateRule(context.Background(), admin.ID, tt.draft)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Nil(t, created)
+				assert.Empty(t, loaded)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, created, rule)
+			assert.Equal(t, admin.ID, *rule.CreatedBy)
+			assert.Len(t, loaded, 1, "фильтр должен перечитать правила после изменения")
+		})
+	}
+}
+
+func TestContentFilterUsecase_UpdateRule(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	existing := &entity.ContentFilterRule{
+		ID: uuid.New(), Kind: entity.ContentFilterKindWord, Pattern: "darn",
+		Action: entity.ContentFilterActionMask, Enabled: true, CreatedAt: time.Now().Add(-time.Hour),
+	}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(admin)}
+	var updated *entity.ContentFilterRule
+	ruleRepo := &mocks.ContentFilterRuleRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.ContentFilterRule, error) {
+			if id != existing.ID {
+				return nil, &mocks.NotFoundError{Message: "content filter rule not found"}
+			}
+			copied := *existing
+			return &copied, nil
+		},
+		UpdateFunc: func(ctx context.Context, rule *entity.ContentFilterRule) error {
+			updated = rule
+			return nil
+		},
+	}
+	var loaded [][]*entity.ContentFilterRule
+	filter := &mocks.ContentFilterMock{
+		LoadFunc: func(rules []*entity.ContentFilterRule) {
+			loaded = append(loaded, rules)
+		},
+	}
+
+	usecase := NewContentFilterUsecase(ruleRepo, userRepo, filter, logger)
+
+	// Act
+	rule, err := usecase.UpdateRule(context.Background(), admin.ID, existing.ID, &entity.ContentFilterRule{
+		Kind: entity.ContentFilterKindWord, Pattern: "darn", Action: entity.ContentFilterActionReject, Description: "  hard ban ",
+	})
+	_, missingErr := usecase.UpdateRule(context.Background(), admin.ID, uuid.New(), existing)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, updated, rule)
+	assert.Equal(t, entity.ContentFilterActionReject, rule.Action)
+	assert.False(t, rule.Enabled)
+	assert.Equal(t, "hard ban", rule.Description)
+	assert.Equal(t, existing.CreatedAt, rule.CreatedAt)
+	assert.Len(t, loaded, 1)
+
+	var notFound *mocks.NotFoundError
+	assert.ErrorAs(t, missingErr, &notFound)
+}
+
+func TestContentFilterUsecase_DeleteRule_ReloadsFilter(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(admin)}
+	var deletedID uuid.UUID
+	ruleRepo := &mocks.ContentFilterRuleRepoMock{
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deletedID = id
+			return nil
+		},
+	}
+	var loaded [][]*entity.ContentFilterRule
+	filter := &mocks.ContentFilterMock{
+		LoadFunc: func(rules []*entity.ContentFilterRule) {
+			loaded = append(loaded, rules)
+		},
+	}
+	ruleID := uuid.New()
+
+	usecase := NewContentFilterUsecase(ruleRepo, userRepo, filter, logger)
+
+	// Act
+	err := usecase.DeleteRule(context.Background(), admin.ID, ruleID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ruleID, deletedID)
+	assert.Len(t, loaded, 1)
+}
+
+func TestContentFilterUsecase_Reload_KeepsRulesOnError(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	ruleRepo := &mocks.ContentFilterRuleRepoMock{
+		ListFunc: func(ctx context.Context) ([]*entity.ContentFilterRule, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	var loaded [][]*entity.ContentFilterRule
+	filter := &mocks.ContentFilterMock{
+		LoadFunc: func(rules []*entity.ContentFilterRule) {
+			loaded = append(loaded, rules)
+		},
+	}
+
+	usecase := NewContentFilterUsecase(ruleRepo, &mocks.UserRepoMock{}, filter, logger)
+
+	// Act
+	err := usecase.Reload(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Empty(t, loaded, "при ошибке фильтр не должен получить пустой набор правил")
+}
+
+func TestContentFilterUsecase_WatchRules(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	ruleRepo := &mocks.ContentFilterRuleRepoMock{
+		ListFunc: func(ctx context.Context) ([]*entity.ContentFilterRule, error) {
+			return []*entity.ContentFilterRule{{ID: uuid.New()}}, nil
+		},
+	}
+	reloaded := make(chan struct{}, 1)
+	filter := &mocks.ContentFilterMock{
+		LoadFunc: func(loaded []*entity.ContentFilterRule) {
+			select {
+			case reloaded <- struct{}{}:
+			default:
+			}
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	usecase := NewContentFilterUsecase(ruleRepo, &mocks.UserRepoMock{}, filter, logger)
+
+	// Act
+	go func() {
+		usecase.WatchRules(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	// Assert
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("rules were not reloaded")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,19 @@
+package contentfilter
+
+import (
+	"chat-service/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ContentFilterUsecase interface {
+	ListRules(ctx context.Context, actorID uuid.UUID) ([]*entity.ContentFilterRule, error)
+	CreateRule(ctx context.Context, actorID uuid.UUID, draft *entity.ContentFilterRule) (*entity.ContentFilterRule, error)
+	UpdateRule(ctx context.Context, actorID, ruleID uuid.UUID, changes *entity.ContentFilterRule) (*entity.ContentFilterRule, error)
+	DeleteRule(ctx context.Context, actorID, ruleID uuid.UUID) error
+	ReloadRules(ctx context.Context, actorID uuid.UUID) error
+	Reload(ctx context.Context) error
+	WatchRules(ctx context.Context, interval time.Duration)
+}
@@ -0,0 +1,244 @@
+package contentfilter
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const maxDescriptionRunes = 500
+
+type contentFilterUsecase struct {
+	ruleRepo usecase.ContentFilterRuleRepository
+	userRepo usecase.UserRepository
+	filter   service.ContentFilter
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewContentFilterUsecase(
+	ruleRepo usecase.ContentFilterRuleRepository,
+	userRepo usecase.UserRepository,
+	filter service.ContentFilter,
+	logger *logrus.Logger,
+) ContentFilterUsecase {
+	return &contentFilterUsecase{
+		ruleRepo: ruleRepo,
+		userRepo: userRepo,
+		filter:   filter,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// ListRules возвращает все правила, включая выключенные
+func (c *contentFilterUsecase) ListRules(ctx context.Context, actorID uuid.UUID) ([]*entity.ContentFilterRule, error) {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.ListRules", tracing.UserID(actorID))
+	defer span.End()
+
+	if err := c.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	rules, err := c.ruleRepo.List(ctx)
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).Error("failed to list content filter rules")
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateRule сохраняет новое правило и сразу применяет его к фильтру
+func (c *contentFilterUsecase) CreateRule(ctx context.Context, actorID uuid.UUID, draft *entity.ContentFilterRule) (*entity.ContentFilterRule, error) {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.CreateRule", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := c.logger.WithContext(ctx).WithField("actor_id", actorID)
+
+	if err := c.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	rule := &entity.ContentFilterRule{
+		ID:          uuid.New(),
+		Kind:        draft.Kind,
+		Pattern:     draft.Pattern,
+		Action:      draft.Action,
+		Enabled:     draft.Enabled,
+		Description: strings.TrimSpace(draft.Description),
+		CreatedBy:   &actorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := validateRule(rule); err != nil {
+		logger.WithError(err).Warn("content filter rule validation failed")
+		return nil, err
+	}
+
+	if err := c.ruleRepo.Create(ctx, rule); err != nil {
+		logger.WithError(err).Error("failed to create content filter rule")
+		return nil, err
+	}
+
+	logger.WithField("rule_id", rule.ID).Info("content filter rule created")
+	c.reloadAfterChange(ctx)
+	return rule, nil
+}
+
+// UpdateRule заменяет поля правила и сразу применяет изменения к фильтру
+func (c *contentFilterUsecase) UpdateRule(ctx context.Context, actorID, ruleID uuid.UUID, changes *entity.ContentFilterRule) (*entity.ContentFilterRule, error) {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.UpdateRule", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"rule_id":  ruleID,
+	})
+
+	if err := c.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	rule, err := c.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to fetch content filter rule")
+		return nil, err
+	}
+
+	rule.Kind = changes.Kind
+	rule.Pattern = changes.Pattern
+	rule.Action = changes.Action
+	rule.Enabled = changes.Enabled
+	rule.Description = strings.TrimSpace(changes.Description)
+	rule.UpdatedAt = c.now()
+	if err := validateRule(rule); err != nil {
+		logger.WithError(err).Warn("content filter rule validation failed")
+		return nil, err
+	}
+
+	if err := c.ruleRepo.Update(ctx, rule); err != nil {
+		logger.WithError(err).Error("failed to update content filter rule")
+		return nil, err
+	}
+
+	logger.Info("content filter rule updated")
+	c.reloadAfterChange(ctx)
+	return rule, nil
+}
+
+// DeleteRule удаляет правило и сразу убирает его из фильтра
+func (c *contentFilterUsecase) DeleteRule(ctx context.Context, actorID, ruleID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.DeleteRule", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"rule_id":  ruleID,
+	})
+
+	if err := c.authorize(ctx, actorID); err != nil {
+		return err
+	}
+
+	if err := c.ruleRepo.Delete(ctx, ruleID); err != nil {
+		logger.WithError(err).Warn("failed to delete content filter rule")
+		return err
+	}
+
+	logger.Info("content filter rule deleted")
+	c.reloadAfterChange(ctx)
+	return nil
+}
+
+// ReloadRules перечитывает правила по запросу администратора, например после правки таблицы вручную
+func (c *contentFilterUsecase) ReloadRules(ctx context.Context, actorID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.ReloadRules", tracing.UserID(actorID))
+	defer span.End()
+
+	if err := c.authorize(ctx, actorID); err != nil {
+		return err
+	}
+	return c.Reload(ctx)
+}
+
+// Reload загружает правила из хранилища в фильтр. При ошибке фильтр продолжает работать со старым набором
+func (c *contentFilterUsecase) Reload(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "ContentFilterUsecase.Reload")
+	defer span.End()
+
+	rules, err := c.ruleRepo.List(ctx)
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).Error("failed to load content filter rules")
+		return err
+	}
+
+	c.filter.Load(rules)
+	return nil
+}
+
+// WatchRules периодически перечитывает правила, чтобы изменения, сделанные на других
+// экземплярах сервиса, применялись без перезапуска. Блокируется до отмены ctx
+func (c *contentFilterUsecase) WatchRules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Ошибка уже залогирована в Reload, следующая попытка - на следующем тике
+			_ = c.Reload(ctx)
+		}
+	}
+}
+
+// reloadAfterChange применяет изменение правил сразу; сбой не отменяет уже сохраненное изменение,
+// его подхватит следующая периодическая перезагрузка
+func (c *contentFilterUsecase) reloadAfterChange(ctx context.Context) {
+	if err := c.Reload(ctx); err != nil {
+		c.logger.WithContext(ctx).WithError(err).Warn("content filter rules will be applied on next reload")
+	}
+}
+
+func (c *contentFilterUsecase) authorize(ctx context.Context, actorID uuid.UUID) error {
+	actor, err := c.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		if isNotFound(err) {
+			return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+		}
+		c.logger.WithContext(ctx).WithError(err).WithField("actor_id", actorID).Error("failed to fetch actor")
+		return err
+	}
+	if !actor.Role.Can(entity.PermissionContentFilterManage) {
+		c.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"actor_id": actorID,
+			"role":     actor.Role,
+		}).Warn("permission denied")
+		return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+func validateRule(rule *entity.ContentFilterRule) error {
+	if len([]rune(rule.Description)) > maxDescriptionRunes {
+		return apperror.InvalidField(apperror.CodeValidationFailed, "description", fmt.Sprintf("description must be at most %d characters", maxDescriptionRunes))
+	}
+	return rule.Validate()
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
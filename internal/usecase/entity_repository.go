@@ -3,6 +3,7 @@ package usecase
 import (
 	"chat-service/internal/entity"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,10 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) error
+	UpdateRole(ctx context.Context, id uuid.UUID, role entity.Role) error
+	List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error)
+	SetSuspended(ctx context.Context, id uuid.UUID, suspendedAt *time.Time, reason string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -20,6 +25,7 @@ type MessageRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Message, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Message, error)
 	GetAll(ctx context.Context) ([]*entity.Message, error)
+	SetHidden(ctx context.Context, id uuid.UUID, hiddenAt *time.Time) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -29,4 +35,115 @@ type SessionRepository interface {
 	GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.Session, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	DeleteByToken(ctx context.Context, token string) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteByUserIDExcept(ctx context.Context, userID, keepSessionID uuid.UUID) error
+	CountActive(ctx context.Context, now time.Time) (int, error)
+}
+
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error)
+	Upsert(ctx context.Context, mfa *entity.UserMFA) error
+	UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	CreateChallenge(ctx context.Context, challenge *entity.MFAChallengeState) error
+	// UseChallengeAttempt атомарно учитывает попытку ввода кода и возвращает их число с учетом этой.
+	// Для неизвестного, истекшего или исчерпавшего maxAttempts челленджа возвращается NotFoundError
+	UseChallengeAttempt(ctx context.Context, id, userID uuid.UUID, maxAttempts int, now time.Time) (int, error)
+	// DeleteChallenge завершает челлендж; если он уже удален, возвращается NotFoundError
+	DeleteChallenge(ctx context.Context, id uuid.UUID) error
+	// DeleteChallengesByUserID завершает все челленджи пользователя
+	DeleteChallengesByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) error
+}
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *entity.EmailVerificationToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error)
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *entity.LoginAttempt) error
+	GetAccountFailureStats(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error)
+	GetIPFailureStats(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) error
+}
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	TouchLastLogin(ctx context.Context, id uuid.UUID) error
+}
+
+type OIDCAuthRequestRepository interface {
+	Create(ctx context.Context, request *entity.OIDCAuthRequest) error
+	Consume(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error)
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error)
+	CountActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	Revoke(ctx context.Context, id, userID uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type ReportRepository interface {
+	Create(ctx context.Context, report *entity.Report) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Report, error)
+	List(ctx context.Context, filter entity.ReportFilter) ([]*entity.Report, int, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error
+}
+
+type ModerationActionRepository interface {
+	Create(ctx context.Context, action *entity.ModerationAction) error
+	ListByReportID(ctx context.Context, reportID uuid.UUID) ([]*entity.ModerationAction, error)
+}
+
+type ContentFilterRuleRepository interface {
+	Create(ctx context.Context, rule *entity.ContentFilterRule) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ContentFilterRule, error)
+	List(ctx context.Context) ([]*entity.ContentFilterRule, error)
+	Update(ctx context.Context, rule *entity.ContentFilterRule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type UserBlockRepository interface {
+	Create(ctx context.Context, block *entity.UserBlock) error
+	Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListByBlocker(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error)
+	ListBlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+	FindBlockersByUsername(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error)
+	FindBlockedByUsername(ctx context.Context, blockerID uuid.UUID, usernames []string) ([]string, error)
+}
+
+type RateLimitRepository interface {
+	// Increment учитывает запрос в окне windowStart и возвращает число запросов в этом окне
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error)
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type IdempotencyRepository interface {
+	// Create сохраняет запись о начале запроса. Существующая запись заменяется, только если она истекла
+	// или осталась незавершенной с момента раньше staleBefore; иначе возвращается false
+	Create(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error)
+	Get(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error)
+	// Complete и Delete меняют запись, только если ее создала та же попытка (совпадает CreatedAt)
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	Delete(ctx context.Context, record *entity.IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, before time.Time) error
 }
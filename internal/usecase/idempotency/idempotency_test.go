@@ -0,0 +1,250 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{TTL: 24 * time.Hour, InProgressTimeout: time.Minute}
+
+func newTestUsecase(repo *mocks.IdempotencyRepoMock) *idempotencyUsecase {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	u := NewIdempotencyUsecase(repo, testConfig, logger).(*idempotencyUsecase)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	u.now = func() time.Time { return now }
+	return u
+}
+
+// existingKeyRepo репозиторий, в котором ключ уже занят записью existing
+func existingKeyRepo(existing *entity.IdempotencyRecord) *mocks.IdempotencyRepoMock {
+	return &mocks.IdempotencyRepoMock{
+		CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+			return false, nil
+		},
+		GetFunc: func(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error) {
+			return existing, nil
+		},
+	}
+}
+
+func TestIdempotency_Begin_NewKey(t *testing.T) {
+	// Arrange
+	var created *entity.IdempotencyRecord
+	var staleBefore time.Time
+	repo := &mocks.IdempotencyRepoMock{
+		CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, stale time.Time) (bool, error) {
+			created, staleBefore = record, stale
+			return true, nil
+		},
+	}
+	u := newTestUsecase(repo)
+	userID := uuid.New()
+
+	// Act
+	acquired, err := u.Begin(context.Background(), userID, "key-1", "hash")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Same(t, created, acquired)
+	assert.False(t, acquired.IsCompleted())
+	assert.Equal(t, userID, created.UserID)
+	assert.Equal(t, created.CreatedAt.Add(24*time.Hour), created.ExpiresAt)
+	assert.Equal(t, created.CreatedAt.Add(-time.Minute), staleBefore)
+}
+
+func TestIdempotency_Begin_ExistingKey(t *testing.T) {
+	completedAt := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+	completed := &entity.IdempotencyRecord{RequestHash: "hash", StatusCode: 201, Body: []byte(`{"id":1}`), CompletedAt: &completedAt}
+
+	tests := []struct {
+		name     string
+		existing *entity.IdempotencyRecord
+		hash     string
+		wantCode string
+	}{
+		{name: "completed replays response", existing: completed, hash: "hash"},
+		{name: "different payload", existing: completed, hash: "other", wantCode: apperror.CodeIdempotencyKeyReused},
+		{name: "in progress", existing: &entity.IdempotencyRecord{RequestHash: "hash"}, hash: "hash", wantCode: apperror.CodeIdempotencyInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			u := newTestUsecase(existingKeyRepo(tt.existing))
+
+			// Act
+			stored, err := u.Begin(context.Background(), uuid.New(), "key-1", tt.hash)
+
+			// Assert
+			if tt.wantCode != "" {
+				assert.Nil(t, stored)
+				assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, tt.existing, stored)
+		})
+	}
+}
+
+func TestIdempotency_Begin_StatusOfErrors(t *testing.T) {
+	// Arrange
+	u := newTestUsecase(existingKeyRepo(&entity.IdempotencyRecord{RequestHash: "hash"}))
+
+	// Act
+	_, inProgressErr := u.Begin(context.Background(), uuid.New(), "key-1", "hash")
+	_, reusedErr := u.Begin(context.Background(), uuid.New(), "key-1", "other")
+
+	// Assert
+	assert.True(t, apperror.IsKind(inProgressErr, apperror.KindConflict))
+	assert.True(t, apperror.IsKind(reusedErr, apperror.KindUnprocessable))
+}
+
+func TestIdempotency_Begin_RecordReleasedMeanwhile(t *testing.T) {
+	// Arrange: первый запрос освободил ключ между Create и Get
+	attempts := 0
+	repo := &mocks.IdempotencyRepoMock{
+		CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+			attempts++
+			return attempts > 1, nil
+		},
+		GetFunc: func(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error) {
+			return nil, &NotFoundError{"idempotency key not found"}
+		},
+	}
+	u := newTestUsecase(repo)
+
+	// Act
+	acquired, err := u.Begin(context.Background(), uuid.New(), "key-1", "hash")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, acquired)
+	assert.False(t, acquired.IsCompleted())
+	assert.Equal(t, 2, attempts)
+}
+
+func TestIdempotency_Begin_InvalidKey(t *testing.T) {
+	keys := []string{"", "with space", "ключ", string(make([]byte, MaxKeyLength+1))}
+
+	for _, key := range keys {
+		// Arrange
+		u := newTestUsecase(&mocks.IdempotencyRepoMock{
+			CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+				t.Fatal("repository must not be called for an invalid key")
+				return false, nil
+			},
+		})
+
+		// Act
+		_, err := u.Begin(context.Background(), uuid.New(), key, "hash")
+
+		// Assert
+		assert.Equal(t, apperror.CodeIdempotencyKeyInvalid, apperror.CodeOf(err))
+	}
+}
+
+func TestIdempotency_Complete_IgnoresCancellation(t *testing.T) {
+	// Arrange
+	var saved *entity.IdempotencyRecord
+	repo := &mocks.IdempotencyRepoMock{
+		CompleteFunc: func(ctx context.Context, record *entity.IdempotencyRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			saved = record
+			return nil
+		},
+	}
+	u := newTestUsecase(repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := u.Complete(ctx, &entity.IdempotencyRecord{UserID: uuid.New(), Key: "key-1", RequestHash: "hash", StatusCode: 201})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.True(t, saved.IsCompleted())
+}
+
+func TestIdempotency_StaleAttemptDoesNotTouchTakeover(t *testing.T) {
+	// Arrange: репозиторий меняет запись, только если совпадает CreatedAt попытки, как в БД
+	var current *entity.IdempotencyRecord
+	repo := &mocks.IdempotencyRepoMock{
+		CreateFunc: func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+			if current != nil && !(current.CompletedAt == nil && current.CreatedAt.Before(staleBefore)) {
+				return false, nil
+			}
+			copied := *record
+			current = &copied
+			return true, nil
+		},
+		CompleteFunc: func(ctx context.Context, record *entity.IdempotencyRecord) error {
+			if current != nil && current.CompletedAt == nil && current.CreatedAt.Equal(record.CreatedAt) {
+				copied := *record
+				current = &copied
+			}
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, record *entity.IdempotencyRecord) error {
+			if current != nil && current.CompletedAt == nil && current.CreatedAt.Equal(record.CreatedAt) {
+				current = nil
+			}
+			return nil
+		},
+	}
+	u := newTestUsecase(repo)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	u.now = func() time.Time { return now }
+	userID := uuid.New()
+
+	stale, err := u.Begin(context.Background(), userID, "key-1", "hash")
+	require.NoError(t, err)
+	now = now.Add(testConfig.InProgressTimeout + time.Second)
+	takeover, err := u.Begin(context.Background(), userID, "key-1", "hash")
+	require.NoError(t, err)
+	require.NotNil(t, takeover)
+
+	// Act: прерванная попытка завершается после того, как ключ перехватили
+	stale.StatusCode = 201
+	completeErr := u.Complete(context.Background(), stale)
+	releaseErr := u.Release(context.Background(), stale)
+
+	// Assert
+	require.NoError(t, completeErr)
+	require.NoError(t, releaseErr)
+	require.NotNil(t, current, "запись повтора не удалена")
+	assert.Equal(t, takeover.CreatedAt, current.CreatedAt)
+	assert.False(t, current.IsCompleted(), "ответ прерванной попытки не сохранен в записи повтора")
+	assert.Equal(t, takeover.CreatedAt, takeover.CreatedAt.Truncate(time.Microsecond))
+}
+
+// NotFoundError представляет ошибку, когда ресурс не найден.
+type NotFoundError struct {
+	Message string
+}
+
+// Error реализует интерфейс error.
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// NotFound сигнализирует, что это ошибка "не найдено".
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
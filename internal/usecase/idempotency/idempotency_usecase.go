@@ -0,0 +1,20 @@
+package idempotency
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyUsecase interface {
+	// Begin начинает запрос с ключом идемпотентности. Возвращает сохраненный ответ, если запрос
+	// с этим ключом уже выполнен, или занятую незавершенную запись, если запрос нужно выполнить
+	// и затем передать эту запись в Complete или Release. Ключ с другим запросом отклоняется
+	// с кодом idempotency.key_reused, ключ выполняющегося запроса - с кодом idempotency.request_in_progress
+	Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyRecord, error)
+	// Complete сохраняет ответ в записи из Begin для повторов до истечения срока ключа
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Release освобождает ключ запроса, ответ которого не сохраняется, чтобы его можно было повторить
+	Release(ctx context.Context, record *entity.IdempotencyRecord) error
+}
@@ -0,0 +1,158 @@
+package idempotency
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxKeyLength максимальная длина ключа идемпотентности
+const MaxKeyLength = 255
+
+// Как часто удалять истекшие ключи
+const cleanupInterval = time.Hour
+
+type Config struct {
+	// TTL сколько хранится ответ с момента первого запроса
+	TTL time.Duration
+	// InProgressTimeout через сколько незавершенный запрос считается прерванным, и ключ можно занять заново
+	InProgressTimeout time.Duration
+}
+
+type idempotencyUsecase struct {
+	repo   usecase.IdempotencyRepository
+	config Config
+	logger *logrus.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewIdempotencyUsecase(repo usecase.IdempotencyRepository, config Config, logger *logrus.Logger) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (u *idempotencyUsecase) Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*entity.IdempotencyRecord, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyUsecase.Begin")
+	defer span.End()
+
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	// CreatedAt отличает эту попытку от повтора, перехватившего ключ; в БД время хранится с точностью до микросекунд
+	now := u.now().Truncate(time.Microsecond)
+	u.cleanup(ctx, now)
+
+	logger := u.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":         userID,
+		"idempotency_key": key,
+	})
+
+	record := &entity.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(u.config.TTL),
+	}
+	// Запись могут удалить между Create и Get, если первый запрос завершился ошибкой; тогда пробуем еще раз
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := u.repo.Create(ctx, record, now.Add(-u.config.InProgressTimeout))
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return record, nil
+		}
+
+		existing, err := u.repo.Get(ctx, userID, key)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if existing.RequestHash != requestHash {
+			logger.Warn("idempotency key reused with a different request")
+			return nil, apperror.Unprocessable(apperror.CodeIdempotencyKeyReused,
+				"idempotency key was already used with a different request")
+		}
+		if !existing.IsCompleted() {
+			logger.Info("duplicate request rejected: original request is still in progress")
+			return nil, apperror.Conflict(apperror.CodeIdempotencyInProgress,
+				"a request with this idempotency key is still in progress")
+		}
+
+		logger.Info("replaying stored response for idempotency key")
+		return existing, nil
+	}
+
+	return nil, apperror.Conflict(apperror.CodeIdempotencyInProgress,
+		"a request with this idempotency key is still in progress")
+}
+
+func (u *idempotencyUsecase) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	// Ответ уже отправлен клиенту: отмена запроса не должна помешать его сохранить
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "IdempotencyUsecase.Complete")
+	defer span.End()
+
+	completedAt := u.now()
+	record.CompletedAt = &completedAt
+	return u.repo.Complete(ctx, record)
+}
+
+func (u *idempotencyUsecase) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "IdempotencyUsecase.Release")
+	defer span.End()
+
+	return u.repo.Delete(ctx, record)
+}
+
+// cleanup время от времени удаляет истекшие ключи; ошибка только логируется
+func (u *idempotencyUsecase) cleanup(ctx context.Context, now time.Time) {
+	u.mu.Lock()
+	if now.Sub(u.lastCleanup) < cleanupInterval {
+		u.mu.Unlock()
+		return
+	}
+	u.lastCleanup = now
+	u.mu.Unlock()
+
+	if err := u.repo.DeleteExpired(ctx, now); err != nil {
+		u.logger.WithContext(ctx).WithError(err).Warn("failed to delete expired idempotency keys")
+	}
+}
+
+// validateKey допускает печатные ASCII символы без пробелов, например UUID
+func validateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return apperror.Validation(apperror.CodeIdempotencyKeyInvalid, "idempotency key must be 1 to 255 characters long")
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] > '~' {
+			return apperror.Validation(apperror.CodeIdempotencyKeyInvalid, "idempotency key must contain only printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
@@ -0,0 +1,221 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	return Config{
+		Window:             15 * time.Minute,
+		AccountMaxFailures: 5,
+		IPMaxFailures:      20,
+		BaseLockout:        time.Minute,
+		MaxLockout:         time.Hour,
+	}
+}
+
+func newTestGuard(repo *mocks.LoginAttemptRepoMock, now time.Time) *loginGuardUsecase {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	guard := NewLoginGuardUsecase(repo, newTestConfig(), logger).(*loginGuardUsecase)
+	guard.now = func() time.Time { return now }
+	return guard
+}
+
+func TestLoginGuard_Check_BelowThreshold(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now.Add(-time.Second)
+
+	repo.GetAccountFailureStatsFunc = func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+		assert.Equal(t, "test@example.com", email) // email нормализован
+		assert.Equal(t, now.Add(-15*time.Minute), since)
+		return &entity.LoginFailureStats{Count: 4, LastFailureAt: &lastFailure}, nil
+	}
+
+	repo.CreateFunc = func(ctx context.Context, attempt *entity.LoginAttempt) error {
+		t.Fatal("allowed attempt must not be recorded as locked")
+		return nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), " Test@Example.com ", "10.0.0.1")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestLoginGuard_Check_AccountLocked(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now.Add(-20 * time.Second)
+	recorded := false
+
+	repo.GetAccountFailureStatsFunc = func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+		return &entity.LoginFailureStats{Count: 5, LastFailureAt: &lastFailure}, nil
+	}
+
+	repo.CreateFunc = func(ctx context.Context, attempt *entity.LoginAttempt) error {
+		recorded = true
+		return nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), "test@example.com", "10.0.0.1")
+
+	// Assert
+	require.Error(t, err)
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, 40*time.Second, tooMany.RetryAfter())
+	assert.False(t, recorded, "попытка во время блокировки не пишется в БД")
+}
+
+func TestLoginGuard_Check_DeletesOldAttempts(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	var deletedBefore []time.Time
+	repo := &mocks.LoginAttemptRepoMock{
+		DeleteOlderThanFunc: func(ctx context.Context, before time.Time) error {
+			deletedBefore = append(deletedBefore, before)
+			return nil
+		},
+	}
+	guard := newTestGuard(repo, now)
+
+	// Act
+	require.NoError(t, guard.Check(context.Background(), "test@example.com", "10.0.0.1"))
+	require.NoError(t, guard.Check(context.Background(), "test@example.com", "10.0.0.1"))
+	guard.now = func() time.Time { return now.Add(cleanupInterval) }
+	require.NoError(t, guard.Check(context.Background(), "test@example.com", "10.0.0.1"))
+
+	// Assert: окно 15 минут, блокировка до часа - хранится час
+	assert.Equal(t, []time.Time{now.Add(-time.Hour), now}, deletedBefore)
+}
+
+func TestLoginGuard_Check_ExponentialBackoff(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now
+
+	repo.GetAccountFailureStatsFunc = func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+		return &entity.LoginFailureStats{Count: 8, LastFailureAt: &lastFailure}, nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), "test@example.com", "10.0.0.1")
+
+	// Assert - 3 неудачи сверх порога: 1m * 2^3
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, 8*time.Minute, tooMany.RetryAfter())
+}
+
+func TestLoginGuard_Check_LockoutCapped(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now
+
+	repo.GetAccountFailureStatsFunc = func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+		return &entity.LoginFailureStats{Count: 500, LastFailureAt: &lastFailure}, nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), "test@example.com", "10.0.0.1")
+
+	// Assert
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, time.Hour, tooMany.RetryAfter())
+}
+
+func TestLoginGuard_Check_LockoutExpired(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now.Add(-2 * time.Minute)
+
+	repo.GetAccountFailureStatsFunc = func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+		return &entity.LoginFailureStats{Count: 5, LastFailureAt: &lastFailure}, nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), "test@example.com", "10.0.0.1")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestLoginGuard_Check_IPLocked(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	lastFailure := now
+
+	// Перебор по разным аккаунтам с одного адреса
+	repo.GetIPFailureStatsFunc = func(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error) {
+		assert.Equal(t, "10.0.0.1", ipAddress)
+		return &entity.LoginFailureStats{Count: 20, LastFailureAt: &lastFailure}, nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	err := guard.Check(context.Background(), "other@example.com", "10.0.0.1")
+
+	// Assert
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, time.Minute, tooMany.RetryAfter())
+}
+
+func TestLoginGuard_RecordSuccess(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repo := &mocks.LoginAttemptRepoMock{}
+	userID := uuid.New()
+	var recorded *entity.LoginAttempt
+
+	repo.CreateFunc = func(ctx context.Context, attempt *entity.LoginAttempt) error {
+		recorded = attempt
+		return nil
+	}
+
+	guard := newTestGuard(repo, now)
+
+	// Act
+	guard.RecordSuccess(context.Background(), "Test@Example.com", "10.0.0.1", userID)
+
+	// Assert
+	require.NotNil(t, recorded)
+	assert.Equal(t, entity.LoginOutcomeSuccess, recorded.Outcome)
+	assert.Equal(t, "test@example.com", recorded.Email)
+	require.NotNil(t, recorded.UserID)
+	assert.Equal(t, userID, *recorded.UserID)
+}
@@ -0,0 +1,15 @@
+package loginguard
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type LoginGuardUsecase interface {
+	Check(ctx context.Context, email, ipAddress string) error
+	RecordFailure(ctx context.Context, email, ipAddress string)
+	RecordSuccess(ctx context.Context, email, ipAddress string, userID uuid.UUID)
+	// UpdateConfig применяет новые пороги без перезапуска
+	UpdateConfig(config Config)
+}
@@ -0,0 +1,172 @@
+package loginguard
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config пороги защиты от перебора паролей.
+// После MaxFailures неудач в пределах Window вход блокируется на BaseLockout,
+// каждая следующая неудача удваивает блокировку, но не больше MaxLockout
+type Config struct {
+	Window             time.Duration
+	AccountMaxFailures int
+	IPMaxFailures      int
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+}
+
+// Как часто удалять старые попытки входа
+const cleanupInterval = time.Hour
+
+type loginGuardUsecase struct {
+	attemptRepo usecase.LoginAttemptRepository
+	configMu    sync.RWMutex
+	config      Config
+	logger      *logrus.Logger
+	now         func() time.Time
+
+	cleanupMu   sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewLoginGuardUsecase(attemptRepo usecase.LoginAttemptRepository, config Config, logger *logrus.Logger) LoginGuardUsecase {
+	return &loginGuardUsecase{
+		attemptRepo: attemptRepo,
+		config:      config,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Check возвращает ошибку с кодом auth.login_locked, если аккаунт или IP-адрес временно заблокированы
+func (g *loginGuardUsecase) Check(ctx context.Context, email, ipAddress string) error {
+	ctx, span := tracing.Start(ctx, "LoginGuardUsecase.Check")
+	defer span.End()
+
+	config := g.currentConfig()
+	email = normalizeEmail(email)
+	now := g.now()
+	since := now.Add(-config.Window)
+	g.cleanup(ctx, config, now)
+
+	accountStats, err := g.attemptRepo.GetAccountFailureStats(ctx, email, since)
+	if err != nil {
+		g.logger.WithContext(ctx).WithError(err).Error("failed to fetch account login failures")
+		return err
+	}
+
+	ipStats, err := g.attemptRepo.GetIPFailureStats(ctx, ipAddress, since)
+	if err != nil {
+		g.logger.WithContext(ctx).WithError(err).Error("failed to fetch ip login failures")
+		return err
+	}
+
+	wait := max(
+		lockoutRemaining(config, accountStats, config.AccountMaxFailures, now),
+		lockoutRemaining(config, ipStats, config.IPMaxFailures, now),
+	)
+	if wait <= 0 {
+		return nil
+	}
+
+	g.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"email":       email,
+		"ip_address":  ipAddress,
+		"retry_after": wait,
+	}).Warn("login rejected: too many failed attempts")
+	// Отклоненная попытка не пишется в БД: иначе перебор во время блокировки наполнял бы таблицу
+
+	return apperror.TooManyRequests(apperror.CodeLoginLocked, "too many failed login attempts, try again later", wait)
+}
+
+// UpdateConfig применяет новые пороги; история неудач хранится в БД и не теряется
+func (g *loginGuardUsecase) UpdateConfig(config Config) {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+	g.config = config
+}
+
+func (g *loginGuardUsecase) currentConfig() Config {
+	g.configMu.RLock()
+	defer g.configMu.RUnlock()
+	return g.config
+}
+
+func (g *loginGuardUsecase) RecordFailure(ctx context.Context, email, ipAddress string) {
+	ctx, span := tracing.Start(ctx, "LoginGuardUsecase.RecordFailure")
+	defer span.End()
+
+	g.record(ctx, normalizeEmail(email), ipAddress, nil, entity.LoginOutcomeFailure)
+}
+
+// RecordSuccess сбрасывает счетчик неудач аккаунта (но не IP-адреса)
+func (g *loginGuardUsecase) RecordSuccess(ctx context.Context, email, ipAddress string, userID uuid.UUID) {
+	ctx, span := tracing.Start(ctx, "LoginGuardUsecase.RecordSuccess", tracing.UserID(userID))
+	defer span.End()
+
+	g.record(ctx, normalizeEmail(email), ipAddress, &userID, entity.LoginOutcomeSuccess)
+}
+
+// cleanup время от времени удаляет попытки старше окна подсчета и максимальной блокировки; ошибка только логируется
+func (g *loginGuardUsecase) cleanup(ctx context.Context, config Config, now time.Time) {
+	g.cleanupMu.Lock()
+	if now.Sub(g.lastCleanup) < cleanupInterval {
+		g.cleanupMu.Unlock()
+		return
+	}
+	g.lastCleanup = now
+	g.cleanupMu.Unlock()
+
+	if err := g.attemptRepo.DeleteOlderThan(ctx, now.Add(-max(config.Window, config.MaxLockout))); err != nil {
+		g.logger.WithContext(ctx).WithError(err).Warn("failed to delete old login attempts")
+	}
+}
+
+// lockoutRemaining возвращает оставшееся время блокировки с экспоненциальным ростом
+func lockoutRemaining(config Config, stats *entity.LoginFailureStats, maxFailures int, now time.Time) time.Duration {
+	if stats == nil || stats.LastFailureAt == nil || maxFailures <= 0 || stats.Count < maxFailures {
+		return 0
+	}
+
+	lockout := config.BaseLockout
+	for i := maxFailures; i < stats.Count && lockout < config.MaxLockout; i++ {
+		lockout *= 2
+	}
+	lockout = min(lockout, config.MaxLockout)
+
+	return stats.LastFailureAt.Add(lockout).Sub(now)
+}
+
+// record пишет событие аудита. Ошибка записи не должна ломать вход, поэтому только логируется
+func (g *loginGuardUsecase) record(ctx context.Context, email, ipAddress string, userID *uuid.UUID, outcome string) {
+	attempt := &entity.LoginAttempt{
+		ID:        uuid.New(),
+		Email:     email,
+		IPAddress: ipAddress,
+		UserID:    userID,
+		Outcome:   outcome,
+		CreatedAt: g.now(),
+	}
+
+	if err := g.attemptRepo.Create(ctx, attempt); err != nil {
+		g.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"email":      email,
+			"ip_address": ipAddress,
+			"outcome":    outcome,
+		}).Error("failed to record login attempt")
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
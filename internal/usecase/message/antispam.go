@@ -0,0 +1,183 @@
+package message
+
+import (
+	"crypto/sha256"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-service/internal/apperror"
+
+	"github.com/google/uuid"
+)
+
+// Как часто удалять из памяти состояние пользователей, которые давно ничего не публиковали
+const antiSpamSweepInterval = time.Minute
+
+// AntiSpamConfig ограничения частоты публикации. Нулевые значения отключают соответствующую проверку
+type AntiSpamConfig struct {
+	// Burst сколько сообщений подряд можно отправить без ожидания (емкость token bucket)
+	Burst int
+	// RefillInterval за какое время восстанавливается одно сообщение из Burst
+	RefillInterval time.Duration
+	// DuplicateWindow в течение какого времени повторная отправка того же текста отклоняется
+	DuplicateWindow time.Duration
+	// SlowMode минимальный интервал между сообщениями одного пользователя.
+	// Модераторы и администраторы от него освобождены
+	SlowMode time.Duration
+}
+
+// posterState состояние пользователя: остаток token bucket, время последнего сообщения
+// и отпечатки недавних сообщений для поиска повторов
+type posterState struct {
+	tokens     float64
+	refilledAt time.Time
+	lastPostAt time.Time
+	recent     map[[sha256.Size]byte]time.Time
+}
+
+// antiSpam хранит состояние в памяти процесса: при нескольких экземплярах сервиса
+// лимиты действуют на каждом экземпляре отдельно
+type antiSpam struct {
+	config    AntiSpamConfig
+	mu        sync.Mutex
+	posters   map[uuid.UUID]*posterState
+	lastSweep time.Time
+}
+
+func newAntiSpam(config AntiSpamConfig) *antiSpam {
+	return &antiSpam{
+		config:  config,
+		posters: make(map[uuid.UUID]*posterState),
+	}
+}
+
+// setConfig меняет ограничения. Остаток token bucket приводится к новому Burst при следующем пополнении
+func (a *antiSpam) setConfig(config AntiSpamConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = config
+}
+
+// allow проверяет, можно ли пользователю опубликовать content сейчас, и при успехе учитывает публикацию.
+// exemptSlowMode освобождает от slow mode, но не от остальных ограничений. Возвращенная функция
+// отменяет учет, если сообщение так и не было опубликовано
+func (a *antiSpam) allow(userID uuid.UUID, content string, exemptSlowMode bool, now time.Time) (func(), error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sweep(now)
+
+	state, ok := a.posters[userID]
+	if !ok {
+		state = &posterState{
+			tokens:     float64(a.config.Burst),
+			refilledAt: now,
+			recent:     make(map[[sha256.Size]byte]time.Time),
+		}
+		a.posters[userID] = state
+	}
+
+	if a.config.SlowMode > 0 && !exemptSlowMode && !state.lastPostAt.IsZero() {
+		if wait := state.lastPostAt.Add(a.config.SlowMode).Sub(now); wait > 0 {
+			return nil, apperror.TooManyRequests(apperror.CodeMessageSlowMode, "slow mode is enabled, please wait before posting again", wait)
+		}
+	}
+
+	fingerprint := contentFingerprint(content)
+	if a.config.DuplicateWindow > 0 {
+		for hash, postedAt := range state.recent {
+			if !now.Before(postedAt.Add(a.config.DuplicateWindow)) {
+				delete(state.recent, hash)
+			}
+		}
+		if postedAt, ok := state.recent[fingerprint]; ok {
+			return nil, apperror.TooManyRequests(
+				apperror.CodeMessageDuplicate,
+				"duplicate message, you have already posted this recently",
+				postedAt.Add(a.config.DuplicateWindow).Sub(now),
+			)
+		}
+	}
+
+	tokenSpent := false
+	if a.rateLimited() {
+		a.refill(state, now)
+		if state.tokens < 1 {
+			wait := time.Duration((1 - state.tokens) * float64(a.config.RefillInterval))
+			return nil, apperror.TooManyRequests(apperror.CodeMessageRateLimited, "too many messages, please slow down", wait)
+		}
+		state.tokens--
+		tokenSpent = true
+	}
+
+	previousPostAt := state.lastPostAt
+	state.lastPostAt = now
+	if a.config.DuplicateWindow > 0 {
+		state.recent[fingerprint] = now
+	}
+	return func() {
+		a.release(userID, fingerprint, tokenSpent, previousPostAt, now)
+	}, nil
+}
+
+// release отменяет учет публикации, сделанной в момент postedAt. Если после нее пользователь
+// успел опубликовать другое сообщение, его время и отпечаток сохраняются
+func (a *antiSpam) release(userID uuid.UUID, fingerprint [sha256.Size]byte, tokenSpent bool, previousPostAt, postedAt time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.posters[userID]
+	if !ok {
+		return
+	}
+	if tokenSpent {
+		state.tokens = math.Min(float64(a.config.Burst), state.tokens+1)
+	}
+	if state.lastPostAt.Equal(postedAt) {
+		state.lastPostAt = previousPostAt
+	}
+	if recentAt, ok := state.recent[fingerprint]; ok && recentAt.Equal(postedAt) {
+		delete(state.recent, fingerprint)
+	}
+}
+
+func (a *antiSpam) rateLimited() bool {
+	return a.config.Burst > 0 && a.config.RefillInterval > 0
+}
+
+func (a *antiSpam) refill(state *posterState, now time.Time) {
+	elapsed := now.Sub(state.refilledAt)
+	if elapsed <= 0 {
+		return
+	}
+	state.tokens = math.Min(float64(a.config.Burst), state.tokens+float64(elapsed)/float64(a.config.RefillInterval))
+	state.refilledAt = now
+}
+
+// sweep удаляет пользователей, состояние которых уже ни на что не влияет:
+// bucket полон, slow mode и окно повторов истекли
+func (a *antiSpam) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < antiSpamSweepInterval {
+		return
+	}
+	a.lastSweep = now
+
+	idleAfter := max(a.config.SlowMode, a.config.DuplicateWindow)
+	if a.rateLimited() {
+		idleAfter = max(idleAfter, time.Duration(a.config.Burst)*a.config.RefillInterval)
+	}
+	for userID, state := range a.posters {
+		if now.Sub(state.lastPostAt) >= idleAfter {
+			delete(a.posters, userID)
+		}
+	}
+}
+
+// contentFingerprint отпечаток текста для поиска повторов: регистр и пробелы не учитываются,
+// чтобы повтор нельзя было обойти лишним пробелом
+func contentFingerprint(content string) [sha256.Size]byte {
+	normalized := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	return sha256.Sum256([]byte(normalized))
+}
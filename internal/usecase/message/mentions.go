@@ -0,0 +1,33 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Сколько разных пользователей можно упомянуть в одном сообщении
+const maxMentions = 20
+
+// @ должен стоять в начале текста или после символа, который не может входить в имя,
+// чтобы адрес вида user@example.com не считался упоминанием
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@-])@([\p{L}\p{N}_.-]+)`)
+
+// parseMentions возвращает имена упомянутых пользователей в нижнем регистре без повторов.
+// Точка или дефис в конце считаются знаком препинания, а не частью имени
+func parseMentions(content string) []string {
+	seen := make(map[string]struct{})
+	mentions := make([]string, 0)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		// Короче допустимого имени пользователя
+		if len(username) < 3 {
+			continue
+		}
+		if _, ok := seen[username]; ok {
+			continue
+		}
+		seen[username] = struct{}{}
+		mentions = append(mentions, username)
+	}
+	return mentions
+}
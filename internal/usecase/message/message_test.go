@@ -2,15 +2,19 @@ package message
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
 	"chat-service/internal/usecase/mocks"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMessageUsecase_CreateMessage_Success(t *testing.T) {
@@ -35,7 +39,7 @@ func TestMessageUsecase_CreateMessage_Success(t *testing.T) {
 		return nil
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
 	message, err := usecase.CreateMessage(context.Background(), testUserID, testContent)
@@ -66,7 +70,7 @@ func TestMessageUsecase_CreateMessage_UserNotFound(t *testing.T) {
 		return nil, &NotFoundError{"user not found"}
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
 	message, err := usecase.CreateMessage(context.Background(), testUserID, testContent)
@@ -77,6 +81,61 @@ func TestMessageUsecase_CreateMessage_UserNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "user not found")
 }
 
+func TestMessageUsecase_CreateMessage_EmailNotVerified(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	messageRepo := &mocks.MessageRepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+
+	testUserID := uuid.New()
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id}, nil // email не подтвержден
+	}
+
+	messageRepo.CreateFunc = func(ctx context.Context, message *entity.Message) error {
+		t.Fatal("message must not be created before email verification")
+		return nil
+	}
+
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{RequireVerifiedEmail: true}, logger)
+
+	// Act
+	message, err := usecase.CreateMessage(context.Background(), testUserID, "Test message content")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, message)
+	assert.Contains(t, err.Error(), "email must be verified")
+}
+
+func TestMessageUsecase_CreateMessage_EmailVerified(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	messageRepo := &mocks.MessageRepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+
+	testUserID := uuid.New()
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, EmailVerifiedAt: &verifiedAt}, nil
+	}
+
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{RequireVerifiedEmail: true}, logger)
+
+	// Act
+	message, err := usecase.CreateMessage(context.Background(), testUserID, "Test message content")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, message)
+}
+
 func TestMessageUsecase_CreateMessage_ValidationFailed(t *testing.T) {
 	// Arrange
 	logger := logrus.New()
@@ -93,7 +152,7 @@ func TestMessageUsecase_CreateMessage_ValidationFailed(t *testing.T) {
 		return &entity.User{ID: id}, nil
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
 	message, err := usecase.CreateMessage(context.Background(), testUserID, invalidContent)
@@ -126,10 +185,10 @@ func TestMessageUsecase_GetMessageByID_Success(t *testing.T) {
 		return expectedMessage, nil
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
-	message, err := usecase.GetMessageByID(context.Background(), testMessageID)
+	message, err := usecase.GetMessageByID(context.Background(), testMessageID, uuid.New(), entity.RoleUser)
 
 	// Assert
 	assert.NoError(t, err)
@@ -152,10 +211,10 @@ func TestMessageUsecase_GetMessageByID_NotFound(t *testing.T) {
 		return nil, &NotFoundError{"message not found"}
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
-	message, err := usecase.GetMessageByID(context.Background(), testMessageID)
+	message, err := usecase.GetMessageByID(context.Background(), testMessageID, uuid.New(), entity.RoleUser)
 
 	// Assert
 	assert.Error(t, err)
@@ -199,7 +258,7 @@ func TestMessageUsecase_GetMessagesByUser_Success(t *testing.T) {
 		return messages, nil
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
 	result, err := usecase.GetMessagesByUser(context.Background(), testUserID)
@@ -226,7 +285,7 @@ func TestMessageUsecase_GetMessagesByUser_UserNotFound(t *testing.T) {
 		return nil, &NotFoundError{"user not found"}
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
 	messages, err := usecase.GetMessagesByUser(context.Background(), testUserID)
@@ -267,10 +326,10 @@ func TestMessageUsecase_GetAllMessages_Success(t *testing.T) {
 		return messages, nil
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
 
 	// Act
-	result, err := usecase.GetAllMessages(context.Background())
+	result, err := usecase.GetAllMessages(context.Background(), uuid.Nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -288,33 +347,612 @@ func TestMessageUsecase_DeleteMessage_Success(t *testing.T) {
 	userRepo := &mocks.UserRepoMock{}
 
 	testMessageID := uuid.New()
+	testUserID := uuid.New()
 
 	// Настраиваем моки
+	messageRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+		return &entity.Message{ID: id, UserID: testUserID, Content: "hello"}, nil
+	}
 	messageRepo.DeleteFunc = func(ctx context.Context, id uuid.UUID) error {
 		return nil // Успешное удаление
 	}
 
-	usecase := NewMessageUsecase(messageRepo, userRepo, logger)
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
+
+	// Act
+	err := usecase.DeleteMessage(context.Background(), testMessageID, testUserID, entity.RoleUser)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestMessageUsecase_DeleteMessage_Permissions(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      entity.Role
+		wantError bool
+	}{
+		{"пользователь не может удалить чужое сообщение", entity.RoleUser, true},
+		{"модератор удаляет любое сообщение", entity.RoleModerator, false},
+		{"администратор удаляет любое сообщение", entity.RoleAdmin, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			messageRepo := &mocks.MessageRepoMock{}
+			userRepo := &mocks.UserRepoMock{}
+
+			ownerID := uuid.New()
+			deleted := false
+
+			messageRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+				return &entity.Message{ID: id, UserID: ownerID, Content: "hello"}, nil
+			}
+			messageRepo.DeleteFunc = func(ctx context.Context, id uuid.UUID) error {
+				deleted = true
+				return nil
+			}
+
+			usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
+
+			// Act
+			err := usecase.DeleteMessage(context.Background(), uuid.New(), uuid.New(), tt.role)
+
+			// Assert
+			if tt.wantError {
+				assert.Equal(t, apperror.CodeMessageNotOwner, apperror.CodeOf(err))
+				assert.False(t, deleted)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, deleted)
+			}
+		})
+	}
+}
+
+func TestMessageUsecase_DeleteMessage_NotFound(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	messageRepo := &mocks.MessageRepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+
+	messageRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+		return nil, &NotFoundError{"message not found"}
+	}
+
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
+
+	// Act
+	err := usecase.DeleteMessage(context.Background(), uuid.New(), uuid.New(), entity.RoleAdmin)
+
+	// Assert
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestMessageUsecase_GetMessageByID_Hidden(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	ownerID := uuid.New()
+	hiddenAt := time.Now()
+	hidden := &entity.Message{ID: uuid.New(), UserID: ownerID, Content: "spam", HiddenAt: &hiddenAt}
+
+	messageRepo := &mocks.MessageRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+			return hidden, nil
+		},
+	}
+	usecase := NewMessageUsecase(messageRepo, &mocks.UserRepoMock{}, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{}, logger)
+
+	tests := []struct {
+		name    string
+		actorID uuid.UUID
+		role    entity.Role
+		visible bool
+	}{
+		{"автор видит свое скрытое сообщение", ownerID, entity.RoleUser, true},
+		{"модератор видит скрытое сообщение", uuid.New(), entity.RoleModerator, true},
+		{"другой пользователь получает 404", uuid.New(), entity.RoleUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			message, err := usecase.GetMessageByID(context.Background(), hidden.ID, tt.actorID, tt.role)
+
+			// Assert
+			if tt.visible {
+				require.NoError(t, err)
+				assert.Equal(t, hidden.ID, message.ID)
+				return
+			}
+			assert.Nil(t, message)
+			assert.Equal(t, apperror.CodeMessageNotFound, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestMessageUsecase_CreateMessage_ContentFilter(t *testing.T) {
+	ruleID := uuid.New()
+
+	tests := []struct {
+		name        string
+		result      *entity.ContentFilterResult
+		wantErr     bool
+		wantContent string
+		wantReport  bool
+	}{
+		{"без совпадений", &entity.ContentFilterResult{Content: "hello"}, false, "hello", false},
+		{"маскирование", &entity.ContentFilterResult{Content: "oh ****", MatchedRules: []uuid.UUID{ruleID}}, false, "oh ****", false},
+		{"пометка для модераторов", &entity.ContentFilterResult{Content: "hello", Flagged: true, MatchedRules: []uuid.UUID{ruleID}}, false, "hello", true},
+		{"отклонение", &entity.ContentFilterResult{Rejected: true, MatchedRules: []uuid.UUID{ruleID}}, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var saved *entity.Message
+			messageRepo := &mocks.MessageRepoMock{
+				CreateFunc: func(ctx context.Context, message *entity.Message) error {
+					saved = message
+					return nil
+				},
+			}
+			userRepo := &mocks.UserRepoMock{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return &entity.User{ID: id}, nil
+				},
+			}
+			var report *entity.Report
+			reportRepo := &mocks.ReportRepoMock{
+				CreateFunc: func(ctx context.Context, r *entity.Report) error {
+					report = r
+					return nil
+				},
+			}
+			filter := &mocks.ContentFilterMock{
+				ApplyFunc: func(content string) *entity.ContentFilterResult {
+					return tt.result
+				},
+			}
+			usecase := NewMessageUsecase(messageRepo, userRepo, reportRepo, &mocks.UserBlockRepoMock{}, filter, Config{}, logger)
+
+			// Act
+			message, err := usecase.CreateMessage(context.Background(), uuid.New(), "original")
+
+			// Assert
+			if tt.wantErr {
+				assert.Equal(t, apperror.CodeMessageProhibitedContent, apperror.CodeOf(err))
+				assert.Nil(t, saved)
+				assert.Nil(t, report)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, message.Content)
+			assert.Equal(t, tt.wantContent, saved.Content)
+			if !tt.wantReport {
+				assert.Nil(t, report)
+				return
+			}
+			require.NotNil(t, report)
+			assert.Equal(t, message.ID, report.MessageID)
+			assert.Nil(t, report.ReporterID)
+			assert.Equal(t, entity.ReportReasonContentFilter, report.Reason)
+			assert.Contains(t, report.Comment, ruleID.String())
+			assert.NoError(t, report.Validate())
+		})
+	}
+}
+
+// newAntiSpamUsecase создает usecase с управляемыми часами; возвращает функцию сдвига времени
+func newAntiSpamUsecase(config AntiSpamConfig, role entity.Role) (*messageUsecase, func(time.Duration)) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, Role: role}, nil
+		},
+	}
+	uc := NewMessageUsecase(&mocks.MessageRepoMock{}, userRepo, &mocks.ReportRepoMock{}, &mocks.UserBlockRepoMock{}, nil, Config{AntiSpam: config}, logger).(*messageUsecase)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	return uc, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMessageUsecase_CreateMessage_RateLimit(t *testing.T) {
+	// Arrange
+	uc, advance := newAntiSpamUsecase(AntiSpamConfig{Burst: 3, RefillInterval: 10 * time.Second}, entity.RoleUser)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	// Act & Assert: burst расходуется, следующее сообщение отклоняется
+	for i := 0; i < 3; i++ {
+		_, err := uc.CreateMessage(ctx, userID, fmt.Sprintf("message %d", i))
+		require.NoError(t, err)
+	}
+	_, err := uc.CreateMessage(ctx, userID, "one more")
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, apperror.CodeMessageRateLimited, tooMany.Code)
+	assert.Equal(t, 10*time.Second, tooMany.RetryAfter())
+
+	// Другой пользователь не затронут
+	_, err = uc.CreateMessage(ctx, uuid.New(), "hello")
+	assert.NoError(t, err)
+
+	// Через интервал восстанавливается одно сообщение
+	advance(10 * time.Second)
+	_, err = uc.CreateMessage(ctx, userID, "after refill")
+	assert.NoError(t, err)
+	_, err = uc.CreateMessage(ctx, userID, "too soon")
+	assert.ErrorAs(t, err, &tooMany)
+}
+
+func TestMessageUsecase_CreateMessage_Duplicate(t *testing.T) {
+	// Arrange
+	uc, advance := newAntiSpamUsecase(AntiSpamConfig{DuplicateWindow: time.Minute}, entity.RoleUser)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := uc.CreateMessage(ctx, userID, "Buy now")
+	require.NoError(t, err)
+	advance(20 * time.Second)
+
+	// Act
+	_, duplicateErr := uc.CreateMessage(ctx, userID, "  buy   NOW ")
+	_, otherErr := uc.CreateMessage(ctx, userID, "something else")
+	advance(40 * time.Second)
+	_, afterWindowErr := uc.CreateMessage(ctx, userID, "Buy now")
+
+	// Assert
+	var tooMany *apperror.Error
+	require.ErrorAs(t, duplicateErr, &tooMany)
+	assert.Equal(t, apperror.CodeMessageDuplicate, tooMany.Code)
+	assert.Equal(t, 40*time.Second, tooMany.RetryAfter())
+	assert.NoError(t, otherErr)
+	assert.NoError(t, afterWindowErr)
+}
+
+func TestMessageUsecase_CreateMessage_SlowMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    entity.Role
+		limited bool
+	}{
+		{"пользователь ждет интервал", entity.RoleUser, true},
+		{"модератор освобожден", entity.RoleModerator, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			uc, advance := newAntiSpamUsecase(AntiSpamConfig{SlowMode: 30 * time.Second}, tt.role)
+			userID := uuid.New()
+			ctx := context.Background()
+
+			_, err := uc.CreateMessage(ctx, userID, "first")
+			require.NoError(t, err)
+			advance(10 * time.Second)
+
+			// Act
+			_, err = uc.CreateMessage(ctx, userID, "second")
+
+			// Assert
+			if !tt.limited {
+				assert.NoError(t, err)
+				return
+			}
+			var tooMany *apperror.Error
+			require.ErrorAs(t, err, &tooMany)
+			assert.Equal(t, apperror.CodeMessageSlowMode, tooMany.Code)
+			assert.Equal(t, 20*time.Second, tooMany.RetryAfter())
+
+			advance(20 * time.Second)
+			_, err = uc.CreateMessage(ctx, userID, "third")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMessageUsecase_CreateMessage_RejectedMessageDoesNotConsumeLimit(t *testing.T) {
+	// Arrange
+	uc, _ := newAntiSpamUsecase(AntiSpamConfig{Burst: 1, RefillInterval: time.Minute}, entity.RoleUser)
+	userID := uuid.New()
 
 	// Act
-	err := usecase.DeleteMessage(context.Background(), testMessageID)
+	_, invalidErr := uc.CreateMessage(context.Background(), userID, "")
+	_, err := uc.CreateMessage(context.Background(), userID, "valid")
 
 	// Assert
+	assert.Error(t, invalidErr)
 	assert.NoError(t, err)
 }
 
-// NotFoundError представляет ошибку, когда ресурс не найден.
+func TestMessageUsecase_CreateMessage_FailedSaveDoesNotConsumeLimit(t *testing.T) {
+	// Arrange
+	uc, _ := newAntiSpamUsecase(AntiSpamConfig{
+		Burst:           1,
+		RefillInterval:  time.Minute,
+		DuplicateWindow: time.Minute,
+		SlowMode:        time.Minute,
+	}, entity.RoleUser)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	saveErr := errors.New("database is unavailable")
+	uc.messageRepo = &mocks.MessageRepoMock{
+		CreateFunc: func(ctx context.Context, message *entity.Message) error {
+			return saveErr
+		},
+	}
+
+	// Act: повтор того же сообщения после ошибки сохранения
+	_, failedErr := uc.CreateMessage(ctx, userID, "hello")
+	uc.messageRepo = &mocks.MessageRepoMock{}
+	_, retryErr := uc.CreateMessage(ctx, userID, "hello")
+	_, limitedErr := uc.CreateMessage(ctx, userID, "hello again")
+
+	// Assert
+	assert.ErrorIs(t, failedErr, saveErr)
+	assert.NoError(t, retryErr, "неудачное сохранение не должно расходовать лимиты")
+	var tooMany *apperror.Error
+	require.ErrorAs(t, limitedErr, &tooMany)
+	assert.Equal(t, apperror.CodeMessageSlowMode, tooMany.Code)
+}
+
+func TestMessageUsecase_UpdateConfig(t *testing.T) {
+	// Arrange
+	uc, _ := newAntiSpamUsecase(AntiSpamConfig{}, entity.RoleUser)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := uc.CreateMessage(ctx, userID, "before slow mode")
+	require.NoError(t, err)
+
+	// Act
+	uc.UpdateConfig(Config{AntiSpam: AntiSpamConfig{SlowMode: time.Minute}})
+	_, err = uc.CreateMessage(ctx, userID, "after slow mode")
+
+	// Assert: новый лимит учитывает сообщение, отправленное до изменения
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, apperror.CodeMessageSlowMode, tooMany.Code)
+	assert.Equal(t, time.Minute, tooMany.RetryAfter())
+}
+
+func TestMessageUsecase_GetAllMessages_ExcludesBlockedUsers(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	viewerID := uuid.New()
+	blockedID := uuid.New()
+	visible := &entity.Message{ID: uuid.New(), UserID: uuid.New(), Content: "hello"}
+	messages := []*entity.Message{
+		{ID: uuid.New(), UserID: blockedID, Content: "spam"},
+		visible,
+	}
+
+	messageRepo := &mocks.MessageRepoMock{
+		GetAllFunc: func(ctx context.Context) ([]*entity.Message, error) {
+			return messages, nil
+		},
+	}
+	blockRepo := &mocks.UserBlockRepoMock{
+		ListBlockedIDsFunc: func(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+			if blockerID != viewerID {
+				return nil, nil
+			}
+			return []uuid.UUID{blockedID}, nil
+		},
+	}
+	usecase := NewMessageUsecase(messageRepo, &mocks.UserRepoMock{}, &mocks.ReportRepoMock{}, blockRepo, nil, Config{}, logger)
+
+	// Act
+	viewerResult, viewerErr := usecase.GetAllMessages(context.Background(), viewerID)
+	anonymousResult, anonymousErr := usecase.GetAllMessages(context.Background(), uuid.Nil)
+
+	// Assert
+	require.NoError(t, viewerErr)
+	assert.Equal(t, []*entity.Message{visible}, viewerResult)
+	require.NoError(t, anonymousErr)
+	assert.Len(t, anonymousResult, 2, "анонимный просмотр не фильтруется")
+}
+
+func TestMessageUsecase_GetMessageByID_BlockedAuthor(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	viewerID := uuid.New()
+	blockedID := uuid.New()
+	message := &entity.Message{ID: uuid.New(), UserID: blockedID, Content: "spam"}
+
+	messageRepo := &mocks.MessageRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+			return message, nil
+		},
+	}
+	blockRepo := &mocks.UserBlockRepoMock{
+		ListBlockedIDsFunc: func(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+			if blockerID != viewerID {
+				return nil, nil
+			}
+			return []uuid.UUID{blockedID}, nil
+		},
+	}
+	usecase := NewMessageUsecase(messageRepo, &mocks.UserRepoMock{}, &mocks.ReportRepoMock{}, blockRepo, nil, Config{}, logger)
+
+	// Act
+	blockedResult, blockedErr := usecase.GetMessageByID(context.Background(), message.ID, viewerID, entity.RoleUser)
+	otherResult, otherErr := usecase.GetMessageByID(context.Background(), message.ID, uuid.New(), entity.RoleUser)
+
+	// Assert
+	assert.Nil(t, blockedResult)
+	assert.Equal(t, apperror.CodeMessageNotFound, apperror.CodeOf(blockedErr))
+	require.NoError(t, otherErr)
+	assert.Equal(t, message, otherResult)
+}
+
+func TestMessageUsecase_CreateMessage_MentionOfBlocker(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantQuery []string
+		forbidden bool
+	}{
+		{"упоминание заблокировавшего", "hey @Alice, look", []string{"alice"}, true},
+		{"упоминание другого пользователя", "thanks @bob.", []string{"bob"}, false},
+		{"адрес почты не упоминание", "write to alice@example.com", nil, false},
+		{"повторы проверяются один раз", "@carol @CAROL @alice", []string{"carol", "alice"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			authorID := uuid.New()
+			var queried []string
+			saved := false
+
+			userRepo := &mocks.UserRepoMock{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+					return &entity.User{ID: id, Role: entity.RoleUser}, nil
+				},
+			}
+			messageRepo := &mocks.MessageRepoMock{
+				CreateFunc: func(ctx context.Context, message *entity.Message) error {
+					saved = true
+					return nil
+				},
+			}
+			blockRepo := &mocks.UserBlockRepoMock{
+				FindBlockersByUsernameFunc: func(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error) {
+					assert.Equal(t, authorID, blockedID)
+					queried = usernames
+					for _, username := range usernames {
+						if username == "alice" {
+							return []string{"Alice"}, nil
+						}
+					}
+					return nil, nil
+				},
+			}
+			usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, blockRepo, nil, Config{}, logger)
+
+			// Act
+			_, err := usecase.CreateMessage(context.Background(), authorID, tt.content)
+
+			// Assert
+			assert.Equal(t, tt.wantQuery, queried)
+			if !tt.forbidden {
+				assert.NoError(t, err)
+				assert.True(t, saved)
+				return
+			}
+			assert.Equal(t, apperror.CodeMessageMentionBlocked, apperror.CodeOf(err))
+			assert.False(t, saved)
+		})
+	}
+}
+
+func TestMessageUsecase_CreateMessage_MentionOfBlockedUser(t *testing.T) {
+	// Arrange: автор заблокировал упомянутого пользователя, сам он никем не заблокирован
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	authorID := uuid.New()
+	saved := false
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, Role: entity.RoleUser}, nil
+		},
+	}
+	messageRepo := &mocks.MessageRepoMock{
+		CreateFunc: func(ctx context.Context, message *entity.Message) error {
+			saved = true
+			return nil
+		},
+	}
+	blockRepo := &mocks.UserBlockRepoMock{
+		FindBlockedByUsernameFunc: func(ctx context.Context, blockerID uuid.UUID, usernames []string) ([]string, error) {
+			assert.Equal(t, authorID, blockerID)
+			assert.Equal(t, []string{"mallory"}, usernames)
+			return []string{"Mallory"}, nil
+		},
+	}
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, blockRepo, nil, Config{}, logger)
+
+	// Act
+	_, err := usecase.CreateMessage(context.Background(), authorID, "what do you think, @Mallory?")
+
+	// Assert
+	assert.Equal(t, apperror.CodeMessageMentionBlocked, apperror.CodeOf(err))
+	assert.False(t, saved)
+}
+
 type NotFoundError struct {
 	Message string
 }
 
-// Error реализует интерфейс error.
 func (e *NotFoundError) Error() string {
 	return e.Message
 }
 
-// NotFound сигнализирует, что это ошибка "не найдено".
-// Полезно для проверки типа в хендлерах или других местах.
 func (e *NotFoundError) NotFound() bool {
 	return true
 }
+
+func TestMessageUsecase_CreateMessage_TooManyMentions(t *testing.T) {
+	// Arrange: заблокировавший автора пользователь упомянут после maxMentions других
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	content := ""
+	for i := 0; i < maxMentions; i++ {
+		content += fmt.Sprintf("@user%02d ", i)
+	}
+	content += "@alice"
+
+	saved := false
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, Role: entity.RoleUser}, nil
+		},
+	}
+	messageRepo := &mocks.MessageRepoMock{
+		CreateFunc: func(ctx context.Context, message *entity.Message) error {
+			saved = true
+			return nil
+		},
+	}
+	blockRepo := &mocks.UserBlockRepoMock{
+		FindBlockersByUsernameFunc: func(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error) {
+			t.Fatal("mentions must be rejected before the block check")
+			return nil, nil
+		},
+	}
+	usecase := NewMessageUsecase(messageRepo, userRepo, &mocks.ReportRepoMock{}, blockRepo, nil, Config{}, logger)
+
+	// Act
+	_, err := usecase.CreateMessage(context.Background(), uuid.New(), content)
+
+	// Assert
+	assert.Equal(t, apperror.CodeMessageTooManyMentions, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+	assert.False(t, saved)
+}
@@ -8,9 +8,12 @@ import (
 )
 
 type MessageUsecase interface {
+	// CreateMessage единственный путь записи текста сообщения, поэтому фильтр содержимого применяется только здесь
 	CreateMessage(ctx context.Context, userID uuid.UUID, content string) (*entity.Message, error)
-	GetMessageByID(ctx context.Context, messageID uuid.UUID) (*entity.Message, error)
+	GetMessageByID(ctx context.Context, messageID, actorID uuid.UUID, actorRole entity.Role) (*entity.Message, error)
 	GetMessagesByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Message, error)
-	GetAllMessages(ctx context.Context) ([]*entity.Message, error)
-	DeleteMessage(ctx context.Context, messageID uuid.UUID) error
+	GetAllMessages(ctx context.Context, viewerID uuid.UUID) ([]*entity.Message, error)
+	DeleteMessage(ctx context.Context, messageID, actorID uuid.UUID, actorRole entity.Role) error
+	// UpdateConfig применяет новые параметры публикации без перезапуска
+	UpdateConfig(config Config)
 }
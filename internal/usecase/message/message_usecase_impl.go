@@ -1,123 +1,371 @@
 package message
 
 import (
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
 	"chat-service/internal/usecase"
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// Config параметры публикации сообщений
+type Config struct {
+	// RequireVerifiedEmail запрещает публикацию до подтверждения email
+	RequireVerifiedEmail bool
+	// AntiSpam ограничения частоты публикации
+	AntiSpam AntiSpamConfig
+}
+
 type messageUsecase struct {
-	messageRepo usecase.MessageRepository
-	userRepo    usecase.UserRepository
-	logger      *logrus.Logger
+	messageRepo   usecase.MessageRepository
+	userRepo      usecase.UserRepository
+	reportRepo    usecase.ReportRepository
+	blockRepo     usecase.UserBlockRepository
+	contentFilter service.ContentFilter
+	configMu      sync.RWMutex
+	config        Config
+	antiSpam      *antiSpam
+	logger        *logrus.Logger
+	now           func() time.Time
 }
 
-func NewMessageUsecase(messageRepo usecase.MessageRepository, userRepo usecase.UserRepository, logger *logrus.Logger) MessageUsecase {
+// NewMessageUsecase создает usecase сообщений. contentFilter может быть nil - тогда фильтрация отключена
+func NewMessageUsecase(
+	messageRepo usecase.MessageRepository,
+	userRepo usecase.UserRepository,
+	reportRepo usecase.ReportRepository,
+	blockRepo usecase.UserBlockRepository,
+	contentFilter service.ContentFilter,
+	config Config,
+	logger *logrus.Logger,
+) MessageUsecase {
 	return &messageUsecase{
-		messageRepo: messageRepo,
-		userRepo:    userRepo,
-		logger:      logger,
+		messageRepo:   messageRepo,
+		userRepo:      userRepo,
+		reportRepo:    reportRepo,
+		blockRepo:     blockRepo,
+		contentFilter: contentFilter,
+		config:        config,
+		antiSpam:      newAntiSpam(config.AntiSpam),
+		logger:        logger,
+		now:           time.Now,
 	}
 }
 
+// UpdateConfig применяет новые параметры публикации. Накопленное состояние антиспама сохраняется
+func (m *messageUsecase) UpdateConfig(config Config) {
+	m.configMu.Lock()
+	m.config = config
+	m.configMu.Unlock()
+
+	m.antiSpam.setConfig(config.AntiSpam)
+}
+
+func (m *messageUsecase) currentConfig() Config {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
+	return m.config
+}
+
 func (m *messageUsecase) CreateMessage(ctx context.Context, userID uuid.UUID, content string) (*entity.Message, error) {
-	m.logger.WithFields(logrus.Fields{
+	ctx, span := tracing.Start(ctx, "MessageUsecase.CreateMessage", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"user_id": userID,
 		"content": content[:min(50, len(content))],
 	}).Info("creating new message")
 
 	// Проверяем существование пользователя
-	m.logger.WithField("user_id", userID).Debug("checking user existence")
-	_, err := m.userRepo.GetByID(ctx, userID)
+	m.logger.WithContext(ctx).WithField("user_id", userID).Debug("checking user existence")
+	user, err := m.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		m.logger.WithError(err).WithField("user_id", userID).Warn("user not found")
-		return nil, &BusinessError{"user not found"}
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("user not found")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found")
+	}
+
+	if m.currentConfig().RequireVerifiedEmail && !user.IsEmailVerified() {
+		m.logger.WithContext(ctx).WithField("user_id", userID).Warn("message rejected: email not verified")
+		return nil, apperror.Forbidden(apperror.CodeUserEmailNotVerified, "email must be verified before posting messages")
 	}
 
+	var filtered *entity.ContentFilterResult
+	if m.contentFilter != nil {
+		filtered = m.contentFilter.Apply(content)
+		if filtered.Rejected {
+			m.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"user_id": userID,
+				"rules":   filtered.MatchedRules,
+			}).Warn("message rejected by content filter")
+			return nil, apperror.Validation(apperror.CodeMessageProhibitedContent, "message contains prohibited content")
+		}
+		content = filtered.Content
+	}
+
+	now := m.now()
 	message := &entity.Message{
 		ID:        uuid.New(),
 		UserID:    userID,
 		Content:   content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	if err := message.Validate(); err != nil {
-		m.logger.WithError(err).Warn("message validation failed")
+		m.logger.WithContext(ctx).WithError(err).Warn("message validation failed")
+		return nil, err
+	}
+
+	if err := m.checkMentions(ctx, userID, content); err != nil {
 		return nil, err
 	}
 
-	m.logger.WithField("message_id", message.ID).Debug("saving message to repository")
+	// Лимиты проверяются последними, чтобы отклоненные сообщения не расходовали их,
+	// а если сообщение не удалось сохранить, учет публикации отменяется
+	release, err := m.antiSpam.allow(userID, content, user.Role.Can(entity.PermissionReportsReview), now)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("message rejected by anti-spam")
+		return nil, err
+	}
+
+	m.logger.WithContext(ctx).WithField("message_id", message.ID).Debug("saving message to repository")
 	if err := m.messageRepo.Create(ctx, message); err != nil {
-		m.logger.WithError(err).WithField("message_id", message.ID).Error("failed to create message")
+		release()
+		m.logger.WithContext(ctx).WithError(err).WithField("message_id", message.ID).Error("failed to create message")
 		return nil, err
 	}
 
-	m.logger.WithField("message_id", message.ID).Info("message created successfully")
+	if filtered != nil && filtered.Flagged {
+		m.flagMessage(ctx, message, filtered.MatchedRules)
+	}
+
+	m.logger.WithContext(ctx).WithField("message_id", message.ID).Info("message created successfully")
 	return message, nil
 }
 
-func (m *messageUsecase) GetMessageByID(ctx context.Context, messageID uuid.UUID) (*entity.Message, error) {
-	m.logger.WithField("message_id", messageID).Debug("fetching message by ID")
+// checkMentions запрещает упоминать пользователей, которые заблокировали автора или которых
+// заблокировал он сам. Сообщение с большим числом упоминаний отклоняется целиком, чтобы
+// проверялись все упомянутые
+func (m *messageUsecase) checkMentions(ctx context.Context, userID uuid.UUID, content string) error {
+	mentions := parseMentions(content)
+	if len(mentions) == 0 {
+		return nil
+	}
+	if len(mentions) > maxMentions {
+		return apperror.Validation(apperror.CodeMessageTooManyMentions,
+			fmt.Sprintf("message cannot mention more than %d users", maxMentions))
+	}
+
+	blockers, err := m.blockRepo.FindBlockersByUsername(ctx, userID, mentions)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to check mentioned users")
+		return err
+	}
+	if len(blockers) > 0 {
+		m.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"user_id":  userID,
+			"blockers": blockers,
+		}).Warn("message rejected: mentions a user who blocked the author")
+		return apperror.Forbidden(apperror.CodeMessageMentionBlocked, "you cannot mention a user who has blocked you")
+	}
+
+	blocked, err := m.blockRepo.FindBlockedByUsername(ctx, userID, mentions)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to check mentioned users")
+		return err
+	}
+	if len(blocked) > 0 {
+		m.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"user_id": userID,
+			"blocked": blocked,
+		}).Warn("message rejected: mentions a user blocked by the author")
+		return apperror.Forbidden(apperror.CodeMessageMentionBlocked, "you cannot mention a user you have blocked")
+	}
+	return nil
+}
+
+// flagMessage отправляет сообщение в очередь модерации системной жалобой. Сообщение уже
+// опубликовано, поэтому сбой только логируется
+func (m *messageUsecase) flagMessage(ctx context.Context, message *entity.Message, rules []uuid.UUID) {
+	// Комментарий жалобы ограничен 1000 символами, этого хватает на 20 ID
+	ruleIDs := make([]string, 0, len(rules))
+	for _, id := range rules[:min(len(rules), 20)] {
+		ruleIDs = append(ruleIDs, id.String())
+	}
+
+	report := &entity.Report{
+		ID:        uuid.New(),
+		MessageID: message.ID,
+		Reason:    entity.ReportReasonContentFilter,
+		Comment:   "matched content filter rules: " + strings.Join(ruleIDs, ", "),
+		Status:    entity.ReportStatusOpen,
+		CreatedAt: message.CreatedAt,
+		UpdatedAt: message.CreatedAt,
+	}
+	if err := m.reportRepo.Create(ctx, report); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("message_id", message.ID).Error("failed to flag message for moderation")
+		return
+	}
+
+	m.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"message_id": message.ID,
+		"report_id":  report.ID,
+	}).Info("message flagged by content filter")
+}
+
+// GetMessageByID возвращает сообщение. Скрытое модератором сообщение видят только автор и модераторы,
+// сообщение заблокированного actor пользователя не возвращается, как и в ленте
+func (m *messageUsecase) GetMessageByID(ctx context.Context, messageID, actorID uuid.UUID, actorRole entity.Role) (*entity.Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageUsecase.GetMessageByID", tracing.UserID(actorID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("message_id", messageID).Debug("fetching message by ID")
 
 	message, err := m.messageRepo.GetByID(ctx, messageID)
 	if err != nil {
-		m.logger.WithError(err).WithField("message_id", messageID).Error("failed to fetch message")
+		m.logger.WithContext(ctx).WithError(err).WithField("message_id", messageID).Error("failed to fetch message")
 		return nil, err
 	}
 
-	m.logger.WithField("message_id", messageID).Debug("message fetched successfully")
+	if message.IsHidden() && message.UserID != actorID && !actorRole.Can(entity.PermissionReportsReview) {
+		m.logger.WithContext(ctx).WithField("message_id", messageID).Debug("hidden message requested")
+		return nil, apperror.NotFound(apperror.CodeMessageNotFound, "message not found")
+	}
+
+	visible, err := m.excludeBlocked(ctx, actorID, []*entity.Message{message})
+	if err != nil {
+		return nil, err
+	}
+	if len(visible) == 0 {
+		m.logger.WithContext(ctx).WithField("message_id", messageID).Debug("message of a blocked user requested")
+		return nil, apperror.NotFound(apperror.CodeMessageNotFound, "message not found")
+	}
+
+	m.logger.WithContext(ctx).WithField("message_id", messageID).Debug("message fetched successfully")
 	return message, nil
 }
 
 func (m *messageUsecase) GetMessagesByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Message, error) {
-	m.logger.WithField("user_id", userID).Debug("fetching messages by user")
+	ctx, span := tracing.Start(ctx, "MessageUsecase.GetMessagesByUser", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Debug("fetching messages by user")
 
 	// Проверяем существование пользователя
-	m.logger.WithField("user_id", userID).Debug("checking user existence")
+	m.logger.WithContext(ctx).WithField("user_id", userID).Debug("checking user existence")
 	_, err := m.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		m.logger.WithError(err).WithField("user_id", userID).Warn("user not found")
-		return nil, &BusinessError{"user not found"}
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("user not found")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found")
 	}
 
 	messages, err := m.messageRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		m.logger.WithError(err).WithField("user_id", userID).Error("failed to fetch user messages")
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch user messages")
 		return nil, err
 	}
 
-	m.logger.WithField("user_id", userID).Debugf("fetched %d messages for user", len(messages))
+	m.logger.WithContext(ctx).WithField("user_id", userID).Debugf("fetched %d messages for user", len(messages))
 	return messages, nil
 }
 
-func (m *messageUsecase) GetAllMessages(ctx context.Context) ([]*entity.Message, error) {
-	m.logger.Debug("fetching all messages")
+// GetAllMessages возвращает все сообщения. Если viewerID не uuid.Nil, сообщения
+// заблокированных им пользователей исключаются
+func (m *messageUsecase) GetAllMessages(ctx context.Context, viewerID uuid.UUID) ([]*entity.Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageUsecase.GetAllMessages", tracing.UserID(viewerID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).Debug("fetching all messages")
 
 	messages, err := m.messageRepo.GetAll(ctx)
 	if err != nil {
-		m.logger.WithError(err).Error("failed to fetch all messages")
+		m.logger.WithContext(ctx).WithError(err).Error("failed to fetch all messages")
 		return nil, err
 	}
 
-	m.logger.Debugf("fetched %d messages total", len(messages))
+	messages, err = m.excludeBlocked(ctx, viewerID, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.WithContext(ctx).Debugf("fetched %d messages total", len(messages))
 	return messages, nil
 }
 
-func (m *messageUsecase) DeleteMessage(ctx context.Context, messageID uuid.UUID) error {
-	m.logger.WithField("message_id", messageID).Warn("deleting message")
+// excludeBlocked убирает из списка сообщения пользователей, заблокированных viewerID
+func (m *messageUsecase) excludeBlocked(ctx context.Context, viewerID uuid.UUID, messages []*entity.Message) ([]*entity.Message, error) {
+	if viewerID == uuid.Nil {
+		return messages, nil
+	}
 
-	err := m.messageRepo.Delete(ctx, messageID)
+	blockedIDs, err := m.blockRepo.ListBlockedIDs(ctx, viewerID)
 	if err != nil {
-		m.logger.WithError(err).WithField("message_id", messageID).Error("failed to delete message")
+		m.logger.WithContext(ctx).WithError(err).WithField("viewer_id", viewerID).Error("failed to fetch blocked users")
+		return nil, err
+	}
+	if len(blockedIDs) == 0 {
+		return messages, nil
+	}
+
+	blocked := make(map[uuid.UUID]struct{}, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = struct{}{}
+	}
+
+	visible := make([]*entity.Message, 0, len(messages))
+	for _, message := range messages {
+		if _, ok := blocked[message.UserID]; !ok {
+			visible = append(visible, message)
+		}
+	}
+	return visible, nil
+}
+
+// DeleteMessage удаляет сообщение, если actor его автор или его роли разрешено удалять любые сообщения
+func (m *messageUsecase) DeleteMessage(ctx context.Context, messageID, actorID uuid.UUID, actorRole entity.Role) error {
+	ctx, span := tracing.Start(ctx, "MessageUsecase.DeleteMessage", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := m.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"message_id": messageID,
+		"actor_id":   actorID,
+	})
+	logger.Warn("deleting message")
+
+	message, err := m.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		logger.WithError(err).Error("failed to fetch message for deletion")
 		return err
 	}
 
-	m.logger.WithField("message_id", messageID).Info("message deleted successfully")
+	if message.UserID != actorID && !actorRole.Can(entity.PermissionMessagesDeleteAny) {
+		logger.WithField("owner_id", message.UserID).Warn("user trying to delete another user's message")
+		return apperror.Forbidden(apperror.CodeMessageNotOwner, "you can only delete your own messages")
+	}
+
+	err = m.messageRepo.Delete(ctx, messageID)
+	if err != nil {
+		logger.WithError(err).Error("failed to delete message")
+		return err
+	}
+
+	if message.UserID != actorID {
+		logger.WithFields(logrus.Fields{
+			"owner_id": message.UserID,
+			"role":     actorRole,
+		}).Info("message deleted by moderator")
+		return nil
+	}
+
+	logger.Info("message deleted successfully")
 	return nil
 }
 
@@ -127,15 +375,3 @@ func min(a, b int) int {
 	}
 	return b
 }
-
-type BusinessError struct {
-	Message string
-}
-
-func (e *BusinessError) Error() string {
-	return e.Message
-}
-
-func (e *BusinessError) ValidationError() bool {
-	return true
-}
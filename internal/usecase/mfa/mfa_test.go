@@ -0,0 +1,522 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig() Config {
+	return Config{
+		Issuer:               "Chat Service",
+		ChallengeTTL:         5 * time.Minute,
+		RecoveryCodeCount:    10,
+		MaxChallengeAttempts: 5,
+	}
+}
+
+func TestMFAUsecase_Enroll_Success(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	testUserID := uuid.New()
+	var saved *entity.UserMFA
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "test@example.com"}, nil
+	}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return nil, &NotFoundError{"mfa settings not found"} // 2FA еще не настроена
+	}
+
+	mfaRepo.UpsertFunc = func(ctx context.Context, mfa *entity.UserMFA) error {
+		saved = mfa
+		return nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	enrollment, err := usecase.Enroll(context.Background(), testUserID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, enrollment)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "test@example.com")
+	assert.NotNil(t, saved)
+	assert.False(t, saved.Enabled) // Включается только после подтверждения
+}
+
+func TestMFAUsecase_Enroll_AlreadyEnabled(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "test@example.com"}, nil
+	}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	enrollment, err := usecase.Enroll(context.Background(), uuid.New())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, enrollment)
+	assert.Contains(t, err.Error(), "already enabled")
+	assert.Equal(t, apperror.CodeMFAAlreadyEnabled, apperror.CodeOf(err))
+}
+
+func TestMFAUsecase_ConfirmEnrollment_Success(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	testUserID := uuid.New()
+	var storedHashes []string
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET"}, nil
+	}
+
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 42, code == "123456"
+	}
+
+	mfaRepo.ReplaceRecoveryCodesFunc = func(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+		storedHashes = codeHashes
+		return nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	codes, err := usecase.ConfirmEnrollment(context.Background(), testUserID, "123456")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	assert.Len(t, storedHashes, 10)
+	for i, code := range codes {
+		assert.Regexp(t, `^[a-z0-9]{5}-[a-z0-9]{5}$`, code)
+		// В БД попадает только хэш, а не сам код
+		assert.Equal(t, service.HashToken(normalizeRecoveryCode(code)), storedHashes[i])
+		assert.NotEqual(t, code, storedHashes[i])
+	}
+}
+
+func TestMFAUsecase_ConfirmEnrollment_InvalidCode(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET"}, nil
+	}
+
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 0, false
+	}
+
+	mfaRepo.UpsertFunc = func(ctx context.Context, mfa *entity.UserMFA) error {
+		t.Fatal("mfa must not be enabled with an invalid code")
+		return nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	codes, err := usecase.ConfirmEnrollment(context.Background(), uuid.New(), "000000")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, codes)
+	assert.Contains(t, err.Error(), "invalid verification code")
+}
+
+func TestMFAUsecase_VerifyChallenge_TOTPSuccess(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	testUserID := uuid.New()
+	var usedStep int64
+
+	jwtService.ValidateMFATokenFunc = func(token string) (uuid.UUID, uuid.UUID, error) {
+		return testUserID, uuid.New(), nil
+	}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 100, true
+	}
+
+	mfaRepo.UpdateLastUsedStepFunc = func(ctx context.Context, userID uuid.UUID, step int64) error {
+		usedStep = step
+		return nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	userID, err := usecase.VerifyChallenge(context.Background(), "challenge", "123456")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, userID)
+	assert.Equal(t, int64(100), usedStep)
+}
+
+func TestMFAUsecase_VerifyChallenge_ReplayedCode(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+
+	// Шаг уже использовался - репозиторий не обновил запись
+	mfaRepo.UpdateLastUsedStepFunc = func(ctx context.Context, userID uuid.UUID, step int64) error {
+		return &NotFoundError{"totp step already used"}
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	userID, err := usecase.VerifyChallenge(context.Background(), "challenge", "123456")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, userID)
+	assert.Contains(t, err.Error(), "invalid verification code")
+	assert.Equal(t, apperror.CodeMFAInvalidCode, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindUnauthorized), "неверный код при входе - ошибка аутентификации")
+}
+
+func TestMFAUsecase_VerifyChallenge_RecoveryCode(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	testUserID := uuid.New()
+	var usedHash string
+
+	jwtService.ValidateMFATokenFunc = func(token string) (uuid.UUID, uuid.UUID, error) {
+		return testUserID, uuid.New(), nil
+	}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 0, false // Это не TOTP-код
+	}
+
+	mfaRepo.UseRecoveryCodeFunc = func(ctx context.Context, userID uuid.UUID, codeHash string) error {
+		usedHash = codeHash
+		return nil
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act - регистр и дефис не важны
+	userID, err := usecase.VerifyChallenge(context.Background(), "challenge", "ABCDE-FGHJK")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, userID)
+	assert.Equal(t, service.HashToken("abcdefghjk"), usedHash)
+}
+
+func TestMFAUsecase_VerifyChallenge_InvalidToken(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	jwtService.ValidateMFATokenFunc = func(token string) (uuid.UUID, uuid.UUID, error) {
+		return uuid.Nil, uuid.Nil, errors.New("token expired")
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	userID, err := usecase.VerifyChallenge(context.Background(), "expired", "123456")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, userID)
+	assert.Contains(t, err.Error(), "invalid or expired mfa challenge")
+	assert.Equal(t, apperror.CodeMFAChallengeInvalid, apperror.CodeOf(err))
+}
+
+// withChallengeStore хранит челленджи в памяти и подключает к моку репозитория; токен челленджа - его ID
+func withChallengeStore(mfaRepo *mocks.MFARepoMock, jwtService *mocks.JWTServiceMock) map[uuid.UUID]*entity.MFAChallengeState {
+	challenges := make(map[uuid.UUID]*entity.MFAChallengeState)
+	mfaRepo.CreateChallengeFunc = func(ctx context.Context, challenge *entity.MFAChallengeState) error {
+		challenges[challenge.ID] = challenge
+		return nil
+	}
+	mfaRepo.UseChallengeAttemptFunc = func(ctx context.Context, id, userID uuid.UUID, maxAttempts int, now time.Time) (int, error) {
+		challenge, ok := challenges[id]
+		if !ok || challenge.UserID != userID || !challenge.ExpiresAt.After(now) || challenge.Attempts >= maxAttempts {
+			return 0, &NotFoundError{"mfa challenge not found"}
+		}
+		challenge.Attempts++
+		return challenge.Attempts, nil
+	}
+	mfaRepo.DeleteChallengeFunc = func(ctx context.Context, id uuid.UUID) error {
+		if _, ok := challenges[id]; !ok {
+			return &NotFoundError{"mfa challenge not found"}
+		}
+		delete(challenges, id)
+		return nil
+	}
+	mfaRepo.DeleteChallengesByUserIDFunc = func(ctx context.Context, userID uuid.UUID) error {
+		for id, challenge := range challenges {
+			if challenge.UserID == userID {
+				delete(challenges, id)
+			}
+		}
+		return nil
+	}
+	jwtService.GenerateMFATokenFunc = func(userID, challengeID uuid.UUID, ttl time.Duration) (string, error) {
+		return challengeID.String(), nil
+	}
+	jwtService.ValidateMFATokenFunc = func(token string) (uuid.UUID, uuid.UUID, error) {
+		challengeID := uuid.MustParse(token)
+		if challenge, ok := challenges[challengeID]; ok {
+			return challenge.UserID, challengeID, nil
+		}
+		return uuid.New(), challengeID, nil
+	}
+	return challenges
+}
+
+func TestMFAUsecase_CreateChallenge_StoresChallenge(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	challenges := withChallengeStore(mfaRepo, jwtService)
+	usecase := NewMFAUsecase(mfaRepo, &mocks.UserRepoMock{}, &mocks.TOTPServiceMock{}, jwtService, newTestConfig(), logger)
+	testUserID := uuid.New()
+
+	// Act
+	challenge, err := usecase.CreateChallenge(context.Background(), testUserID)
+
+	// Assert
+	assert.NoError(t, err)
+	stored, ok := challenges[uuid.MustParse(challenge.Token)]
+	assert.True(t, ok, "jti токена совпадает с ID сохраненного челленджа")
+	assert.Equal(t, testUserID, stored.UserID)
+	assert.Equal(t, challenge.ExpiresAt, stored.ExpiresAt)
+}
+
+func TestMFAUsecase_CreateChallenge_InvalidatesPrevious(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	challenges := withChallengeStore(mfaRepo, jwtService)
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 100, true
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, &mocks.UserRepoMock{}, totpService, jwtService, newTestConfig(), logger)
+	testUserID := uuid.New()
+	otherUserID := uuid.New()
+	first, err := usecase.CreateChallenge(context.Background(), testUserID)
+	assert.NoError(t, err)
+	_, err = usecase.CreateChallenge(context.Background(), otherUserID)
+	assert.NoError(t, err)
+
+	// Act: повторный вход с паролем выдает новый челлендж
+	second, err := usecase.CreateChallenge(context.Background(), testUserID)
+	assert.NoError(t, err)
+	_, firstErr := usecase.VerifyChallenge(context.Background(), first.Token, "123456")
+
+	// Assert
+	assert.Equal(t, apperror.CodeMFAChallengeInvalid, apperror.CodeOf(firstErr))
+	assert.Len(t, challenges, 2, "челлендж другого пользователя не затронут")
+	assert.Contains(t, challenges, uuid.MustParse(second.Token))
+}
+
+func TestMFAUsecase_VerifyChallenge_SingleUse(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	withChallengeStore(mfaRepo, jwtService)
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+	step := int64(100)
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		step++ // Следующий код из нового окна, чтобы защита от повтора кода не мешала
+		return step, true
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, &mocks.UserRepoMock{}, totpService, jwtService, newTestConfig(), logger)
+	challenge, err := usecase.CreateChallenge(context.Background(), uuid.New())
+	assert.NoError(t, err)
+
+	// Act
+	_, firstErr := usecase.VerifyChallenge(context.Background(), challenge.Token, "123456")
+	_, secondErr := usecase.VerifyChallenge(context.Background(), challenge.Token, "654321")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Equal(t, apperror.CodeMFAChallengeInvalid, apperror.CodeOf(secondErr))
+}
+
+func TestMFAUsecase_VerifyChallenge_OutOfAttempts(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	withChallengeStore(mfaRepo, jwtService)
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return &entity.UserMFA{UserID: userID, Secret: "SECRET", Enabled: true}, nil
+	}
+	totpService.ValidateFunc = func(code, secret string, at time.Time) (int64, bool) {
+		return 100, code == "111111"
+	}
+	mfaRepo.UseRecoveryCodeFunc = func(ctx context.Context, userID uuid.UUID, codeHash string) error {
+		return &NotFoundError{"recovery code not found"}
+	}
+
+	config := newTestConfig()
+	usecase := NewMFAUsecase(mfaRepo, &mocks.UserRepoMock{}, totpService, jwtService, config, logger)
+	challenge, err := usecase.CreateChallenge(context.Background(), uuid.New())
+	assert.NoError(t, err)
+
+	// Act: все попытки челленджа потрачены на неверные коды
+	for i := 0; i < config.MaxChallengeAttempts; i++ {
+		_, err := usecase.VerifyChallenge(context.Background(), challenge.Token, "000000")
+		assert.Equal(t, apperror.CodeMFAInvalidCode, apperror.CodeOf(err))
+	}
+	userID, err := usecase.VerifyChallenge(context.Background(), challenge.Token, "111111")
+
+	// Assert: верный код после исчерпания попыток не принимается
+	assert.Equal(t, uuid.Nil, userID)
+	assert.Equal(t, apperror.CodeMFAChallengeInvalid, apperror.CodeOf(err))
+}
+
+func TestMFAUsecase_IsEnabled_NotConfigured(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mfaRepo := &mocks.MFARepoMock{}
+	userRepo := &mocks.UserRepoMock{}
+	totpService := &mocks.TOTPServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	mfaRepo.GetByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+		return nil, &NotFoundError{"mfa settings not found"}
+	}
+
+	usecase := NewMFAUsecase(mfaRepo, userRepo, totpService, jwtService, newTestConfig(), logger)
+
+	// Act
+	enabled, err := usecase.IsEnabled(context.Background(), uuid.New())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+// NotFoundError представляет ошибку, когда ресурс не найден.
+type NotFoundError struct {
+	Message string
+}
+
+// Error реализует интерфейс error.
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// NotFound сигнализирует, что это ошибка "не найдено".
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
@@ -0,0 +1,20 @@
+package mfa
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type MFAUsecase interface {
+	Enroll(ctx context.Context, userID uuid.UUID) (*entity.MFAEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	CreateChallenge(ctx context.Context, userID uuid.UUID) (*entity.MFAChallenge, error)
+	// ChallengeUser возвращает пользователя, которому выдан токен челленджа, не проверяя код
+	ChallengeUser(ctx context.Context, challengeToken string) (uuid.UUID, error)
+	VerifyChallenge(ctx context.Context, challengeToken, code string) (uuid.UUID, error)
+}
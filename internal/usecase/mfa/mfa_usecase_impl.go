@@ -0,0 +1,412 @@
+package mfa
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config параметры двухфакторной аутентификации
+type Config struct {
+	Issuer            string
+	ChallengeTTL      time.Duration
+	RecoveryCodeCount int
+	// MaxChallengeAttempts сколько кодов можно ввести по одному челленджу; затем нужно снова войти по паролю
+	MaxChallengeAttempts int
+}
+
+// 32 символа без l, i, o и 1, чтобы b%32 не давал смещения
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+type mfaUsecase struct {
+	mfaRepo     usecase.MFARepository
+	userRepo    usecase.UserRepository
+	totpService service.TOTPService
+	jwtService  service.JWTService
+	config      Config
+	logger      *logrus.Logger
+}
+
+func NewMFAUsecase(
+	mfaRepo usecase.MFARepository,
+	userRepo usecase.UserRepository,
+	totpService service.TOTPService,
+	jwtService service.JWTService,
+	config Config,
+	logger *logrus.Logger,
+) MFAUsecase {
+	return &mfaUsecase{
+		mfaRepo:     mfaRepo,
+		userRepo:    userRepo,
+		totpService: totpService,
+		jwtService:  jwtService,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+func (m *mfaUsecase) Enroll(ctx context.Context, userID uuid.UUID) (*entity.MFAEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.Enroll", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("starting mfa enrollment")
+
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("user not found")
+		return nil, apperror.NotFound(apperror.CodeUserNotFound, "user not found")
+	}
+
+	existing, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil && !isNotFound(err) {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch mfa settings")
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		m.logger.WithContext(ctx).WithField("user_id", userID).Warn("mfa already enabled")
+		return nil, apperror.Conflict(apperror.CodeMFAAlreadyEnabled, "two-factor authentication is already enabled")
+	}
+
+	secret, err := m.totpService.GenerateSecret()
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).Error("failed to generate totp secret")
+		return nil, err
+	}
+
+	now := time.Now()
+	settings := &entity.UserMFA{
+		UserID:    userID,
+		Secret:    secret,
+		Enabled:   false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := m.mfaRepo.Upsert(ctx, settings); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to save pending mfa enrollment")
+		return nil, err
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("mfa enrollment started")
+	return &entity.MFAEnrollment{
+		Secret: secret,
+		URI:    m.totpService.BuildURI(m.config.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (m *mfaUsecase) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.ConfirmEnrollment", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("confirming mfa enrollment")
+
+	settings, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, apperror.Conflict(apperror.CodeMFAEnrollmentMissing, "two-factor enrollment has not been started")
+		}
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch mfa settings")
+		return nil, err
+	}
+	if settings.Enabled {
+		return nil, apperror.Conflict(apperror.CodeMFAAlreadyEnabled, "two-factor authentication is already enabled")
+	}
+
+	step, ok := m.totpService.Validate(code, settings.Secret, time.Now())
+	if !ok {
+		m.logger.WithContext(ctx).WithField("user_id", userID).Warn("invalid confirmation code")
+		return nil, apperror.InvalidField(apperror.CodeMFAInvalidCode, "code", "invalid verification code")
+	}
+
+	now := time.Now()
+	settings.Enabled = true
+	settings.LastUsedStep = step
+	settings.ConfirmedAt = &now
+	settings.UpdatedAt = now
+
+	if err := m.mfaRepo.Upsert(ctx, settings); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to enable mfa")
+		return nil, err
+	}
+
+	codes, err := m.issueRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("mfa enabled successfully")
+	return codes, nil
+}
+
+func (m *mfaUsecase) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.Disable", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Warn("disabling mfa")
+
+	settings, err := m.enabledSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.verifyCode(ctx, settings, code); err != nil {
+		return err
+	}
+
+	if err := m.mfaRepo.Delete(ctx, userID); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to disable mfa")
+		return err
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("mfa disabled successfully")
+	return nil
+}
+
+func (m *mfaUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.RegenerateRecoveryCodes", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("regenerating recovery codes")
+
+	settings, err := m.enabledSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verifyCode(ctx, settings, code); err != nil {
+		return nil, err
+	}
+
+	return m.issueRecoveryCodes(ctx, userID)
+}
+
+func (m *mfaUsecase) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.IsEnabled", tracing.UserID(userID))
+	defer span.End()
+
+	settings, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch mfa settings")
+		return false, err
+	}
+
+	return settings != nil && settings.Enabled, nil
+}
+
+func (m *mfaUsecase) CreateChallenge(ctx context.Context, userID uuid.UUID) (*entity.MFAChallenge, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.CreateChallenge", tracing.UserID(userID))
+	defer span.End()
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("creating mfa challenge")
+
+	now := time.Now()
+	if err := m.mfaRepo.DeleteExpiredChallenges(ctx, now); err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("failed to delete expired mfa challenges")
+	}
+
+	// У пользователя действует только последний челлендж, иначе повторный вход с паролем
+	// давал бы новые попытки ввода кода, не отменяя старые
+	if err := m.mfaRepo.DeleteChallengesByUserID(ctx, userID); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete previous mfa challenges")
+		return nil, err
+	}
+
+	challenge := &entity.MFAChallengeState{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ExpiresAt: now.Add(m.config.ChallengeTTL),
+		CreatedAt: now,
+	}
+	if err := m.mfaRepo.CreateChallenge(ctx, challenge); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to store mfa challenge")
+		return nil, err
+	}
+
+	token, err := m.jwtService.GenerateMFAToken(userID, challenge.ID, m.config.ChallengeTTL)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to generate mfa challenge token")
+		return nil, err
+	}
+
+	return &entity.MFAChallenge{
+		Token:     token,
+		ExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+func (m *mfaUsecase) ChallengeUser(ctx context.Context, challengeToken string) (uuid.UUID, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.ChallengeUser")
+	defer span.End()
+
+	userID, _, err := m.jwtService.ValidateMFAToken(challengeToken)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("invalid mfa challenge token")
+		return uuid.Nil, errChallengeInvalid()
+	}
+	return userID, nil
+}
+
+func (m *mfaUsecase) VerifyChallenge(ctx context.Context, challengeToken, code string) (uuid.UUID, error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.VerifyChallenge")
+	defer span.End()
+
+	userID, challengeID, err := m.jwtService.ValidateMFAToken(challengeToken)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("invalid mfa challenge token")
+		return uuid.Nil, errChallengeInvalid()
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("verifying mfa challenge")
+
+	// Попытка учитывается до проверки кода, чтобы параллельные запросы не обходили лимит
+	attempts, err := m.mfaRepo.UseChallengeAttempt(ctx, challengeID, userID, m.config.MaxChallengeAttempts, time.Now())
+	if err != nil {
+		if isNotFound(err) {
+			m.logger.WithContext(ctx).WithField("user_id", userID).Warn("mfa challenge already used, expired or out of attempts")
+			return uuid.Nil, errChallengeInvalid()
+		}
+		return uuid.Nil, err
+	}
+
+	settings, err := m.enabledSettings(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := m.verifyCode(ctx, settings, code); err != nil {
+		if attempts >= m.config.MaxChallengeAttempts {
+			m.logger.WithContext(ctx).WithField("user_id", userID).Warn("mfa challenge is out of attempts")
+		}
+		// При входе неверный код означает неверные учетные данные, а не ошибку заполнения формы
+		if appErr, ok := apperror.As(err); ok && appErr.Kind == apperror.KindValidation {
+			return uuid.Nil, apperror.Unauthorized(appErr.Code, appErr.Message)
+		}
+		return uuid.Nil, err
+	}
+
+	// Челлендж одноразовый: токен нельзя предъявить еще раз
+	if err := m.mfaRepo.DeleteChallenge(ctx, challengeID); err != nil {
+		if isNotFound(err) {
+			m.logger.WithContext(ctx).WithField("user_id", userID).Warn("mfa challenge was used concurrently")
+			return uuid.Nil, errChallengeInvalid()
+		}
+		return uuid.Nil, err
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", userID).Info("mfa challenge passed")
+	return userID, nil
+}
+
+func errChallengeInvalid() error {
+	return apperror.Unauthorized(apperror.CodeMFAChallengeInvalid, "invalid or expired mfa challenge")
+}
+
+func (m *mfaUsecase) enabledSettings(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	settings, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, apperror.Conflict(apperror.CodeMFANotEnabled, "two-factor authentication is not enabled")
+		}
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch mfa settings")
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, apperror.Conflict(apperror.CodeMFANotEnabled, "two-factor authentication is not enabled")
+	}
+	return settings, nil
+}
+
+// verifyCode принимает либо TOTP-код, либо одноразовый код восстановления
+func (m *mfaUsecase) verifyCode(ctx context.Context, settings *entity.UserMFA, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return apperror.InvalidField(apperror.CodeValidationFailed, "code", "verification code is required")
+	}
+
+	if step, ok := m.totpService.Validate(code, settings.Secret, time.Now()); ok {
+		// Один и тот же код нельзя использовать повторно в пределах окна
+		if err := m.mfaRepo.UpdateLastUsedStep(ctx, settings.UserID, step); err != nil {
+			if isNotFound(err) {
+				m.logger.WithContext(ctx).WithField("user_id", settings.UserID).Warn("totp code replay detected")
+				return apperror.InvalidField(apperror.CodeMFAInvalidCode, "code", "invalid verification code")
+			}
+			return err
+		}
+		return nil
+	}
+
+	hash := service.HashToken(normalizeRecoveryCode(code))
+	if err := m.mfaRepo.UseRecoveryCode(ctx, settings.UserID, hash); err != nil {
+		if isNotFound(err) {
+			m.logger.WithContext(ctx).WithField("user_id", settings.UserID).Warn("invalid mfa code")
+			return apperror.InvalidField(apperror.CodeMFAInvalidCode, "code", "invalid verification code")
+		}
+		return err
+	}
+
+	m.logger.WithContext(ctx).WithField("user_id", settings.UserID).Warn("recovery code used for authentication")
+	return nil
+}
+
+func (m *mfaUsecase) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, m.config.RecoveryCodeCount)
+	hashes := make([]string, 0, m.config.RecoveryCodeCount)
+
+	for i := 0; i < m.config.RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			m.logger.WithContext(ctx).WithError(err).Error("failed to generate recovery code")
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, service.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := m.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to store recovery codes")
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode возвращает код вида "xxxxx-xxxxx" без похожих символов
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, b := range buf {
+		if i == 5 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepoMock struct {
+	CreateFunc              func(ctx context.Context, key *entity.APIKey) error
+	GetByHashFunc           func(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListByUserIDFunc        func(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error)
+	CountActiveByUserIDFunc func(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	RevokeFunc              func(ctx context.Context, id, userID uuid.UUID) error
+	RevokeAllByUserIDFunc   func(ctx context.Context, userID uuid.UUID) error
+	TouchLastUsedFunc       func(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+func (m *APIKeyRepoMock) Create(ctx context.Context, key *entity.APIKey) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, key)
+	}
+	return nil
+}
+
+func (m *APIKeyRepoMock) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	if m.GetByHashFunc != nil {
+		return m.GetByHashFunc(ctx, keyHash)
+	}
+	return nil, nil
+}
+
+func (m *APIKeyRepoMock) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.APIKey, error) {
+	if m.ListByUserIDFunc != nil {
+		return m.ListByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *APIKeyRepoMock) CountActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	if m.CountActiveByUserIDFunc != nil {
+		return m.CountActiveByUserIDFunc(ctx, userID, now)
+	}
+	return 0, nil
+}
+
+func (m *APIKeyRepoMock) Revoke(ctx context.Context, id, userID uuid.UUID) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, id, userID)
+	}
+	return nil
+}
+
+func (m *APIKeyRepoMock) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	if m.RevokeAllByUserIDFunc != nil {
+		return m.RevokeAllByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *APIKeyRepoMock) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if m.TouchLastUsedFunc != nil {
+		return m.TouchLastUsedFunc(ctx, id, at)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"chat-service/internal/entity"
+)
+
+type ContentFilterMock struct {
+	LoadFunc  func(rules []*entity.ContentFilterRule)
+	ApplyFunc func(content string) *entity.ContentFilterResult
+}
+
+func (m *ContentFilterMock) Load(rules []*entity.ContentFilterRule) {
+	if m.LoadFunc != nil {
+		m.LoadFunc(rules)
+	}
+}
+
+func (m *ContentFilterMock) Apply(content string) *entity.ContentFilterResult {
+	if m.ApplyFunc != nil {
+		return m.ApplyFunc(content)
+	}
+	return &entity.ContentFilterResult{Content: content}
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type ContentFilterRuleRepoMock struct {
+	CreateFunc  func(ctx context.Context, rule *entity.ContentFilterRule) error
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*entity.ContentFilterRule, error)
+	ListFunc    func(ctx context.Context) ([]*entity.ContentFilterRule, error)
+	UpdateFunc  func(ctx context.Context, rule *entity.ContentFilterRule) error
+	DeleteFunc  func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *ContentFilterRuleRepoMock) Create(ctx context.Context, rule *entity.ContentFilterRule) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, rule)
+	}
+	return nil
+}
+
+func (m *ContentFilterRuleRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*entity.ContentFilterRule, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *ContentFilterRuleRepoMock) List(ctx context.Context) ([]*entity.ContentFilterRule, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *ContentFilterRuleRepoMock) Update(ctx context.Context, rule *entity.ContentFilterRule) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, rule)
+	}
+	return nil
+}
+
+func (m *ContentFilterRuleRepoMock) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerificationRepoMock struct {
+	CreateFunc            func(ctx context.Context, token *entity.EmailVerificationToken) error
+	GetByTokenHashFunc    func(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error)
+	GetLatestByUserIDFunc func(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error)
+	MarkUsedFunc          func(ctx context.Context, id uuid.UUID) error
+	DeleteByUserIDFunc    func(ctx context.Context, userID uuid.UUID) error
+}
+
+func (m *EmailVerificationRepoMock) Create(ctx context.Context, token *entity.EmailVerificationToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *EmailVerificationRepoMock) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *EmailVerificationRepoMock) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error) {
+	if m.GetLatestByUserIDFunc != nil {
+		return m.GetLatestByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *EmailVerificationRepoMock) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	if m.MarkUsedFunc != nil {
+		return m.MarkUsedFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *EmailVerificationRepoMock) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteByUserIDFunc != nil {
+		return m.DeleteByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package mocks
 type HashServiceMock struct {
 	HashPasswordFunc      func(password string) (string, error)
 	CheckPasswordHashFunc func(password, hash string) bool
+	NeedsRehashFunc       func(hash string) bool
 }
 
 func (m *HashServiceMock) HashPassword(password string) (string, error) {
@@ -18,3 +19,10 @@ func (m *HashServiceMock) CheckPasswordHash(password, hash string) bool {
 	}
 	return true
 }
+
+func (m *HashServiceMock) NeedsRehash(hash string) bool {
+	if m.NeedsRehashFunc != nil {
+		return m.NeedsRehashFunc(hash)
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepoMock struct {
+	CreateFunc        func(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error)
+	GetFunc           func(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error)
+	CompleteFunc      func(ctx context.Context, record *entity.IdempotencyRecord) error
+	DeleteFunc        func(ctx context.Context, record *entity.IdempotencyRecord) error
+	DeleteExpiredFunc func(ctx context.Context, before time.Time) error
+}
+
+func (m *IdempotencyRepoMock) Create(ctx context.Context, record *entity.IdempotencyRecord, staleBefore time.Time) (bool, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, record, staleBefore)
+	}
+	return true, nil
+}
+
+func (m *IdempotencyRepoMock) Get(ctx context.Context, userID uuid.UUID, key string) (*entity.IdempotencyRecord, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, userID, key)
+	}
+	return nil, nil
+}
+
+func (m *IdempotencyRepoMock) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	if m.CompleteFunc != nil {
+		return m.CompleteFunc(ctx, record)
+	}
+	return nil
+}
+
+func (m *IdempotencyRepoMock) Delete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, record)
+	}
+	return nil
+}
+
+func (m *IdempotencyRepoMock) DeleteExpired(ctx context.Context, before time.Time) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx, before)
+	}
+	return nil
+}
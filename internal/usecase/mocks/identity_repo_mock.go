@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type UserIdentityRepoMock struct {
+	CreateFunc               func(ctx context.Context, identity *entity.UserIdentity) error
+	GetByProviderSubjectFunc func(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	TouchLastLoginFunc       func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *UserIdentityRepoMock) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, identity)
+	}
+	return nil
+}
+
+func (m *UserIdentityRepoMock) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	if m.GetByProviderSubjectFunc != nil {
+		return m.GetByProviderSubjectFunc(ctx, provider, subject)
+	}
+	return nil, nil
+}
+
+func (m *UserIdentityRepoMock) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	if m.TouchLastLoginFunc != nil {
+		return m.TouchLastLoginFunc(ctx, id)
+	}
+	return nil
+}
+
+type OIDCAuthRequestRepoMock struct {
+	CreateFunc        func(ctx context.Context, request *entity.OIDCAuthRequest) error
+	ConsumeFunc       func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error)
+	DeleteExpiredFunc func(ctx context.Context, before time.Time) error
+}
+
+func (m *OIDCAuthRequestRepoMock) Create(ctx context.Context, request *entity.OIDCAuthRequest) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, request)
+	}
+	return nil
+}
+
+func (m *OIDCAuthRequestRepoMock) Consume(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+	if m.ConsumeFunc != nil {
+		return m.ConsumeFunc(ctx, stateHash)
+	}
+	return nil, nil
+}
+
+func (m *OIDCAuthRequestRepoMock) DeleteExpired(ctx context.Context, before time.Time) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx, before)
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type JWTServiceMock struct {
-	GenerateTokenFunc func(userID uuid.UUID) (string, error)
-	ValidateTokenFunc func(token string) (uuid.UUID, error)
+	GenerateTokenFunc    func(userID uuid.UUID) (string, error)
+	ValidateTokenFunc    func(token string) (uuid.UUID, error)
+	GenerateMFATokenFunc func(userID, challengeID uuid.UUID, ttl time.Duration) (string, error)
+	ValidateMFATokenFunc func(token string) (uuid.UUID, uuid.UUID, error)
 }
 
 func (m *JWTServiceMock) GenerateToken(userID uuid.UUID) (string, error) {
@@ -22,3 +26,17 @@ func (m *JWTServiceMock) ValidateToken(token string) (uuid.UUID, error) {
 	}
 	return uuid.New(), nil
 }
+
+func (m *JWTServiceMock) GenerateMFAToken(userID, challengeID uuid.UUID, ttl time.Duration) (string, error) {
+	if m.GenerateMFATokenFunc != nil {
+		return m.GenerateMFATokenFunc(userID, challengeID, ttl)
+	}
+	return "test_mfa_token", nil
+}
+
+func (m *JWTServiceMock) ValidateMFAToken(token string) (uuid.UUID, uuid.UUID, error) {
+	if m.ValidateMFATokenFunc != nil {
+		return m.ValidateMFATokenFunc(token)
+	}
+	return uuid.New(), uuid.New(), nil
+}
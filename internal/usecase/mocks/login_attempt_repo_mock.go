@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+)
+
+type LoginAttemptRepoMock struct {
+	CreateFunc                 func(ctx context.Context, attempt *entity.LoginAttempt) error
+	GetAccountFailureStatsFunc func(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error)
+	GetIPFailureStatsFunc      func(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error)
+	DeleteOlderThanFunc        func(ctx context.Context, before time.Time) error
+}
+
+func (m *LoginAttemptRepoMock) Create(ctx context.Context, attempt *entity.LoginAttempt) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, attempt)
+	}
+	return nil
+}
+
+func (m *LoginAttemptRepoMock) GetAccountFailureStats(ctx context.Context, email string, since time.Time) (*entity.LoginFailureStats, error) {
+	if m.GetAccountFailureStatsFunc != nil {
+		return m.GetAccountFailureStatsFunc(ctx, email, since)
+	}
+	return &entity.LoginFailureStats{}, nil
+}
+
+func (m *LoginAttemptRepoMock) GetIPFailureStats(ctx context.Context, ipAddress string, since time.Time) (*entity.LoginFailureStats, error) {
+	if m.GetIPFailureStatsFunc != nil {
+		return m.GetIPFailureStatsFunc(ctx, ipAddress, since)
+	}
+	return &entity.LoginFailureStats{}, nil
+}
+
+func (m *LoginAttemptRepoMock) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	if m.DeleteOlderThanFunc != nil {
+		return m.DeleteOlderThanFunc(ctx, before)
+	}
+	return nil
+}
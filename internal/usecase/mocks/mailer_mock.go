@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/service"
+)
+
+type MailerMock struct {
+	SendFunc func(ctx context.Context, msg *service.MailMessage) error
+}
+
+func (m *MailerMock) Send(ctx context.Context, msg *service.MailMessage) error {
+	if m.SendFunc != nil {
+		return m.SendFunc(ctx, msg)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"chat-service/internal/entity"
 
@@ -13,6 +14,7 @@ type MessageRepoMock struct {
 	GetByIDFunc     func(ctx context.Context, id uuid.UUID) (*entity.Message, error)
 	GetByUserIDFunc func(ctx context.Context, userID uuid.UUID) ([]*entity.Message, error)
 	GetAllFunc      func(ctx context.Context) ([]*entity.Message, error)
+	SetHiddenFunc   func(ctx context.Context, id uuid.UUID, hiddenAt *time.Time) error
 	DeleteFunc      func(ctx context.Context, id uuid.UUID) error
 }
 
@@ -44,6 +46,13 @@ func (m *MessageRepoMock) GetAll(ctx context.Context) ([]*entity.Message, error)
 	return nil, nil
 }
 
+func (m *MessageRepoMock) SetHidden(ctx context.Context, id uuid.UUID, hiddenAt *time.Time) error {
+	if m.SetHiddenFunc != nil {
+		return m.SetHiddenFunc(ctx, id, hiddenAt)
+	}
+	return nil
+}
+
 func (m *MessageRepoMock) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type MFARepoMock struct {
+	GetByUserIDFunc              func(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error)
+	UpsertFunc                   func(ctx context.Context, mfa *entity.UserMFA) error
+	UpdateLastUsedStepFunc       func(ctx context.Context, userID uuid.UUID, step int64) error
+	DeleteFunc                   func(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodesFunc     func(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCodeFunc          func(ctx context.Context, userID uuid.UUID, codeHash string) error
+	CreateChallengeFunc          func(ctx context.Context, challenge *entity.MFAChallengeState) error
+	UseChallengeAttemptFunc      func(ctx context.Context, id, userID uuid.UUID, maxAttempts int, now time.Time) (int, error)
+	DeleteChallengeFunc          func(ctx context.Context, id uuid.UUID) error
+	DeleteChallengesByUserIDFunc func(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredChallengesFunc  func(ctx context.Context, before time.Time) error
+}
+
+func (m *MFARepoMock) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserMFA, error) {
+	if m.GetByUserIDFunc != nil {
+		return m.GetByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MFARepoMock) Upsert(ctx context.Context, mfa *entity.UserMFA) error {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(ctx, mfa)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	if m.UpdateLastUsedStepFunc != nil {
+		return m.UpdateLastUsedStepFunc(ctx, userID, step)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) Delete(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	if m.ReplaceRecoveryCodesFunc != nil {
+		return m.ReplaceRecoveryCodesFunc(ctx, userID, codeHashes)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(ctx, userID, codeHash)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) CreateChallenge(ctx context.Context, challenge *entity.MFAChallengeState) error {
+	if m.CreateChallengeFunc != nil {
+		return m.CreateChallengeFunc(ctx, challenge)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) UseChallengeAttempt(ctx context.Context, id, userID uuid.UUID, maxAttempts int, now time.Time) (int, error) {
+	if m.UseChallengeAttemptFunc != nil {
+		return m.UseChallengeAttemptFunc(ctx, id, userID, maxAttempts, now)
+	}
+	return 1, nil
+}
+
+func (m *MFARepoMock) DeleteChallenge(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteChallengeFunc != nil {
+		return m.DeleteChallengeFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) DeleteChallengesByUserID(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteChallengesByUserIDFunc != nil {
+		return m.DeleteChallengesByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MFARepoMock) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	if m.DeleteExpiredChallengesFunc != nil {
+		return m.DeleteExpiredChallengesFunc(ctx, before)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package mocks
+
+// NotFoundError ошибка "не найдено", как у репозиториев PostgreSQL: usecase распознают ее по методу NotFound
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
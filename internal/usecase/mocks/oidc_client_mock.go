@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/service"
+)
+
+type OIDCClientMock struct {
+	AuthCodeURLFunc func(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	ExchangeFunc    func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error)
+}
+
+func (m *OIDCClientMock) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	if m.AuthCodeURLFunc != nil {
+		return m.AuthCodeURLFunc(ctx, state, nonce, codeVerifier)
+	}
+	return "", nil
+}
+
+func (m *OIDCClientMock) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+	if m.ExchangeFunc != nil {
+		return m.ExchangeFunc(ctx, code, codeVerifier, nonce)
+	}
+	return nil, nil
+}
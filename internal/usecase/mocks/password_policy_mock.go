@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"chat-service/internal/entity"
+)
+
+type PasswordPolicyMock struct {
+	ValidateFunc func(password string, user *entity.User) error
+}
+
+func (m *PasswordPolicyMock) Validate(password string, user *entity.User) error {
+	if m.ValidateFunc != nil {
+		return m.ValidateFunc(password, user)
+	}
+	return nil
+}
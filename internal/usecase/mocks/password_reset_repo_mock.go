@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepoMock struct {
+	CreateFunc         func(ctx context.Context, token *entity.PasswordResetToken) error
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	MarkUsedFunc       func(ctx context.Context, id uuid.UUID) error
+	DeleteByUserIDFunc func(ctx context.Context, userID uuid.UUID) error
+}
+
+func (m *PasswordResetRepoMock) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *PasswordResetRepoMock) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *PasswordResetRepoMock) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	if m.MarkUsedFunc != nil {
+		return m.MarkUsedFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *PasswordResetRepoMock) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteByUserIDFunc != nil {
+		return m.DeleteByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"context"
+	"time"
+)
+
+type RateLimitRepoMock struct {
+	IncrementFunc     func(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error)
+	DeleteExpiredFunc func(ctx context.Context, before time.Time) error
+}
+
+func (m *RateLimitRepoMock) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	if m.IncrementFunc != nil {
+		return m.IncrementFunc(ctx, key, windowStart, expiresAt)
+	}
+	return 1, nil
+}
+
+func (m *RateLimitRepoMock) DeleteExpired(ctx context.Context, before time.Time) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx, before)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type ReportRepoMock struct {
+	CreateFunc       func(ctx context.Context, report *entity.Report) error
+	GetByIDFunc      func(ctx context.Context, id uuid.UUID) (*entity.Report, error)
+	ListFunc         func(ctx context.Context, filter entity.ReportFilter) ([]*entity.Report, int, error)
+	UpdateStatusFunc func(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error
+}
+
+func (m *ReportRepoMock) Create(ctx context.Context, report *entity.Report) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, report)
+	}
+	return nil
+}
+
+func (m *ReportRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *ReportRepoMock) List(ctx context.Context, filter entity.ReportFilter) ([]*entity.Report, int, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, filter)
+	}
+	return nil, 0, nil
+}
+
+func (m *ReportRepoMock) UpdateStatus(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+	if m.UpdateStatusFunc != nil {
+		return m.UpdateStatusFunc(ctx, id, from, to, resolvedBy, at)
+	}
+	return nil
+}
+
+type ModerationActionRepoMock struct {
+	CreateFunc         func(ctx context.Context, action *entity.ModerationAction) error
+	ListByReportIDFunc func(ctx context.Context, reportID uuid.UUID) ([]*entity.ModerationAction, error)
+}
+
+func (m *ModerationActionRepoMock) Create(ctx context.Context, action *entity.ModerationAction) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, action)
+	}
+	return nil
+}
+
+func (m *ModerationActionRepoMock) ListByReportID(ctx context.Context, reportID uuid.UUID) ([]*entity.ModerationAction, error) {
+	if m.ListByReportIDFunc != nil {
+		return m.ListByReportIDFunc(ctx, reportID)
+	}
+	return nil, nil
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"chat-service/internal/entity"
 
@@ -9,11 +10,14 @@ import (
 )
 
 type SessionRepoMock struct {
-	CreateFunc        func(ctx context.Context, session *entity.Session) error
-	GetByTokenFunc    func(ctx context.Context, token string) (*entity.Session, error)
-	GetByUserIDFunc   func(ctx context.Context, userID uuid.UUID) (*entity.Session, error)
-	DeleteFunc        func(ctx context.Context, id uuid.UUID) error
-	DeleteByTokenFunc func(ctx context.Context, token string) error
+	CreateFunc               func(ctx context.Context, session *entity.Session) error
+	GetByTokenFunc           func(ctx context.Context, token string) (*entity.Session, error)
+	GetByUserIDFunc          func(ctx context.Context, userID uuid.UUID) (*entity.Session, error)
+	DeleteFunc               func(ctx context.Context, id uuid.UUID) error
+	DeleteByTokenFunc        func(ctx context.Context, token string) error
+	DeleteByUserIDFunc       func(ctx context.Context, userID uuid.UUID) error
+	DeleteByUserIDExceptFunc func(ctx context.Context, userID, keepSessionID uuid.UUID) error
+	CountActiveFunc          func(ctx context.Context, now time.Time) (int, error)
 }
 
 func (m *SessionRepoMock) Create(ctx context.Context, session *entity.Session) error {
@@ -50,3 +54,24 @@ func (m *SessionRepoMock) DeleteByToken(ctx context.Context, token string) error
 	}
 	return nil
 }
+
+func (m *SessionRepoMock) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	if m.DeleteByUserIDFunc != nil {
+		return m.DeleteByUserIDFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *SessionRepoMock) DeleteByUserIDExcept(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+	if m.DeleteByUserIDExceptFunc != nil {
+		return m.DeleteByUserIDExceptFunc(ctx, userID, keepSessionID)
+	}
+	return nil
+}
+
+func (m *SessionRepoMock) CountActive(ctx context.Context, now time.Time) (int, error) {
+	if m.CountActiveFunc != nil {
+		return m.CountActiveFunc(ctx, now)
+	}
+	return 0, nil
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import "time"
+
+type TOTPServiceMock struct {
+	GenerateSecretFunc func() (string, error)
+	BuildURIFunc       func(issuer, account, secret string) string
+	ValidateFunc       func(code, secret string, at time.Time) (int64, bool)
+}
+
+func (m *TOTPServiceMock) GenerateSecret() (string, error) {
+	if m.GenerateSecretFunc != nil {
+		return m.GenerateSecretFunc()
+	}
+	return "JBSWY3DPEHPK3PXP", nil
+}
+
+func (m *TOTPServiceMock) BuildURI(issuer, account, secret string) string {
+	if m.BuildURIFunc != nil {
+		return m.BuildURIFunc(issuer, account, secret)
+	}
+	return "otpauth://totp/" + issuer + ":" + account + "?secret=" + secret
+}
+
+func (m *TOTPServiceMock) Validate(code, secret string, at time.Time) (int64, bool) {
+	if m.ValidateFunc != nil {
+		return m.ValidateFunc(code, secret, at)
+	}
+	return at.Unix() / 30, true
+}
@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+
+	"chat-service/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type UserBlockRepoMock struct {
+	CreateFunc                 func(ctx context.Context, block *entity.UserBlock) error
+	DeleteFunc                 func(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListByBlockerFunc          func(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error)
+	ListBlockedIDsFunc         func(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+	FindBlockersByUsernameFunc func(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error)
+	FindBlockedByUsernameFunc  func(ctx context.Context, blockerID uuid.UUID, usernames []string) ([]string, error)
+}
+
+func (m *UserBlockRepoMock) Create(ctx context.Context, block *entity.UserBlock) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, block)
+	}
+	return nil
+}
+
+func (m *UserBlockRepoMock) Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, blockerID, blockedID)
+	}
+	return nil
+}
+
+func (m *UserBlockRepoMock) ListByBlocker(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error) {
+	if m.ListByBlockerFunc != nil {
+		return m.ListByBlockerFunc(ctx, blockerID)
+	}
+	return nil, nil
+}
+
+func (m *UserBlockRepoMock) ListBlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	if m.ListBlockedIDsFunc != nil {
+		return m.ListBlockedIDsFunc(ctx, blockerID)
+	}
+	return nil, nil
+}
+
+func (m *UserBlockRepoMock) FindBlockersByUsername(ctx context.Context, blockedID uuid.UUID, usernames []string) ([]string, error) {
+	if m.FindBlockersByUsernameFunc != nil {
+		return m.FindBlockersByUsernameFunc(ctx, blockedID, usernames)
+	}
+	return nil, nil
+}
+
+func (m *UserBlockRepoMock) FindBlockedByUsername(ctx context.Context, blockerID uuid.UUID, usernames []string) ([]string, error) {
+	if m.FindBlockedByUsernameFunc != nil {
+		return m.FindBlockedByUsernameFunc(ctx, blockerID, usernames)
+	}
+	return nil, nil
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"chat-service/internal/entity"
 
@@ -9,11 +10,28 @@ import (
 )
 
 type UserRepoMock struct {
-	CreateFunc     func(ctx context.Context, user *entity.User) error
-	GetByIDFunc    func(ctx context.Context, id uuid.UUID) (*entity.User, error)
-	GetByEmailFunc func(ctx context.Context, email string) (*entity.User, error)
-	UpdateFunc     func(ctx context.Context, user *entity.User) error
-	DeleteFunc     func(ctx context.Context, id uuid.UUID) error
+	CreateFunc            func(ctx context.Context, user *entity.User) error
+	GetByIDFunc           func(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	GetByEmailFunc        func(ctx context.Context, email string) (*entity.User, error)
+	UpdateFunc            func(ctx context.Context, user *entity.User) error
+	MarkEmailVerifiedFunc func(ctx context.Context, id uuid.UUID, email string) error
+	UpdateRoleFunc        func(ctx context.Context, id uuid.UUID, role entity.Role) error
+	ListFunc              func(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error)
+	SetSuspendedFunc      func(ctx context.Context, id uuid.UUID, suspendedAt *time.Time, reason string) error
+	DeleteFunc            func(ctx context.Context, id uuid.UUID) error
+}
+
+// UsersByID реализация GetByIDFunc по списку пользователей: возвращает копию найденного или NotFoundError
+func UsersByID(users ...*entity.User) func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		for _, user := range users {
+			if user.ID == id {
+				copied := *user
+				return &copied, nil
+			}
+		}
+		return nil, &NotFoundError{"user not found"}
+	}
 }
 
 func (m *UserRepoMock) Create(ctx context.Context, user *entity.User) error {
@@ -44,6 +62,34 @@ func (m *UserRepoMock) Update(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+func (m *UserRepoMock) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) error {
+	if m.MarkEmailVerifiedFunc != nil {
+		return m.MarkEmailVerifiedFunc(ctx, id, email)
+	}
+	return nil
+}
+
+func (m *UserRepoMock) UpdateRole(ctx context.Context, id uuid.UUID, role entity.Role) error {
+	if m.UpdateRoleFunc != nil {
+		return m.UpdateRoleFunc(ctx, id, role)
+	}
+	return nil
+}
+
+func (m *UserRepoMock) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, filter)
+	}
+	return nil, 0, nil
+}
+
+func (m *UserRepoMock) SetSuspended(ctx context.Context, id uuid.UUID, suspendedAt *time.Time, reason string) error {
+	if m.SetSuspendedFunc != nil {
+		return m.SetSuspendedFunc(ctx, id, suspendedAt, reason)
+	}
+	return nil
+}
+
 func (m *UserRepoMock) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
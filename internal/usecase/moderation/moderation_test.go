@@ -0,0 +1,414 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenReport(author *entity.User) *entity.Report {
+	reporterID := uuid.New()
+	return &entity.Report{
+		ID:         uuid.New(),
+		MessageID:  uuid.New(),
+		ReporterID: &reporterID,
+		Reason:     entity.ReportReasonSpam,
+		Status:     entity.ReportStatusOpen,
+		Message:    &entity.Message{UserID: author.ID, Content: "buy now"},
+	}
+}
+
+func TestReportStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, entity.ReportStatusOpen.CanTransitionTo(entity.ReportStatusDismissed))
+	assert.True(t, entity.ReportStatusOpen.CanTransitionTo(entity.ReportStatusActioned))
+	assert.True(t, entity.ReportStatusDismissed.CanTransitionTo(entity.ReportStatusOpen))
+	assert.True(t, entity.ReportStatusActioned.CanTransitionTo(entity.ReportStatusActioned))
+	assert.False(t, entity.ReportStatusActioned.CanTransitionTo(entity.ReportStatusOpen))
+	assert.False(t, entity.ReportStatusDismissed.CanTransitionTo(entity.ReportStatusActioned))
+}
+
+func TestModerationUsecase_ReportMessage(t *testing.T) {
+	reporterID := uuid.New()
+	hiddenAt := time.Now()
+
+	tests := []struct {
+		name     string
+		message  *entity.Message
+		reason   entity.ReportReason
+		wantErr  interface{}
+		wantCode string
+	}{
+		{"жалоба на чужое сообщение", &entity.Message{UserID: uuid.New()}, entity.ReportReasonSpam, nil, ""},
+		{"жалоба на свое сообщение", &entity.Message{UserID: reporterID}, entity.ReportReasonSpam, &apperror.Error{}, apperror.CodeReportOwnMessage},
+		{"неизвестная причина", &entity.Message{UserID: uuid.New()}, "boring", &entity.ValidationError{}, ""},
+		{"скрытое сообщение", &entity.Message{UserID: uuid.New(), HiddenAt: &hiddenAt}, entity.ReportReasonSpam, &apperror.Error{}, apperror.CodeMessageNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+			messageID := uuid.New()
+			tt.message.ID = messageID
+			messageRepo := &mocks.MessageRepoMock{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
+					return tt.message, nil
+				},
+			}
+			var created *entity.Report
+			reportRepo := &mocks.ReportRepoMock{
+				CreateFunc: func(ctx context.Context, report *entity.Report) error {
+					created = report
+					return nil
+				},
+			}
+
+			usecase := NewModerationUsecase(reportRepo, &mocks.ModerationActionRepoMock{}, messageRepo,
+				&mocks.UserRepoMock{}, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+			// Act
+			report, err := usecase.ReportMessage(context.Background(), reporterID, messageID, tt.reason, "  see above ")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				assert.IsType(t, tt.wantErr, err)
+				assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+				assert.Nil(t, created)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, created, report)
+			assert.Equal(t, entity.ReportStatusOpen, report.Status)
+			assert.Equal(t, "see above", report.Comment)
+		})
+	}
+}
+
+func TestModerationUsecase_RequiresPermission(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	user := &entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	report := newOpenReport(&entity.User{ID: uuid.New()})
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(user)}
+	reportRepo := &mocks.ReportRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+			t.Fatal("report must not be loaded without permission")
+			return nil, nil
+		},
+	}
+
+	usecase := NewModerationUsecase(reportRepo, &mocks.ModerationActionRepoMock{}, &mocks.MessageRepoMock{},
+		userRepo, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+	// Act
+	_, listErr := usecase.ListReports(context.Background(), user.ID, entity.ReportFilter{})
+	_, actionErr := usecase.TakeAction(context.Background(), user.ID, report.ID, entity.ModerationActionHideMessage, "")
+
+	// Assert
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(listErr))
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(actionErr))
+}
+
+func TestModerationUsecase_ListReports_Defaults(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator)}
+	var gotFilter entity.ReportFilter
+	reportRepo := &mocks.ReportRepoMock{
+		ListFunc: func(ctx context.Context, filter entity.ReportFilter) ([]*entity.Report, int, error) {
+			gotFilter = filter
+			return []*entity.Report{}, 3, nil
+		},
+	}
+	status := entity.ReportStatusOpen
+
+	usecase := NewModerationUsecase(reportRepo, &mocks.ModerationActionRepoMock{}, &mocks.MessageRepoMock{},
+		userRepo, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+	// Act
+	list, err := usecase.ListReports(context.Background(), moderator.ID, entity.ReportFilter{Status: &status})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, defaultListLimit, gotFilter.Limit)
+	assert.Equal(t, &status, gotFilter.Status)
+	assert.Equal(t, 3, list.Total)
+
+	_, err = usecase.ListReports(context.Background(), moderator.ID, entity.ReportFilter{Limit: maxListLimit + 1})
+	assert.Equal(t, apperror.CodeValidationFailed, apperror.CodeOf(err))
+}
+
+func TestModerationUsecase_TakeAction_HideMessage(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	author := &entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	report := newOpenReport(author)
+
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator, author)}
+	reportRepo := &mocks.ReportRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+			copied := *report
+			return &copied, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+			report.Status = to
+			return nil
+		},
+	}
+	var hiddenID uuid.UUID
+	messageRepo := &mocks.MessageRepoMock{
+		SetHiddenFunc: func(ctx context.Context, id uuid.UUID, hiddenAt *time.Time) error {
+			require.NotNil(t, hiddenAt)
+			hiddenID = id
+			return nil
+		},
+	}
+	var recorded []*entity.ModerationAction
+	actionRepo := &mocks.ModerationActionRepoMock{
+		CreateFunc: func(ctx context.Context, action *entity.ModerationAction) error {
+			recorded = append(recorded, action)
+			return nil
+		},
+	}
+
+	usecase := NewModerationUsecase(reportRepo, actionRepo, messageRepo, userRepo, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+	// Act
+	result, err := usecase.TakeAction(context.Background(), moderator.ID, report.ID, entity.ModerationActionHideMessage, "spam link")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, report.MessageID, hiddenID)
+	assert.Equal(t, entity.ReportStatusActioned, result.Status)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, entity.ModerationActionHideMessage, recorded[0].Action)
+	assert.Equal(t, moderator.ID, *recorded[0].ModeratorID)
+	assert.Equal(t, author.ID, recorded[0].TargetUserID)
+	assert.Equal(t, "spam link", recorded[0].Note)
+}
+
+func TestModerationUsecase_TakeAction_WarnAuthor(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	author := &entity.User{ID: uuid.New(), Username: "bob", Email: "bob@example.com"}
+	report := newOpenReport(author)
+
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator, author)}
+	reportRepo := &mocks.ReportRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+			copied := *report
+			return &copied, nil
+		},
+	}
+	var sent *service.MailMessage
+	mailer := &mocks.MailerMock{
+		SendFunc: func(ctx context.Context, msg *service.MailMessage) error {
+			sent = msg
+			return nil
+		},
+	}
+	var recorded []*entity.ModerationAction
+	actionRepo := &mocks.ModerationActionRepoMock{
+		CreateFunc: func(ctx context.Context, action *entity.ModerationAction) error {
+			recorded = append(recorded, action)
+			return nil
+		},
+	}
+
+	usecase := NewModerationUsecase(reportRepo, actionRepo, &mocks.MessageRepoMock{}, userRepo, &mocks.SessionRepoMock{}, mailer, logger)
+
+	// Act
+	_, err := usecase.TakeAction(context.Background(), moderator.ID, report.ID, entity.ModerationActionWarnAuthor, "")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, sent)
+	assert.Equal(t, author.Email, sent.To)
+	assert.Contains(t, sent.Body, "buy now")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, entity.ModerationActionWarnAuthor, recorded[0].Action)
+}
+
+func TestModerationUsecase_TakeAction_SuspendAuthor(t *testing.T) {
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+
+	tests := []struct {
+		name     string
+		role     entity.Role
+		wantCode string
+	}{
+		{name: "блокировка автора", role: entity.RoleUser},
+		{name: "автор из персонала", role: entity.RoleAdmin, wantCode: apperror.CodeModerationStaffTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			author := &entity.User{ID: uuid.New(), Role: tt.role}
+			report := newOpenReport(author)
+
+			var suspendedReason string
+			userRepo := &mocks.UserRepoMock{
+				GetByIDFunc: mocks.UsersByID(moderator, author),
+				SetSuspendedFunc: func(ctx context.Context, id uuid.UUID, at *time.Time, reason string) error {
+					assert.Equal(t, author.ID, id)
+					suspendedReason = reason
+					return nil
+				},
+			}
+			reportRepo := &mocks.ReportRepoMock{
+				GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+					copied := *report
+					return &copied, nil
+				},
+				UpdateStatusFunc: func(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+					report.Status = to
+					return nil
+				},
+			}
+			revoked := false
+			sessionRepo := &mocks.SessionRepoMock{
+				DeleteByUserIDFunc: func(ctx context.Context, userID uuid.UUID) error {
+					revoked = userID == author.ID
+					return nil
+				},
+			}
+			var recorded []*entity.ModerationAction
+			actionRepo := &mocks.ModerationActionRepoMock{
+				CreateFunc: func(ctx context.Context, action *entity.ModerationAction) error {
+					recorded = append(recorded, action)
+					return nil
+				},
+			}
+
+			usecase := NewModerationUsecase(reportRepo, actionRepo, &mocks.MessageRepoMock{}, userRepo, sessionRepo, &mocks.MailerMock{}, logger)
+
+			// Act
+			_, err := usecase.TakeAction(context.Background(), moderator.ID, report.ID, entity.ModerationActionSuspendAuthor, "")
+
+			// Assert
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+				assert.Empty(t, suspendedReason, "staff account must not be suspended")
+				assert.Empty(t, recorded)
+				assert.Equal(t, entity.ReportStatusOpen, report.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "reported message: spam", suspendedReason)
+			assert.True(t, revoked)
+			require.Len(t, recorded, 1)
+		})
+	}
+}
+
+func TestModerationUsecase_DismissAndReopen(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	report := newOpenReport(&entity.User{ID: uuid.New()})
+
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator)}
+	reportRepo := &mocks.ReportRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+			copied := *report
+			return &copied, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+			report.Status = to
+			return nil
+		},
+	}
+	var recorded []*entity.ModerationAction
+	actionRepo := &mocks.ModerationActionRepoMock{
+		CreateFunc: func(ctx context.Context, action *entity.ModerationAction) error {
+			recorded = append(recorded, action)
+			return nil
+		},
+	}
+
+	usecase := NewModerationUsecase(reportRepo, actionRepo, &mocks.MessageRepoMock{}, userRepo, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+	// Act & Assert
+	dismissed, err := usecase.DismissReport(context.Background(), moderator.ID, report.ID, "not spam")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ReportStatusDismissed, dismissed.Status)
+
+	// Отклоненную жалобу нельзя сразу перевести в actioned, только вернуть в очередь
+	_, err = usecase.TakeAction(context.Background(), moderator.ID, report.ID, entity.ModerationActionHideMessage, "")
+	assert.Equal(t, apperror.CodeReportTransition, apperror.CodeOf(err))
+
+	reopened, err := usecase.ReopenReport(context.Background(), moderator.ID, report.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ReportStatusOpen, reopened.Status)
+
+	require.Len(t, recorded, 2)
+	assert.Equal(t, entity.ModerationActionDismiss, recorded[0].Action)
+	assert.Equal(t, entity.ModerationActionReopen, recorded[1].Action)
+}
+
+func TestModerationUsecase_TakeAction_ConcurrentUpdate(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	author := &entity.User{ID: uuid.New()}
+	report := newOpenReport(author)
+
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(moderator, author)}
+	reportRepo := &mocks.ReportRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.Report, error) {
+			copied := *report
+			return &copied, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, id uuid.UUID, from, to entity.ReportStatus, resolvedBy uuid.UUID, at time.Time) error {
+			return &mocks.NotFoundError{Message: "report not found"}
+		},
+	}
+	var recorded []*entity.ModerationAction
+	actionRepo := &mocks.ModerationActionRepoMock{
+		CreateFunc: func(ctx context.Context, action *entity.ModerationAction) error {
+			recorded = append(recorded, action)
+			return nil
+		},
+	}
+
+	usecase := NewModerationUsecase(reportRepo, actionRepo, &mocks.MessageRepoMock{}, userRepo, &mocks.SessionRepoMock{}, &mocks.MailerMock{}, logger)
+
+	// Act
+	_, err := usecase.TakeAction(context.Background(), moderator.ID, report.ID, entity.ModerationActionHideMessage, "")
+
+	// Assert
+	assert.Equal(t, apperror.CodeReportConflict, apperror.CodeOf(err))
+	assert.Empty(t, recorded)
+}
@@ -0,0 +1,17 @@
+package moderation
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ModerationUsecase interface {
+	ReportMessage(ctx context.Context, reporterID, messageID uuid.UUID, reason entity.ReportReason, comment string) (*entity.Report, error)
+	ListReports(ctx context.Context, moderatorID uuid.UUID, filter entity.ReportFilter) (*entity.ReportList, error)
+	GetReport(ctx context.Context, moderatorID, reportID uuid.UUID) (*entity.Report, error)
+	DismissReport(ctx context.Context, moderatorID, reportID uuid.UUID, note string) (*entity.Report, error)
+	ReopenReport(ctx context.Context, moderatorID, reportID uuid.UUID, note string) (*entity.Report, error)
+	TakeAction(ctx context.Context, moderatorID, reportID uuid.UUID, action entity.ModerationActionType, note string) (*entity.Report, error)
+}
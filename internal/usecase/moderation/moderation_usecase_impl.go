@@ -0,0 +1,407 @@
+package moderation
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+	maxNoteRunes     = 1000
+	excerptRunes     = 200
+)
+
+type moderationUsecase struct {
+	reportRepo  usecase.ReportRepository
+	actionRepo  usecase.ModerationActionRepository
+	messageRepo usecase.MessageRepository
+	userRepo    usecase.UserRepository
+	sessionRepo usecase.SessionRepository
+	mailer      service.Mailer
+	logger      *logrus.Logger
+	now         func() time.Time
+}
+
+func NewModerationUsecase(
+	reportRepo usecase.ReportRepository,
+	actionRepo usecase.ModerationActionRepository,
+	messageRepo usecase.MessageRepository,
+	userRepo usecase.UserRepository,
+	sessionRepo usecase.SessionRepository,
+	mailer service.Mailer,
+	logger *logrus.Logger,
+) ModerationUsecase {
+	return &moderationUsecase{
+		reportRepo:  reportRepo,
+		actionRepo:  actionRepo,
+		messageRepo: messageRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mailer,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// ReportMessage создает жалобу на сообщение. На свое сообщение жаловаться нельзя,
+// повторная жалоба того же пользователя отклоняется репозиторием
+func (m *moderationUsecase) ReportMessage(ctx context.Context, reporterID, messageID uuid.UUID, reason entity.ReportReason, comment string) (*entity.Report, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.ReportMessage", tracing.UserID(reporterID))
+	defer span.End()
+
+	logger := m.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"reporter_id": reporterID,
+		"message_id":  messageID,
+		"reason":      reason,
+	})
+	logger.Info("message report submitted")
+
+	message, err := m.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to fetch reported message")
+		return nil, err
+	}
+	// Скрытое сообщение уже недоступно остальным пользователям
+	if message.IsHidden() && message.UserID != reporterID {
+		return nil, apperror.NotFound(apperror.CodeMessageNotFound, "message not found")
+	}
+	if message.UserID == reporterID {
+		return nil, apperror.Validation(apperror.CodeReportOwnMessage, "you cannot report your own message")
+	}
+
+	now := m.now()
+	report := &entity.Report{
+		ID:         uuid.New(),
+		MessageID:  messageID,
+		ReporterID: &reporterID,
+		Reason:     reason,
+		Comment:    strings.TrimSpace(comment),
+		Status:     entity.ReportStatusOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := report.Validate(); err != nil {
+		logger.WithError(err).Warn("report validation failed")
+		return nil, err
+	}
+
+	if err := m.reportRepo.Create(ctx, report); err != nil {
+		logger.WithError(err).Warn("failed to create report")
+		return nil, err
+	}
+
+	logger.WithField("report_id", report.ID).Info("message reported")
+	return report, nil
+}
+
+// ListReports возвращает страницу очереди модерации, старые жалобы первыми
+func (m *moderationUsecase) ListReports(ctx context.Context, moderatorID uuid.UUID, filter entity.ReportFilter) (*entity.ReportList, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.ListReports", tracing.UserID(moderatorID))
+	defer span.End()
+
+	if err := m.authorize(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxListLimit {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "limit", fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	if filter.Offset < 0 {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "offset", "offset must not be negative")
+	}
+	if filter.Status != nil && !filter.Status.IsValid() {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "status", "unknown report status: "+string(*filter.Status))
+	}
+
+	reports, total, err := m.reportRepo.List(ctx, filter)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).Error("failed to list reports")
+		return nil, err
+	}
+
+	return &entity.ReportList{
+		Reports: reports,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// GetReport возвращает жалобу вместе с сообщением и журналом решений по ней
+func (m *moderationUsecase) GetReport(ctx context.Context, moderatorID, reportID uuid.UUID) (*entity.Report, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.GetReport", tracing.UserID(moderatorID))
+	defer span.End()
+
+	if err := m.authorize(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+	return m.loadReport(ctx, reportID)
+}
+
+// DismissReport отклоняет жалобу без мер
+func (m *moderationUsecase) DismissReport(ctx context.Context, moderatorID, reportID uuid.UUID, note string) (*entity.Report, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.DismissReport", tracing.UserID(moderatorID))
+	defer span.End()
+
+	return m.changeStatus(ctx, moderatorID, reportID, entity.ReportStatusDismissed, entity.ModerationActionDismiss, note)
+}
+
+// ReopenReport возвращает отклоненную жалобу в очередь
+func (m *moderationUsecase) ReopenReport(ctx context.Context, moderatorID, reportID uuid.UUID, note string) (*entity.Report, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.ReopenReport", tracing.UserID(moderatorID))
+	defer span.End()
+
+	return m.changeStatus(ctx, moderatorID, reportID, entity.ReportStatusOpen, entity.ModerationActionReopen, note)
+}
+
+// TakeAction применяет меру по жалобе (скрыть сообщение, предупредить или заблокировать автора)
+// и переводит жалобу в статус actioned
+func (m *moderationUsecase) TakeAction(ctx context.Context, moderatorID, reportID uuid.UUID, action entity.ModerationActionType, note string) (*entity.Report, error) {
+	ctx, span := tracing.Start(ctx, "ModerationUsecase.TakeAction", tracing.UserID(moderatorID))
+	defer span.End()
+
+	logger := m.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"moderator_id": moderatorID,
+		"report_id":    reportID,
+		"action":       action,
+	})
+	logger.Info("moderation action requested")
+
+	if !slices.Contains(entity.ModerationEnforcements, action) {
+		return nil, apperror.InvalidField(apperror.CodeValidationFailed, "action", "unknown moderation action: "+string(action))
+	}
+
+	report, note, err := m.prepareDecision(ctx, moderatorID, reportID, entity.ReportStatusActioned, note)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := m.userRepo.GetByID(ctx, report.Message.UserID)
+	if err != nil {
+		logger.WithError(err).Error("failed to fetch message author")
+		return nil, err
+	}
+
+	now := m.now()
+	switch action {
+	case entity.ModerationActionHideMessage:
+		err = m.hideMessage(ctx, report, now)
+	case entity.ModerationActionWarnAuthor:
+		m.warnAuthor(ctx, report, author, note)
+	case entity.ModerationActionSuspendAuthor:
+		err = m.suspendAuthor(ctx, report, author, note, now)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("failed to apply moderation action")
+		return nil, err
+	}
+
+	if err := m.reportRepo.UpdateStatus(ctx, reportID, report.Status, entity.ReportStatusActioned, moderatorID, now); err != nil {
+		return nil, m.statusUpdateError(err, logger)
+	}
+
+	if err := m.record(ctx, report, moderatorID, action, note, now); err != nil {
+		return nil, err
+	}
+
+	logger.WithField("author_id", author.ID).Info("moderation action applied")
+	return m.loadReport(ctx, reportID)
+}
+
+func (m *moderationUsecase) changeStatus(ctx context.Context, moderatorID, reportID uuid.UUID, to entity.ReportStatus, action entity.ModerationActionType, note string) (*entity.Report, error) {
+	logger := m.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"moderator_id": moderatorID,
+		"report_id":    reportID,
+		"status":       to,
+	})
+
+	report, note, err := m.prepareDecision(ctx, moderatorID, reportID, to, note)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	if err := m.reportRepo.UpdateStatus(ctx, reportID, report.Status, to, moderatorID, now); err != nil {
+		return nil, m.statusUpdateError(err, logger)
+	}
+
+	if err := m.record(ctx, report, moderatorID, action, note, now); err != nil {
+		return nil, err
+	}
+
+	logger.Info("report status changed")
+	return m.loadReport(ctx, reportID)
+}
+
+// prepareDecision проверяет права, заметку и допустимость перехода статуса
+func (m *moderationUsecase) prepareDecision(ctx context.Context, moderatorID, reportID uuid.UUID, to entity.ReportStatus, note string) (*entity.Report, string, error) {
+	if err := m.authorize(ctx, moderatorID); err != nil {
+		return nil, "", err
+	}
+
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxNoteRunes {
+		return nil, "", apperror.InvalidField(apperror.CodeValidationFailed, "note", fmt.Sprintf("note must be at most %d characters", maxNoteRunes))
+	}
+
+	report, err := m.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Warn("failed to fetch report")
+		return nil, "", err
+	}
+
+	if !report.Status.CanTransitionTo(to) {
+		return nil, "", apperror.Conflict(apperror.CodeReportTransition, fmt.Sprintf("report cannot be moved from %s to %s", report.Status, to))
+	}
+
+	return report, note, nil
+}
+
+func (m *moderationUsecase) hideMessage(ctx context.Context, report *entity.Report, now time.Time) error {
+	if report.Message.IsHidden() {
+		return nil
+	}
+	return m.messageRepo.SetHidden(ctx, report.MessageID, &now)
+}
+
+// warnAuthor отправляет автору письмо с предупреждением. Недоставленное письмо не отменяет
+// решение модератора: предупреждение все равно остается в журнале
+func (m *moderationUsecase) warnAuthor(ctx context.Context, report *entity.Report, author *entity.User, note string) {
+	body := fmt.Sprintf(
+		"Hello, %s!\n\nA moderator reviewed a report about your message and issued a warning.\n\n"+
+			"Message: %s\nReason: %s\n",
+		author.Username, excerpt(report.Message.Content), report.Reason,
+	)
+	if note != "" {
+		body += "Moderator note: " + note + "\n"
+	}
+	body += "\nRepeated violations may lead to account suspension.\n"
+
+	msg := &service.MailMessage{
+		To:      author.Email,
+		Subject: "Warning from moderators",
+		Body:    body,
+	}
+	if err := m.mailer.Send(ctx, msg); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", author.ID).Error("failed to send moderation warning email")
+	}
+}
+
+// suspendAuthor блокирует автора и завершает его сессии. Аккаунты модераторов и администраторов
+// так заблокировать нельзя: это делает администратор через админ API
+func (m *moderationUsecase) suspendAuthor(ctx context.Context, report *entity.Report, author *entity.User, note string, now time.Time) error {
+	if author.Role.Can(entity.PermissionReportsReview) {
+		return apperror.Forbidden(apperror.CodeModerationStaffTarget, "staff accounts cannot be suspended from the moderation queue")
+	}
+	if author.IsSuspended() {
+		return nil
+	}
+
+	reason := note
+	if reason == "" {
+		reason = "reported message: " + string(report.Reason)
+	}
+	if err := m.userRepo.SetSuspended(ctx, author.ID, &now, reason); err != nil {
+		return err
+	}
+	if err := m.sessionRepo.DeleteByUserID(ctx, author.ID); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("user_id", author.ID).Warn("failed to revoke sessions of suspended user")
+	}
+	return nil
+}
+
+// record сохраняет решение в журнал модерации
+func (m *moderationUsecase) record(ctx context.Context, report *entity.Report, moderatorID uuid.UUID, action entity.ModerationActionType, note string, now time.Time) error {
+	entry := &entity.ModerationAction{
+		ID:           uuid.New(),
+		ReportID:     &report.ID,
+		ModeratorID:  &moderatorID,
+		Action:       action,
+		MessageID:    report.MessageID,
+		TargetUserID: report.Message.UserID,
+		Note:         note,
+		CreatedAt:    now,
+	}
+	if err := m.actionRepo.Create(ctx, entry); err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("report_id", report.ID).Error("failed to record moderation action")
+		return err
+	}
+	return nil
+}
+
+func (m *moderationUsecase) loadReport(ctx context.Context, reportID uuid.UUID) (*entity.Report, error) {
+	report, err := m.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Warn("failed to fetch report")
+		return nil, err
+	}
+
+	actions, err := m.actionRepo.ListByReportID(ctx, reportID)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).WithField("report_id", reportID).Error("failed to fetch moderation actions")
+		return nil, err
+	}
+	report.Actions = actions
+	return report, nil
+}
+
+// statusUpdateError превращает промах условного обновления в понятную ошибку:
+// жалобу успел обработать другой модератор
+func (m *moderationUsecase) statusUpdateError(err error, logger *logrus.Entry) error {
+	if isNotFound(err) {
+		logger.Warn("report status changed concurrently")
+		return apperror.Conflict(apperror.CodeReportConflict, "report was updated by another moderator, reload it and try again")
+	}
+	logger.WithError(err).Error("failed to update report status")
+	return err
+}
+
+// authorize проверяет право модерации по текущей роли из БД
+func (m *moderationUsecase) authorize(ctx context.Context, moderatorID uuid.UUID) error {
+	moderator, err := m.userRepo.GetByID(ctx, moderatorID)
+	if err != nil {
+		if isNotFound(err) {
+			return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+		}
+		m.logger.WithContext(ctx).WithError(err).WithField("moderator_id", moderatorID).Error("failed to fetch moderator")
+		return err
+	}
+	if !moderator.Role.Can(entity.PermissionReportsReview) {
+		m.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"moderator_id": moderatorID,
+			"role":         moderator.Role,
+		}).Warn("permission denied")
+		return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+// excerpt обрезает текст сообщения для письма
+func excerpt(content string) string {
+	runes := []rune(content)
+	if len(runes) <= excerptRunes {
+		return content
+	}
+	return string(runes[:excerptRunes]) + "..."
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
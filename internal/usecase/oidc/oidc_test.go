@@ -0,0 +1,543 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBinding значение cookie браузера, начавшего вход со state "state"
+var testBinding = service.HashToken("state")
+
+var testConfig = Config{StateTTL: 10 * time.Minute}
+
+// consumePendingRequest реализация ConsumeFunc: state действителен и выдан для провайдера "corp"
+func consumePendingRequest(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+	return &entity.OIDCAuthRequest{
+		StateHash:    stateHash,
+		Provider:     "corp",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}, nil
+}
+
+// identityNotLinked реализация GetByProviderSubjectFunc для еще не привязанной внешней учетной записи
+func identityNotLinked(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	return nil, &mocks.NotFoundError{Message: "user identity not found"}
+}
+
+func TestOIDCUsecase_BeginLogin_StoresHashedState(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	var gotState, gotNonce, gotVerifier string
+	client := &mocks.OIDCClientMock{
+		AuthCodeURLFunc: func(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+			gotState, gotNonce, gotVerifier = state, nonce, codeVerifier
+			return "https://idp.example.com/authorize?state=" + state, nil
+		},
+	}
+
+	var stored *entity.OIDCAuthRequest
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{
+		CreateFunc: func(ctx context.Context, request *entity.OIDCAuthRequest) error {
+			stored = request
+			return nil
+		},
+	}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	start, err := usecase.BeginLogin(context.Background(), "corp")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(start.AuthURL, "https://idp.example.com/authorize"))
+	require.NotNil(t, stored)
+	assert.Equal(t, stored.StateHash, start.Binding)
+	assert.Equal(t, stored.ExpiresAt, start.ExpiresAt)
+	assert.Equal(t, service.HashToken(gotState), stored.StateHash)
+	assert.NotEqual(t, gotState, stored.StateHash)
+	assert.Equal(t, gotNonce, stored.Nonce)
+	assert.Equal(t, gotVerifier, stored.CodeVerifier)
+	assert.GreaterOrEqual(t, len(gotVerifier), 43)
+	assert.Equal(t, "corp", stored.Provider)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), stored.ExpiresAt, 5*time.Second)
+}
+
+func TestOIDCUsecase_BeginLogin_UnknownProvider(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": &mocks.OIDCClientMock{}},
+		&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, &mocks.OIDCAuthRequestRepoMock{}, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	_, err := usecase.BeginLogin(context.Background(), "unknown")
+
+	// Assert
+	assert.Equal(t, apperror.CodeOIDCProviderNotFound, apperror.CodeOf(err))
+}
+
+func TestOIDCUsecase_CompleteLogin_InvalidState(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		consume  func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error)
+	}{
+		{
+			name:     "неизвестный или повторный state",
+			provider: "corp",
+			consume: func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+				return nil, &mocks.NotFoundError{Message: "auth request not found"}
+			},
+		},
+		{
+			name:     "истекший state",
+			provider: "corp",
+			consume: func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+				return &entity.OIDCAuthRequest{Provider: "corp", ExpiresAt: time.Now().Add(-time.Second)}, nil
+			},
+		},
+		{
+			name:     "state другого провайдера",
+			provider: "other",
+			consume:  consumePendingRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			client := &mocks.OIDCClientMock{
+				ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+					t.Fatal("code must not be exchanged")
+					return nil, nil
+				},
+			}
+			requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: tt.consume}
+
+			usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client, "other": client},
+				&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+			// Act
+			_, err := usecase.CompleteLogin(context.Background(), tt.provider, "state", "code", testBinding)
+
+			// Assert
+			assert.Equal(t, apperror.CodeOIDCLoginInvalid, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestOIDCUsecase_CompleteLogin_BrowserBinding(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding string
+	}{
+		{name: "без cookie", binding: ""},
+		{name: "cookie другого входа", binding: service.HashToken("attacker-state")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			client := &mocks.OIDCClientMock{
+				ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+					t.Fatal("code must not be exchanged")
+					return nil, nil
+				},
+			}
+			requestRepo := &mocks.OIDCAuthRequestRepoMock{
+				ConsumeFunc: func(ctx context.Context, stateHash string) (*entity.OIDCAuthRequest, error) {
+					t.Fatal("login request must not be consumed")
+					return nil, nil
+				},
+			}
+
+			usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+				&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+			// Act
+			_, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", tt.binding)
+
+			// Assert
+			assert.Equal(t, apperror.CodeOIDCLoginInvalid, apperror.CodeOf(err))
+		})
+	}
+}
+
+func TestOIDCUsecase_CompleteLogin_ExchangeFailed(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			assert.Equal(t, "verifier", codeVerifier)
+			assert.Equal(t, "nonce", nonce)
+			return nil, service.ErrOIDCAuthentication
+		},
+	}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		&mocks.UserRepoMock{}, &mocks.UserIdentityRepoMock{}, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	_, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	assert.Equal(t, apperror.CodeOIDCAuthFailed, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindUnauthorized))
+}
+
+func TestOIDCUsecase_CompleteLogin_ExistingIdentity(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	testUser := &entity.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", Password: "hash"}
+	identityID := uuid.New()
+	touched := false
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1", Email: "other@example.com"}, nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{
+		GetByProviderSubjectFunc: func(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+			assert.Equal(t, "corp", provider)
+			assert.Equal(t, "sub-1", subject)
+			return &entity.UserIdentity{ID: identityID, UserID: testUser.ID, Provider: provider, Subject: subject}, nil
+		},
+		TouchLastLoginFunc: func(ctx context.Context, id uuid.UUID) error {
+			touched = id == identityID
+			return nil
+		},
+	}
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: mocks.UsersByID(testUser),
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			t.Fatal("email lookup is not expected for linked identity")
+			return nil, nil
+		},
+	}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		userRepo, identityRepo, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	result, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, testUser.ID, result.User.ID)
+	assert.Empty(t, result.User.Password)
+	assert.False(t, result.Created)
+	assert.False(t, result.Linked)
+	assert.True(t, touched)
+}
+
+func TestOIDCUsecase_CompleteLogin_SuspendedUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	suspendedAt := time.Now().Add(-time.Hour)
+	testUser := &entity.User{ID: uuid.New(), Email: "alice@example.com", SuspendedAt: &suspendedAt}
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1", Email: testUser.Email}, nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{
+		GetByProviderSubjectFunc: func(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+			return &entity.UserIdentity{ID: uuid.New(), UserID: testUser.ID, Provider: provider, Subject: subject}, nil
+		},
+		TouchLastLoginFunc: func(ctx context.Context, id uuid.UUID) error {
+			t.Fatal("last login must not be updated for suspended user")
+			return nil
+		},
+	}
+	userRepo := &mocks.UserRepoMock{GetByIDFunc: mocks.UsersByID(testUser)}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		userRepo, identityRepo, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	result, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Equal(t, apperror.CodeAccountSuspended, apperror.CodeOf(err))
+}
+
+func TestOIDCUsecase_CompleteLogin_LinksExistingUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	verifiedAt := time.Now()
+	testUser := &entity.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", Password: "hash", EmailVerifiedAt: &verifiedAt}
+	var linked *entity.UserIdentity
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1", Email: "alice@example.com", EmailVerified: true}, nil
+		},
+	}
+	userRepo := &mocks.UserRepoMock{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return testUser, nil
+		},
+		CreateFunc: func(ctx context.Context, user *entity.User) error {
+			t.Fatal("user must not be created when email already exists")
+			return nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{
+		GetByProviderSubjectFunc: identityNotLinked,
+		CreateFunc: func(ctx context.Context, identity *entity.UserIdentity) error {
+			linked = identity
+			return nil
+		},
+	}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		userRepo, identityRepo, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	result, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Linked)
+	assert.Equal(t, testUser.ID, result.User.ID)
+	require.NotNil(t, linked)
+	assert.Equal(t, testUser.ID, linked.UserID)
+	assert.Equal(t, "corp", linked.Provider)
+	assert.Equal(t, "sub-1", linked.Subject)
+}
+
+func TestOIDCUsecase_CompleteLogin_RefusesLinkWithoutVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name            string
+		idpVerified     bool
+		localVerifiedAt *time.Time
+		wantCode        string
+	}{
+		{"провайдер не подтвердил email", false, &verifiedAt, apperror.CodeOIDCProviderEmailUnverified},
+		{"email аккаунта не подтвержден", true, nil, apperror.CodeOIDCAccountEmailUnverified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			client := &mocks.OIDCClientMock{
+				ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+					return &service.OIDCIdentity{Subject: "sub-1", Email: "alice@example.com", EmailVerified: tt.idpVerified}, nil
+				},
+			}
+			userRepo := &mocks.UserRepoMock{
+				GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+					return &entity.User{ID: uuid.New(), Email: email, EmailVerifiedAt: tt.localVerifiedAt}, nil
+				},
+			}
+			identityRepo := &mocks.UserIdentityRepoMock{
+				GetByProviderSubjectFunc: identityNotLinked,
+				CreateFunc: func(ctx context.Context, identity *entity.UserIdentity) error {
+					t.Fatal("identity must not be linked")
+					return nil
+				},
+			}
+			requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+			usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+				userRepo, identityRepo, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+			// Act
+			_, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, apperror.CodeOf(err))
+			assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+		})
+	}
+}
+
+func TestOIDCUsecase_CompleteLogin_CreatesUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	var created *entity.User
+	var linked *entity.UserIdentity
+	markedVerified := false
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1", Email: "Bob.Smith@example.com", EmailVerified: true, PreferredUsername: "Bob Smith"}, nil
+		},
+	}
+	hashService := &mocks.HashServiceMock{
+		HashPasswordFunc: func(password string) (string, error) {
+			assert.NotEmpty(t, password)
+			return "hashed-random-password", nil
+		},
+	}
+	userRepo := &mocks.UserRepoMock{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return nil, &mocks.NotFoundError{Message: "user not found"}
+		},
+		CreateFunc: func(ctx context.Context, user *entity.User) error {
+			created = &entity.User{ID: user.ID, Username: user.Username, Email: user.Email, Password: user.Password}
+			return nil
+		},
+		MarkEmailVerifiedFunc: func(ctx context.Context, id uuid.UUID, email string) error {
+			markedVerified = true
+			return nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{
+		GetByProviderSubjectFunc: identityNotLinked,
+		CreateFunc: func(ctx context.Context, identity *entity.UserIdentity) error {
+			linked = identity
+			return nil
+		},
+	}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		userRepo, identityRepo, requestRepo, hashService, testConfig, logger)
+
+	// Act
+	result, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Created)
+	require.NotNil(t, created)
+	assert.Equal(t, "hashed-random-password", created.Password)
+	assert.True(t, strings.HasPrefix(created.Username, "bobsmith_"))
+	assert.LessOrEqual(t, len(created.Username), 50)
+	assert.True(t, markedVerified)
+	assert.True(t, result.User.IsEmailVerified())
+	assert.Empty(t, result.User.Password)
+	require.NotNil(t, linked)
+	assert.Equal(t, created.ID, linked.UserID)
+}
+
+func TestOIDCUsecase_CompleteLogin_RollsBackUserWhenLinkFails(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	var createdID, deletedID uuid.UUID
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1", Email: "bob@example.com"}, nil
+		},
+	}
+	hashService := &mocks.HashServiceMock{
+		HashPasswordFunc: func(password string) (string, error) {
+			return "hashed-random-password", nil
+		},
+	}
+	userRepo := &mocks.UserRepoMock{
+		GetByEmailFunc: func(ctx context.Context, email string) (*entity.User, error) {
+			return nil, &mocks.NotFoundError{Message: "user not found"}
+		},
+		CreateFunc: func(ctx context.Context, user *entity.User) error {
+			createdID = user.ID
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			deletedID = id
+			return nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{
+		GetByProviderSubjectFunc: identityNotLinked,
+		CreateFunc: func(ctx context.Context, identity *entity.UserIdentity) error {
+			return errors.New("duplicate key")
+		},
+	}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		userRepo, identityRepo, requestRepo, hashService, testConfig, logger)
+
+	// Act
+	_, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, createdID, deletedID)
+}
+
+func TestOIDCUsecase_CompleteLogin_RequiresEmailForNewUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	client := &mocks.OIDCClientMock{
+		ExchangeFunc: func(ctx context.Context, code, codeVerifier, nonce string) (*service.OIDCIdentity, error) {
+			return &service.OIDCIdentity{Subject: "sub-1"}, nil
+		},
+	}
+	identityRepo := &mocks.UserIdentityRepoMock{GetByProviderSubjectFunc: identityNotLinked}
+	requestRepo := &mocks.OIDCAuthRequestRepoMock{ConsumeFunc: consumePendingRequest}
+
+	usecase := NewOIDCUsecase(map[string]service.OIDCClient{"corp": client},
+		&mocks.UserRepoMock{}, identityRepo, requestRepo, &mocks.HashServiceMock{}, testConfig, logger)
+
+	// Act
+	_, err := usecase.CompleteLogin(context.Background(), "corp", "state", "code", testBinding)
+
+	// Assert
+	assert.Equal(t, apperror.CodeOIDCEmailMissing, apperror.CodeOf(err))
+}
+
+func TestGenerateUsername(t *testing.T) {
+	username, err := generateUsername(&service.OIDCIdentity{}, "Jane.Doe+chat@example.com")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(username, "jane.doechat_"))
+
+	username, err = generateUsername(&service.OIDCIdentity{PreferredUsername: "Имя"}, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(username, "user_"))
+}
@@ -0,0 +1,13 @@
+package oidc
+
+import (
+	"chat-service/internal/entity"
+	"context"
+)
+
+type OIDCUsecase interface {
+	Providers() []string
+	BeginLogin(ctx context.Context, provider string) (*entity.OIDCLoginStart, error)
+	// CompleteLogin завершает вход; binding - значение из OIDCLoginStart, сохраненное браузером
+	CompleteLogin(ctx context.Context, provider, state, code, binding string) (*entity.OIDCLoginResult, error)
+}
@@ -0,0 +1,379 @@
+package oidc
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config параметры входа через OpenID Connect
+type Config struct {
+	StateTTL time.Duration
+}
+
+const (
+	stateSize          = 32
+	nonceSize          = 32
+	codeVerifierSize   = 32 // 43 символа base64url — минимальная длина verifier по RFC 7636
+	maxUsernameBaseLen = 40
+)
+
+type oidcUsecase struct {
+	providers    map[string]service.OIDCClient
+	userRepo     usecase.UserRepository
+	identityRepo usecase.UserIdentityRepository
+	requestRepo  usecase.OIDCAuthRequestRepository
+	hashService  service.HashService
+	config       Config
+	logger       *logrus.Logger
+	now          func() time.Time
+}
+
+func NewOIDCUsecase(
+	providers map[string]service.OIDCClient,
+	userRepo usecase.UserRepository,
+	identityRepo usecase.UserIdentityRepository,
+	requestRepo usecase.OIDCAuthRequestRepository,
+	hashService service.HashService,
+	config Config,
+	logger *logrus.Logger,
+) OIDCUsecase {
+	return &oidcUsecase{
+		providers:    providers,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		requestRepo:  requestRepo,
+		hashService:  hashService,
+		config:       config,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Providers возвращает имена настроенных провайдеров
+func (o *oidcUsecase) Providers() []string {
+	names := make([]string, 0, len(o.providers))
+	for name := range o.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeginLogin сохраняет state, nonce и PKCE verifier и возвращает адрес авторизации у провайдера.
+// Binding входа - хэш state: его знает только браузер, начавший вход
+func (o *oidcUsecase) BeginLogin(ctx context.Context, provider string) (*entity.OIDCLoginStart, error) {
+	ctx, span := tracing.Start(ctx, "OIDCUsecase.BeginLogin")
+	defer span.End()
+
+	client, err := o.client(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := service.GenerateRandomToken(stateSize)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to generate oidc state")
+		return nil, err
+	}
+	nonce, err := service.GenerateRandomToken(nonceSize)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to generate oidc nonce")
+		return nil, err
+	}
+	codeVerifier, err := service.GenerateRandomToken(codeVerifierSize)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to generate pkce code verifier")
+		return nil, err
+	}
+
+	authURL, err := client.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).WithField("provider", provider).Error("failed to build oidc authorization url")
+		return nil, err
+	}
+
+	now := o.now()
+	request := &entity.OIDCAuthRequest{
+		StateHash:    service.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(o.config.StateTTL),
+		CreatedAt:    now,
+	}
+	if err := o.requestRepo.Create(ctx, request); err != nil {
+		o.logger.WithContext(ctx).WithError(err).WithField("provider", provider).Error("failed to store oidc auth request")
+		return nil, err
+	}
+
+	// Заодно чистим брошенные запросы; ошибка не мешает входу
+	if err := o.requestRepo.DeleteExpired(ctx, now); err != nil {
+		o.logger.WithContext(ctx).WithError(err).Warn("failed to delete expired oidc auth requests")
+	}
+
+	o.logger.WithContext(ctx).WithField("provider", provider).Info("oidc login started")
+	return &entity.OIDCLoginStart{
+		AuthURL:   authURL,
+		Binding:   request.StateHash,
+		ExpiresAt: request.ExpiresAt,
+	}, nil
+}
+
+// CompleteLogin проверяет state и его привязку к браузеру, обменивает код и находит, привязывает или создает пользователя
+func (o *oidcUsecase) CompleteLogin(ctx context.Context, provider, state, code, binding string) (*entity.OIDCLoginResult, error) {
+	ctx, span := tracing.Start(ctx, "OIDCUsecase.CompleteLogin")
+	defer span.End()
+
+	client, err := o.client(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == "" || code == "" {
+		return nil, apperror.Validation(apperror.CodeInvalidRequest, "state and code are required")
+	}
+
+	// Без этой проверки атакующий мог бы начать вход сам и передать жертве ссылку возврата
+	// со своими code и state, и жертва оказалась бы в его аккаунте
+	stateHash := service.HashToken(state)
+	if subtle.ConstantTimeCompare([]byte(stateHash), []byte(binding)) != 1 {
+		o.logger.WithContext(ctx).WithField("provider", provider).Warn("oidc callback from a browser that did not start the login")
+		return nil, apperror.Unauthorized(apperror.CodeOIDCLoginInvalid, "invalid or expired login request")
+	}
+
+	request, err := o.requestRepo.Consume(ctx, stateHash)
+	if err != nil {
+		if isNotFound(err) {
+			o.logger.WithContext(ctx).WithField("provider", provider).Warn("unknown or reused oidc state")
+			return nil, apperror.Unauthorized(apperror.CodeOIDCLoginInvalid, "invalid or expired login request")
+		}
+		o.logger.WithContext(ctx).WithError(err).Error("failed to consume oidc auth request")
+		return nil, err
+	}
+
+	// state выдан для другого провайдера или уже истек
+	if request.Provider != provider || !o.now().Before(request.ExpiresAt) {
+		o.logger.WithContext(ctx).WithField("provider", provider).Warn("oidc state expired or issued for another provider")
+		return nil, apperror.Unauthorized(apperror.CodeOIDCLoginInvalid, "invalid or expired login request")
+	}
+
+	identity, err := client.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCAuthentication) {
+			return nil, apperror.Unauthorized(apperror.CodeOIDCAuthFailed, "identity provider authentication failed")
+		}
+		o.logger.WithContext(ctx).WithError(err).WithField("provider", provider).Error("oidc code exchange failed")
+		return nil, err
+	}
+	if identity.Subject == "" {
+		return nil, apperror.Unauthorized(apperror.CodeOIDCAuthFailed, "identity provider authentication failed")
+	}
+
+	return o.resolveUser(ctx, provider, identity)
+}
+
+// resolveUser находит пользователя по внешней учетной записи.
+// Существующий аккаунт с тем же email привязывается, только если провайдер подтвердил email
+func (o *oidcUsecase) resolveUser(ctx context.Context, provider string, identity *service.OIDCIdentity) (*entity.OIDCLoginResult, error) {
+	logger := o.logger.WithContext(ctx).WithField("provider", provider)
+
+	linked, err := o.identityRepo.GetByProviderSubject(ctx, provider, identity.Subject)
+	if err == nil {
+		user, err := o.userRepo.GetByID(ctx, linked.UserID)
+		if err != nil {
+			logger.WithError(err).WithField("user_id", linked.UserID).Error("failed to fetch user for linked identity")
+			return nil, err
+		}
+		if user.IsSuspended() {
+			logger.WithField("user_id", user.ID).Warn("oidc login attempt for suspended user")
+			return nil, apperror.Forbidden(apperror.CodeAccountSuspended, "account is suspended")
+		}
+		if err := o.identityRepo.TouchLastLogin(ctx, linked.ID); err != nil {
+			logger.WithError(err).Warn("failed to update identity last login")
+		}
+
+		user.Password = ""
+		logger.WithField("user_id", user.ID).Info("user logged in via oidc")
+		return &entity.OIDCLoginResult{User: user}, nil
+	}
+	if !isNotFound(err) {
+		logger.WithError(err).Error("failed to look up user identity")
+		return nil, err
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" {
+		return nil, apperror.Validation(apperror.CodeOIDCEmailMissing, "identity provider did not return an email address")
+	}
+
+	existing, err := o.userRepo.GetByEmail(ctx, email)
+	if err != nil && !isNotFound(err) {
+		logger.WithError(err).Error("failed to look up user by email")
+		return nil, err
+	}
+
+	if existing != nil {
+		// Без подтвержденного email кто угодно мог бы захватить аккаунт, указав чужой адрес у провайдера
+		if !identity.EmailVerified {
+			logger.WithField("user_id", existing.ID).Warn("refusing to link oidc identity with unverified email")
+			return nil, apperror.Conflict(apperror.CodeOIDCProviderEmailUnverified, "an account with this email already exists; the identity provider must verify the email to link it")
+		}
+		// Иначе чужой аккаунт, заранее зарегистрированный на этот адрес, получил бы доступ через провайдера
+		if !existing.IsEmailVerified() {
+			logger.WithField("user_id", existing.ID).Warn("refusing to link oidc identity to account with unverified email")
+			return nil, apperror.Conflict(apperror.CodeOIDCAccountEmailUnverified, "an account with this email already exists; verify the email before linking an identity provider")
+		}
+		if existing.IsSuspended() {
+			logger.WithField("user_id", existing.ID).Warn("oidc login attempt for suspended user")
+			return nil, apperror.Forbidden(apperror.CodeAccountSuspended, "account is suspended")
+		}
+
+		if err := o.link(ctx, existing.ID, provider, identity); err != nil {
+			return nil, err
+		}
+
+		existing.Password = ""
+		logger.WithField("user_id", existing.ID).Info("oidc identity linked to existing user")
+		return &entity.OIDCLoginResult{User: existing, Linked: true}, nil
+	}
+
+	user, err := o.createUser(ctx, email, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.link(ctx, user.ID, provider, identity); err != nil {
+		// Не оставляем пользователя без способа входа
+		if delErr := o.userRepo.Delete(ctx, user.ID); delErr != nil {
+			logger.WithError(delErr).WithField("user_id", user.ID).Error("failed to roll back user after identity link failure")
+		}
+		return nil, err
+	}
+
+	logger.WithField("user_id", user.ID).Info("user created via oidc")
+	return &entity.OIDCLoginResult{User: user, Created: true}, nil
+}
+
+// createUser заводит пользователя со случайным паролем: войти по паролю можно только после сброса
+func (o *oidcUsecase) createUser(ctx context.Context, email string, identity *service.OIDCIdentity) (*entity.User, error) {
+	password, err := service.GenerateRandomToken(32)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to generate password for oidc user")
+		return nil, err
+	}
+	hashedPassword, err := o.hashService.HashPassword(password)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to hash password for oidc user")
+		return nil, err
+	}
+
+	username, err := generateUsername(identity, email)
+	if err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to generate username for oidc user")
+		return nil, err
+	}
+
+	now := o.now()
+	user := &entity.User{
+		ID:        uuid.New(),
+		Username:  username,
+		Email:     email,
+		Password:  hashedPassword,
+		Role:      entity.RoleUser,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := user.Validate(); err != nil {
+		o.logger.WithContext(ctx).WithError(err).Warn("oidc user validation failed")
+		return nil, err
+	}
+
+	if err := o.userRepo.Create(ctx, user); err != nil {
+		o.logger.WithContext(ctx).WithError(err).Error("failed to create oidc user")
+		return nil, err
+	}
+
+	if identity.EmailVerified {
+		if err := o.userRepo.MarkEmailVerified(ctx, user.ID, email); err != nil {
+			o.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("failed to mark oidc user email as verified")
+		} else {
+			user.EmailVerifiedAt = &now
+		}
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (o *oidcUsecase) link(ctx context.Context, userID uuid.UUID, provider string, identity *service.OIDCIdentity) error {
+	now := o.now()
+	userIdentity := &entity.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Provider:    provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}
+	if err := o.identityRepo.Create(ctx, userIdentity); err != nil {
+		o.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to link oidc identity")
+		return err
+	}
+	return nil
+}
+
+func (o *oidcUsecase) client(provider string) (service.OIDCClient, error) {
+	client, ok := o.providers[provider]
+	if !ok {
+		o.logger.WithField("provider", provider).Warn("unknown oidc provider requested")
+		return nil, apperror.NotFound(apperror.CodeOIDCProviderNotFound, "identity provider not found")
+	}
+	return client, nil
+}
+
+// generateUsername строит имя из preferred_username или локальной части email
+// и добавляет случайный суффикс, чтобы не пересечься с существующими именами
+func generateUsername(identity *service.OIDCIdentity, email string) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' || r == '.' {
+			b.WriteRune(r)
+		}
+		if b.Len() >= maxUsernameBaseLen {
+			break
+		}
+	}
+	base = b.String()
+	if base == "" {
+		base = "user"
+	}
+
+	suffix, err := service.GenerateRandomToken(4)
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + strings.ToLower(suffix), nil
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
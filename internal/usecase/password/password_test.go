@@ -0,0 +1,323 @@
+package password
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	return Config{
+		ResetTokenTTL: time.Hour,
+		ResetURL:      "https://chat.example.com/reset-password",
+	}
+}
+
+func TestPasswordUsecase_RequestReset_SendsEmail(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	testUser := &entity.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com"}
+	var storedToken *entity.PasswordResetToken
+	var sentMessage *service.MailMessage
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return testUser, nil
+	}
+
+	resetRepo.CreateFunc = func(ctx context.Context, token *entity.PasswordResetToken) error {
+		storedToken = token
+		return nil
+	}
+
+	mailer.SendFunc = func(ctx context.Context, msg *service.MailMessage) error {
+		sentMessage = msg
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.RequestReset(context.Background(), "test@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, storedToken)
+	require.NotNil(t, sentMessage)
+	assert.Equal(t, testUser.ID, storedToken.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), storedToken.ExpiresAt, time.Minute)
+	assert.Equal(t, "test@example.com", sentMessage.To)
+
+	// В письме открытый токен, в БД - только его хэш
+	start := strings.Index(sentMessage.Body, "https://chat.example.com/reset-password?token=")
+	require.NotEqual(t, -1, start)
+	link := strings.Fields(sentMessage.Body[start:])[0]
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	token := parsed.Query().Get("token")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, service.HashToken(token), storedToken.TokenHash)
+	assert.NotContains(t, sentMessage.Body, storedToken.TokenHash)
+}
+
+func TestPasswordUsecase_RequestReset_UnknownEmail(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return nil, &NotFoundError{"user not found"}
+	}
+
+	mailer.SendFunc = func(ctx context.Context, msg *service.MailMessage) error {
+		t.Fatal("email must not be sent for unknown address")
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.RequestReset(context.Background(), "unknown@example.com")
+
+	// Assert - ответ такой же, как для существующего адреса
+	assert.NoError(t, err)
+}
+
+func TestPasswordUsecase_ResetPassword_Success(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	testUserID := uuid.New()
+	resetToken := &entity.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    testUserID,
+		TokenHash: service.HashToken("plain-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	var markedUsed, sessionsRevoked bool
+	var updatedUser *entity.User
+
+	resetRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+		if tokenHash != resetToken.TokenHash {
+			return nil, &NotFoundError{"reset token not found"}
+		}
+		return resetToken, nil
+	}
+
+	resetRepo.MarkUsedFunc = func(ctx context.Context, id uuid.UUID) error {
+		markedUsed = id == resetToken.ID
+		return nil
+	}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Username: "testuser", Email: "test@example.com", Password: "old_hash"}, nil
+	}
+
+	hashService.HashPasswordFunc = func(password string) (string, error) {
+		return "new_hash", nil
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		updatedUser = user
+		return nil
+	}
+
+	sessionRepo.DeleteByUserIDFunc = func(ctx context.Context, userID uuid.UUID) error {
+		sessionsRevoked = userID == testUserID
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResetPassword(context.Background(), "plain-token", "new_password")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, markedUsed)
+	assert.True(t, sessionsRevoked)
+	require.NotNil(t, updatedUser)
+	assert.Equal(t, "new_hash", updatedUser.Password)
+}
+
+func TestPasswordUsecase_ResetPassword_ExpiredToken(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	resetRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+		return &entity.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(-time.Minute), // Истек
+		}, nil
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		t.Fatal("password must not be changed with an expired token")
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResetPassword(context.Background(), "plain-token", "new_password")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid or expired reset token")
+	assert.Equal(t, apperror.CodePasswordResetInvalid, apperror.CodeOf(err))
+}
+
+func TestPasswordUsecase_ResetPassword_TokenAlreadyUsed(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	resetRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+		return &entity.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Username: "testuser", Email: "test@example.com"}, nil
+	}
+
+	// Параллельный запрос успел использовать токен
+	resetRepo.MarkUsedFunc = func(ctx context.Context, id uuid.UUID) error {
+		return &NotFoundError{"reset token not found"}
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		t.Fatal("password must not be changed with a used token")
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResetPassword(context.Background(), "plain-token", "new_password")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid or expired reset token")
+	assert.Equal(t, apperror.CodePasswordResetInvalid, apperror.CodeOf(err))
+}
+
+func TestPasswordUsecase_ResetPassword_WeakPassword(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	resetRepo := &mocks.PasswordResetRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+	mailer := &mocks.MailerMock{}
+
+	resetRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+		return &entity.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Username: "testuser", Email: "test@example.com"}, nil
+	}
+
+	passwordPolicy.ValidateFunc = func(password string, user *entity.User) error {
+		require.NotNil(t, user) // Политика получает пользователя для проверки имени и email
+		assert.Equal(t, "testuser", user.Username)
+		return &entity.FieldValidationError{Errors: []entity.FieldError{
+			{Field: "password", Message: "password must be at least 8 characters"},
+		}}
+	}
+
+	resetRepo.MarkUsedFunc = func(ctx context.Context, id uuid.UUID) error {
+		t.Fatal("token must stay usable when the new password is rejected")
+		return nil
+	}
+
+	usecase := NewPasswordUsecase(userRepo, sessionRepo, resetRepo, hashService, passwordPolicy, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResetPassword(context.Background(), "plain-token", "123")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 8 characters")
+}
+
+// NotFoundError представляет ошибку, когда ресурс не найден.
+type NotFoundError struct {
+	Message string
+}
+
+// Error реализует интерфейс error.
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// NotFound сигнализирует, что это ошибка "не найдено".
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
@@ -0,0 +1,10 @@
+package password
+
+import (
+	"context"
+)
+
+type PasswordUsecase interface {
+	RequestReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
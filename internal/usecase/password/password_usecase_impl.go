@@ -0,0 +1,213 @@
+package password
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config параметры сброса пароля
+type Config struct {
+	ResetTokenTTL time.Duration
+	ResetURL      string
+}
+
+const resetTokenSize = 32
+
+type passwordUsecase struct {
+	userRepo    usecase.UserRepository
+	sessionRepo usecase.SessionRepository
+	resetRepo   usecase.PasswordResetRepository
+	hashService service.HashService
+	policy      service.PasswordPolicy
+	mailer      service.Mailer
+	config      Config
+	logger      *logrus.Logger
+}
+
+func NewPasswordUsecase(
+	userRepo usecase.UserRepository,
+	sessionRepo usecase.SessionRepository,
+	resetRepo usecase.PasswordResetRepository,
+	hashService service.HashService,
+	policy service.PasswordPolicy,
+	mailer service.Mailer,
+	config Config,
+	logger *logrus.Logger,
+) PasswordUsecase {
+	return &passwordUsecase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		resetRepo:   resetRepo,
+		hashService: hashService,
+		policy:      policy,
+		mailer:      mailer,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// RequestReset отправляет письмо со ссылкой для сброса пароля.
+// Для неизвестного email возвращает nil, чтобы нельзя было перебирать зарегистрированные адреса
+func (p *passwordUsecase) RequestReset(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "PasswordUsecase.RequestReset")
+	defer span.End()
+
+	p.logger.WithContext(ctx).WithField("email", email).Info("password reset requested")
+
+	user, err := p.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if isNotFound(err) {
+			p.logger.WithContext(ctx).WithField("email", email).Warn("password reset requested for unknown email")
+			return nil
+		}
+		p.logger.WithContext(ctx).WithError(err).Error("failed to fetch user for password reset")
+		return err
+	}
+
+	token, err := service.GenerateRandomToken(resetTokenSize)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).Error("failed to generate reset token")
+		return err
+	}
+
+	now := time.Now()
+	resetToken := &entity.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: service.HashToken(token),
+		ExpiresAt: now.Add(p.config.ResetTokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := p.resetRepo.Create(ctx, resetToken); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to store reset token")
+		return err
+	}
+
+	msg := &service.MailMessage{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nTo reset your password, follow the link below:\n%s\n\n"+
+				"The link is valid for %s and can be used only once.\n"+
+				"If you did not request a password reset, just ignore this email.\n",
+			user.Username, p.resetLink(token), p.config.ResetTokenTTL,
+		),
+	}
+
+	if err := p.mailer.Send(ctx, msg); err != nil {
+		// Клиенту ответ тот же, что и для неизвестного адреса
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to send password reset email")
+		return nil
+	}
+
+	p.logger.WithContext(ctx).WithField("user_id", user.ID).Info("password reset email sent")
+	return nil
+}
+
+func (p *passwordUsecase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "PasswordUsecase.ResetPassword")
+	defer span.End()
+
+	p.logger.WithContext(ctx).Info("password reset attempt")
+
+	if token == "" {
+		return apperror.Validation(apperror.CodePasswordResetInvalid, "invalid or expired reset token")
+	}
+
+	resetToken, err := p.resetRepo.GetByTokenHash(ctx, service.HashToken(token))
+	if err != nil {
+		if isNotFound(err) {
+			p.logger.WithContext(ctx).Warn("unknown password reset token")
+			return apperror.Validation(apperror.CodePasswordResetInvalid, "invalid or expired reset token")
+		}
+		p.logger.WithContext(ctx).WithError(err).Error("failed to fetch reset token")
+		return err
+	}
+
+	if !resetToken.IsUsable(time.Now()) {
+		p.logger.WithContext(ctx).WithField("token_id", resetToken.ID).Warn("password reset token expired or already used")
+		return apperror.Validation(apperror.CodePasswordResetInvalid, "invalid or expired reset token")
+	}
+
+	user, err := p.userRepo.GetByID(ctx, resetToken.UserID)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", resetToken.UserID).Error("failed to fetch user for password reset")
+		return err
+	}
+
+	if err := p.policy.Validate(newPassword, user); err != nil {
+		p.logger.WithContext(ctx).WithError(err).Warn("new password rejected by policy")
+		return err
+	}
+
+	hashedPassword, err := p.hashService.HashPassword(newPassword)
+	if err != nil {
+		p.logger.WithContext(ctx).WithError(err).Error("failed to hash password")
+		return err
+	}
+
+	// Сначала атомарно "забираем" токен, чтобы параллельный запрос с тем же токеном не прошел
+	if err := p.resetRepo.MarkUsed(ctx, resetToken.ID); err != nil {
+		if isNotFound(err) {
+			p.logger.WithContext(ctx).WithField("token_id", resetToken.ID).Warn("password reset token already used")
+			return apperror.Validation(apperror.CodePasswordResetInvalid, "invalid or expired reset token")
+		}
+		p.logger.WithContext(ctx).WithError(err).WithField("token_id", resetToken.ID).Error("failed to mark reset token as used")
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := p.userRepo.Update(ctx, user); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to update password")
+		return err
+	}
+
+	if err := p.revokeCredentials(ctx, user.ID); err != nil {
+		return err
+	}
+
+	p.logger.WithContext(ctx).WithField("user_id", user.ID).Info("password reset successfully")
+	return nil
+}
+
+// revokeCredentials завершает все сессии и аннулирует остальные токены сброса пользователя
+func (p *passwordUsecase) revokeCredentials(ctx context.Context, userID uuid.UUID) error {
+	if err := p.sessionRepo.DeleteByUserID(ctx, userID); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to revoke user sessions after password change")
+		return err
+	}
+	if err := p.resetRepo.DeleteByUserID(ctx, userID); err != nil {
+		p.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("failed to clean up reset tokens")
+	}
+	return nil
+}
+
+func (p *passwordUsecase) resetLink(token string) string {
+	link, err := url.Parse(p.config.ResetURL)
+	if err != nil {
+		return p.config.ResetURL + "?token=" + url.QueryEscape(token)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	return Config{
+		Default: Policy{Name: "default", Key: KeyIP, Limit: 100, Window: time.Minute},
+		Policies: []Policy{
+			{Name: "auth", Routes: []string{"POST /api/v1/login"}, Key: KeyIP, Limit: 2, Window: time.Minute},
+			{Name: "messages_write", Routes: []string{"POST /api/v1/messages"}, Key: KeyAPIKey, Limit: 1, Window: time.Minute},
+		},
+	}
+}
+
+// newCounterStore хранилище счетчиков в памяти, считающее запросы по ключу и началу окна
+func newCounterStore() *mocks.RateLimitRepoMock {
+	counters := make(map[string]int)
+	return &mocks.RateLimitRepoMock{
+		IncrementFunc: func(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+			counterKey := key + "|" + windowStart.String()
+			counters[counterKey]++
+			return counters[counterKey], nil
+		},
+	}
+}
+
+// newTestLimiter создает usecase с хранилищем в памяти и управляемыми часами; возвращает функцию сдвига времени
+func newTestLimiter(config Config) (*rateLimitUsecase, func(time.Duration)) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	limiter := NewRateLimitUsecase(newCounterStore(), config, logger).(*rateLimitUsecase)
+	now := time.Date(2025, 1, 1, 12, 0, 15, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimit_Allow_ExceedsRoutePolicy(t *testing.T) {
+	// Arrange
+	limiter, advance := newTestLimiter(newTestConfig())
+	ctx := context.Background()
+	client := Client{IP: "203.0.113.7"}
+
+	// Act & Assert: два запроса проходят, третий отклоняется до конца окна
+	for i := 0; i < 2; i++ {
+		status, err := limiter.Allow(ctx, "POST /api/v1/login", client)
+		require.NoError(t, err)
+		assert.Equal(t, 2-i-1, status.Remaining)
+	}
+	status, err := limiter.Allow(ctx, "POST /api/v1/login", client)
+	var tooMany *apperror.Error
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, apperror.CodeRateLimited, tooMany.Code)
+	assert.Equal(t, 45*time.Second, tooMany.RetryAfter())
+	assert.Equal(t, "auth", status.Policy)
+	assert.Equal(t, 0, status.Remaining)
+
+	// Другой адрес и маршрут с политикой по умолчанию не затронуты
+	_, err = limiter.Allow(ctx, "POST /api/v1/login", Client{IP: "203.0.113.8"})
+	assert.NoError(t, err)
+	status, err = limiter.Allow(ctx, "GET /api/v1/profile", client)
+	require.NoError(t, err)
+	assert.Equal(t, "default", status.Policy)
+
+	// В новом окне счетчик начинается заново
+	advance(45 * time.Second)
+	_, err = limiter.Allow(ctx, "POST /api/v1/login", client)
+	assert.NoError(t, err)
+}
+
+func TestRateLimit_Allow_KeyFallback(t *testing.T) {
+	// Arrange
+	limiter, _ := newTestLimiter(newTestConfig())
+	ctx := context.Background()
+	userID := uuid.New()
+	route := "POST /api/v1/messages"
+
+	// Act: лимит API ключа не расходует лимит сессии того же пользователя и наоборот
+	_, keyErr := limiter.Allow(ctx, route, Client{IP: "203.0.113.7", UserID: userID, APIKeyID: uuid.New()})
+	_, sessionErr := limiter.Allow(ctx, route, Client{IP: "203.0.113.7", UserID: userID})
+	_, sessionOtherIPErr := limiter.Allow(ctx, route, Client{IP: "198.51.100.1", UserID: userID})
+
+	// Assert: запросы сессии считаются по пользователю, а не по IP-адресу
+	assert.NoError(t, keyErr)
+	assert.NoError(t, sessionErr)
+	assert.Error(t, sessionOtherIPErr)
+}
+
+func TestRateLimit_Allow_Allowlist(t *testing.T) {
+	// Arrange
+	config := newTestConfig()
+	allowlist, err := ParseAllowlist([]string{"10.0.0.0/8", "::ffff:192.0.2.1"})
+	require.NoError(t, err)
+	config.Allowlist = allowlist
+	limiter, _ := newTestLimiter(config)
+
+	// Act & Assert
+	for i := 0; i < 5; i++ {
+		status, err := limiter.Allow(context.Background(), "POST /api/v1/login", Client{IP: "10.1.2.3"})
+		require.NoError(t, err)
+		assert.Nil(t, status)
+	}
+	status, err := limiter.Allow(context.Background(), "POST /api/v1/login", Client{IP: "192.0.2.1"})
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestRateLimit_Allow_StoreFailureLetsRequestThrough(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	repo := &mocks.RateLimitRepoMock{
+		IncrementFunc: func(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+			return 0, errors.New("connection refused")
+		},
+	}
+	limiter := NewRateLimitUsecase(repo, newTestConfig(), logger)
+
+	// Act
+	status, err := limiter.Allow(context.Background(), "POST /api/v1/login", Client{IP: "203.0.113.7"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestRateLimit_UpdateConfig(t *testing.T) {
+	// Arrange
+	limiter, _ := newTestLimiter(newTestConfig())
+	client := Client{IP: "203.0.113.7"}
+
+	// Act: политика маршрута удалена, лимит по умолчанию отключен
+	limiter.UpdateConfig(Config{})
+	status, err := limiter.Allow(context.Background(), "POST /api/v1/login", client)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
@@ -0,0 +1,24 @@
+package ratelimit
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Client от чьего имени выполняется запрос. UserID и APIKeyID пусты до аутентификации
+type Client struct {
+	IP       string
+	UserID   uuid.UUID
+	APIKeyID uuid.UUID
+}
+
+type RateLimitUsecase interface {
+	// Allow учитывает запрос к маршруту route ("METHOD /path") и возвращает состояние лимита
+	// и ошибку с кодом rate_limit.exceeded, если лимит исчерпан. Для маршрута без политики
+	// и адреса из allowlist возвращает nil
+	Allow(ctx context.Context, route string, client Client) (*entity.RateLimitStatus, error)
+	// UpdateConfig применяет новые политики без перезапуска; накопленные счетчики сохраняются
+	UpdateConfig(config Config)
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Ключ, по которому считаются запросы политики
+const (
+	KeyIP     = "ip"      // IP-адрес клиента
+	KeyUser   = "user"    // пользователь; до аутентификации - IP-адрес
+	KeyAPIKey = "api_key" // API ключ; для сессии - пользователь, до аутентификации - IP-адрес
+)
+
+// Как часто удалять из хранилища счетчики закончившихся окон
+const cleanupInterval = time.Minute
+
+// Policy не больше Limit запросов за Window на один ключ. Окно фиксированное: счетчик
+// обнуляется в начале каждого окна
+type Policy struct {
+	Name   string
+	Routes []string // "METHOD /path" в формате описания маршрута gin
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+// Config политики ограничения частоты запросов
+type Config struct {
+	// Default применяется к маршрутам без своей политики; Limit 0 отключает ее
+	Default   Policy
+	Policies  []Policy
+	Allowlist []netip.Prefix
+}
+
+type rateLimitUsecase struct {
+	repo   usecase.RateLimitRepository
+	logger *logrus.Logger
+	now    func() time.Time
+
+	mu          sync.RWMutex
+	config      Config
+	routes      map[string]Policy
+	lastCleanup time.Time
+}
+
+func NewRateLimitUsecase(repo usecase.RateLimitRepository, config Config, logger *logrus.Logger) RateLimitUsecase {
+	r := &rateLimitUsecase{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+	r.UpdateConfig(config)
+	return r
+}
+
+func (r *rateLimitUsecase) UpdateConfig(config Config) {
+	routes := make(map[string]Policy)
+	for _, policy := range config.Policies {
+		for _, route := range policy.Routes {
+			routes[route] = policy
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = config
+	r.routes = routes
+}
+
+func (r *rateLimitUsecase) Allow(ctx context.Context, route string, client Client) (*entity.RateLimitStatus, error) {
+	policy, ok := r.policyFor(route, client.IP)
+	if !ok {
+		return nil, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "RateLimitUsecase.Allow")
+	defer span.End()
+
+	now := r.now()
+	windowStart := now.Truncate(policy.Window)
+	resetAt := windowStart.Add(policy.Window)
+	key := policy.Name + ":" + client.key(policy.Key)
+
+	count, err := r.repo.Increment(ctx, key, windowStart, resetAt)
+	if err != nil {
+		// Недоступное хранилище не должно останавливать сервис: запрос пропускается без учета
+		r.logger.WithContext(ctx).WithError(err).WithField("policy", policy.Name).Error("rate limit check skipped")
+		return nil, nil
+	}
+	r.cleanup(ctx, now)
+
+	status := &entity.RateLimitStatus{
+		Policy:     policy.Name,
+		Limit:      policy.Limit,
+		Remaining:  max(policy.Limit-count, 0),
+		Window:     policy.Window,
+		ResetAfter: resetAt.Sub(now),
+	}
+	if count > policy.Limit {
+		r.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"policy": policy.Name,
+			"route":  route,
+			"key":    key,
+		}).Warn("request rejected: rate limit exceeded")
+		return status, apperror.TooManyRequests(apperror.CodeRateLimited, "too many requests, please try again later", status.ResetAfter)
+	}
+	return status, nil
+}
+
+// policyFor находит политику маршрута; адреса из allowlist не ограничиваются
+func (r *rateLimitUsecase) policyFor(route, ip string) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		addr = addr.Unmap()
+		for _, prefix := range r.config.Allowlist {
+			if prefix.Contains(addr) {
+				return Policy{}, false
+			}
+		}
+	}
+
+	if policy, ok := r.routes[route]; ok {
+		return policy, true
+	}
+	return r.config.Default, r.config.Default.Limit > 0
+}
+
+// cleanup время от времени удаляет счетчики закончившихся окон; ошибка только логируется
+func (r *rateLimitUsecase) cleanup(ctx context.Context, now time.Time) {
+	r.mu.Lock()
+	if now.Sub(r.lastCleanup) < cleanupInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastCleanup = now
+	r.mu.Unlock()
+
+	if err := r.repo.DeleteExpired(ctx, now); err != nil {
+		r.logger.WithContext(ctx).WithError(err).Warn("failed to delete expired rate limit counters")
+	}
+}
+
+// key ключ клиента для политики. Если клиент не аутентифицирован нужным способом,
+// используется следующий по точности ключ
+func (c Client) key(kind string) string {
+	switch kind {
+	case KeyAPIKey:
+		if c.APIKeyID != uuid.Nil {
+			return KeyAPIKey + ":" + c.APIKeyID.String()
+		}
+		fallthrough
+	case KeyUser:
+		if c.UserID != uuid.Nil {
+			return KeyUser + ":" + c.UserID.String()
+		}
+	}
+	return KeyIP + ":" + c.IP
+}
+
+// ParseAllowlist разбирает IP-адреса и подсети в нотации CIDR
+func ParseAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
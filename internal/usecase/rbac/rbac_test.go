@@ -0,0 +1,210 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+	return logger
+}
+
+// usersByID мок репозитория с пользователями в памяти
+func usersByID(users ...*entity.User) *mocks.UserRepoMock {
+	repo := &mocks.UserRepoMock{}
+	repo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		for _, u := range users {
+			if u.ID == id {
+				copied := *u
+				return &copied, nil
+			}
+		}
+		return nil, &NotFoundError{"user not found"}
+	}
+	return repo
+}
+
+func TestRole_Can(t *testing.T) {
+	assert.False(t, entity.RoleUser.Can(entity.PermissionMessagesDeleteAny))
+	assert.True(t, entity.RoleModerator.Can(entity.PermissionMessagesDeleteAny))
+	assert.False(t, entity.RoleModerator.Can(entity.PermissionRolesManage))
+	assert.True(t, entity.RoleAdmin.Can(entity.PermissionRolesManage))
+	assert.False(t, entity.Role("root").Can(entity.PermissionRolesManage))
+	assert.False(t, entity.Role("root").IsValid())
+}
+
+func TestRBACUsecase_GetRole_DefaultsToUser(t *testing.T) {
+	// Arrange
+	user := &entity.User{ID: uuid.New()}
+	uc := NewRBACUsecase(usersByID(user), newTestLogger())
+
+	// Act
+	role, err := uc.GetRole(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, entity.RoleUser, role)
+}
+
+func TestRBACUsecase_Authorize(t *testing.T) {
+	// Arrange
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	uc := NewRBACUsecase(usersByID(moderator), newTestLogger())
+
+	// Act & Assert
+	assert.NoError(t, uc.Authorize(context.Background(), moderator.ID, entity.PermissionMessagesDeleteAny))
+
+	err := uc.Authorize(context.Background(), moderator.ID, entity.PermissionRolesManage)
+	assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden))
+}
+
+func TestRBACUsecase_GrantRole_Success(t *testing.T) {
+	// Arrange
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New(), Role: entity.RoleUser, Password: "hash"}
+	repo := usersByID(admin, target)
+
+	var updatedID uuid.UUID
+	var updatedRole entity.Role
+	repo.UpdateRoleFunc = func(ctx context.Context, id uuid.UUID, role entity.Role) error {
+		updatedID, updatedRole = id, role
+		return nil
+	}
+
+	uc := NewRBACUsecase(repo, newTestLogger())
+
+	// Act
+	user, err := uc.GrantRole(context.Background(), admin.ID, target.ID, entity.RoleModerator)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, updatedID)
+	assert.Equal(t, entity.RoleModerator, updatedRole)
+	assert.Equal(t, entity.RoleModerator, user.Role)
+	assert.Empty(t, user.Password)
+}
+
+func TestRBACUsecase_RevokeRole_Success(t *testing.T) {
+	// Arrange
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	target := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	repo := usersByID(admin, target)
+
+	var updatedRole entity.Role
+	repo.UpdateRoleFunc = func(ctx context.Context, id uuid.UUID, role entity.Role) error {
+		updatedRole = role
+		return nil
+	}
+
+	uc := NewRBACUsecase(repo, newTestLogger())
+
+	// Act
+	user, err := uc.RevokeRole(context.Background(), admin.ID, target.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, entity.RoleUser, updatedRole)
+	assert.Equal(t, entity.RoleUser, user.Role)
+}
+
+func TestRBACUsecase_GrantRole_Rejected(t *testing.T) {
+	admin := &entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	moderator := &entity.User{ID: uuid.New(), Role: entity.RoleModerator}
+	target := &entity.User{ID: uuid.New(), Role: entity.RoleUser}
+
+	tests := []struct {
+		name     string
+		actorID  uuid.UUID
+		targetID uuid.UUID
+		role     entity.Role
+		check    func(t *testing.T, err error)
+	}{
+		{"неизвестная роль", admin.ID, target.ID, "root", func(t *testing.T, err error) {
+			assert.Equal(t, apperror.CodeRoleInvalid, apperror.CodeOf(err))
+		}},
+		{"модератор не управляет ролями", moderator.ID, target.ID, entity.RoleModerator, func(t *testing.T, err error) {
+			assert.Equal(t, apperror.CodePermissionDenied, apperror.CodeOf(err))
+		}},
+		{"своя роль", admin.ID, admin.ID, entity.RoleUser, func(t *testing.T, err error) {
+			assert.Equal(t, apperror.CodeUserSelfAction, apperror.CodeOf(err))
+		}},
+		{"пользователь не найден", admin.ID, uuid.New(), entity.RoleModerator, func(t *testing.T, err error) {
+			var notFound *NotFoundError
+			assert.ErrorAs(t, err, &notFound)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := usersByID(admin, moderator, target)
+			repo.UpdateRoleFunc = func(ctx context.Context, id uuid.UUID, role entity.Role) error {
+				t.Fatal("role must not be updated")
+				return nil
+			}
+			uc := NewRBACUsecase(repo, newTestLogger())
+
+			// Act
+			_, err := uc.GrantRole(context.Background(), tt.actorID, tt.targetID, tt.role)
+
+			// Assert
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestRBACUsecase_BootstrapAdmins(t *testing.T) {
+	// Arrange
+	existing := &entity.User{ID: uuid.New(), Email: "ops@example.com", Role: entity.RoleUser}
+	alreadyAdmin := &entity.User{ID: uuid.New(), Email: "root@example.com", Role: entity.RoleAdmin}
+
+	repo := &mocks.UserRepoMock{}
+	repo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		for _, u := range []*entity.User{existing, alreadyAdmin} {
+			if u.Email == email {
+				return u, nil
+			}
+		}
+		return nil, &NotFoundError{"user not found"}
+	}
+
+	var promoted []uuid.UUID
+	repo.UpdateRoleFunc = func(ctx context.Context, id uuid.UUID, role entity.Role) error {
+		assert.Equal(t, entity.RoleAdmin, role)
+		promoted = append(promoted, id)
+		return nil
+	}
+
+	uc := NewRBACUsecase(repo, newTestLogger())
+
+	// Act
+	err := uc.BootstrapAdmins(context.Background(), []string{"ops@example.com", "root@example.com", "missing@example.com"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{existing.ID}, promoted)
+}
+
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
@@ -0,0 +1,16 @@
+package rbac
+
+import (
+	"chat-service/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type RBACUsecase interface {
+	GetRole(ctx context.Context, userID uuid.UUID) (entity.Role, error)
+	Authorize(ctx context.Context, userID uuid.UUID, permission entity.Permission) error
+	GrantRole(ctx context.Context, actorID, targetID uuid.UUID, role entity.Role) (*entity.User, error)
+	RevokeRole(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error)
+	BootstrapAdmins(ctx context.Context, emails []string) error
+}
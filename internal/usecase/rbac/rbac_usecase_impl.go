@@ -0,0 +1,145 @@
+package rbac
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type rbacUsecase struct {
+	userRepo usecase.UserRepository
+	logger   *logrus.Logger
+}
+
+func NewRBACUsecase(userRepo usecase.UserRepository, logger *logrus.Logger) RBACUsecase {
+	return &rbacUsecase{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// GetRole возвращает текущую роль пользователя. Роль читается из БД на каждый запрос,
+// поэтому отзыв роли действует сразу, без перевыпуска токенов
+func (r *rbacUsecase) GetRole(ctx context.Context, userID uuid.UUID) (entity.Role, error) {
+	ctx, span := tracing.Start(ctx, "RBACUsecase.GetRole", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := r.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("failed to fetch user role")
+		return "", err
+	}
+	return user.EffectiveRole(), nil
+}
+
+// Authorize возвращает ошибку с кодом auth.permission_denied, если у роли пользователя нет права
+func (r *rbacUsecase) Authorize(ctx context.Context, userID uuid.UUID, permission entity.Permission) error {
+	ctx, span := tracing.Start(ctx, "RBACUsecase.Authorize", tracing.UserID(userID))
+	defer span.End()
+
+	role, err := r.GetRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !role.Can(permission) {
+		r.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"user_id":    userID,
+			"role":       role,
+			"permission": permission,
+		}).Warn("permission denied")
+		return apperror.Forbidden(apperror.CodePermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+// GrantRole назначает пользователю роль. Свою роль менять нельзя, чтобы администратор не лишил себя прав случайно
+func (r *rbacUsecase) GrantRole(ctx context.Context, actorID, targetID uuid.UUID, role entity.Role) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "RBACUsecase.GrantRole", tracing.UserID(actorID))
+	defer span.End()
+
+	logger := r.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"actor_id":  actorID,
+		"target_id": targetID,
+		"role":      role,
+	})
+	logger.Info("role change requested")
+
+	if !role.IsValid() {
+		return nil, apperror.InvalidField(apperror.CodeRoleInvalid, "role", "invalid role: "+string(role))
+	}
+
+	if err := r.Authorize(ctx, actorID, entity.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+
+	if actorID == targetID {
+		logger.Warn("attempt to change own role")
+		return nil, apperror.Validation(apperror.CodeUserSelfAction, "you cannot change your own role")
+	}
+
+	target, err := r.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		logger.WithError(err).Warn("failed to fetch user for role change")
+		return nil, err
+	}
+
+	if target.Role != role {
+		if err := r.userRepo.UpdateRole(ctx, targetID, role); err != nil {
+			logger.WithError(err).Error("failed to update role")
+			return nil, err
+		}
+		logger.WithField("previous_role", target.Role).Info("role changed")
+	}
+
+	target.Role = role
+	target.Password = ""
+	return target, nil
+}
+
+// RevokeRole возвращает пользователю базовую роль
+func (r *rbacUsecase) RevokeRole(ctx context.Context, actorID, targetID uuid.UUID) (*entity.User, error) {
+	ctx, span := tracing.Start(ctx, "RBACUsecase.RevokeRole", tracing.UserID(actorID))
+	defer span.End()
+
+	return r.GrantRole(ctx, actorID, targetID, entity.RoleUser)
+}
+
+// BootstrapAdmins назначает роль администратора пользователям из конфигурации.
+// Нужен для первого администратора; отсутствующие пользователи пропускаются
+func (r *rbacUsecase) BootstrapAdmins(ctx context.Context, emails []string) error {
+	ctx, span := tracing.Start(ctx, "RBACUsecase.BootstrapAdmins")
+	defer span.End()
+
+	for _, email := range emails {
+		user, err := r.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			if isNotFound(err) {
+				r.logger.WithContext(ctx).WithField("email", email).Warn("bootstrap admin not registered yet")
+				continue
+			}
+			r.logger.WithContext(ctx).WithError(err).WithField("email", email).Error("failed to fetch bootstrap admin")
+			return err
+		}
+		if user == nil || user.Role == entity.RoleAdmin {
+			continue
+		}
+
+		if err := r.userRepo.UpdateRole(ctx, user.ID, entity.RoleAdmin); err != nil {
+			r.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to grant admin role")
+			return err
+		}
+		r.logger.WithContext(ctx).WithField("user_id", user.ID).Info("admin role granted from configuration")
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
@@ -2,9 +2,11 @@ package session
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
 	"chat-service/internal/usecase/mocks"
 
@@ -33,7 +35,7 @@ func TestSessionUsecase_CreateSession_Success(t *testing.T) {
 		return nil // Успешное создание сессии
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
 	session, err := usecase.CreateSession(context.Background(), testUserID)
@@ -60,10 +62,10 @@ func TestSessionUsecase_CreateSession_JWTError(t *testing.T) {
 
 	// Настраиваем моки - ошибка генерации токена
 	jwtService.GenerateTokenFunc = func(userID uuid.UUID) (string, error) {
-		return "", &BusinessError{"failed to generate token"}
+		return "", errors.New("failed to generate token")
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
 	session, err := usecase.CreateSession(context.Background(), testUserID)
@@ -101,15 +103,65 @@ func TestSessionUsecase_ValidateSession_Success(t *testing.T) {
 		return testUserID, nil // Токен валиден
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id}, nil // Пользователь активен
+		},
+	}
+
+	usecase := NewSessionUsecase(sessionRepo, userRepo, jwtService, logger)
 
 	// Act
-	session, err := usecase.ValidateSession(context.Background(), testToken)
+	session, user, err := usecase.ValidateSession(context.Background(), testToken)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, session)
 	assert.Equal(t, testSession, session)
+	assert.Equal(t, testUserID, user.ID)
+}
+
+func TestSessionUsecase_ValidateSession_SuspendedUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	sessionRepo := &mocks.SessionRepoMock{}
+	jwtService := &mocks.JWTServiceMock{}
+
+	testToken := "valid_token"
+	testUserID := uuid.New()
+	suspendedAt := time.Now().Add(-time.Minute)
+
+	sessionRepo.GetByTokenFunc = func(ctx context.Context, token string) (*entity.Session, error) {
+		return &entity.Session{
+			ID:        uuid.New(),
+			UserID:    testUserID,
+			Token:     testToken,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}
+	jwtService.ValidateTokenFunc = func(token string) (uuid.UUID, error) {
+		return testUserID, nil
+	}
+
+	// Пользователь заблокирован администратором
+	userRepo := &mocks.UserRepoMock{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+			return &entity.User{ID: id, SuspendedAt: &suspendedAt}, nil
+		},
+	}
+
+	usecase := NewSessionUsecase(sessionRepo, userRepo, jwtService, logger)
+
+	// Act
+	session, _, err := usecase.ValidateSession(context.Background(), testToken)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, session)
+	assert.Contains(t, err.Error(), "account is suspended")
+	assert.Equal(t, apperror.CodeAccountSuspended, apperror.CodeOf(err))
 }
 
 func TestSessionUsecase_ValidateSession_SessionNotFound(t *testing.T) {
@@ -127,15 +179,16 @@ func TestSessionUsecase_ValidateSession_SessionNotFound(t *testing.T) {
 		return nil, &NotFoundError{"session not found"}
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
-	session, err := usecase.ValidateSession(context.Background(), testToken)
+	session, _, err := usecase.ValidateSession(context.Background(), testToken)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, session)
 	assert.Contains(t, err.Error(), "invalid session")
+	assert.Equal(t, apperror.CodeSessionInvalid, apperror.CodeOf(err))
 }
 
 func TestSessionUsecase_ValidateSession_SessionExpired(t *testing.T) {
@@ -165,15 +218,17 @@ func TestSessionUsecase_ValidateSession_SessionExpired(t *testing.T) {
 		return nil // Успешное удаление
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
-	session, err := usecase.ValidateSession(context.Background(), testToken)
+	session, _, err := usecase.ValidateSession(context.Background(), testToken)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, session)
 	assert.Contains(t, err.Error(), "session expired")
+	assert.Equal(t, apperror.CodeSessionExpired, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindUnauthorized))
 }
 
 func TestSessionUsecase_ValidateSession_InvalidJWT(t *testing.T) {
@@ -199,18 +254,19 @@ func TestSessionUsecase_ValidateSession_InvalidJWT(t *testing.T) {
 	}
 
 	jwtService.ValidateTokenFunc = func(token string) (uuid.UUID, error) {
-		return uuid.Nil, &BusinessError{"invalid token"}
+		return uuid.Nil, errors.New("invalid token")
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
-	session, err := usecase.ValidateSession(context.Background(), testToken)
+	session, _, err := usecase.ValidateSession(context.Background(), testToken)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, session)
 	assert.Contains(t, err.Error(), "invalid token")
+	assert.Equal(t, apperror.CodeTokenInvalid, apperror.CodeOf(err))
 }
 
 func TestSessionUsecase_DeleteSession_Success(t *testing.T) {
@@ -228,7 +284,7 @@ func TestSessionUsecase_DeleteSession_Success(t *testing.T) {
 		return nil // Успешное удаление
 	}
 
-	usecase := NewSessionUsecase(sessionRepo, jwtService, logger)
+	usecase := NewSessionUsecase(sessionRepo, &mocks.UserRepoMock{}, jwtService, logger)
 
 	// Act
 	err := usecase.DeleteSession(context.Background(), testToken)
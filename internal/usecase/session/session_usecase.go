@@ -9,6 +9,7 @@ import (
 
 type SessionUsecase interface {
 	CreateSession(ctx context.Context, userID uuid.UUID) (*entity.Session, error)
-	ValidateSession(ctx context.Context, token string) (*entity.Session, error)
+	// ValidateSession проверяет токен сессии и возвращает сессию вместе с ее владельцем
+	ValidateSession(ctx context.Context, token string) (*entity.Session, *entity.User, error)
 	DeleteSession(ctx context.Context, token string) error
 }
@@ -2,15 +2,18 @@ package user
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
 	"chat-service/internal/usecase/mocks"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserUsecase_Register_Success(t *testing.T) {
@@ -22,6 +25,7 @@ func TestUserUsecase_Register_Success(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	// Настраиваем моки
 	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
@@ -36,7 +40,7 @@ func TestUserUsecase_Register_Success(t *testing.T) {
 		return "hashed_password", nil
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	user, err := usecase.Register(context.Background(), "testuser", "test@example.com", "password123")
@@ -49,6 +53,46 @@ func TestUserUsecase_Register_Success(t *testing.T) {
 	assert.Empty(t, user.Password) // Пароль должен быть очищен
 }
 
+func TestUserUsecase_Register_PasswordRejectedByPolicy(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return nil, &NotFoundError{"user not found"}
+	}
+
+	passwordPolicy.ValidateFunc = func(password string, user *entity.User) error {
+		assert.Equal(t, "testuser", user.Username)
+		assert.Equal(t, "test@example.com", user.Email)
+		return &entity.FieldValidationError{Errors: []entity.FieldError{
+			{Field: "password", Message: "password must not contain the username"},
+		}}
+	}
+
+	userRepo.CreateFunc = func(ctx context.Context, user *entity.User) error {
+		t.Fatal("user must not be created with a rejected password")
+		return nil
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	user, err := usecase.Register(context.Background(), "testuser", "test@example.com", "testuser123")
+
+	// Assert
+	assert.Nil(t, user)
+	var fieldErr *entity.FieldValidationError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, []string{"password must not contain the username"}, fieldErr.FieldErrors()["password"])
+}
+
 func TestUserUsecase_Register_UserExists(t *testing.T) {
 	// Arrange
 	logger := logrus.New()
@@ -58,6 +102,7 @@ func TestUserUsecase_Register_UserExists(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	// Настраиваем моки - пользователь уже существует
 	existingUser := &entity.User{
@@ -71,7 +116,7 @@ func TestUserUsecase_Register_UserExists(t *testing.T) {
 		return existingUser, nil // Пользователь существует
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	user, err := usecase.Register(context.Background(), "testuser", "test@example.com", "password123")
@@ -80,6 +125,8 @@ func TestUserUsecase_Register_UserExists(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.Contains(t, err.Error(), "user with this email already exists")
+	assert.Equal(t, apperror.CodeUserEmailTaken, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
 }
 
 func TestUserUsecase_Login_Success(t *testing.T) {
@@ -91,6 +138,7 @@ func TestUserUsecase_Login_Success(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	// Настраиваем моки
 	testUser := &entity.User{
@@ -110,7 +158,7 @@ func TestUserUsecase_Login_Success(t *testing.T) {
 		return true // Правильный пароль
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	user, err := usecase.Login(context.Background(), "test@example.com", "password123")
@@ -123,6 +171,118 @@ func TestUserUsecase_Login_Success(t *testing.T) {
 	assert.Empty(t, user.Password) // Пароль должен быть очищен
 }
 
+func TestUserUsecase_Login_RehashesOutdatedHash(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	testUser := &entity.User{ID: uuid.New(), Email: "test@example.com", Password: "$2a$10$legacy"}
+	var updatedPassword string
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return testUser, nil
+	}
+
+	hashService.NeedsRehashFunc = func(hash string) bool {
+		return hash == "$2a$10$legacy"
+	}
+
+	hashService.HashPasswordFunc = func(password string) (string, error) {
+		assert.Equal(t, "password123", password)
+		return "$argon2id$new", nil
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		updatedPassword = user.Password
+		return nil
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	user, err := usecase.Login(context.Background(), "test@example.com", "password123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+	assert.Equal(t, "$argon2id$new", updatedPassword)
+	assert.Empty(t, user.Password) // Пароль все равно очищается перед возвратом
+}
+
+func TestUserUsecase_Login_RehashFailureDoesNotBlockLogin(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return &entity.User{ID: uuid.New(), Email: email, Password: "$2a$10$legacy"}, nil
+	}
+
+	hashService.NeedsRehashFunc = func(hash string) bool {
+		return true
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		return errors.New("database unavailable")
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	user, err := usecase.Login(context.Background(), "test@example.com", "password123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+}
+
+func TestUserUsecase_Login_SuspendedUser(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	suspendedAt := time.Now().Add(-time.Hour)
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return &entity.User{
+			ID:          uuid.New(),
+			Email:       email,
+			Password:    "hashed_password",
+			SuspendedAt: &suspendedAt,
+		}, nil
+	}
+	hashService.CheckPasswordHashFunc = func(password, hash string) bool {
+		return true
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	user, err := usecase.Login(context.Background(), "test@example.com", "password123")
+
+	// Assert
+	assert.Nil(t, user)
+	assert.Equal(t, apperror.CodeAccountSuspended, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden))
+}
+
 func TestUserUsecase_Login_InvalidCredentials(t *testing.T) {
 	// Arrange
 	logger := logrus.New()
@@ -132,13 +292,14 @@ func TestUserUsecase_Login_InvalidCredentials(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	// Настраиваем моки - пользователь не найден
 	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
 		return nil, &NotFoundError{"user not found"}
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	user, err := usecase.Login(context.Background(), "test@example.com", "wrongpassword")
@@ -147,6 +308,51 @@ func TestUserUsecase_Login_InvalidCredentials(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.Contains(t, err.Error(), "invalid credentials")
+	assert.Equal(t, apperror.CodeInvalidCredentials, apperror.CodeOf(err))
+	assert.True(t, apperror.IsKind(err, apperror.KindUnauthorized))
+}
+
+func TestUserUsecase_Login_UnknownEmailChecksDummyHash(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	var hashCalls, checkCalls int
+	var checkedHash string
+
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*entity.User, error) {
+		return nil, &NotFoundError{"user not found"}
+	}
+
+	hashService.HashPasswordFunc = func(password string) (string, error) {
+		hashCalls++
+		return "dummy_hash", nil
+	}
+
+	hashService.CheckPasswordHashFunc = func(password, hash string) bool {
+		checkCalls++
+		checkedHash = hash
+		return false
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	_, err1 := usecase.Login(context.Background(), "unknown@example.com", "password")
+	_, err2 := usecase.Login(context.Background(), "unknown@example.com", "password")
+
+	// Assert - проверка хэша выполняется так же, как для существующего пользователя
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Equal(t, 2, checkCalls)
+	assert.Equal(t, "dummy_hash", checkedHash)
+	assert.Equal(t, 1, hashCalls) // Фиктивный хэш вычисляется один раз
 }
 
 func TestUserUsecase_GetProfile_Success(t *testing.T) {
@@ -158,6 +364,7 @@ func TestUserUsecase_GetProfile_Success(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	testUserID := uuid.New()
 	testUser := &entity.User{
@@ -173,7 +380,7 @@ func TestUserUsecase_GetProfile_Success(t *testing.T) {
 		return testUser, nil
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	user, err := usecase.GetProfile(context.Background(), testUserID)
@@ -195,6 +402,7 @@ func TestUserUsecase_UpdateProfile_Success(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	testUser := &entity.User{
 		ID:        uuid.New(),
@@ -208,7 +416,7 @@ func TestUserUsecase_UpdateProfile_Success(t *testing.T) {
 		return nil // Успешное обновление
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	err := usecase.UpdateProfile(context.Background(), testUser)
@@ -217,6 +425,137 @@ func TestUserUsecase_UpdateProfile_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUserUsecase_ChangePassword_Success(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	testUserID := uuid.New()
+	currentSessionID := uuid.New()
+	var updatedUser *entity.User
+	var keptSessionID uuid.UUID
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Username: "testuser", Email: "test@example.com", Password: "old_hash"}, nil
+	}
+
+	hashService.CheckPasswordHashFunc = func(password, hash string) bool {
+		return password == "old_password" && hash == "old_hash"
+	}
+
+	hashService.HashPasswordFunc = func(password string) (string, error) {
+		return "new_hash", nil
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		updatedUser = user
+		return nil
+	}
+
+	sessionRepo.DeleteByUserIDExceptFunc = func(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+		assert.Equal(t, testUserID, userID)
+		keptSessionID = keepSessionID
+		return nil
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	err := usecase.ChangePassword(context.Background(), testUserID, currentSessionID, "old_password", "new_password")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, updatedUser)
+	assert.Equal(t, "new_hash", updatedUser.Password)
+	assert.Equal(t, currentSessionID, keptSessionID) // Текущая сессия сохраняется
+}
+
+func TestUserUsecase_ChangePassword_WrongCurrentPassword(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Password: "old_hash"}, nil
+	}
+
+	hashService.CheckPasswordHashFunc = func(password, hash string) bool {
+		return false
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		t.Fatal("password must not be changed without the current password")
+		return nil
+	}
+
+	sessionRepo.DeleteByUserIDExceptFunc = func(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+		t.Fatal("sessions must not be revoked when the change is rejected")
+		return nil
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	err := usecase.ChangePassword(context.Background(), uuid.New(), uuid.New(), "wrong_password", "new_password")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "current password is incorrect")
+	assert.Equal(t, apperror.CodeUserCurrentPasswordInvalid, apperror.CodeOf(err))
+}
+
+func TestUserUsecase_ChangePassword_WeakPassword(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	sessionRepo := &mocks.SessionRepoMock{}
+	hashService := &mocks.HashServiceMock{}
+	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Password: "old_hash"}, nil
+	}
+
+	hashService.CheckPasswordHashFunc = func(password, hash string) bool {
+		return true
+	}
+
+	passwordPolicy.ValidateFunc = func(password string, user *entity.User) error {
+		return &entity.FieldValidationError{Errors: []entity.FieldError{
+			{Field: "password", Message: "password must be at least 8 characters"},
+		}}
+	}
+
+	userRepo.UpdateFunc = func(ctx context.Context, user *entity.User) error {
+		t.Fatal("weak password must be rejected")
+		return nil
+	}
+
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
+
+	// Act
+	err := usecase.ChangePassword(context.Background(), uuid.New(), uuid.New(), "old_password", "123")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 8 characters")
+}
+
 func TestUserUsecase_DeleteUser_Success(t *testing.T) {
 	// Arrange
 	logger := logrus.New()
@@ -226,6 +565,7 @@ func TestUserUsecase_DeleteUser_Success(t *testing.T) {
 	sessionRepo := &mocks.SessionRepoMock{}
 	hashService := &mocks.HashServiceMock{}
 	jwtService := &mocks.JWTServiceMock{}
+	passwordPolicy := &mocks.PasswordPolicyMock{}
 
 	testUserID := uuid.New()
 
@@ -237,7 +577,7 @@ func TestUserUsecase_DeleteUser_Success(t *testing.T) {
 		return nil // Успешное удаление
 	}
 
-	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, logger)
+	usecase := NewUserUsecase(userRepo, sessionRepo, hashService, jwtService, passwordPolicy, logger)
 
 	// Act
 	err := usecase.DeleteUser(context.Background(), testUserID)
@@ -12,5 +12,6 @@ type UserUsecase interface {
 	Login(ctx context.Context, email, password string) (*entity.User, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (*entity.User, error)
 	UpdateProfile(ctx context.Context, user *entity.User) error
+	ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword, newPassword string) error
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
 }
@@ -1,10 +1,13 @@
 package user
 
 import (
+	"chat-service/internal/apperror"
 	"chat-service/internal/entity"
 	"chat-service/internal/service"
+	"chat-service/internal/tracing"
 	"chat-service/internal/usecase"
 	"context"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,7 +19,11 @@ type userUsecase struct {
 	sessionRepo usecase.SessionRepository
 	hashService service.HashService
 	jwtService  service.JWTService
+	policy      service.PasswordPolicy
 	logger      *logrus.Logger
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 func NewUserUsecase(
@@ -24,6 +31,7 @@ func NewUserUsecase(
 	sessionRepo usecase.SessionRepository,
 	hashService service.HashService,
 	jwtService service.JWTService,
+	policy service.PasswordPolicy,
 	logger *logrus.Logger,
 ) UserUsecase {
 	return &userUsecase{
@@ -31,12 +39,16 @@ func NewUserUsecase(
 		sessionRepo: sessionRepo,
 		hashService: hashService,
 		jwtService:  jwtService,
+		policy:      policy,
 		logger:      logger,
 	}
 }
 
 func (u *userUsecase) Register(ctx context.Context, username, email, password string) (*entity.User, error) {
-	u.logger.WithFields(logrus.Fields{
+	ctx, span := tracing.Start(ctx, "UserUsecase.Register")
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithFields(logrus.Fields{
 		"username": username,
 		"email":    email,
 	}).Info("registering new user")
@@ -44,16 +56,22 @@ func (u *userUsecase) Register(ctx context.Context, username, email, password st
 	// Проверяем, существует ли пользователь с таким email
 	existingUser, _ := u.userRepo.GetByEmail(ctx, email)
 	if existingUser != nil {
-		err := &BusinessError{"user with this email already exists"}
-		u.logger.WithField("email", email).Warn("user already exists")
+		err := apperror.Conflict(apperror.CodeUserEmailTaken, "user with this email already exists")
+		u.logger.WithContext(ctx).WithField("email", email).Warn("user already exists")
+		return nil, err
+	}
+
+	// Проверяем пароль по политике до хэширования
+	if err := u.policy.Validate(password, &entity.User{Username: username, Email: email}); err != nil {
+		u.logger.WithContext(ctx).WithError(err).Warn("password rejected by policy")
 		return nil, err
 	}
 
 	// Хэшируем пароль
-	u.logger.Debug("hashing user password")
+	u.logger.WithContext(ctx).Debug("hashing user password")
 	hashedPassword, err := u.hashService.HashPassword(password)
 	if err != nil {
-		u.logger.WithError(err).Error("failed to hash password")
+		u.logger.WithContext(ctx).WithError(err).Error("failed to hash password")
 		return nil, err
 	}
 
@@ -62,112 +80,211 @@ func (u *userUsecase) Register(ctx context.Context, username, email, password st
 		Username:  username,
 		Email:     email,
 		Password:  hashedPassword,
+		Role:      entity.RoleUser,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	// Валидируем пользователя
 	if err := user.Validate(); err != nil {
-		u.logger.WithError(err).Warn("user validation failed")
+		u.logger.WithContext(ctx).WithError(err).Warn("user validation failed")
 		return nil, err
 	}
 
 	// Создаем пользователя
-	u.logger.WithField("user_id", user.ID).Debug("creating user in repository")
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Debug("creating user in repository")
 	if err := u.userRepo.Create(ctx, user); err != nil {
-		u.logger.WithError(err).WithField("user_id", user.ID).Error("failed to create user")
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to create user")
 		return nil, err
 	}
 
 	// Очищаем пароль перед возвратом
 	user.Password = ""
-	u.logger.WithField("user_id", user.ID).Info("user registered successfully")
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Info("user registered successfully")
 	return user, nil
 }
 
 func (u *userUsecase) Login(ctx context.Context, email, password string) (*entity.User, error) {
-	u.logger.WithField("email", email).Info("user login attempt")
+	ctx, span := tracing.Start(ctx, "UserUsecase.Login")
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithField("email", email).Info("user login attempt")
 
 	user, err := u.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		u.logger.WithField("email", email).Warn("user not found during login")
-		return nil, &BusinessError{"invalid credentials"}
+		// Проверяем пароль против фиктивного хэша, чтобы неизвестный email
+		// нельзя было отличить от неверного пароля по времени ответа
+		u.hashService.CheckPasswordHash(password, u.dummyPasswordHash())
+		u.logger.WithContext(ctx).WithField("email", email).Warn("user not found during login")
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid credentials")
 	}
 
 	// Проверяем пароль
-	u.logger.Debug("checking password hash")
+	u.logger.WithContext(ctx).Debug("checking password hash")
 	if !u.hashService.CheckPasswordHash(password, user.Password) {
-		u.logger.WithField("email", email).Warn("invalid password during login")
-		return nil, &BusinessError{"invalid credentials"}
+		u.logger.WithContext(ctx).WithField("email", email).Warn("invalid password during login")
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid credentials")
+	}
+
+	// Статус блокировки раскрываем только после проверки пароля,
+	// чтобы по нему нельзя было перебирать существующие аккаунты
+	if user.IsSuspended() {
+		u.logger.WithContext(ctx).WithField("user_id", user.ID).Warn("login attempt for suspended user")
+		return nil, apperror.Forbidden(apperror.CodeAccountSuspended, "account is suspended")
+	}
+
+	// Пароль известен только сейчас: обновляем хэш, если он создан устаревшим алгоритмом или параметрами
+	if u.hashService.NeedsRehash(user.Password) {
+		u.rehashPassword(ctx, user, password)
 	}
 
 	// Очищаем пароль перед возвратом
 	user.Password = ""
-	u.logger.WithField("user_id", user.ID).Info("user login successful")
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Info("user login successful")
 	return user, nil
 }
 
 func (u *userUsecase) GetProfile(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
-	u.logger.WithField("user_id", userID).Debug("fetching user profile")
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetProfile", tracing.UserID(userID))
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithField("user_id", userID).Debug("fetching user profile")
 
 	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		u.logger.WithError(err).WithField("user_id", userID).Error("failed to fetch user profile")
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch user profile")
 		return nil, err
 	}
 
 	user.Password = "" // Очищаем пароль перед возвратом
-	u.logger.WithField("user_id", userID).Debug("user profile fetched successfully")
+	u.logger.WithContext(ctx).WithField("user_id", userID).Debug("user profile fetched successfully")
 	return user, nil
 }
 
 func (u *userUsecase) UpdateProfile(ctx context.Context, user *entity.User) error {
-	u.logger.WithField("user_id", user.ID).Info("updating user profile")
+	ctx, span := tracing.Start(ctx, "UserUsecase.UpdateProfile")
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Info("updating user profile")
 
 	user.UpdatedAt = time.Now()
 	if err := user.ValidateForUpdate(); err != nil {
-		u.logger.WithError(err).WithField("user_id", user.ID).Warn("user validation failed during update")
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("user validation failed during update")
 		return err
 	}
 
 	err := u.userRepo.Update(ctx, user)
 	if err != nil {
-		u.logger.WithError(err).WithField("user_id", user.ID).Error("failed to update user profile")
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to update user profile")
 		return err
 	}
 
-	u.logger.WithField("user_id", user.ID).Info("user profile updated successfully")
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Info("user profile updated successfully")
+	return nil
+}
+
+// ChangePassword меняет пароль после проверки текущего и завершает все сессии, кроме текущей
+func (u *userUsecase) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangePassword", tracing.UserID(userID))
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithField("user_id", userID).Info("password change attempt")
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch user for password change")
+		return err
+	}
+
+	if !u.hashService.CheckPasswordHash(currentPassword, user.Password) {
+		u.logger.WithContext(ctx).WithField("user_id", userID).Warn("invalid current password during password change")
+		return apperror.InvalidField(apperror.CodeUserCurrentPasswordInvalid, "current_password", "current password is incorrect")
+	}
+
+	if err := u.policy.Validate(newPassword, user); err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Warn("new password rejected by policy")
+		return err
+	}
+
+	if newPassword == currentPassword {
+		return apperror.InvalidField(apperror.CodeUserPasswordUnchanged, "new_password", "new password must differ from the current one")
+	}
+
+	hashedPassword, err := u.hashService.HashPassword(newPassword)
+	if err != nil {
+		u.logger.WithContext(ctx).WithError(err).Error("failed to hash password")
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to update password")
+		return err
+	}
+
+	// Остальные сессии могли быть открыты тем, кто знал старый пароль
+	if err := u.sessionRepo.DeleteByUserIDExcept(ctx, userID, currentSessionID); err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to revoke other sessions after password change")
+		return err
+	}
+
+	u.logger.WithContext(ctx).WithField("user_id", userID).Info("password changed successfully")
 	return nil
 }
 
 func (u *userUsecase) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	u.logger.WithField("user_id", userID).Warn("deleting user")
+	ctx, span := tracing.Start(ctx, "UserUsecase.DeleteUser", tracing.UserID(userID))
+	defer span.End()
+
+	u.logger.WithContext(ctx).WithField("user_id", userID).Warn("deleting user")
 
 	// Удаляем сессии пользователя
 	_, err := u.sessionRepo.GetByUserID(ctx, userID)
 	if err == nil {
 		// Здесь можно добавить удаление всех сессий пользователя
-		u.logger.WithField("user_id", userID).Debug("cleaning up user sessions")
+		u.logger.WithContext(ctx).WithField("user_id", userID).Debug("cleaning up user sessions")
 	}
 
 	err = u.userRepo.Delete(ctx, userID)
 	if err != nil {
-		u.logger.WithError(err).WithField("user_id", userID).Error("failed to delete user")
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to delete user")
 		return err
 	}
 
-	u.logger.WithField("user_id", userID).Info("user deleted successfully")
+	u.logger.WithContext(ctx).WithField("user_id", userID).Info("user deleted successfully")
 	return nil
 }
 
-type BusinessError struct {
-	Message string
-}
+// rehashPassword сохраняет хэш с актуальными параметрами. Ошибка не мешает входу
+func (u *userUsecase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hashedPassword, err := u.hashService.HashPassword(password)
+	if err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("failed to rehash password")
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		u.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("failed to persist rehashed password")
+		return
+	}
 
-func (e *BusinessError) Error() string {
-	return e.Message
+	u.logger.WithContext(ctx).WithField("user_id", user.ID).Info("password hash upgraded")
 }
 
-func (e *BusinessError) ValidationError() bool {
-	return true
+// dummyPasswordHash лениво вычисляет хэш случайного пароля теми же параметрами, что и у настоящих хэшей
+func (u *userUsecase) dummyPasswordHash() string {
+	u.dummyHashOnce.Do(func() {
+		password, err := service.GenerateRandomToken(16)
+		if err != nil {
+			u.logger.WithError(err).Error("failed to generate dummy password")
+			return
+		}
+		u.dummyHash, err = u.hashService.HashPassword(password)
+		if err != nil {
+			u.logger.WithError(err).Error("failed to compute dummy password hash")
+		}
+	})
+	return u.dummyHash
 }
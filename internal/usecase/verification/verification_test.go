@@ -0,0 +1,299 @@
+package verification
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/usecase/mocks"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	return Config{
+		TokenTTL:       24 * time.Hour,
+		ResendInterval: time.Minute,
+		VerifyURL:      "https://chat.example.com/api/v1/verify-email",
+	}
+}
+
+func TestVerificationUsecase_SendVerification_SendsEmail(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Отключаем логи в тестах
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	testUser := &entity.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com"}
+	var storedToken *entity.EmailVerificationToken
+	var sentMessage *service.MailMessage
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return testUser, nil
+	}
+
+	verificationRepo.CreateFunc = func(ctx context.Context, token *entity.EmailVerificationToken) error {
+		storedToken = token
+		return nil
+	}
+
+	mailer.SendFunc = func(ctx context.Context, msg *service.MailMessage) error {
+		sentMessage = msg
+		return nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.SendVerification(context.Background(), testUser.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, storedToken)
+	require.NotNil(t, sentMessage)
+	assert.Equal(t, testUser.ID, storedToken.UserID)
+	assert.Equal(t, "test@example.com", storedToken.Email)
+	assert.Equal(t, "test@example.com", sentMessage.To)
+
+	// В письме открытый токен, в БД - только его хэш
+	start := strings.Index(sentMessage.Body, "https://chat.example.com/api/v1/verify-email?token=")
+	require.NotEqual(t, -1, start)
+	parsed, err := url.Parse(strings.Fields(sentMessage.Body[start:])[0])
+	require.NoError(t, err)
+	assert.Equal(t, service.HashToken(parsed.Query().Get("token")), storedToken.TokenHash)
+}
+
+func TestVerificationUsecase_ResendVerification_Throttled(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	testUserID := uuid.New()
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "test@example.com"}, nil
+	}
+
+	verificationRepo.GetLatestByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error) {
+		return &entity.EmailVerificationToken{ID: uuid.New(), UserID: userID, CreatedAt: time.Now().Add(-20 * time.Second)}, nil
+	}
+
+	mailer.SendFunc = func(ctx context.Context, msg *service.MailMessage) error {
+		t.Fatal("email must not be sent while throttled")
+		return nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResendVerification(context.Background(), testUserID)
+
+	// Assert
+	require.Error(t, err)
+	var throttled *apperror.Error
+	require.ErrorAs(t, err, &throttled)
+	assert.Equal(t, apperror.CodeVerificationResendWait, throttled.Code)
+	assert.InDelta(t, 40*time.Second, throttled.RetryAfter(), float64(2*time.Second))
+}
+
+func TestVerificationUsecase_ResendVerification_AfterInterval(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	testUserID := uuid.New()
+	var sent bool
+
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "test@example.com"}, nil
+	}
+
+	verificationRepo.GetLatestByUserIDFunc = func(ctx context.Context, userID uuid.UUID) (*entity.EmailVerificationToken, error) {
+		return &entity.EmailVerificationToken{ID: uuid.New(), UserID: userID, CreatedAt: time.Now().Add(-2 * time.Minute)}, nil
+	}
+
+	mailer.SendFunc = func(ctx context.Context, msg *service.MailMessage) error {
+		sent = true
+		return nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResendVerification(context.Background(), testUserID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, sent)
+}
+
+func TestVerificationUsecase_ResendVerification_AlreadyVerified(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	verifiedAt := time.Now()
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "test@example.com", EmailVerifiedAt: &verifiedAt}, nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.ResendVerification(context.Background(), uuid.New())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already verified")
+	assert.Equal(t, apperror.CodeVerificationDone, apperror.CodeOf(err))
+}
+
+func TestVerificationUsecase_VerifyEmail_Success(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	verificationToken := &entity.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Email:     "test@example.com",
+		TokenHash: service.HashToken("plain-token"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	var verifiedEmail string
+
+	verificationRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+		if tokenHash != verificationToken.TokenHash {
+			return nil, &NotFoundError{"verification token not found"}
+		}
+		return verificationToken, nil
+	}
+
+	userRepo.MarkEmailVerifiedFunc = func(ctx context.Context, id uuid.UUID, email string) error {
+		assert.Equal(t, verificationToken.UserID, id)
+		verifiedEmail = email
+		return nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.VerifyEmail(context.Background(), "plain-token")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", verifiedEmail)
+}
+
+func TestVerificationUsecase_VerifyEmail_ExpiredToken(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	verificationRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+		return &entity.EmailVerificationToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			Email:     "test@example.com",
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(-time.Minute), // Истек
+		}, nil
+	}
+
+	userRepo.MarkEmailVerifiedFunc = func(ctx context.Context, id uuid.UUID, email string) error {
+		t.Fatal("email must not be verified with an expired token")
+		return nil
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.VerifyEmail(context.Background(), "plain-token")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid or expired verification token")
+	assert.Equal(t, apperror.CodeVerificationInvalid, apperror.CodeOf(err))
+}
+
+func TestVerificationUsecase_VerifyEmail_EmailChanged(t *testing.T) {
+	// Arrange
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	userRepo := &mocks.UserRepoMock{}
+	verificationRepo := &mocks.EmailVerificationRepoMock{}
+	mailer := &mocks.MailerMock{}
+
+	verificationRepo.GetByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+		return &entity.EmailVerificationToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			Email:     "old@example.com",
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	// Email пользователя сменился после выпуска токена
+	userRepo.MarkEmailVerifiedFunc = func(ctx context.Context, id uuid.UUID, email string) error {
+		return &NotFoundError{"user not found"}
+	}
+
+	usecase := NewVerificationUsecase(userRepo, verificationRepo, mailer, newTestConfig(), logger)
+
+	// Act
+	err := usecase.VerifyEmail(context.Background(), "plain-token")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid or expired verification token")
+	assert.Equal(t, apperror.CodeVerificationInvalid, apperror.CodeOf(err))
+}
+
+// NotFoundError представляет ошибку, когда ресурс не найден.
+type NotFoundError struct {
+	Message string
+}
+
+// Error реализует интерфейс error.
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// NotFound сигнализирует, что это ошибка "не найдено".
+func (e *NotFoundError) NotFound() bool {
+	return true
+}
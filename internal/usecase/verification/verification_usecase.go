@@ -0,0 +1,13 @@
+package verification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type VerificationUsecase interface {
+	SendVerification(ctx context.Context, userID uuid.UUID) error
+	ResendVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) error
+}
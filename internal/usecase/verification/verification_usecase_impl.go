@@ -0,0 +1,214 @@
+package verification
+
+import (
+	"chat-service/internal/apperror"
+	"chat-service/internal/entity"
+	"chat-service/internal/service"
+	"chat-service/internal/tracing"
+	"chat-service/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Config параметры подтверждения email
+type Config struct {
+	TokenTTL       time.Duration
+	ResendInterval time.Duration
+	VerifyURL      string
+}
+
+const verificationTokenSize = 32
+
+type verificationUsecase struct {
+	userRepo         usecase.UserRepository
+	verificationRepo usecase.EmailVerificationRepository
+	mailer           service.Mailer
+	config           Config
+	logger           *logrus.Logger
+}
+
+func NewVerificationUsecase(
+	userRepo usecase.UserRepository,
+	verificationRepo usecase.EmailVerificationRepository,
+	mailer service.Mailer,
+	config Config,
+	logger *logrus.Logger,
+) VerificationUsecase {
+	return &verificationUsecase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		config:           config,
+		logger:           logger,
+	}
+}
+
+// SendVerification выпускает токен и отправляет письмо на текущий email пользователя.
+// Вызывается после регистрации и смены email, поэтому не ограничивается по частоте
+func (v *verificationUsecase) SendVerification(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.SendVerification", tracing.UserID(userID))
+	defer span.End()
+
+	user, err := v.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch user for email verification")
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		v.logger.WithContext(ctx).WithField("user_id", userID).Debug("email already verified, skipping verification email")
+		return nil
+	}
+
+	return v.send(ctx, user)
+}
+
+// ResendVerification повторно отправляет письмо, но не чаще одного раза в ResendInterval
+func (v *verificationUsecase) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.ResendVerification", tracing.UserID(userID))
+	defer span.End()
+
+	v.logger.WithContext(ctx).WithField("user_id", userID).Info("verification email resend requested")
+
+	user, err := v.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch user for email verification")
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return apperror.Conflict(apperror.CodeVerificationDone, "email is already verified")
+	}
+
+	latest, err := v.verificationRepo.GetLatestByUserID(ctx, userID)
+	if err != nil && !isNotFound(err) {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", userID).Error("failed to fetch latest verification token")
+		return err
+	}
+
+	if err == nil && latest != nil {
+		if wait := latest.CreatedAt.Add(v.config.ResendInterval).Sub(time.Now()); wait > 0 {
+			v.logger.WithContext(ctx).WithField("user_id", userID).Warn("verification email resend throttled")
+			return apperror.TooManyRequests(apperror.CodeVerificationResendWait, "verification email was sent recently, try again later", wait)
+		}
+	}
+
+	return v.send(ctx, user)
+}
+
+func (v *verificationUsecase) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "VerificationUsecase.VerifyEmail")
+	defer span.End()
+
+	v.logger.WithContext(ctx).Info("email verification attempt")
+
+	if token == "" {
+		return apperror.Validation(apperror.CodeVerificationInvalid, "invalid or expired verification token")
+	}
+
+	verificationToken, err := v.verificationRepo.GetByTokenHash(ctx, service.HashToken(token))
+	if err != nil {
+		if isNotFound(err) {
+			v.logger.WithContext(ctx).Warn("unknown email verification token")
+			return apperror.Validation(apperror.CodeVerificationInvalid, "invalid or expired verification token")
+		}
+		v.logger.WithContext(ctx).WithError(err).Error("failed to fetch verification token")
+		return err
+	}
+
+	if !verificationToken.IsUsable(time.Now()) {
+		v.logger.WithContext(ctx).WithField("token_id", verificationToken.ID).Warn("verification token expired or already used")
+		return apperror.Validation(apperror.CodeVerificationInvalid, "invalid or expired verification token")
+	}
+
+	if err := v.verificationRepo.MarkUsed(ctx, verificationToken.ID); err != nil {
+		if isNotFound(err) {
+			v.logger.WithContext(ctx).WithField("token_id", verificationToken.ID).Warn("verification token already used")
+			return apperror.Validation(apperror.CodeVerificationInvalid, "invalid or expired verification token")
+		}
+		v.logger.WithContext(ctx).WithError(err).WithField("token_id", verificationToken.ID).Error("failed to mark verification token as used")
+		return err
+	}
+
+	// Подтверждаем только тот адрес, на который выпускался токен: если email сменили, токен уже недействителен
+	if err := v.userRepo.MarkEmailVerified(ctx, verificationToken.UserID, verificationToken.Email); err != nil {
+		if isNotFound(err) {
+			v.logger.WithContext(ctx).WithField("user_id", verificationToken.UserID).Warn("email changed since verification token was issued")
+			return apperror.Validation(apperror.CodeVerificationInvalid, "invalid or expired verification token")
+		}
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", verificationToken.UserID).Error("failed to mark email as verified")
+		return err
+	}
+
+	if err := v.verificationRepo.DeleteByUserID(ctx, verificationToken.UserID); err != nil {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", verificationToken.UserID).Warn("failed to clean up verification tokens")
+	}
+
+	v.logger.WithContext(ctx).WithField("user_id", verificationToken.UserID).Info("email verified successfully")
+	return nil
+}
+
+func (v *verificationUsecase) send(ctx context.Context, user *entity.User) error {
+	token, err := service.GenerateRandomToken(verificationTokenSize)
+	if err != nil {
+		v.logger.WithContext(ctx).WithError(err).Error("failed to generate verification token")
+		return err
+	}
+
+	now := time.Now()
+	verificationToken := &entity.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: service.HashToken(token),
+		ExpiresAt: now.Add(v.config.TokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := v.verificationRepo.Create(ctx, verificationToken); err != nil {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to store verification token")
+		return err
+	}
+
+	msg := &service.MailMessage{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nTo confirm your email address, follow the link below:\n%s\n\n"+
+				"The link is valid for %s.\n"+
+				"If you did not create an account, just ignore this email.\n",
+			user.Username, v.verifyLink(token), v.config.TokenTTL,
+		),
+	}
+
+	if err := v.mailer.Send(ctx, msg); err != nil {
+		v.logger.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Error("failed to send verification email")
+		return err
+	}
+
+	v.logger.WithContext(ctx).WithField("user_id", user.ID).Info("verification email sent")
+	return nil
+}
+
+func (v *verificationUsecase) verifyLink(token string) string {
+	link, err := url.Parse(v.config.VerifyURL)
+	if err != nil {
+		return v.config.VerifyURL + "?token=" + url.QueryEscape(token)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
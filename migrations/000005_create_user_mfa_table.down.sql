@@ -0,0 +1,6 @@
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_user_mfa_updated_at ON user_mfa;
+
+-- Drop tables
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
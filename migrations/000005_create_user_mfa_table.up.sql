@@ -0,0 +1,40 @@
+-- Create user_mfa table
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create mfa_recovery_codes table
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE user_mfa IS 'TOTP two-factor authentication settings per user';
+COMMENT ON COLUMN user_mfa.user_id IS 'Reference to the user who owns the settings';
+COMMENT ON COLUMN user_mfa.secret IS 'Base32 encoded TOTP shared secret';
+COMMENT ON COLUMN user_mfa.enabled IS 'Whether enrollment was confirmed with a valid code';
+COMMENT ON COLUMN user_mfa.last_used_step IS 'Last accepted TOTP time step, protects against code replay';
+COMMENT ON COLUMN user_mfa.confirmed_at IS 'Timestamp when enrollment was confirmed';
+COMMENT ON TABLE mfa_recovery_codes IS 'One-time recovery codes for two-factor authentication';
+COMMENT ON COLUMN mfa_recovery_codes.code_hash IS 'SHA-256 hash of the recovery code';
+COMMENT ON COLUMN mfa_recovery_codes.used_at IS 'Timestamp when the code was used, NULL if unused';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mfa_recovery_codes_hash ON mfa_recovery_codes(code_hash);
+
+-- Add triggers for updated_at
+CREATE TRIGGER update_user_mfa_updated_at
+    BEFORE UPDATE ON user_mfa
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,20 @@
+-- Create password_reset_tokens table
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE password_reset_tokens IS 'Single-use password reset tokens';
+COMMENT ON COLUMN password_reset_tokens.user_id IS 'Reference to the user who requested the reset';
+COMMENT ON COLUMN password_reset_tokens.token_hash IS 'SHA-256 hash of the reset token, the token itself is never stored';
+COMMENT ON COLUMN password_reset_tokens.expires_at IS 'Timestamp when token expires';
+COMMENT ON COLUMN password_reset_tokens.used_at IS 'Timestamp when token was used, NULL if unused';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_expires_at ON password_reset_tokens(expires_at);
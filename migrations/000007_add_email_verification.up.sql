@@ -0,0 +1,25 @@
+-- Add email verification column to users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN users.email_verified_at IS 'Timestamp when the current email was verified, NULL if not verified';
+
+-- Create email_verification_tokens table
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE email_verification_tokens IS 'Single-use email verification tokens';
+COMMENT ON COLUMN email_verification_tokens.user_id IS 'Reference to the user who owns the email';
+COMMENT ON COLUMN email_verification_tokens.email IS 'Email address the token was issued for';
+COMMENT ON COLUMN email_verification_tokens.token_hash IS 'SHA-256 hash of the verification token';
+COMMENT ON COLUMN email_verification_tokens.used_at IS 'Timestamp when token was used, NULL if unused';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_created ON email_verification_tokens(user_id, created_at DESC);
@@ -0,0 +1,20 @@
+-- Create login_attempts table
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    outcome VARCHAR(16) NOT NULL CHECK (outcome IN ('success', 'failure', 'locked')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE login_attempts IS 'Audit log of login attempts, used for brute-force protection';
+COMMENT ON COLUMN login_attempts.email IS 'Normalized email the login was attempted for';
+COMMENT ON COLUMN login_attempts.ip_address IS 'Client IP address';
+COMMENT ON COLUMN login_attempts.user_id IS 'Reference to the user on successful login';
+COMMENT ON COLUMN login_attempts.outcome IS 'success, failure (invalid credentials) or locked (rejected by lockout)';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email_created ON login_attempts(email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_created ON login_attempts(ip_address, created_at DESC);
@@ -0,0 +1,5 @@
+-- Drop oidc_auth_requests table
+DROP TABLE IF EXISTS oidc_auth_requests;
+
+-- Drop user_identities table
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,39 @@
+-- Create user_identities table
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (provider, subject)
+);
+
+-- Add comments
+COMMENT ON TABLE user_identities IS 'External identity provider accounts linked to users';
+COMMENT ON COLUMN user_identities.provider IS 'Configured OIDC provider name';
+COMMENT ON COLUMN user_identities.subject IS 'Subject (sub claim) of the user at the provider';
+COMMENT ON COLUMN user_identities.email IS 'Email reported by the provider at link time';
+COMMENT ON COLUMN user_identities.last_login_at IS 'Timestamp of the last login through this identity';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+-- Create oidc_auth_requests table
+CREATE TABLE IF NOT EXISTS oidc_auth_requests (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(64) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE oidc_auth_requests IS 'Pending OIDC authorization requests (state, nonce, PKCE verifier)';
+COMMENT ON COLUMN oidc_auth_requests.state_hash IS 'SHA-256 hash of the state parameter';
+COMMENT ON COLUMN oidc_auth_requests.code_verifier IS 'PKCE code verifier sent with the token request';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_oidc_auth_requests_expires_at ON oidc_auth_requests(expires_at);
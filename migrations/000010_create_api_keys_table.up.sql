@@ -0,0 +1,26 @@
+-- Create api_keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE api_keys IS 'Personal API keys for bots and scripts';
+COMMENT ON COLUMN api_keys.name IS 'Human readable name given by the user';
+COMMENT ON COLUMN api_keys.prefix IS 'Visible beginning of the key to help users identify it';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 hash of the full key';
+COMMENT ON COLUMN api_keys.scopes IS 'Permissions granted to the key';
+COMMENT ON COLUMN api_keys.expires_at IS 'Expiration time, NULL means the key does not expire';
+COMMENT ON COLUMN api_keys.last_used_at IS 'Timestamp of the last successful authentication';
+COMMENT ON COLUMN api_keys.revoked_at IS 'Timestamp when the key was revoked';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
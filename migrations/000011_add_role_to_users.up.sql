@@ -0,0 +1,10 @@
+-- Add role column to users table
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- Add comments
+COMMENT ON COLUMN users.role IS 'Global role of the user: user, moderator or admin';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role) WHERE role <> 'user';
@@ -0,0 +1,6 @@
+-- Drop suspension columns from users table
+DROP INDEX IF EXISTS idx_users_suspended_at;
+DROP INDEX IF EXISTS idx_users_created_at;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS suspension_reason,
+    DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,12 @@
+-- Add suspension columns to users table
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+-- Add comments
+COMMENT ON COLUMN users.suspended_at IS 'Timestamp when the user was suspended, NULL if the user is active';
+COMMENT ON COLUMN users.suspension_reason IS 'Reason given by the administrator who suspended the user';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_suspended_at ON users(suspended_at) WHERE suspended_at IS NOT NULL;
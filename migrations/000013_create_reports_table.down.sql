@@ -0,0 +1,5 @@
+-- Drop moderation tables
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS reports;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS hidden_at;
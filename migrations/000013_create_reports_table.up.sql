@@ -0,0 +1,50 @@
+-- Add moderation column to messages table
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN messages.hidden_at IS 'Timestamp when a moderator hid the message, NULL if the message is visible';
+
+-- Create reports table
+CREATE TABLE IF NOT EXISTS reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL,
+    comment TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT reports_reason_check CHECK (reason IN ('spam', 'harassment', 'hate', 'illegal', 'other')),
+    CONSTRAINT reports_status_check CHECK (status IN ('open', 'dismissed', 'actioned')),
+    CONSTRAINT reports_message_reporter_unique UNIQUE (message_id, reporter_id)
+);
+
+-- Create moderation_actions table
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    report_id UUID REFERENCES reports(id) ON DELETE SET NULL,
+    moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    message_id UUID NOT NULL,
+    target_user_id UUID NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT moderation_actions_action_check CHECK (action IN ('dismiss', 'reopen', 'hide_message', 'warn_author', 'suspend_author'))
+);
+
+-- Add comments
+COMMENT ON TABLE reports IS 'User reports about abusive messages';
+COMMENT ON COLUMN reports.reason IS 'Report category chosen by the reporter';
+COMMENT ON COLUMN reports.status IS 'Review status: open, dismissed or actioned';
+COMMENT ON COLUMN reports.resolved_by IS 'Moderator who last changed the report status';
+COMMENT ON TABLE moderation_actions IS 'Audit log of moderator decisions on reports';
+COMMENT ON COLUMN moderation_actions.message_id IS 'Reported message; kept without a foreign key so the log survives message deletion';
+COMMENT ON COLUMN moderation_actions.target_user_id IS 'Author of the reported message';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_reports_status_created_at ON reports(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_reports_message_id ON reports(message_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_report_id ON moderation_actions(report_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_target_user_id ON moderation_actions(target_user_id);
@@ -0,0 +1,9 @@
+-- Remove system reports and restore reports constraints
+DELETE FROM reports WHERE reporter_id IS NULL;
+ALTER TABLE reports
+    ALTER COLUMN reporter_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS reports_reason_check,
+    ADD CONSTRAINT reports_reason_check CHECK (reason IN ('spam', 'harassment', 'hate', 'illegal', 'other'));
+
+-- Drop content_filter_rules table
+DROP TABLE IF EXISTS content_filter_rules;
@@ -0,0 +1,25 @@
+-- Create content_filter_rules table
+CREATE TABLE IF NOT EXISTS content_filter_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(10) NOT NULL,
+    pattern TEXT NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT content_filter_rules_kind_check CHECK (kind IN ('word', 'regex')),
+    CONSTRAINT content_filter_rules_action_check CHECK (action IN ('reject', 'mask', 'flag'))
+);
+
+-- Add comments
+COMMENT ON TABLE content_filter_rules IS 'Admin managed rules applied to message content';
+COMMENT ON COLUMN content_filter_rules.kind IS 'word matches a whole word or phrase after normalization, regex matches a regular expression';
+COMMENT ON COLUMN content_filter_rules.action IS 'reject refuses the message, mask replaces the match with asterisks, flag sends the message to moderators';
+
+-- Messages flagged by the content filter are reported by the system, without a reporter
+ALTER TABLE reports
+    ALTER COLUMN reporter_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS reports_reason_check,
+    ADD CONSTRAINT reports_reason_check CHECK (reason IN ('spam', 'harassment', 'hate', 'illegal', 'other', 'content_filter'));
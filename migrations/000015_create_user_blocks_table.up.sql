@@ -0,0 +1,16 @@
+-- Create user_blocks table
+CREATE TABLE IF NOT EXISTS user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT user_blocks_not_self_check CHECK (blocker_id <> blocked_id)
+);
+
+-- Add comments
+COMMENT ON TABLE user_blocks IS 'Users hidden by another user: their messages are filtered out and they cannot mention the blocker';
+COMMENT ON COLUMN user_blocks.blocker_id IS 'User who created the block';
+COMMENT ON COLUMN user_blocks.blocked_id IS 'User who is blocked';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks(blocked_id);
@@ -0,0 +1,17 @@
+-- Create rate_limit_counters table. Counters are disposable, so the table is not written to WAL
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_counters (
+    key VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+-- Add comments
+COMMENT ON TABLE rate_limit_counters IS 'HTTP request counters per rate limit policy and client, shared by all service instances';
+COMMENT ON COLUMN rate_limit_counters.key IS 'Policy name and client key, e.g. login:ip:203.0.113.7';
+COMMENT ON COLUMN rate_limit_counters.window_start IS 'Start of the fixed window the requests were counted in';
+COMMENT ON COLUMN rate_limit_counters.expires_at IS 'End of the window, after which the row can be deleted';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
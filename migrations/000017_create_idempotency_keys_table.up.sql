@@ -0,0 +1,21 @@
+-- Create idempotency_keys table
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+-- Add comments
+COMMENT ON TABLE idempotency_keys IS 'First response to a request with an Idempotency-Key header, replayed for retries';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of method, path and body; a retry with another payload is rejected';
+COMMENT ON COLUMN idempotency_keys.completed_at IS 'NULL while the first request is in flight';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
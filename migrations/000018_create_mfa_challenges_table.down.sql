@@ -0,0 +1,2 @@
+-- Drop mfa_challenges table
+DROP TABLE IF EXISTS mfa_challenges;
@@ -0,0 +1,16 @@
+-- Create mfa_challenges table
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments
+COMMENT ON TABLE mfa_challenges IS 'Pending second factor checks after a successful password login';
+COMMENT ON COLUMN mfa_challenges.id IS 'jti of the mfa_token; the row is deleted when the challenge is passed';
+COMMENT ON COLUMN mfa_challenges.attempts IS 'Codes submitted for the challenge, limited by mfa.max_challenge_attempts';
+
+-- Add indexes
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
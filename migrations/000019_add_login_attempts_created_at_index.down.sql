@@ -0,0 +1,4 @@
+-- Drop index for cleanup of old login attempts
+DROP INDEX IF EXISTS idx_login_attempts_created_at;
+
+COMMENT ON TABLE login_attempts IS 'Audit log of login attempts, used for brute-force protection';
@@ -0,0 +1,5 @@
+-- Index for periodic cleanup of old login attempts
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts(created_at);
+
+-- Update comments
+COMMENT ON TABLE login_attempts IS 'Audit log of login attempts, used for brute-force protection; rows older than the protection window are deleted';
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -9,11 +12,29 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	App      AppConfig      `mapstructure:"app"`
+	Server          ServerConfig          `mapstructure:"server"`
+	Database        DatabaseConfig        `mapstructure:"database"`
+	JWT             JWTConfig             `mapstructure:"jwt"`
+	Logger          LoggerConfig          `mapstructure:"logger"`
+	App             AppConfig             `mapstructure:"app"`
+	MFA             MFAConfig             `mapstructure:"mfa"`
+	Mail            MailConfig            `mapstructure:"mail"`
+	Password        PasswordConfig        `mapstructure:"password"`
+	Verification    VerificationConfig    `mapstructure:"verification"`
+	LoginProtection LoginProtectionConfig `mapstructure:"login_protection"`
+	Hashing         HashingConfig         `mapstructure:"hashing"`
+	PasswordPolicy  PasswordPolicyConfig  `mapstructure:"password_policy"`
+	OIDC            OIDCConfig            `mapstructure:"oidc"`
+	APIKeys         APIKeysConfig         `mapstructure:"api_keys"`
+	RBAC            RBACConfig            `mapstructure:"rbac"`
+	ContentFilter   ContentFilterConfig   `mapstructure:"content_filter"`
+	AntiSpam        AntiSpamConfig        `mapstructure:"anti_spam"`
+	Metrics         MetricsConfig         `mapstructure:"metrics"`
+	Tracing         TracingConfig         `mapstructure:"tracing"`
+	Health          HealthConfig          `mapstructure:"health"`
+	CORS            CORSConfig            `mapstructure:"cors"`
+	RateLimit       RateLimitConfig       `mapstructure:"rate_limit"`
+	Idempotency     IdempotencyConfig     `mapstructure:"idempotency"`
 }
 
 type ServerConfig struct {
@@ -23,6 +44,9 @@ type ServerConfig struct {
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
 	Debug        bool          `mapstructure:"debug"`
+	// TrustedProxies прокси, которым доверяется X-Forwarded-For при определении IP клиента; пусто - никому,
+	// и IP клиента берется из адреса соединения
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -44,9 +68,30 @@ type JWTConfig struct {
 }
 
 type LoggerConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
-	Output string `mapstructure:"output"`
+	Level            string            `mapstructure:"level"`
+	Format           string            `mapstructure:"format"`
+	Output           string            `mapstructure:"output"` // stdout, stderr или file
+	File             LogFileConfig     `mapstructure:"file"`
+	RedactFields     []string          `mapstructure:"redact_fields"` // пусто - список по умолчанию
+	EmailFields      []string          `mapstructure:"email_fields"`  // email в этих полях маскируется частично
+	Sampling         LogSamplingConfig `mapstructure:"sampling"`
+	MaxLevelOverride time.Duration     `mapstructure:"max_level_override"` // наибольший срок временного уровня через API
+}
+
+// LogFileConfig файл лога при output: file
+type LogFileConfig struct {
+	Path       string        `mapstructure:"path"`
+	MaxSizeMB  int           `mapstructure:"max_size_mb"` // ротация по размеру
+	MaxAge     time.Duration `mapstructure:"max_age"`     // ротация по возрасту; 0 - только по размеру
+	MaxBackups int           `mapstructure:"max_backups"` // 0 - хранить все старые файлы
+	Compress   bool          `mapstructure:"compress"`
+}
+
+// LogSamplingConfig прореживание повторяющихся отладочных записей; initial 0 отключает его
+type LogSamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Interval   time.Duration `mapstructure:"interval"`
 }
 
 type AppConfig struct {
@@ -55,35 +100,223 @@ type AppConfig struct {
 	Environment string `mapstructure:"environment"`
 }
 
-// Load загружает конфигурацию из файла и environment variables
-func Load(configPath string) (*Config, error) {
+type MFAConfig struct {
+	Issuer            string        `mapstructure:"issuer"`
+	ChallengeTTL      time.Duration `mapstructure:"challenge_ttl"`
+	RecoveryCodeCount int           `mapstructure:"recovery_code_count"`
+	// MaxChallengeAttempts сколько кодов можно ввести по одному mfa_token
+	MaxChallengeAttempts int `mapstructure:"max_challenge_attempts"`
+}
+
+type MailConfig struct {
+	Driver    string        `mapstructure:"driver"` // smtp, log или file
+	From      string        `mapstructure:"from"`
+	OutboxDir string        `mapstructure:"outbox_dir"`
+	SMTP      SMTPConfig    `mapstructure:"smtp"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type PasswordConfig struct {
+	ResetTokenTTL time.Duration `mapstructure:"reset_token_ttl"`
+	ResetURL      string        `mapstructure:"reset_url"`
+}
+
+type VerificationConfig struct {
+	TokenTTL              time.Duration `mapstructure:"token_ttl"`
+	ResendInterval        time.Duration `mapstructure:"resend_interval"`
+	VerifyURL             string        `mapstructure:"verify_url"`
+	RequireVerifiedToPost bool          `mapstructure:"require_verified_to_post"`
+}
+
+type LoginProtectionConfig struct {
+	Window             time.Duration `mapstructure:"window"`
+	AccountMaxFailures int           `mapstructure:"account_max_failures"`
+	IPMaxFailures      int           `mapstructure:"ip_max_failures"`
+	BaseLockout        time.Duration `mapstructure:"base_lockout"`
+	MaxLockout         time.Duration `mapstructure:"max_lockout"`
+}
+
+type HashingConfig struct {
+	Algorithm  string       `mapstructure:"algorithm"` // argon2id или bcrypt
+	BcryptCost int          `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+}
+
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"` // KiB
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength        int    `mapstructure:"min_length"`
+	MaxLength        int    `mapstructure:"max_length"`
+	RequireUpper     bool   `mapstructure:"require_upper"`
+	RequireLower     bool   `mapstructure:"require_lower"`
+	RequireDigit     bool   `mapstructure:"require_digit"`
+	RequireSymbol    bool   `mapstructure:"require_symbol"`
+	DisallowUserInfo bool   `mapstructure:"disallow_user_info"`
+	BreachedListPath string `mapstructure:"breached_list_path"` // файл SHA1[:COUNT] или каталог <PREFIX>.txt
+}
+
+type OIDCConfig struct {
+	StateTTL    time.Duration        `mapstructure:"state_ttl"`
+	HTTPTimeout time.Duration        `mapstructure:"http_timeout"`
+	Providers   []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+type OIDCProviderConfig struct {
+	Name            string   `mapstructure:"name"`
+	Issuer          string   `mapstructure:"issuer"`
+	ClientID        string   `mapstructure:"client_id"`
+	ClientSecret    string   `mapstructure:"client_secret"`
+	ClientSecretEnv string   `mapstructure:"client_secret_env"` // переменная окружения с секретом, чтобы не хранить его в файле
+	RedirectURL     string   `mapstructure:"redirect_url"`
+	Scopes          []string `mapstructure:"scopes"`
+}
+
+type APIKeysConfig struct {
+	MaxPerUser int           `mapstructure:"max_per_user"`
+	MaxTTL     time.Duration `mapstructure:"max_ttl"` // 0 — разрешены бессрочные ключи
+}
+
+type RBACConfig struct {
+	AdminEmails []string `mapstructure:"admin_emails"` // получают роль admin при запуске
+}
+
+// AntiSpamConfig ограничения частоты публикации сообщений; 0 отключает проверку
+type AntiSpamConfig struct {
+	Burst           int           `mapstructure:"burst"`            // сообщений подряд без ожидания
+	RefillInterval  time.Duration `mapstructure:"refill_interval"`  // время восстановления одного сообщения
+	DuplicateWindow time.Duration `mapstructure:"duplicate_window"` // окно, в котором повтор текста отклоняется
+	SlowMode        time.Duration `mapstructure:"slow_mode"`        // минимальный интервал между сообщениями
+}
+
+// ContentFilterConfig фильтр текста сообщений. Текст проверяется только при публикации, новые правила
+// к уже опубликованным сообщениям не применяются
+type ContentFilterConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // как часто перечитывать правила из БД
+}
+
+// MetricsConfig публикация метрик Prometheus на /metrics
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// HealthConfig пробы живости (/livez) и готовности (/readyz)
+type HealthConfig struct {
+	CheckTimeout       time.Duration `mapstructure:"check_timeout"`        // время на одну проверку
+	CacheTTL           time.Duration `mapstructure:"cache_ttl"`            // сколько переиспользовать результат готовности
+	MigrationsPath     string        `mapstructure:"migrations_path"`      // отсюда берется ожидаемая версия схемы; пусто - не проверять
+	PoolMaxUtilization float64       `mapstructure:"pool_max_utilization"` // доля занятых соединений, при которой сервис не готов
+	DrainDelay         time.Duration `mapstructure:"drain_delay"`          // пауза между провалом готовности и остановкой сервера
+}
+
+// TracingConfig экспорт трасс OpenTelemetry в коллектор по OTLP/HTTP
+type TracingConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Endpoint      string        `mapstructure:"endpoint"` // host:port коллектора
+	URLPath       string        `mapstructure:"url_path"`
+	Insecure      bool          `mapstructure:"insecure"`     // HTTP без TLS
+	SampleRatio   float64       `mapstructure:"sample_ratio"` // доля записываемых трасс, от 0 до 1
+	ExportTimeout time.Duration `mapstructure:"export_timeout"`
+}
+
+// CORSConfig политика CORS для браузерных клиентов
+type CORSConfig struct {
+	// AllowedOrigins источники вида https://app.example.com; https://*.example.com разрешает поддомены,
+	// а * - любой источник (несовместимо с AllowCredentials)
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"` // заголовки ответа, доступные скриптам
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"` // сколько браузер кеширует ответ на preflight
+}
+
+// RateLimitConfig ограничение частоты HTTP запросов к /api/v1
+type RateLimitConfig struct {
+	Enabled   bool                    `mapstructure:"enabled"`
+	Store     string                  `mapstructure:"store"`     // memory или postgres (общие счетчики для нескольких экземпляров)
+	Allowlist []string                `mapstructure:"allowlist"` // IP-адреса и подсети без ограничений
+	Default   RateLimitPolicyConfig   `mapstructure:"default"`   // для маршрутов без своей политики; limit 0 - без ограничений
+	Policies  []RateLimitPolicyConfig `mapstructure:"policies"`
+}
+
+// RateLimitPolicyConfig не больше Limit запросов за Window на один ключ
+type RateLimitPolicyConfig struct {
+	Name   string        `mapstructure:"name"`
+	Routes []string      `mapstructure:"routes"` // "METHOD /api/v1/path", путь как в описании маршрута
+	Key    string        `mapstructure:"key"`    // ip, user или api_key
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+// IdempotencyConfig хранение ответов на запросы с заголовком Idempotency-Key
+type IdempotencyConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	TTL               time.Duration `mapstructure:"ttl"`                 // сколько хранится ответ для повторов
+	InProgressTimeout time.Duration `mapstructure:"in_progress_timeout"` // когда незавершенный запрос считается прерванным
+}
+
+// Load загружает конфигурацию из файла и environment variables. Возвращенный Watcher
+// перечитывает файл при изменении после вызова Start
+func Load(configPath string) (*Config, *Watcher, error) {
 	// Инициализация Viper
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
 
 	// Добавляем пути поиска конфига
 	if configPath != "" {
-		viper.AddConfigPath(configPath)
+		v.AddConfigPath(configPath)
 	}
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
+	v.AddConfigPath("./configs")
+	v.AddConfigPath(".")
+
+	setDefaults(v)
 
 	// Чтение конфигурационного файла
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	// Автоматическая привязка environment variables
-	viper.SetEnvPrefix("CHAT")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	v.SetEnvPrefix("CHAT")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
-	// Парсинг конфигурации
+	config, err := decode(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, NewWatcher(v, config), nil
+}
+
+// decode разбирает и проверяет конфигурацию, прочитанную viper
+func decode(v *viper.Viper) (*Config, error) {
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	for i, provider := range config.OIDC.Providers {
+		if provider.ClientSecret == "" && provider.ClientSecretEnv != "" {
+			config.OIDC.Providers[i].ClientSecret = os.Getenv(provider.ClientSecretEnv)
+		}
+	}
+
 	// Валидация конфигурации
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -92,6 +325,102 @@ func Load(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// setDefaults задает значения для необязательных секций конфигурации
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("logger.file.path", "./logs/chat-service.log")
+	v.SetDefault("logger.file.max_size_mb", 100)
+	v.SetDefault("logger.file.max_age", 24*time.Hour)
+	v.SetDefault("logger.file.max_backups", 7)
+	v.SetDefault("logger.sampling.initial", 0)
+	v.SetDefault("logger.sampling.thereafter", 100)
+	v.SetDefault("logger.sampling.interval", time.Second)
+	v.SetDefault("logger.max_level_override", time.Hour)
+
+	v.SetDefault("mfa.issuer", "Chat Service")
+	v.SetDefault("mfa.challenge_ttl", 5*time.Minute)
+	v.SetDefault("mfa.recovery_code_count", 10)
+	v.SetDefault("mfa.max_challenge_attempts", 5)
+
+	v.SetDefault("mail.driver", "log")
+	v.SetDefault("mail.from", "Chat Service <noreply@localhost>")
+	v.SetDefault("mail.outbox_dir", "./outbox")
+	v.SetDefault("mail.timeout", 10*time.Second)
+	v.SetDefault("mail.smtp.port", 587)
+
+	v.SetDefault("password.reset_token_ttl", time.Hour)
+	v.SetDefault("password.reset_url", "http://localhost:8080/reset-password")
+
+	v.SetDefault("verification.token_ttl", 24*time.Hour)
+	v.SetDefault("verification.resend_interval", time.Minute)
+	v.SetDefault("verification.verify_url", "http://localhost:8080/api/v1/verify-email")
+	v.SetDefault("verification.require_verified_to_post", false)
+
+	v.SetDefault("login_protection.window", 15*time.Minute)
+	v.SetDefault("login_protection.account_max_failures", 5)
+	v.SetDefault("login_protection.ip_max_failures", 20)
+	v.SetDefault("login_protection.base_lockout", 30*time.Second)
+	v.SetDefault("login_protection.max_lockout", time.Hour)
+
+	v.SetDefault("hashing.algorithm", "argon2id")
+	v.SetDefault("hashing.bcrypt_cost", 10)
+	v.SetDefault("hashing.argon2.memory", 64*1024)
+	v.SetDefault("hashing.argon2.iterations", 3)
+	v.SetDefault("hashing.argon2.parallelism", 2)
+	v.SetDefault("hashing.argon2.salt_length", 16)
+	v.SetDefault("hashing.argon2.key_length", 32)
+
+	v.SetDefault("password_policy.min_length", 8)
+	v.SetDefault("password_policy.max_length", 128)
+	v.SetDefault("password_policy.disallow_user_info", true)
+
+	v.SetDefault("oidc.state_ttl", 10*time.Minute)
+	v.SetDefault("oidc.http_timeout", 10*time.Second)
+
+	v.SetDefault("api_keys.max_per_user", 20)
+	v.SetDefault("api_keys.max_ttl", 0)
+
+	v.SetDefault("content_filter.enabled", true)
+	v.SetDefault("content_filter.reload_interval", time.Minute)
+
+	v.SetDefault("anti_spam.burst", 10)
+	v.SetDefault("anti_spam.refill_interval", 3*time.Second)
+	v.SetDefault("anti_spam.duplicate_window", time.Minute)
+	v.SetDefault("anti_spam.slow_mode", 0)
+
+	v.SetDefault("metrics.enabled", true)
+
+	v.SetDefault("health.check_timeout", 2*time.Second)
+	v.SetDefault("health.cache_ttl", 5*time.Second)
+	v.SetDefault("health.migrations_path", "./migrations")
+	v.SetDefault("health.pool_max_utilization", 0.9)
+	v.SetDefault("health.drain_delay", 5*time.Second)
+
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.url_path", "/v1/traces")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 0.1)
+	v.SetDefault("tracing.export_timeout", 10*time.Second)
+
+	v.SetDefault("cors.allowed_origins", []string{"*"})
+	v.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("cors.allowed_headers", []string{"Origin", "Content-Type", "Authorization", "traceparent", "tracestate", "X-Request-ID", "Idempotency-Key"})
+	v.SetDefault("cors.exposed_headers", []string{"X-Request-ID", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Idempotent-Replayed"})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", 10*time.Minute)
+
+	v.SetDefault("rate_limit.enabled", true)
+	v.SetDefault("rate_limit.store", "memory")
+	v.SetDefault("rate_limit.default.name", "default")
+	v.SetDefault("rate_limit.default.key", "ip")
+	v.SetDefault("rate_limit.default.limit", 300)
+	v.SetDefault("rate_limit.default.window", time.Minute)
+
+	v.SetDefault("idempotency.enabled", true)
+	v.SetDefault("idempotency.ttl", 24*time.Hour)
+	v.SetDefault("idempotency.in_progress_timeout", time.Minute)
+}
+
 // Validate проверяет корректность конфигурации
 func (c *Config) Validate() error {
 	// Проверка сервера
@@ -131,6 +460,190 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid logger format: %s", c.Logger.Format)
 	}
 
+	validOutputs := map[string]bool{"stdout": true, "stderr": true, "file": true}
+	if !validOutputs[c.Logger.Output] {
+		return fmt.Errorf("invalid logger output: %s", c.Logger.Output)
+	}
+	if c.Logger.Output == "file" && (c.Logger.File.Path == "" || c.Logger.File.MaxSizeMB <= 0) {
+		return fmt.Errorf("logger file path and positive max size are required for file output")
+	}
+	if c.Logger.File.MaxAge < 0 || c.Logger.File.MaxBackups < 0 {
+		return fmt.Errorf("logger file max age and max backups must not be negative")
+	}
+	if c.Logger.Sampling.Initial < 0 || c.Logger.Sampling.Thereafter < 0 {
+		return fmt.Errorf("logger sampling counts must not be negative")
+	}
+	if c.Logger.Sampling.Initial > 0 && c.Logger.Sampling.Interval <= 0 {
+		return fmt.Errorf("logger sampling interval must be positive")
+	}
+	if c.Logger.MaxLevelOverride <= 0 {
+		return fmt.Errorf("logger max level override must be positive")
+	}
+
+	// Проверка MFA
+	if c.MFA.Issuer == "" {
+		return fmt.Errorf("mfa issuer is required")
+	}
+	if c.MFA.ChallengeTTL <= 0 {
+		return fmt.Errorf("invalid mfa challenge ttl: %v", c.MFA.ChallengeTTL)
+	}
+	if c.MFA.RecoveryCodeCount <= 0 {
+		return fmt.Errorf("invalid mfa recovery code count: %d", c.MFA.RecoveryCodeCount)
+	}
+	if c.MFA.MaxChallengeAttempts <= 0 {
+		return fmt.Errorf("invalid mfa max challenge attempts: %d", c.MFA.MaxChallengeAttempts)
+	}
+
+	// Проверка почты
+	validMailDrivers := map[string]bool{"smtp": true, "log": true, "file": true}
+	if !validMailDrivers[c.Mail.Driver] {
+		return fmt.Errorf("invalid mail driver: %s", c.Mail.Driver)
+	}
+	if c.Mail.From == "" {
+		return fmt.Errorf("mail from address is required")
+	}
+	if c.Mail.Driver == "smtp" && c.Mail.SMTP.Host == "" {
+		return fmt.Errorf("smtp host is required for smtp mail driver")
+	}
+	if c.Mail.Driver == "file" && c.Mail.OutboxDir == "" {
+		return fmt.Errorf("mail outbox dir is required for file mail driver")
+	}
+
+	// Проверка сброса пароля
+	if c.Password.ResetTokenTTL <= 0 {
+		return fmt.Errorf("invalid password reset token ttl: %v", c.Password.ResetTokenTTL)
+	}
+	if c.Password.ResetURL == "" {
+		return fmt.Errorf("password reset url is required")
+	}
+
+	// Проверка подтверждения email
+	if c.Verification.TokenTTL <= 0 {
+		return fmt.Errorf("invalid verification token ttl: %v", c.Verification.TokenTTL)
+	}
+	if c.Verification.ResendInterval < 0 {
+		return fmt.Errorf("invalid verification resend interval: %v", c.Verification.ResendInterval)
+	}
+	if c.Verification.VerifyURL == "" {
+		return fmt.Errorf("email verification url is required")
+	}
+
+	// Проверка защиты от перебора паролей
+	if c.LoginProtection.Window <= 0 {
+		return fmt.Errorf("invalid login protection window: %v", c.LoginProtection.Window)
+	}
+	if c.LoginProtection.AccountMaxFailures <= 0 || c.LoginProtection.IPMaxFailures <= 0 {
+		return fmt.Errorf("login protection failure thresholds must be positive")
+	}
+	if c.LoginProtection.BaseLockout <= 0 || c.LoginProtection.MaxLockout < c.LoginProtection.BaseLockout {
+		return fmt.Errorf("invalid login protection lockout: base %v, max %v", c.LoginProtection.BaseLockout, c.LoginProtection.MaxLockout)
+	}
+
+	// Проверка хэширования паролей
+	switch c.Hashing.Algorithm {
+	case "argon2id":
+		a := c.Hashing.Argon2
+		if a.Memory < 8*uint32(a.Parallelism) || a.Iterations == 0 || a.Parallelism == 0 {
+			return fmt.Errorf("invalid argon2 parameters: memory=%d iterations=%d parallelism=%d", a.Memory, a.Iterations, a.Parallelism)
+		}
+		if a.SaltLength < 8 || a.KeyLength < 16 {
+			return fmt.Errorf("argon2 salt length must be at least 8 and key length at least 16")
+		}
+	case "bcrypt":
+		if c.Hashing.BcryptCost < 4 || c.Hashing.BcryptCost > 31 {
+			return fmt.Errorf("invalid bcrypt cost: %d", c.Hashing.BcryptCost)
+		}
+	default:
+		return fmt.Errorf("invalid hashing algorithm: %s", c.Hashing.Algorithm)
+	}
+
+	// Проверка политики паролей
+	if c.PasswordPolicy.MinLength < 1 {
+		return fmt.Errorf("password policy min length must be positive")
+	}
+	if c.PasswordPolicy.MaxLength != 0 && c.PasswordPolicy.MaxLength < c.PasswordPolicy.MinLength {
+		return fmt.Errorf("password policy max length must not be less than min length")
+	}
+	if c.Hashing.Algorithm == "bcrypt" && (c.PasswordPolicy.MaxLength == 0 || c.PasswordPolicy.MaxLength > 72) {
+		// bcrypt молча обрезает пароль после 72 байт
+		return fmt.Errorf("password policy max length must be set to at most 72 with bcrypt hashing")
+	}
+
+	// Проверка OIDC провайдеров
+	if len(c.OIDC.Providers) > 0 && c.OIDC.StateTTL <= 0 {
+		return fmt.Errorf("oidc state ttl must be positive")
+	}
+	providerNames := make(map[string]bool, len(c.OIDC.Providers))
+	for _, p := range c.OIDC.Providers {
+		if p.Name == "" || strings.ContainsAny(p.Name, "/?#") {
+			return fmt.Errorf("invalid oidc provider name: %q", p.Name)
+		}
+		if providerNames[p.Name] {
+			return fmt.Errorf("duplicate oidc provider: %s", p.Name)
+		}
+		providerNames[p.Name] = true
+		if p.ClientID == "" {
+			return fmt.Errorf("oidc provider %s: client_id is required", p.Name)
+		}
+		for field, value := range map[string]string{"issuer": p.Issuer, "redirect_url": p.RedirectURL} {
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("oidc provider %s: invalid %s: %q", p.Name, field, value)
+			}
+		}
+	}
+
+	// Проверка API ключей
+	if c.APIKeys.MaxPerUser < 0 || c.APIKeys.MaxTTL < 0 {
+		return fmt.Errorf("api key limits must not be negative")
+	}
+
+	// Проверка фильтра содержимого
+	if c.ContentFilter.Enabled && c.ContentFilter.ReloadInterval <= 0 {
+		return fmt.Errorf("content filter reload interval must be positive")
+	}
+
+	// Проверка проб
+	if c.Health.CheckTimeout <= 0 {
+		return fmt.Errorf("health check timeout must be positive")
+	}
+	if c.Health.CacheTTL < 0 || c.Health.DrainDelay < 0 {
+		return fmt.Errorf("health cache ttl and drain delay must not be negative")
+	}
+	if c.Health.PoolMaxUtilization <= 0 || c.Health.PoolMaxUtilization > 1 {
+		return fmt.Errorf("health pool max utilization must be between 0 and 1")
+	}
+
+	// Проверка трассировки
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		return fmt.Errorf("tracing endpoint is required when tracing is enabled")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+
+	// Проверка CORS
+	if err := c.CORS.validate(); err != nil {
+		return err
+	}
+
+	// Проверка ограничения частоты запросов
+	if err := c.RateLimit.validate(); err != nil {
+		return err
+	}
+
+	// Проверка ключей идемпотентности
+	if c.Idempotency.Enabled && (c.Idempotency.TTL <= 0 || c.Idempotency.InProgressTimeout <= 0) {
+		return fmt.Errorf("idempotency ttl and in-progress timeout must be positive")
+	}
+
+	// Проверка антиспама
+	if c.AntiSpam.Burst < 0 || c.AntiSpam.RefillInterval < 0 || c.AntiSpam.DuplicateWindow < 0 || c.AntiSpam.SlowMode < 0 {
+		return fmt.Errorf("anti-spam limits must not be negative")
+	}
+	if c.AntiSpam.Burst > 0 && c.AntiSpam.RefillInterval == 0 {
+		return fmt.Errorf("anti-spam refill interval is required when burst is set")
+	}
+
 	// Проверка приложения
 	validEnvs := map[string]bool{"development": true, "staging": true, "production": true}
 	if !validEnvs[c.App.Environment] {
@@ -140,6 +653,93 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (c CORSConfig) validate() error {
+	if len(c.AllowedMethods) == 0 {
+		return fmt.Errorf("cors allowed methods are required")
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors max age must not be negative")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			// Браузеры не принимают * вместе с Access-Control-Allow-Credentials
+			if c.AllowCredentials {
+				return fmt.Errorf("cors allowed origin * cannot be used with allow credentials")
+			}
+			continue
+		}
+		// Шаблон поддоменов проверяется как обычный источник без "*."
+		parsed, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+			parsed.Path != "" || parsed.RawQuery != "" || parsed.User != nil || strings.Contains(parsed.Host, "*") {
+			return fmt.Errorf("invalid cors allowed origin %q: expected scheme://host[:port], optionally with *. before the host", origin)
+		}
+	}
+	return nil
+}
+
+func (c RateLimitConfig) validate() error {
+	if c.Store != "memory" && c.Store != "postgres" {
+		return fmt.Errorf("invalid rate limit store: %s", c.Store)
+	}
+	for _, entry := range c.Allowlist {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(entry); err != nil {
+			return fmt.Errorf("invalid rate limit allowlist entry %q: expected IP address or CIDR", entry)
+		}
+	}
+
+	if c.Default.Limit < 0 {
+		return fmt.Errorf("rate limit default limit must not be negative")
+	}
+	if c.Default.Limit > 0 {
+		if err := c.Default.validate(); err != nil {
+			return err
+		}
+	}
+
+	names := map[string]bool{c.Default.Name: true}
+	routes := map[string]bool{}
+	for _, policy := range c.Policies {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("duplicate rate limit policy name: %s", policy.Name)
+		}
+		names[policy.Name] = true
+		if len(policy.Routes) == 0 {
+			return fmt.Errorf("rate limit policy %s has no routes", policy.Name)
+		}
+		for _, route := range policy.Routes {
+			method, path, ok := strings.Cut(route, " ")
+			if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/") {
+				return fmt.Errorf("invalid route %q in rate limit policy %s: expected \"METHOD /path\"", route, policy.Name)
+			}
+			if routes[route] {
+				return fmt.Errorf("route %q is used by several rate limit policies", route)
+			}
+			routes[route] = true
+		}
+	}
+	return nil
+}
+
+func (p RateLimitPolicyConfig) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("rate limit policy name is required")
+	}
+	if p.Key != "ip" && p.Key != "user" && p.Key != "api_key" {
+		return fmt.Errorf("invalid key %q in rate limit policy %s: expected ip, user or api_key", p.Key, p.Name)
+	}
+	if p.Limit <= 0 || p.Window <= 0 {
+		return fmt.Errorf("rate limit policy %s must have positive limit and window", p.Name)
+	}
+	return nil
+}
+
 // GetServerAddress возвращает адрес сервера в формате host:port
 func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
@@ -175,6 +775,15 @@ func (c *Config) Print() {
 	fmt.Printf("Server: %s\n", c.GetServerAddress())
 	fmt.Printf("Database: %s@%s:%d/%s\n", c.Database.Username, c.Database.Host, c.Database.Port, c.Database.Name)
 	fmt.Printf("JWT Expires: %v\n", c.JWT.ExpiresIn)
-	fmt.Printf("Logger: %s level, %s format\n", c.Logger.Level, c.Logger.Format)
+	fmt.Printf("Logger: %s level, %s format, %s output\n", c.Logger.Level, c.Logger.Format, c.Logger.Output)
+	fmt.Printf("Mail: %s driver\n", c.Mail.Driver)
+	fmt.Printf("Password hashing: %s\n", c.Hashing.Algorithm)
+	fmt.Printf("OIDC providers: %d\n", len(c.OIDC.Providers))
+	fmt.Printf("Content filter: enabled=%v\n", c.ContentFilter.Enabled)
+	fmt.Printf("Metrics: enabled=%v\n", c.Metrics.Enabled)
+	fmt.Printf("Tracing: enabled=%v, sample ratio %v\n", c.Tracing.Enabled, c.Tracing.SampleRatio)
+	fmt.Printf("CORS origins: %s\n", strings.Join(c.CORS.AllowedOrigins, ", "))
+	fmt.Printf("Rate limit: enabled=%v, %s store, %d policies\n", c.RateLimit.Enabled, c.RateLimit.Store, len(c.RateLimit.Policies))
+	fmt.Printf("Idempotency: enabled=%v, ttl %v\n", c.Idempotency.Enabled, c.Idempotency.TTL)
 	fmt.Printf("================================\n")
 }
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// RuntimeSettings настройки, которые применяются без перезапуска сервиса.
+// Изменения остальных секций файла вступают в силу только после перезапуска
+type RuntimeSettings struct {
+	LogLevel              string
+	AntiSpam              AntiSpamConfig
+	LoginProtection       LoginProtectionConfig
+	RequireVerifiedToPost bool
+	CORSOrigins           []string
+	RateLimit             RateLimitConfig // store и enabled применяются только при перезапуске
+}
+
+// RuntimeSettings возвращает настройки, применяемые без перезапуска
+func (c *Config) RuntimeSettings() RuntimeSettings {
+	return RuntimeSettings{
+		LogLevel:              c.Logger.Level,
+		AntiSpam:              c.AntiSpam,
+		LoginProtection:       c.LoginProtection,
+		RequireVerifiedToPost: c.Verification.RequireVerifiedToPost,
+		CORSOrigins:           c.CORS.AllowedOrigins,
+		RateLimit:             c.RateLimit,
+	}
+}
+
+// Watcher перечитывает файл конфигурации при изменении и оповещает подписчиков
+type Watcher struct {
+	v    *viper.Viper
+	once sync.Once
+
+	mu          sync.Mutex
+	onChange    []func(RuntimeSettings)
+	onError     []func(error)
+	lastApplied RuntimeSettings
+}
+
+// NewWatcher создает Watcher для конфигурации, прочитанной v; initial - уже примененные настройки
+func NewWatcher(v *viper.Viper, initial *Config) *Watcher {
+	return &Watcher{
+		v:           v,
+		lastApplied: initial.RuntimeSettings(),
+	}
+}
+
+// OnChange регистрирует обработчик, вызываемый после того, как измененный файл конфигурации
+// прочитан и прошел валидацию. Обработчик получает новые значения всех настроек из RuntimeSettings
+func (w *Watcher) OnChange(fn func(RuntimeSettings)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onChange = append(w.onChange, fn)
+}
+
+// OnReloadError регистрирует обработчик ошибок перезагрузки. При ошибке действуют прежние настройки
+func (w *Watcher) OnReloadError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = append(w.onError, fn)
+}
+
+// Start включает отслеживание файла конфигурации; повторные вызовы ничего не делают
+func (w *Watcher) Start() {
+	w.once.Do(func() {
+		w.v.OnConfigChange(func(fsnotify.Event) {
+			w.reload()
+		})
+		w.v.WatchConfig()
+	})
+}
+
+func (w *Watcher) reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Viper при ошибке чтения только пишет в свой лог и оставляет прежние значения,
+	// поэтому файл перечитывается здесь, чтобы сообщить об ошибке подписчикам
+	if err := w.v.ReadInConfig(); err != nil {
+		w.fail(fmt.Errorf("failed to reload config: %w", err))
+		return
+	}
+	config, err := decode(w.v)
+	if err != nil {
+		w.fail(fmt.Errorf("failed to reload config: %w", err))
+		return
+	}
+
+	// Редакторы сохраняют файл несколькими событиями; оповещаем только о фактических изменениях
+	settings := config.RuntimeSettings()
+	if reflect.DeepEqual(settings, w.lastApplied) {
+		return
+	}
+	w.lastApplied = settings
+
+	for _, fn := range w.onChange {
+		fn(settings)
+	}
+}
+
+func (w *Watcher) fail(err error) {
+	for _, fn := range w.onError {
+		fn(err)
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configFile копия configs/config.yaml во временном каталоге
+type configFile struct {
+	t        *testing.T
+	dir      string
+	original string
+}
+
+func newConfigFile(t *testing.T) *configFile {
+	content, err := os.ReadFile(filepath.Join("..", "..", "configs", "config.yaml"))
+	require.NoError(t, err)
+
+	f := &configFile{t: t, dir: t.TempDir(), original: string(content)}
+	f.write(f.original)
+	return f
+}
+
+// write заменяет файл целиком, как это делают редакторы при сохранении
+func (f *configFile) write(content string) {
+	tmp := filepath.Join(f.dir, "config.yaml.tmp")
+	require.NoError(f.t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(f.t, os.Rename(tmp, filepath.Join(f.dir, "config.yaml")))
+}
+
+// replace записывает исходный файл с заменой old на new
+func (f *configFile) replace(old, new string) {
+	require.Contains(f.t, f.original, old)
+	f.write(strings.Replace(f.original, old, new, 1))
+}
+
+// watchRecorder собирает вызовы обработчиков Watcher
+type watchRecorder struct {
+	mu      sync.Mutex
+	changes []RuntimeSettings
+	errors  []error
+}
+
+func newWatchRecorder(w *Watcher) *watchRecorder {
+	r := &watchRecorder{}
+	w.OnChange(func(settings RuntimeSettings) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.changes = append(r.changes, settings)
+	})
+	w.OnReloadError(func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.errors = append(r.errors, err)
+	})
+	return r
+}
+
+func (r *watchRecorder) counts() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.changes), len(r.errors)
+}
+
+func TestWatcher_ReloadRuntimeChange(t *testing.T) {
+	// Arrange
+	file := newConfigFile(t)
+	cfg, watcher, err := Load(file.dir)
+	require.NoError(t, err)
+	require.Equal(t, "info", cfg.Logger.Level)
+	recorder := newWatchRecorder(watcher)
+
+	// Act
+	file.replace(`level: "info"`, `level: "debug"`)
+	watcher.reload()
+
+	// Assert
+	require.Len(t, recorder.changes, 1)
+	assert.Equal(t, "debug", recorder.changes[0].LogLevel)
+	assert.Empty(t, recorder.errors)
+}
+
+func TestWatcher_ReloadWithoutRuntimeChange(t *testing.T) {
+	// Arrange
+	file := newConfigFile(t)
+	_, watcher, err := Load(file.dir)
+	require.NoError(t, err)
+	recorder := newWatchRecorder(watcher)
+
+	// Act: файл сохранен без изменений, затем изменена секция, требующая перезапуска
+	file.write(file.original)
+	watcher.reload()
+	file.replace("port: 8080", "port: 9090")
+	watcher.reload()
+
+	// Assert
+	assert.Empty(t, recorder.changes)
+	assert.Empty(t, recorder.errors)
+}
+
+func TestWatcher_ReloadRepeatedChange(t *testing.T) {
+	// Arrange
+	file := newConfigFile(t)
+	_, watcher, err := Load(file.dir)
+	require.NoError(t, err)
+	recorder := newWatchRecorder(watcher)
+
+	// Act: редактор сохраняет одно изменение несколькими событиями
+	file.replace(`level: "info"`, `level: "warn"`)
+	watcher.reload()
+	watcher.reload()
+
+	// Assert
+	require.Len(t, recorder.changes, 1)
+	assert.Equal(t, "warn", recorder.changes[0].LogLevel)
+}
+
+func TestWatcher_ReloadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		wantErr string
+	}{
+		{name: "invalid yaml", old: "server:", new: "server: [", wantErr: "failed to reload config"},
+		{name: "invalid value", old: `level: "info"`, new: `level: "verbose"`, wantErr: "invalid logger level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			file := newConfigFile(t)
+			_, watcher, err := Load(file.dir)
+			require.NoError(t, err)
+			recorder := newWatchRecorder(watcher)
+
+			// Act
+			file.replace(tt.old, tt.new)
+			watcher.reload()
+
+			// Assert
+			assert.Empty(t, recorder.changes)
+			require.Len(t, recorder.errors, 1)
+			assert.ErrorContains(t, recorder.errors[0], tt.wantErr)
+		})
+	}
+}
+
+func TestWatcher_ReloadAfterError(t *testing.T) {
+	// Arrange
+	file := newConfigFile(t)
+	_, watcher, err := Load(file.dir)
+	require.NoError(t, err)
+	recorder := newWatchRecorder(watcher)
+	file.replace(`level: "info"`, `level: "verbose"`)
+	watcher.reload()
+
+	// Act: исправленный файл с прежними значениями ничего не меняет
+	file.write(file.original)
+	watcher.reload()
+
+	// Assert
+	assert.Empty(t, recorder.changes)
+	assert.Len(t, recorder.errors, 1)
+}
+
+func TestWatcher_Start(t *testing.T) {
+	// Arrange
+	file := newConfigFile(t)
+	_, watcher, err := Load(file.dir)
+	require.NoError(t, err)
+	recorder := newWatchRecorder(watcher)
+	watcher.Start()
+
+	// Act
+	file.replace(`level: "info"`, `level: "error"`)
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		changes, _ := recorder.counts()
+		return changes > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, "error", recorder.changes[len(recorder.changes)-1].LogLevel)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDField имя поля с ID запроса в записях лога
+const RequestIDField = "request_id"
+
+// Поля с ID трассы и спана, по которым запись лога находится в системе трассировки
+const (
+	TraceIDField = "trace_id"
+	SpanIDField  = "span_id"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет ID запроса в контексте
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext возвращает ID запроса из контекста или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextHook добавляет в запись поля запроса из контекста, переданного через WithContext.
+// Благодаря ему logger.WithContext(ctx) в обработчиках, usecase и адаптере
+// помечает записи одного запроса одинаковым request_id
+type ContextHook struct{}
+
+func (ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (ContextHook) Fire(entry *logrus.Entry) error {
+	if requestID := RequestIDFromContext(entry.Context); requestID != "" {
+		entry.Data[RequestIDField] = requestID
+	}
+	if spanCtx := trace.SpanContextFromContext(entry.Context); spanCtx.IsValid() {
+		entry.Data[TraceIDField] = spanCtx.TraceID().String()
+		entry.Data[SpanIDField] = spanCtx.SpanID().String()
+	}
+	return nil
+}
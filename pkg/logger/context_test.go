@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newHookedLogger() (*logrus.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(ContextHook{})
+	return logger, &buf
+}
+
+func TestContextHook_AddsRequestFields(t *testing.T) {
+	// Arrange
+	logger, buf := newHookedLogger()
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-42"), spanCtx)
+
+	// Act
+	logger.WithContext(ctx).Info("handled")
+
+	// Assert
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "req-42", entry[RequestIDField])
+	assert.Equal(t, spanCtx.TraceID().String(), entry[TraceIDField])
+	assert.Equal(t, spanCtx.SpanID().String(), entry[SpanIDField])
+}
+
+func TestContextHook_WithoutRequestContext(t *testing.T) {
+	// Arrange
+	logger, buf := newHookedLogger()
+
+	// Act
+	logger.Info("startup")
+	logger.WithContext(context.Background()).Info("background job")
+
+	// Assert
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &entry))
+		assert.NotContains(t, entry, RequestIDField)
+		assert.NotContains(t, entry, TraceIDField)
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	// Запись без WithContext передает в хук пустой контекст
+	var noContext context.Context
+
+	assert.Equal(t, "req-42", RequestIDFromContext(WithRequestID(context.Background(), "req-42")))
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+	assert.Empty(t, RequestIDFromContext(noContext))
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelStatus текущий уровень логгера
+type LevelStatus struct {
+	Level     string     `json:"level" example:"debug"`
+	BaseLevel string     `json:"base_level" example:"info"` // уровень из конфигурации
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`      // когда временный уровень будет отменен
+}
+
+// LevelController меняет уровень логгера без перезапуска. Базовый уровень задается
+// конфигурацией, а временный уровень через API отменяется автоматически по истечении срока
+type LevelController struct {
+	logger      *logrus.Logger
+	maxOverride time.Duration
+
+	mu        sync.Mutex
+	base      logrus.Level
+	override  *logrus.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// NewLevelController берет текущий уровень logger как базовый; maxOverride ограничивает срок временного уровня
+func NewLevelController(logger *logrus.Logger, maxOverride time.Duration) *LevelController {
+	return &LevelController{
+		logger:      logger,
+		maxOverride: maxOverride,
+		base:        logger.GetLevel(),
+	}
+}
+
+// MaxOverride наибольший срок временного уровня
+func (c *LevelController) MaxOverride() time.Duration {
+	return c.maxOverride
+}
+
+// SetBase меняет базовый уровень. Если действует временный уровень, новый базовый
+// применится после его отмены
+func (c *LevelController) SetBase(level logrus.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.base == level {
+		return
+	}
+	c.base = level
+	if c.override == nil {
+		c.logger.SetLevel(level)
+		c.logger.WithField("level", level.String()).Info("log level changed by configuration")
+	}
+}
+
+// Override устанавливает временный уровень на duration, заменяя предыдущий временный уровень
+func (c *LevelController) Override(level logrus.Level, duration time.Duration) (LevelStatus, error) {
+	if duration <= 0 || duration > c.maxOverride {
+		return LevelStatus{}, fmt.Errorf("override duration must be between 0 and %s", c.maxOverride)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.override = &level
+	c.expiresAt = time.Now().Add(duration)
+	c.timer = time.AfterFunc(duration, c.expire)
+	c.logger.SetLevel(level)
+
+	c.logger.WithFields(logrus.Fields{
+		"level":      level.String(),
+		"expires_at": c.expiresAt,
+	}).Warn("log level temporarily overridden")
+	return c.status(), nil
+}
+
+// Reset отменяет временный уровень и возвращает базовый
+func (c *LevelController) Reset() LevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.restore()
+	return c.status()
+}
+
+// Status возвращает действующий и базовый уровни
+func (c *LevelController) Status() LevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.status()
+}
+
+// expire вызывается таймером по истечении срока временного уровня
+func (c *LevelController) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Таймер мог сработать одновременно с новым Override, который уже продлил срок
+	if c.override == nil || time.Now().Before(c.expiresAt) {
+		return
+	}
+	c.restore()
+}
+
+func (c *LevelController) restore() {
+	if c.override == nil {
+		return
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.override = nil
+	c.expiresAt = time.Time{}
+	c.logger.SetLevel(c.base)
+	c.logger.WithField("level", c.base.String()).Info("log level override reverted")
+}
+
+func (c *LevelController) status() LevelStatus {
+	status := LevelStatus{
+		Level:     c.logger.GetLevel().String(),
+		BaseLevel: c.base.String(),
+	}
+	if c.override != nil {
+		expiresAt := c.expiresAt
+		status.ExpiresAt = &expiresAt
+	}
+	return status
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLevelController(level logrus.Level) (*logrus.Logger, *LevelController) {
+	logger := logrus.New()
+	logger.SetLevel(level)
+	logger.SetOutput(io.Discard)
+	return logger, NewLevelController(logger, time.Hour)
+}
+
+func TestLevelController_OverrideExpires(t *testing.T) {
+	// Arrange
+	logger, levels := newTestLevelController(logrus.InfoLevel)
+
+	// Act
+	status, err := levels.Override(logrus.DebugLevel, 20*time.Millisecond)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "debug", status.Level)
+	assert.Equal(t, "info", status.BaseLevel)
+	require.NotNil(t, status.ExpiresAt)
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == logrus.InfoLevel
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, levels.Status().ExpiresAt)
+}
+
+func TestLevelController_OverrideInvalidDuration(t *testing.T) {
+	_, levels := newTestLevelController(logrus.InfoLevel)
+
+	_, err := levels.Override(logrus.DebugLevel, 0)
+	assert.Error(t, err)
+	_, err = levels.Override(logrus.DebugLevel, 2*time.Hour)
+	assert.Error(t, err)
+}
+
+func TestLevelController_SetBaseDuringOverride(t *testing.T) {
+	// Arrange
+	logger, levels := newTestLevelController(logrus.InfoLevel)
+	_, err := levels.Override(logrus.TraceLevel, time.Hour)
+	require.NoError(t, err)
+
+	// Act: конфигурация меняется, пока действует временный уровень
+	levels.SetBase(logrus.WarnLevel)
+	overridden := logger.GetLevel()
+	status := levels.Reset()
+
+	// Assert
+	assert.Equal(t, logrus.TraceLevel, overridden)
+	assert.Equal(t, logrus.WarnLevel, logger.GetLevel())
+	assert.Equal(t, "warning", status.Level)
+	assert.Nil(t, status.ExpiresAt)
+}
+
+func TestLevelController_OverrideExpiresToChangedBase(t *testing.T) {
+	// Arrange
+	logger, levels := newTestLevelController(logrus.InfoLevel)
+	_, err := levels.Override(logrus.DebugLevel, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	// Act: конфигурация меняется, пока действует временный уровень
+	levels.SetBase(logrus.ErrorLevel)
+
+	// Assert
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == logrus.ErrorLevel
+	}, time.Second, 5*time.Millisecond)
+	status := levels.Status()
+	assert.Equal(t, "error", status.Level)
+	assert.Nil(t, status.ExpiresAt)
+}
+
+func TestLevelController_RenewedOverrideNotRevertedEarly(t *testing.T) {
+	// Arrange
+	logger, levels := newTestLevelController(logrus.InfoLevel)
+	_, err := levels.Override(logrus.DebugLevel, time.Hour)
+	require.NoError(t, err)
+	_, err = levels.Override(logrus.TraceLevel, time.Hour)
+	require.NoError(t, err)
+
+	// Act: таймер первого временного уровня срабатывает после продления
+	levels.expire()
+
+	// Assert
+	assert.Equal(t, logrus.TraceLevel, logger.GetLevel())
+	assert.NotNil(t, levels.Status().ExpiresAt)
+}
+
+func TestLevelController_ExpireAfterReset(t *testing.T) {
+	// Arrange
+	logger, levels := newTestLevelController(logrus.InfoLevel)
+	_, err := levels.Override(logrus.DebugLevel, time.Hour)
+	require.NoError(t, err)
+	levels.Reset()
+	levels.SetBase(logrus.WarnLevel)
+
+	// Act
+	levels.expire()
+
+	// Assert
+	assert.Equal(t, logrus.WarnLevel, logger.GetLevel())
+	assert.Nil(t, levels.Status().ExpiresAt)
+}
@@ -1,15 +1,104 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// Config настройки логгера из секции logger конфигурации
+type Config struct {
+	Level        string
+	Format       string // json или text
+	Output       string // stdout, stderr или file
+	File         FileConfig
+	RedactFields []string // поля, значения которых скрываются; пусто - DefaultRedactFields
+	EmailFields  []string // поля с email, который маскируется частично; пусто - DefaultEmailFields
+	Sampling     SamplingConfig
+}
+
+// FileConfig файл лога с ротацией по размеру и возрасту
+type FileConfig struct {
+	Path       string
+	MaxSizeMB  int           // файл ротируется, достигнув этого размера
+	MaxAge     time.Duration // файл ротируется, если открыт дольше; 0 - только по размеру
+	MaxBackups int           // сколько старых файлов хранить; 0 - все
+	Compress   bool          // сжимать старые файлы gzip
+}
+
+// NewLogger создает логгер по умолчанию: JSON в stdout на уровне Debug.
+// Используется до загрузки конфигурации
 func NewLogger() *logrus.Logger {
 	logger := logrus.New()
 	logger.SetOutput(os.Stdout)
 	logger.SetLevel(logrus.DebugLevel)
 	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(ContextHook{})
 	return logger
 }
+
+// New создает логгер по конфигурации. Возвращаемый io.Closer закрывает файл лога и должен
+// вызываться при завершении приложения
+func New(cfg Config) (*logrus.Logger, io.Closer, error) {
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	var formatter logrus.Formatter
+	switch cfg.Format {
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	case "text":
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	default:
+		return nil, nil, fmt.Errorf("unknown log format: %s", cfg.Format)
+	}
+
+	var (
+		out    io.Writer
+		closer io.Closer = nopCloser{}
+	)
+	switch cfg.Output {
+	case "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	case "file":
+		file, err := newRotatingFile(cfg.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, closer = file, file
+	default:
+		return nil, nil, fmt.Errorf("unknown log output: %s", cfg.Output)
+	}
+
+	redactFields, emailFields := cfg.RedactFields, cfg.EmailFields
+	if len(redactFields) == 0 {
+		redactFields = DefaultRedactFields
+	}
+	if len(emailFields) == 0 {
+		emailFields = DefaultEmailFields
+	}
+	formatter = newRedactingFormatter(formatter, redactFields, emailFields)
+	if cfg.Sampling.Initial > 0 {
+		formatter = newSamplingFormatter(formatter, cfg.Sampling)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetLevel(level)
+	logger.SetFormatter(formatter)
+	logger.AddHook(ContextHook{})
+	return logger, closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
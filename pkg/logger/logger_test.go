@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "level", cfg: Config{Level: "verbose", Format: "json", Output: "stdout"}},
+		{name: "format", cfg: Config{Level: "info", Format: "xml", Output: "stdout"}},
+		{name: "output", cfg: Config{Level: "info", Format: "json", Output: "syslog"}},
+		{name: "file without path", cfg: Config{Level: "info", Format: "json", Output: "file"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := New(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_FileOutput(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	logger, closer, err := New(Config{
+		Level:  "warn",
+		Format: "text",
+		Output: "file",
+		File:   FileConfig{Path: path, MaxSizeMB: 1},
+	})
+	require.NoError(t, err)
+
+	// Act
+	logger.Info("below configured level")
+	logger.WithField("password", "hunter2").Warn("login failed")
+	require.NoError(t, closer.Close())
+
+	// Assert
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "below configured level")
+	assert.Contains(t, string(content), `msg="login failed"`)
+	assert.Contains(t, string(content), "password=\"[REDACTED]\"")
+	assert.NotContains(t, string(content), "hunter2")
+}
+
+func TestRedactingFormatter(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(newRedactingFormatter(&logrus.JSONFormatter{}, DefaultRedactFields, DefaultEmailFields))
+
+	// Act
+	logger.WithFields(logrus.Fields{
+		"email":    "alice@example.com",
+		"to":       "not-an-email",
+		"Token":    "abc123",
+		"user_id":  "42",
+		"password": 12345,
+	}).Info("user login attempt")
+
+	// Assert
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "a***@example.com", entry["email"])
+	assert.Equal(t, Redacted, entry["to"])
+	assert.Equal(t, Redacted, entry["Token"])
+	assert.Equal(t, Redacted, entry["password"])
+	assert.Equal(t, "42", entry["user_id"])
+}
+
+func TestMaskEmail(t *testing.T) {
+	assert.Equal(t, "a***@example.com", MaskEmail("alice@example.com"))
+	assert.Equal(t, "ж***@пример.рф", MaskEmail("женя@пример.рф"))
+	assert.Equal(t, Redacted, MaskEmail("@example.com"))
+	assert.Equal(t, Redacted, MaskEmail("alice"))
+}
+
+func TestSamplingFormatter(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+	sampler := newSamplingFormatter(&logrus.TextFormatter{DisableTimestamp: true}, SamplingConfig{
+		Initial:    2,
+		Thereafter: 3,
+		Interval:   time.Second,
+	})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sampler.now = func() time.Time { return now }
+	logger.SetFormatter(sampler)
+
+	// Act: 8 одинаковых отладочных записей, затем новый интервал
+	for i := 0; i < 8; i++ {
+		logger.Debug("executing database query")
+	}
+	logger.Info("request completed")
+	now = now.Add(time.Second)
+	logger.Debug("executing database query")
+
+	// Assert: 1, 2, 5, 8 из первого интервала, запись Info и первая запись нового интервала
+	assert.Equal(t, 5, strings.Count(buf.String(), "executing database query"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "request completed"))
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	file, err := newRotatingFile(FileConfig{Path: filepath.Join(dir, "app.log"), MaxSizeMB: 1, MaxAge: time.Hour})
+	require.NoError(t, err)
+	defer file.Close()
+
+	now := file.openedAt
+	file.now = func() time.Time { return now }
+
+	// Act
+	_, err = file.Write([]byte("first\n"))
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	_, err = file.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	// Assert: старый файл переименован, новая запись в новом файле
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	rotated, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(rotated))
+
+	current, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(current))
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted заменяет значение скрытого поля
+const Redacted = "[REDACTED]"
+
+// DefaultRedactFields поля, значения которых скрываются полностью
+var DefaultRedactFields = []string{
+	"password", "current_password", "new_password",
+	"token", "access_token", "refresh_token", "id_token", "mfa_token",
+	"secret", "client_secret", "api_key", "authorization",
+}
+
+// DefaultEmailFields поля с адресом email; адрес маскируется частично,
+// домен остается, чтобы можно было разбирать проблемы доставки
+var DefaultEmailFields = []string{"email", "to"}
+
+type redaction int
+
+const (
+	redactFull redaction = iota + 1
+	redactEmail
+)
+
+// redactingFormatter скрывает значения чувствительных полей до форматирования записи
+type redactingFormatter struct {
+	next   logrus.Formatter
+	fields map[string]redaction
+}
+
+func newRedactingFormatter(next logrus.Formatter, redactFields, emailFields []string) *redactingFormatter {
+	fields := make(map[string]redaction, len(redactFields)+len(emailFields))
+	for _, field := range emailFields {
+		fields[strings.ToLower(field)] = redactEmail
+	}
+	for _, field := range redactFields {
+		fields[strings.ToLower(field)] = redactFull
+	}
+	return &redactingFormatter{next: next, fields: fields}
+}
+
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var data logrus.Fields
+	for key, value := range entry.Data {
+		mode, ok := f.fields[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		// Копируем поля только при необходимости: обычно скрывать нечего
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for k, v := range entry.Data {
+				data[k] = v
+			}
+		}
+		data[key] = redactValue(value, mode)
+	}
+
+	if data == nil {
+		return f.next.Format(entry)
+	}
+
+	redacted := *entry
+	redacted.Data = data
+	return f.next.Format(&redacted)
+}
+
+func redactValue(value interface{}, mode redaction) string {
+	if s, ok := value.(string); ok && mode == redactEmail {
+		return MaskEmail(s)
+	}
+	return Redacted
+}
+
+// MaskEmail оставляет первый символ имени и домен: alice@example.com -> a***@example.com.
+// Значение, не похожее на email, скрывается полностью
+func MaskEmail(value string) string {
+	at := strings.LastIndex(value, "@")
+	if at <= 0 {
+		return Redacted
+	}
+	_, size := utf8.DecodeRuneInString(value)
+	return value[:size] + "***" + value[at:]
+}
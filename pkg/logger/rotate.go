@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatingFile пишет в файл, ротируя его по размеру (средствами lumberjack) и по возрасту:
+// файл, открытый дольше MaxAge, ротируется перед очередной записью
+type rotatingFile struct {
+	file   *lumberjack.Logger
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	openedAt time.Time
+}
+
+func newRotatingFile(cfg FileConfig) (*rotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	return &rotatingFile{
+		file: &lumberjack.Logger{
+			Filename:   cfg.Path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			Compress:   cfg.Compress,
+			LocalTime:  true,
+		},
+		maxAge:   cfg.MaxAge,
+		now:      time.Now,
+		openedAt: time.Now(),
+	}, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if now := f.now(); f.maxAge > 0 && now.Sub(f.openedAt) >= f.maxAge {
+		if err := f.file.Rotate(); err != nil {
+			return 0, fmt.Errorf("failed to rotate log file: %w", err)
+		}
+		f.openedAt = now
+	}
+	return f.file.Write(p)
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SamplingConfig прореживание отладочных записей: в каждом интервале записи с одинаковым
+// сообщением пишутся первые Initial раз, а дальше только каждая Thereafter-я
+type SamplingConfig struct {
+	Initial    int // 0 отключает прореживание
+	Thereafter int // 0 - после Initial записи с этим сообщением отбрасываются до конца интервала
+	Interval   time.Duration
+}
+
+// samplingFormatter отбрасывает часть записей уровня Debug и ниже. Записи остальных уровней
+// пишутся всегда. Отброшенная запись форматируется в пустой срез, и logrus ничего не пишет
+type samplingFormatter struct {
+	next   logrus.Formatter
+	config SamplingConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+func newSamplingFormatter(next logrus.Formatter, config SamplingConfig) *samplingFormatter {
+	return &samplingFormatter{
+		next:   next,
+		config: config,
+		now:    time.Now,
+		counts: make(map[string]int),
+	}
+}
+
+func (f *samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level < logrus.DebugLevel || f.keep(entry.Message) {
+		return f.next.Format(entry)
+	}
+	return []byte{}, nil
+}
+
+func (f *samplingFormatter) keep(message string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Счетчики сбрасываются целиком раз в интервал, поэтому число разных сообщений
+	// в памяти ограничено тем, сколько их встретилось за один интервал
+	if now := f.now(); now.Sub(f.windowStart) >= f.config.Interval {
+		f.windowStart = now
+		clear(f.counts)
+	}
+
+	f.counts[message]++
+	n := f.counts[message]
+	if n <= f.config.Initial {
+		return true
+	}
+	return f.config.Thereafter > 0 && (n-f.config.Initial)%f.config.Thereafter == 0
+}